@@ -0,0 +1,94 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package actor runs actors on lfq.MPSC mailboxes.
+//
+// An actor owns its state and a mailbox. Any number of goroutines Send it
+// messages; the actor's single receive loop handles them one at a time,
+// so the state needs no locks. The mailbox is an MPSC queue, matching the
+// actor model's many senders and one receiver.
+package actor
+
+import (
+	"context"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/iox"
+	"code.hybscloud.com/lfq"
+)
+
+// Actor is a mailbox and the handler that processes its messages.
+type Actor[Msg any] struct {
+	mailbox *lfq.MPSC[Msg]
+	handler func(Msg)
+	stopped atomix.Bool // Run has returned
+}
+
+// New creates an actor whose mailbox holds capacity messages, rounded up
+// to the next power of 2, and whose Run loop passes each to handler.
+func New[Msg any](capacity int, handler func(Msg)) *Actor[Msg] {
+	return &Actor[Msg]{mailbox: lfq.NewMPSC[Msg](capacity), handler: handler}
+}
+
+// Send delivers a message to the actor's mailbox (any goroutine).
+// Returns lfq.ErrFull if the mailbox is full.
+func (a *Actor[Msg]) Send(msg *Msg) error {
+	return a.mailbox.Enqueue(msg)
+}
+
+// Receive takes one message from the mailbox and passes it to process.
+// Only the actor's receiving goroutine may call it; use it to drive the
+// actor by hand instead of Run.
+// Returns lfq.ErrEmpty if the mailbox is empty.
+func (a *Actor[Msg]) Receive(process func(Msg)) error {
+	msg, err := a.mailbox.Dequeue()
+	if err != nil {
+		return err
+	}
+	process(msg)
+	return nil
+}
+
+// Run is the actor's receive loop: it passes each message to the handler,
+// backing off while the mailbox is empty, until ctx is done. It returns
+// ctx.Err(); undelivered messages stay in the mailbox.
+func (a *Actor[Msg]) Run(ctx context.Context) error {
+	a.stopped.Store(false)
+	defer a.stopped.Store(true)
+	backoff := iox.Backoff{}
+	for ctx.Err() == nil {
+		if a.Receive(a.handler) != nil {
+			backoff.Wait()
+			continue
+		}
+		backoff.Reset()
+	}
+	return ctx.Err()
+}
+
+// Cap returns the mailbox capacity.
+func (a *Actor[Msg]) Cap() int {
+	return a.mailbox.Cap()
+}
+
+// FanOutActors sends msg to every actor and returns how many accepted it.
+// It backs off and retries while a mailbox is full, giving up on an
+// actor only if its Run loop has stopped.
+func FanOutActors[Msg any](actors []*Actor[Msg], msg *Msg) int {
+	sent := 0
+	for _, a := range actors {
+		backoff := iox.Backoff{}
+		for {
+			if a.Send(msg) == nil {
+				sent++
+				break
+			}
+			if a.stopped.Load() {
+				break
+			}
+			backoff.Wait()
+		}
+	}
+	return sent
+}
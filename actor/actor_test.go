@@ -0,0 +1,67 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/actor"
+)
+
+// TestReceive drives an actor by hand and checks mailbox order.
+func TestReceive(t *testing.T) {
+	a := actor.New[int](4, nil)
+	for i := range 3 {
+		if err := a.Send(&i); err != nil {
+			t.Fatalf("Send(%d): %v", i, err)
+		}
+	}
+	var got []int
+	for a.Receive(func(m int) { got = append(got, m) }) == nil {
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 2 {
+		t.Fatalf("Receive: got %v, want [0 1 2]", got)
+	}
+	if err := a.Receive(func(int) {}); !errors.Is(err, lfq.ErrEmpty) {
+		t.Fatalf("Receive on empty: got %v, want ErrEmpty", err)
+	}
+}
+
+// TestFanOutActors verifies FanOutActors waits for a full mailbox that a
+// running actor drains, and skips a full actor whose Run has stopped.
+func TestFanOutActors(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	received := make(chan int, 8)
+	live := actor.New(2, func(m int) { received <- m })
+	stopped := actor.New[int](2, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stopped.Run(ctx)
+
+	for i := range 2 {
+		live.Send(&i)
+		stopped.Send(&i)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go live.Run(ctx)
+
+	msg := 7
+	if n := actor.FanOutActors([]*actor.Actor[int]{live, stopped}, &msg); n != 1 {
+		t.Fatalf("FanOutActors: got %d, want 1", n)
+	}
+	for _, want := range []int{0, 1, 7} {
+		if got := <-received; got != want {
+			t.Fatalf("received %d, want %d", got, want)
+		}
+	}
+}
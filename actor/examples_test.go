@@ -0,0 +1,74 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+package actor_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"code.hybscloud.com/iox"
+	"code.hybscloud.com/lfq/actor"
+)
+
+// Transaction is a message to a bank account actor: a credit if Amount is
+// positive, a debit if negative.
+type Transaction struct {
+	Amount int
+	Done   *sync.WaitGroup
+}
+
+// Example shows a bank account actor receiving credits and debits from
+// concurrent clients. Only the actor touches the balance, so it needs no
+// lock, and every message is applied exactly once.
+func Example() {
+	balance := 0
+	applied := 0
+	account := actor.New(64, func(tx Transaction) {
+		balance += tx.Amount
+		applied++
+		tx.Done.Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		account.Run(ctx)
+		close(stopped)
+	}()
+
+	// 8 clients each credit 100 ten times and debit 30 ten times
+	const clients, rounds = 8, 10
+	var done sync.WaitGroup
+	done.Add(clients * rounds * 2)
+	var wg sync.WaitGroup
+	for range clients {
+		wg.Go(func() {
+			backoff := iox.Backoff{}
+			for range rounds {
+				for _, amount := range []int{100, -30} {
+					tx := Transaction{Amount: amount, Done: &done}
+					for account.Send(&tx) != nil {
+						backoff.Wait()
+					}
+					backoff.Reset()
+				}
+			}
+		})
+	}
+	wg.Wait()
+	done.Wait()
+	cancel()
+	<-stopped
+
+	fmt.Println("messages:", applied)
+	fmt.Println("balance:", balance)
+
+	// Output:
+	// messages: 160
+	// balance: 5600
+}
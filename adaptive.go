@@ -0,0 +1,186 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// DefaultGOMAXPROCSChangeInterval is how often an AdaptiveMPMC polls
+// GOMAXPROCS by default.
+const DefaultGOMAXPROCSChangeInterval = time.Second
+
+// AdaptiveMPMC is a striped MPMC queue whose shard count follows
+// GOMAXPROCS.
+//
+// Elements are spread round-robin over one MPMC shard per processor. A
+// background goroutine polls runtime.GOMAXPROCS and, when it changes,
+// installs a new generation of shards. Producers register with a
+// generation before enqueueing, so the old generation is retired only
+// after in-flight enqueues finish; consumers keep draining it until it is
+// empty. No element is lost across a re-shard.
+//
+// Re-sharding briefly serializes with producers: the poller waits for
+// producers that entered the old generation to leave it. Enqueue and
+// Dequeue themselves never block. While the old generation drains, the
+// queue can hold up to the old and the new Cap() together.
+//
+// Ordering: FIFO holds per shard only, as in any striped queue.
+//
+// The background goroutine stops when the queue is collected, or earlier
+// with StopMonitor.
+type AdaptiveMPMC[T any] struct {
+	_        pad
+	cur      atomic.Pointer[adaptiveGen[T]] // Generation producers enqueue into
+	_        pad
+	prev     atomic.Pointer[adaptiveGen[T]] // Retiring generation, nil if none
+	_        pad
+	next     atomix.Uint64 // Round-robin shard selector
+	_        pad
+	interval atomix.Int64 // Polling interval in nanoseconds
+	capacity int
+	poller   *poller
+}
+
+type adaptiveGen[T any] struct {
+	generation
+	shards []*MPMC[T]
+}
+
+// NewAdaptiveMPMC creates a striped MPMC queue with one shard per
+// GOMAXPROCS. Each shard holds capacity/GOMAXPROCS elements rounded up to
+// the next power of 2, and at least 2.
+func NewAdaptiveMPMC[T any](capacity int) *AdaptiveMPMC[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewAdaptiveMPMC", "capacity", capacity, 2))
+	}
+
+	q := &AdaptiveMPMC[T]{capacity: capacity}
+	q.cur.Store(newAdaptiveGen[T](capacity, runtime.GOMAXPROCS(0)))
+	q.interval.Store(int64(DefaultGOMAXPROCSChangeInterval))
+	q.poller = startPoller(q, DefaultGOMAXPROCSChangeInterval, (*AdaptiveMPMC[T]).poll)
+	return q
+}
+
+func newAdaptiveGen[T any](capacity, shards int) *adaptiveGen[T] {
+	g := &adaptiveGen[T]{shards: make([]*MPMC[T], shards)}
+	per := max(2, (capacity+shards-1)/shards)
+	for i := range g.shards {
+		g.shards[i] = NewMPMC[T](per)
+	}
+	return g
+}
+
+// Enqueue adds an element to the next shard in round-robin order, trying
+// the others if it is full. Returns ErrWouldBlock if every shard is full.
+func (q *AdaptiveMPMC[T]) Enqueue(elem *T) error {
+	g := enterGeneration(&q.cur)
+	n := uint64(len(g.shards))
+	start := q.next.AddRelaxed(1)
+	err := error(ErrFull)
+	for i := range n {
+		if g.shards[(start+i)%n].Enqueue(elem) == nil {
+			err = nil
+			break
+		}
+	}
+	g.leave()
+	return err
+}
+
+// Dequeue removes an element, preferring the retiring generation while
+// one exists. Returns ErrWouldBlock if every shard is empty.
+func (q *AdaptiveMPMC[T]) Dequeue() (T, error) {
+	start := q.next.AddRelaxed(1)
+	if p := q.prev.Load(); p != nil {
+		if elem, err := p.dequeue(start); err == nil {
+			return elem, nil
+		}
+	}
+	return q.cur.Load().dequeue(start)
+}
+
+func (g *adaptiveGen[T]) dequeue(start uint64) (T, error) {
+	n := uint64(len(g.shards))
+	for i := range n {
+		if elem, err := g.shards[(start+i)%n].Dequeue(); err == nil {
+			return elem, nil
+		}
+	}
+	var zero T
+	return zero, ErrEmpty
+}
+
+func (g *adaptiveGen[T]) drain() {
+	for _, s := range g.shards {
+		s.Drain()
+	}
+}
+
+func (g *adaptiveGen[T]) empty() bool {
+	for _, s := range g.shards {
+		if s.depth() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetGOMAXPROCSChangeInterval sets how often GOMAXPROCS is polled. The new
+// interval applies from the next poll. Panics if d <= 0.
+func (q *AdaptiveMPMC[T]) SetGOMAXPROCSChangeInterval(d time.Duration) {
+	if d <= 0 {
+		panic(belowMinimum("AdaptiveMPMC.SetGOMAXPROCSChangeInterval", "d", int(d), 1))
+	}
+	q.interval.Store(int64(d))
+}
+
+// Shards returns the current number of shards.
+func (q *AdaptiveMPMC[T]) Shards() int {
+	return len(q.cur.Load().shards)
+}
+
+// Cap returns the total capacity of the current shards.
+func (q *AdaptiveMPMC[T]) Cap() int {
+	g := q.cur.Load()
+	return len(g.shards) * g.shards[0].Cap()
+}
+
+// StopMonitor stops the background goroutine. The queue remains usable
+// but no longer follows GOMAXPROCS.
+func (q *AdaptiveMPMC[T]) StopMonitor() {
+	q.poller.stop()
+}
+
+// poll retires the previous generation and re-shards if GOMAXPROCS
+// changed, and returns the delay until the next poll.
+func (q *AdaptiveMPMC[T]) poll() time.Duration {
+	q.retire()
+	if n := runtime.GOMAXPROCS(0); n != q.Shards() {
+		q.reshard(n)
+	}
+	return time.Duration(q.interval.Load())
+}
+
+// reshard installs a generation of n shards. The previous generation must
+// be fully retired first; otherwise reshard defers to a later poll.
+func (q *AdaptiveMPMC[T]) reshard(n int) {
+	if q.prev.Load() != nil {
+		return
+	}
+	q.prev.Store(q.cur.Load())
+	replaceGeneration(&q.cur, newAdaptiveGen[T](q.capacity, n))
+}
+
+// retire drops the previous generation once consumers have emptied it.
+func (q *AdaptiveMPMC[T]) retire() {
+	if p := q.prev.Load(); p != nil && p.idle() && p.empty() {
+		q.prev.Store(nil)
+	}
+}
@@ -0,0 +1,75 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestAdaptiveMPMCReshard raises GOMAXPROCS from 4 to 8 and verifies the
+// queue re-shards without losing elements.
+func TestAdaptiveMPMCReshard(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	q := lfq.NewAdaptiveMPMC[int](256)
+	defer q.StopMonitor()
+	q.SetGOMAXPROCSChangeInterval(time.Millisecond)
+	if q.Shards() != 4 {
+		t.Fatalf("Shards: got %d, want 4", q.Shards())
+	}
+
+	for i := range 100 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	runtime.GOMAXPROCS(8)
+	retryWithTimeout(t, 2*time.Second, func() bool { return q.Shards() == 8 }, "re-shard to 8")
+
+	for i := 100; i < 200; i++ {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	seen := make([]bool, 200)
+	for range 200 {
+		v, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+		if seen[v] {
+			t.Fatalf("duplicate element %d", v)
+		}
+		seen[v] = true
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestMonitorCollected verifies that the background goroutine of
+// AdaptiveMPMC and AutoMPMC does not keep the queue reachable and stops
+// once the queue is collected.
+func TestMonitorCollected(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for range 4 {
+		lfq.NewAdaptiveMPMC[int](64).SetGOMAXPROCSChangeInterval(time.Millisecond)
+		lfq.NewAutoMPMC[int](64, lfq.WithDynamicSelection()).SetGOMAXPROCSChangeInterval(time.Millisecond)
+	}
+	retryWithTimeout(t, 5*time.Second, func() bool {
+		runtime.GC()
+		return runtime.NumGoroutine() <= before
+	}, "goroutines to stop after collection")
+}
@@ -0,0 +1,124 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// AffineMPSC is an MPSC queue whose producers stage elements in private
+// buffers and publish them in batches.
+//
+// Each producer goroutine obtains its own AffineProducer. Elements are
+// appended to the producer's buffer, and a full buffer is flushed to the
+// shared MPSC with a single FAA claiming a contiguous run of slots. Under
+// heavy producer counts this replaces one contended FAA per element with
+// one per batch.
+//
+// Go does not expose processor identity, so affinity is per producer
+// handle rather than per P; a goroutine that keeps its handle keeps its
+// buffer on the same cache lines.
+//
+// Ordering: FIFO holds per producer. Elements from different producers
+// interleave in batches, and staged elements are invisible to the
+// consumer until flushed, so producers call Flush when they go idle.
+type AffineMPSC[T any] struct {
+	q     *MPSC[T]
+	batch int
+}
+
+// AffineProducer is one producer's staging buffer for an AffineMPSC.
+// It is used by a single goroutine.
+type AffineProducer[T any] struct {
+	q   *MPSC[T]
+	buf []T
+}
+
+// NewAffineMPSC creates an affine MPSC queue whose producers flush every
+// batch elements. Capacity rounds up to the next power of 2.
+// Panics if batch < 1 or batch exceeds the capacity.
+func NewAffineMPSC[T any](capacity, batch int) *AffineMPSC[T] {
+	q := NewMPSC[T](capacity)
+	if batch < 1 {
+		panic(belowMinimum("NewAffineMPSC", "batch", batch, 1))
+	}
+	if batch > q.Cap() {
+		panic(aboveMaximum("NewAffineMPSC", "batch", batch, q.Cap()))
+	}
+	return &AffineMPSC[T]{q: q, batch: batch}
+}
+
+// NewProducer returns a producer handle with an empty staging buffer.
+func (q *AffineMPSC[T]) NewProducer() *AffineProducer[T] {
+	return &AffineProducer[T]{q: q.q, buf: make([]T, 0, q.batch)}
+}
+
+// Dequeue removes and returns an element (single consumer only).
+// Returns (zero-value, ErrWouldBlock) if no flushed element is available.
+func (q *AffineMPSC[T]) Dequeue() (T, error) {
+	return q.q.Dequeue()
+}
+
+// Cap returns the capacity of the shared queue.
+func (q *AffineMPSC[T]) Cap() int {
+	return q.q.Cap()
+}
+
+// State returns the shared queue's lifecycle state.
+func (q *AffineMPSC[T]) State() QueueState {
+	return q.q.State()
+}
+
+// BeginDrain moves the shared queue to StateDraining, after which Flush
+// publishes nothing. See [MPMC.BeginDrain].
+func (q *AffineMPSC[T]) BeginDrain() {
+	q.q.BeginDrain()
+}
+
+// Close moves the shared queue to StateClosed. See [MPMC.Close].
+func (q *AffineMPSC[T]) Close() error {
+	return q.q.Close()
+}
+
+// Enqueue stages an element, flushing when the buffer fills.
+// Returns ErrFull if the buffer is full and the shared queue has no room
+// for any of it. Once the shared queue has left StateActive, a
+// flush fails and Enqueue returns its ErrDraining or ErrClosed: without
+// staging elem if the buffer was full, and after staging it otherwise,
+// in which case elem counts in Pending.
+func (p *AffineProducer[T]) Enqueue(elem *T) error {
+	if len(p.buf) == cap(p.buf) {
+		if err := p.Flush(); err != nil && len(p.buf) == cap(p.buf) {
+			return err
+		}
+	}
+	p.buf = append(p.buf, *elem)
+	if len(p.buf) == cap(p.buf) {
+		if err := p.Flush(); err != nil && !IsWouldBlock(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush publishes staged elements to the shared queue.
+// Returns ErrFull if some elements remain staged because the queue is
+// full; they stay in order for the next Flush. Once the queue has
+// left StateActive, Flush publishes nothing and returns ErrDraining or
+// ErrClosed.
+func (p *AffineProducer[T]) Flush() error {
+	if err := p.q.state.enqueueErr(); err != nil {
+		return err
+	}
+	n := p.q.enqueueRun(p.buf)
+	rest := copy(p.buf, p.buf[n:])
+	clear(p.buf[rest:])
+	p.buf = p.buf[:rest]
+	if rest > 0 {
+		return ErrFull
+	}
+	return nil
+}
+
+// Pending returns the number of staged, unflushed elements.
+func (p *AffineProducer[T]) Pending() int {
+	return len(p.buf)
+}
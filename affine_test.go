@@ -0,0 +1,195 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestAffineMPSCStaging tests batching, explicit Flush and a full queue.
+func TestAffineMPSCStaging(t *testing.T) {
+	q := lfq.NewAffineMPSC[int](8, 4)
+	p := q.NewProducer()
+
+	for i := range 3 {
+		if err := p.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue before flush: got %v, want ErrWouldBlock", err)
+	}
+	if p.Pending() != 3 {
+		t.Fatalf("Pending: got %d, want 3", p.Pending())
+	}
+	// The fourth element fills the batch and flushes it
+	v := 3
+	p.Enqueue(&v)
+	if p.Pending() != 0 {
+		t.Fatalf("Pending after batch: got %d, want 0", p.Pending())
+	}
+
+	// Queue holds 4 of 8; stage 6 more, only 4 fit
+	for i := 4; i < 10; i++ {
+		p.Enqueue(&i)
+	}
+	if err := p.Flush(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Flush on full: got %v, want ErrWouldBlock", err)
+	}
+	if p.Pending() != 2 {
+		t.Fatalf("Pending after partial flush: got %d, want 2", p.Pending())
+	}
+
+	for want := range 8 {
+		got, err := q.Dequeue()
+		if err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	for want := 8; want < 10; want++ {
+		if got, err := q.Dequeue(); err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+}
+
+// TestAffineMPSCDraining verifies Enqueue reports the error of a Flush
+// refused by a draining or closed shared queue.
+func TestAffineMPSCDraining(t *testing.T) {
+	q := lfq.NewAffineMPSC[int](8, 2)
+	p := q.NewProducer()
+	v := 1
+	p.Enqueue(&v)
+	q.BeginDrain()
+
+	// The second element fills the batch, whose flush is refused
+	if err := p.Enqueue(&v); !errors.Is(err, lfq.ErrDraining) {
+		t.Fatalf("Enqueue filling the batch: got %v, want ErrDraining", err)
+	}
+	if p.Pending() != 2 {
+		t.Fatalf("Pending: got %d, want 2", p.Pending())
+	}
+	// A full buffer refuses the element itself
+	q.Close()
+	if err := p.Enqueue(&v); !errors.Is(err, lfq.ErrClosed) {
+		t.Fatalf("Enqueue on full buffer: got %v, want ErrClosed", err)
+	}
+	if p.Pending() != 2 {
+		t.Fatalf("Pending after refused Enqueue: got %d, want 2", p.Pending())
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrClosed) {
+		t.Fatalf("Dequeue: got %v, want ErrClosed", err)
+	}
+}
+
+// TestAffineMPSCPerProducerOrder verifies FIFO per producer with several
+// concurrent producers.
+func TestAffineMPSCPerProducerOrder(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const producers, perProducer = 4, 5000
+	q := lfq.NewAffineMPSC[[2]int](64, 8)
+	var wg sync.WaitGroup
+	for id := range producers {
+		p := q.NewProducer()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				v := [2]int{id, i}
+				if p.Enqueue(&v) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+			for p.Flush() != nil {
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	var next [producers]int
+	for received := 0; received < producers*perProducer; {
+		v, err := q.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if v[1] != next[v[0]] {
+			t.Fatalf("producer %d: got %d, want %d", v[0], v[1], next[v[0]])
+		}
+		next[v[0]]++
+		received++
+	}
+	wg.Wait()
+}
+
+// BenchmarkAffineMPSC compares AffineMPSC against MPSC with 16 producers
+// and one consumer.
+func BenchmarkAffineMPSC(b *testing.B) {
+	const producers = 16
+
+	run := func(b *testing.B, enqueue func(id int) func(v int) error, flush func(id int), dequeue func() (int, error)) {
+		perProducer := max(b.N/producers, 1)
+		var wg sync.WaitGroup
+		b.ResetTimer()
+		for id := range producers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				enq := enqueue(id)
+				for i := 0; i < perProducer; {
+					if enq(i) == nil {
+						i++
+					} else {
+						runtime.Gosched()
+					}
+				}
+				flush(id)
+			}()
+		}
+		for n := 0; n < producers*perProducer; {
+			if _, err := dequeue(); err == nil {
+				n++
+			} else {
+				runtime.Gosched()
+			}
+		}
+		wg.Wait()
+	}
+
+	b.Run("MPSC", func(b *testing.B) {
+		q := lfq.NewMPSC[int](1024)
+		run(b, func(int) func(int) error {
+			return func(v int) error { return q.Enqueue(&v) }
+		}, func(int) {}, q.Dequeue)
+	})
+
+	b.Run("AffineMPSC", func(b *testing.B) {
+		q := lfq.NewAffineMPSC[int](1024, 16)
+		ps := make([]*lfq.AffineProducer[int], producers)
+		for i := range ps {
+			ps[i] = q.NewProducer()
+		}
+		run(b, func(id int) func(int) error {
+			return func(v int) error { return ps[id].Enqueue(&v) }
+		}, func(id int) {
+			for ps[id].Flush() != nil {
+				runtime.Gosched()
+			}
+		}, q.Dequeue)
+	})
+}
@@ -0,0 +1,214 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+// Layout checks complementing size_assert_test.go. Sizes catch a field
+// being added; these tests catch a field being added in the wrong place.
+//
+// Two properties matter. 64-bit atomics must sit at 8-byte offsets: on
+// 32-bit platforms Go only guarantees 4-byte alignment for uint64 fields,
+// and a misaligned atomic access panics there (and splits a cache line
+// everywhere else). And each contended atomic must own its cache line:
+// a producer index sharing a line with the consumer index turns every
+// operation into cross-core coherency traffic. The queues get the second
+// property from 64-byte `_ pad` fields rather than from the alignment of
+// the struct itself, since the Go allocator only aligns objects to their
+// size class, not to cache lines.
+
+package lfq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// cacheLine is the cache line size the padding assumes. It is 64 bytes on
+// current amd64 and most arm64 cores; Apple arm64 uses 128-byte lines, on
+// which adjacent hot fields may still share a line pair.
+const cacheLine = 64
+
+// layoutCases lists every core queue type with the reason its layout is
+// sensitive.
+var layoutCases = []struct {
+	name string
+	typ  reflect.Type
+	why  string
+}{
+	{"SPSC", reflect.TypeFor[lfq.SPSC[int]](), "producer and consumer each write one index and cache the other"},
+	{"MPSC", reflect.TypeFor[lfq.MPSC[int]](), "producers FAA tail while the consumer advances head"},
+	{"SPMC", reflect.TypeFor[lfq.SPMC[int]](), "consumers FAA head and decrement the threshold"},
+	{"MPMC", reflect.TypeFor[lfq.MPMC[int]](), "both indices and the threshold are FAA targets"},
+	{"MPSCSeq", reflect.TypeFor[lfq.MPSCSeq[int]](), "producers CAS tail; slot sequences are 64-bit atomics"},
+	{"SPMCSeq", reflect.TypeFor[lfq.SPMCSeq[int]](), "consumers CAS head; slot sequences are 64-bit atomics"},
+	{"MPMCSeq", reflect.TypeFor[lfq.MPMCSeq[int]](), "both indices are CAS targets and Reset bumps the epoch"},
+	{"SPSCCompact", reflect.TypeFor[lfq.SPSCCompact[int]](), "slot sequences carry all synchronization"},
+	{"SPSCIndirect", reflect.TypeFor[lfq.SPSCIndirect](), "assembly hard-codes the field offsets"},
+	{"MPSCIndirect", reflect.TypeFor[lfq.MPSCIndirect](), "producers FAA tail while the consumer advances head"},
+	{"SPMCIndirect", reflect.TypeFor[lfq.SPMCIndirect](), "consumers FAA head and decrement the threshold"},
+	{"MPMCIndirect", reflect.TypeFor[lfq.MPMCIndirect](), "both indices and the threshold are FAA targets"},
+	{"MPSCCompactIndirect", reflect.TypeFor[lfq.MPSCCompactIndirect](), "slots are single 64-bit atomics packing value and round"},
+	{"SPMCCompactIndirect", reflect.TypeFor[lfq.SPMCCompactIndirect](), "slots are single 64-bit atomics packing value and round"},
+	{"MPMCCompactIndirect", reflect.TypeFor[lfq.MPMCCompactIndirect](), "slots are single 64-bit atomics packing value and round"},
+	{"SPSCPtr", reflect.TypeFor[lfq.SPSCPtr](), "producer and consumer each write one index and cache the other"},
+	{"MPSCPtr", reflect.TypeFor[lfq.MPSCPtr](), "producers FAA tail while the consumer advances head"},
+	{"SPMCPtr", reflect.TypeFor[lfq.SPMCPtr](), "consumers FAA head and decrement the threshold"},
+	{"MPMCPtr", reflect.TypeFor[lfq.MPMCPtr](), "both indices and the threshold are FAA targets"},
+}
+
+// isAtomic reports whether t is an atomic type from atomix or sync/atomic.
+func isAtomic(t reflect.Type) bool {
+	switch t.PkgPath() {
+	case "code.hybscloud.com/atomix", "sync/atomic":
+		return true
+	}
+	return false
+}
+
+// checkAtomicAlignment reports 8-byte atomics in t, including nested
+// structs, arrays and slice elements, whose offset from the start of the
+// enclosing allocation is not a multiple of 8.
+func checkAtomicAlignment(t *testing.T, path string, typ reflect.Type, base uintptr, seen map[reflect.Type]bool) {
+	t.Helper()
+	if isAtomic(typ) {
+		if typ.Size() == 8 && base%8 != 0 {
+			t.Errorf("%s: %s at offset %d is not 8-byte aligned", path, typ, base)
+		}
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			checkAtomicAlignment(t, path+"."+f.Name, f.Type, base+f.Offset, seen)
+		}
+	case reflect.Array:
+		if typ.Len() > 0 {
+			// Every element is aligned iff the first is and the stride is
+			checkAtomicAlignment(t, path+"[0]", typ.Elem(), base, seen)
+			if typ.Len() > 1 && typ.Elem().Size()%8 != 0 && hasAtomic(typ.Elem()) {
+				t.Errorf("%s: element size %d breaks 8-byte alignment", path, typ.Elem().Size())
+			}
+		}
+	case reflect.Slice:
+		// Slice backing arrays are separate allocations, aligned to at
+		// least 8 bytes for element types containing 64-bit words
+		if elem := typ.Elem(); !seen[elem] {
+			seen[elem] = true
+			checkAtomicAlignment(t, path+"[]", elem, 0, seen)
+			if hasAtomic(elem) && elem.Size()%8 != 0 {
+				t.Errorf("%s: element size %d breaks 8-byte alignment", path, elem.Size())
+			}
+		}
+	}
+}
+
+// hasAtomic reports whether typ contains an atomic field.
+func hasAtomic(typ reflect.Type) bool {
+	if isAtomic(typ) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			if hasAtomic(typ.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return hasAtomic(typ.Elem())
+	}
+	return false
+}
+
+// TestAtomicFieldAlignment verifies that every 64-bit atomic, in the queue
+// struct and in its slot type, sits at an 8-byte offset.
+func TestAtomicFieldAlignment(t *testing.T) {
+	for _, tc := range layoutCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkAtomicAlignment(t, tc.name, tc.typ, 0, map[reflect.Type]bool{})
+		})
+	}
+}
+
+// TestHotFieldIsolation verifies that each queue begins with a full cache
+// line of padding and that no two top-level atomics share a cache line.
+func TestHotFieldIsolation(t *testing.T) {
+	for _, tc := range layoutCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first := tc.typ.Field(0)
+			if first.Name != "_" || first.Type.Size() != cacheLine {
+				t.Errorf("first field: got %s of %d bytes, want a %d-byte pad (%s)",
+					first.Name, first.Type.Size(), cacheLine, tc.why)
+			}
+
+			var prev *reflect.StructField
+			for i := range tc.typ.NumField() {
+				f := tc.typ.Field(i)
+				if !isAtomic(f.Type) {
+					continue
+				}
+				if prev != nil && f.Offset-prev.Offset < cacheLine {
+					t.Errorf("%s at %d and %s at %d share a cache line (%s)",
+						prev.Name, prev.Offset, f.Name, f.Offset, tc.why)
+				}
+				prev = &f
+			}
+		})
+	}
+}
+
+// TestHeapAlignment verifies that heap-allocated queues are 8-byte
+// aligned, which the atomic offsets above are relative to. Cache-line
+// alignment of the object itself is logged but not required.
+func TestHeapAlignment(t *testing.T) {
+	for _, tc := range layoutCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := reflect.New(tc.typ).Pointer()
+			if addr%8 != 0 {
+				t.Fatalf("heap address %#x is not 8-byte aligned", addr)
+			}
+			t.Logf("size %d, address mod %d = %d", tc.typ.Size(), cacheLine, addr%cacheLine)
+		})
+	}
+}
+
+// TestIndirectHeadTailSeparation verifies that the head and tail indices
+// of the uintptr queues are on different cache lines. For SPSCIndirect it
+// also checks that each cached index shares neither line, since the
+// assembly fast path reads and writes all four.
+func TestIndirectHeadTailSeparation(t *testing.T) {
+	tests := []struct {
+		typ    reflect.Type
+		fields []string
+	}{
+		{reflect.TypeFor[lfq.SPSCIndirect](), []string{"head", "cachedTail", "tail", "cachedHead"}},
+		{reflect.TypeFor[lfq.MPSCIndirect](), []string{"head", "tail"}},
+		{reflect.TypeFor[lfq.SPMCIndirect](), []string{"head", "tail"}},
+		{reflect.TypeFor[lfq.MPMCIndirect](), []string{"head", "tail"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ.Name(), func(t *testing.T) {
+			offsets := make([]uintptr, len(tt.fields))
+			for i, name := range tt.fields {
+				f, ok := tt.typ.FieldByName(name)
+				if !ok {
+					t.Fatalf("no field %s", name)
+				}
+				offsets[i] = f.Offset
+			}
+			for i := range offsets {
+				for j := i + 1; j < len(offsets); j++ {
+					d := max(offsets[i], offsets[j]) - min(offsets[i], offsets[j])
+					if d < cacheLine {
+						t.Errorf("%s at %d and %s at %d are %d bytes apart, want >= %d",
+							tt.fields[i], offsets[i], tt.fields[j], offsets[j], d, cacheLine)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// AnyQueue is an MPMC queue of dynamically typed values, for systems
+// whose element types are only known at runtime, such as reflection-based
+// event buses.
+//
+// Storing a value in an interface usually boxes it on the heap, so
+// Enqueue may allocate once per element. Latency-critical code should
+// prefer a typed queue, or MPMCPtr when the elements are already
+// pointers.
+type AnyQueue struct {
+	q *MPMC[any]
+}
+
+// NewAnyMPMC creates a new MPMC queue of dynamically typed values.
+// Capacity rounds up to the next power of 2.
+func NewAnyMPMC(capacity int) *AnyQueue {
+	return &AnyQueue{q: NewMPMC[any](capacity)}
+}
+
+// Enqueue adds a value to the queue.
+// Returns ErrFull if the queue is full.
+func (q *AnyQueue) Enqueue(val any) error {
+	return q.q.Enqueue(&val)
+}
+
+// Dequeue removes and returns a value from the queue.
+// Returns (nil, ErrEmpty) if the queue is empty.
+func (q *AnyQueue) Dequeue() (any, error) {
+	return q.q.Dequeue()
+}
+
+// Cap returns the queue capacity.
+func (q *AnyQueue) Cap() int {
+	return q.q.Cap()
+}
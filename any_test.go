@@ -0,0 +1,44 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestAnyQueueMixedTypes enqueues values of different types and verifies
+// they dequeue in order with their dynamic types intact.
+func TestAnyQueueMixedTypes(t *testing.T) {
+	type event struct {
+		Name string
+		ID   int
+	}
+
+	q := lfq.NewAnyMPMC(4)
+	for _, v := range []any{42, "hello", event{"click", 7}} {
+		if err := q.Enqueue(v); err != nil {
+			t.Fatalf("Enqueue(%v): %v", v, err)
+		}
+	}
+
+	v, err := q.Dequeue()
+	if n, ok := v.(int); err != nil || !ok || n != 42 {
+		t.Fatalf("Dequeue: got (%#v, %v), want int 42", v, err)
+	}
+	v, err = q.Dequeue()
+	if s, ok := v.(string); err != nil || !ok || s != "hello" {
+		t.Fatalf("Dequeue: got (%#v, %v), want string \"hello\"", v, err)
+	}
+	v, err = q.Dequeue()
+	if e, ok := v.(event); err != nil || !ok || e != (event{"click", 7}) {
+		t.Fatalf("Dequeue: got (%#v, %v), want event{click 7}", v, err)
+	}
+	if v, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) || v != nil {
+		t.Fatalf("Dequeue on empty: got (%v, %v), want (nil, ErrWouldBlock)", v, err)
+	}
+}
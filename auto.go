@@ -0,0 +1,198 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// AutoFAAMinProcs is the smallest GOMAXPROCS at which AutoMPMC selects
+// the FAA-based MPMC. Below it, the Compact MPMCSeq is selected.
+const AutoFAAMinProcs = 4
+
+// AutoMPMC is an MPMC queue that selects its algorithm from GOMAXPROCS:
+// the FAA-based MPMC on 4 or more processors, where its contention
+// scaling pays off, and the Compact MPMCSeq below that, where its n slots
+// (instead of 2n) save memory.
+//
+// The selection is made once at construction. With WithDynamicSelection,
+// a background goroutine polls GOMAXPROCS and migrates to the other
+// algorithm when the selection changes. Migration follows AdaptiveMPMC:
+// producers register with the current queue before enqueueing, the old
+// queue is retired only after in-flight enqueues finish, and consumers
+// drain it before the new one. No element is lost across a migration,
+// and while the old queue drains the two together can hold up to
+// 2×Cap() elements.
+//
+// The background goroutine stops when the queue is collected, or earlier
+// with StopMonitor.
+type AutoMPMC[T any] struct {
+	_        pad
+	cur      atomic.Pointer[autoImpl[T]] // Queue producers enqueue into
+	_        pad
+	prev     atomic.Pointer[autoImpl[T]] // Retiring queue, nil if none
+	_        pad
+	interval atomix.Int64 // Polling interval in nanoseconds
+	draining atomic.Bool
+	dynamic  bool
+	capacity int
+	poller   *poller // Nil without WithDynamicSelection
+}
+
+type autoImpl[T any] struct {
+	generation
+	q interface {
+		Queue[T]
+		depth() int
+	}
+	faa bool
+}
+
+type autoConfig struct {
+	dynamic bool
+}
+
+// AutoMPMCOption configures NewAutoMPMC.
+type AutoMPMCOption func(*autoConfig)
+
+// WithDynamicSelection makes the queue re-evaluate GOMAXPROCS every
+// DefaultGOMAXPROCSChangeInterval and migrate when the selection changes.
+func WithDynamicSelection() AutoMPMCOption {
+	return func(c *autoConfig) { c.dynamic = true }
+}
+
+// NewAutoMPMC creates an MPMC queue whose algorithm is selected from the
+// current GOMAXPROCS. Capacity rounds up to the next power of 2.
+func NewAutoMPMC[T any](capacity int, opts ...AutoMPMCOption) *AutoMPMC[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewAutoMPMC", "capacity", capacity, 2))
+	}
+	var c autoConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	q := &AutoMPMC[T]{capacity: capacity, dynamic: c.dynamic}
+	q.cur.Store(newAutoImpl[T](capacity, autoSelectFAA()))
+	q.interval.Store(int64(DefaultGOMAXPROCSChangeInterval))
+	if c.dynamic {
+		q.poller = startPoller(q, DefaultGOMAXPROCSChangeInterval, (*AutoMPMC[T]).poll)
+	}
+	return q
+}
+
+func autoSelectFAA() bool {
+	return runtime.GOMAXPROCS(0) >= AutoFAAMinProcs
+}
+
+func newAutoImpl[T any](capacity int, faa bool) *autoImpl[T] {
+	if faa {
+		return &autoImpl[T]{q: NewMPMC[T](capacity), faa: true}
+	}
+	return &autoImpl[T]{q: NewMPMCSeq[T](capacity)}
+}
+
+// Enqueue adds an element to the queue.
+// Returns ErrFull if the queue is full.
+func (q *AutoMPMC[T]) Enqueue(elem *T) error {
+	if !q.dynamic {
+		return q.cur.Load().q.Enqueue(elem)
+	}
+	c := enterGeneration(&q.cur)
+	err := c.q.Enqueue(elem)
+	c.leave()
+	return err
+}
+
+// Dequeue removes and returns an element, preferring the retiring queue
+// while one exists. Returns (zero-value, ErrEmpty) if the queue is empty.
+func (q *AutoMPMC[T]) Dequeue() (T, error) {
+	if p := q.prev.Load(); p != nil {
+		if elem, err := p.q.Dequeue(); err == nil {
+			return elem, nil
+		}
+	}
+	return q.cur.Load().q.Dequeue()
+}
+
+// Drain signals that no more enqueues will occur and stops migration.
+// See [Drainer].
+func (q *AutoMPMC[T]) Drain() {
+	q.draining.Store(true)
+	if p := q.prev.Load(); p != nil {
+		p.drain()
+	}
+	q.cur.Load().drain()
+}
+
+func (c *autoImpl[T]) drain() {
+	if d, ok := c.q.(Drainer); ok {
+		d.Drain()
+	}
+}
+
+// FAA reports whether the FAA-based MPMC is currently selected.
+func (q *AutoMPMC[T]) FAA() bool {
+	return q.cur.Load().faa
+}
+
+// SetGOMAXPROCSChangeInterval sets how often GOMAXPROCS is polled under
+// WithDynamicSelection. The new interval applies from the next poll.
+// Panics if d <= 0.
+func (q *AutoMPMC[T]) SetGOMAXPROCSChangeInterval(d time.Duration) {
+	if d <= 0 {
+		panic(belowMinimum("AutoMPMC.SetGOMAXPROCSChangeInterval", "d", int(d), 1))
+	}
+	q.interval.Store(int64(d))
+}
+
+// Cap returns the queue capacity.
+func (q *AutoMPMC[T]) Cap() int {
+	return q.cur.Load().q.Cap()
+}
+
+// StopMonitor stops the background goroutine, if any. The queue remains
+// usable but keeps its current algorithm.
+func (q *AutoMPMC[T]) StopMonitor() {
+	if q.poller != nil {
+		q.poller.stop()
+	}
+}
+
+// poll retires the previous queue and migrates if the selection changed,
+// and returns the delay until the next poll.
+func (q *AutoMPMC[T]) poll() time.Duration {
+	q.retire()
+	if faa := autoSelectFAA(); faa != q.FAA() && !q.draining.Load() {
+		q.migrate(faa)
+	}
+	return time.Duration(q.interval.Load())
+}
+
+// migrate installs a queue of the selected algorithm. The previous queue
+// must be fully retired first; otherwise migrate defers to a later poll.
+func (q *AutoMPMC[T]) migrate(faa bool) {
+	if q.prev.Load() != nil {
+		return
+	}
+	q.prev.Store(q.cur.Load())
+	next := newAutoImpl[T](q.capacity, faa)
+	replaceGeneration(&q.cur, next)
+	// A Drain that raced with the swap may have missed next
+	if q.draining.Load() {
+		next.drain()
+	}
+}
+
+// retire drops the previous queue once consumers have emptied it.
+func (q *AutoMPMC[T]) retire() {
+	if p := q.prev.Load(); p != nil && p.idle() && p.q.depth() == 0 {
+		q.prev.Store(nil)
+	}
+}
@@ -0,0 +1,101 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/testutil"
+)
+
+// TestAutoMPMCSelection verifies the algorithm chosen at construction.
+func TestAutoMPMCSelection(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	for _, procs := range []int{1, 2, 4, 8} {
+		runtime.GOMAXPROCS(procs)
+		q := lfq.NewAutoMPMC[int](8)
+		if want := procs >= lfq.AutoFAAMinProcs; q.FAA() != want {
+			t.Errorf("GOMAXPROCS=%d: FAA got %v, want %v", procs, q.FAA(), want)
+		}
+		if q.Cap() != 8 {
+			t.Errorf("GOMAXPROCS=%d: Cap got %d, want 8", procs, q.Cap())
+		}
+	}
+}
+
+func TestAutoMPMCCompliance(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	for _, procs := range []int{1, lfq.AutoFAAMinProcs} {
+		runtime.GOMAXPROCS(procs)
+		testutil.VerifyQueueCompliance(t, lfq.NewAutoMPMC[int](8), func(i int) int { return i })
+		testutil.VerifyDrainerCompliance[int](t, lfq.NewAutoMPMC[int](16))
+	}
+}
+
+// TestAutoMPMCMigration raises GOMAXPROCS past the FAA threshold and
+// verifies the queue migrates without losing or reordering elements.
+func TestAutoMPMCMigration(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	q := lfq.NewAutoMPMC[int](64, lfq.WithDynamicSelection())
+	defer q.StopMonitor()
+	q.SetGOMAXPROCSChangeInterval(time.Millisecond)
+	if q.FAA() {
+		t.Fatal("FAA selected at GOMAXPROCS=1")
+	}
+
+	for i := range 40 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	runtime.GOMAXPROCS(lfq.AutoFAAMinProcs)
+	retryWithTimeout(t, 2*time.Second, q.FAA, "migrate to FAA")
+
+	for i := 40; i < 80; i++ {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for want := range 80 {
+		got, err := q.Dequeue()
+		if err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue on empty queue: got %v, want ErrEmpty", err)
+	}
+}
+
+func BenchmarkAutoMPMC(b *testing.B) {
+	for _, procs := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			q := lfq.NewAutoMPMC[int](1024)
+			if want := procs >= lfq.AutoFAAMinProcs; q.FAA() != want {
+				b.Fatalf("FAA: got %v, want %v", q.FAA(), want)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				v := 0
+				for pb.Next() {
+					if q.Enqueue(&v) == nil {
+						q.Dequeue()
+					}
+				}
+			})
+		})
+	}
+}
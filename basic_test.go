@@ -92,6 +92,35 @@ func TestMPSCBasic(t *testing.T) {
 	}
 }
 
+// TestMPSCProducerToken tests token issuance and EnqueueWithToken ordering.
+func TestMPSCProducerToken(t *testing.T) {
+	q := lfq.NewMPSC[int](8)
+
+	a, b := q.NewProducerToken(), q.NewProducerToken()
+	if a == b || a == (lfq.ProducerToken{}) {
+		t.Fatalf("NewProducerToken: got %v and %v, want distinct non-zero tokens", a, b)
+	}
+
+	for i := range 4 {
+		tok := a
+		if i%2 == 1 {
+			tok = b
+		}
+		if err := q.EnqueueWithToken(&i, tok); err != nil {
+			t.Fatalf("EnqueueWithToken(%d): %v", i, err)
+		}
+	}
+	for i := range 4 {
+		val, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue(%d): %v", i, err)
+		}
+		if val != i {
+			t.Fatalf("Dequeue(%d): got %d, want %d", i, val, i)
+		}
+	}
+}
+
 // TestSPMCBasic tests basic SPMC (Single Producer, Multiple Consumer) operations.
 // SPMC provides wait-free enqueue and lock-free dequeue.
 func TestSPMCBasic(t *testing.T) {
@@ -164,6 +193,45 @@ func TestMPMCBasic(t *testing.T) {
 	}
 }
 
+// TestDequeueInto tests DequeueInto on every generic queue algorithm:
+// FIFO delivery into dst, and dst left unchanged on an empty queue.
+func TestDequeueInto(t *testing.T) {
+	type intoQueue interface {
+		lfq.Queue[int]
+		DequeueInto(dst *int) error
+	}
+	queues := []struct {
+		name string
+		q    intoQueue
+	}{
+		{"SPSC", lfq.NewSPSC[int](4)},
+		{"SPSCCompact", lfq.NewSPSCCompact[int](4)},
+		{"MPSC", lfq.NewMPSC[int](4)},
+		{"SPMC", lfq.NewSPMC[int](4)},
+		{"MPMC", lfq.NewMPMC[int](4)},
+		{"MPSCSeq", lfq.NewMPSCSeq[int](4)},
+		{"SPMCSeq", lfq.NewSPMCSeq[int](4)},
+		{"MPMCSeq", lfq.NewMPMCSeq[int](4)},
+	}
+	for _, c := range queues {
+		t.Run(c.name, func(t *testing.T) {
+			for i := range 3 {
+				c.q.Enqueue(&i)
+			}
+			for i := range 3 {
+				dst := -1
+				if err := c.q.DequeueInto(&dst); err != nil || dst != i {
+					t.Fatalf("DequeueInto: got (%d, %v), want (%d, nil)", dst, err, i)
+				}
+			}
+			dst := -1
+			if err := c.q.DequeueInto(&dst); !errors.Is(err, lfq.ErrEmpty) || dst != -1 {
+				t.Fatalf("DequeueInto on empty: got (%d, %v), want (-1, ErrEmpty)", dst, err)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // Compact Queues - Basic Operations (63-bit values)
 // =============================================================================
@@ -554,11 +622,11 @@ func TestZeroValue(t *testing.T) {
 	})
 }
 
-// TestNilPointer tests that nil is a valid pointer value.
+// TestNilPointer tests that EnqueueNilable accepts nil as a sentinel.
 func TestNilPointer(t *testing.T) {
 	q := lfq.NewMPMCPtr(4)
 
-	if err := q.Enqueue(nil); err != nil {
+	if err := q.EnqueueNilable(nil); err != nil {
 		t.Fatalf("enqueue nil: %v", err)
 	}
 
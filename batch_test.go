@@ -0,0 +1,203 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+func ptrs(vals ...int) []*int {
+	out := make([]*int, len(vals))
+	for i := range vals {
+		out[i] = &vals[i]
+	}
+	return out
+}
+
+// TestMPMCEnqueueBatch tests FIFO order, partial batches and wrap-around.
+func TestMPMCEnqueueBatch(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+
+	if n, err := q.EnqueueBatch(nil); n != 0 || err != nil {
+		t.Fatalf("EnqueueBatch(nil): got (%d, %v), want (0, nil)", n, err)
+	}
+
+	for round := range 5 {
+		if n, err := q.EnqueueBatch(ptrs(1, 2, 3)); n != 3 || err != nil {
+			t.Fatalf("round %d: EnqueueBatch: got (%d, %v), want (3, nil)", round, n, err)
+		}
+		if n, err := q.EnqueueBatch(ptrs(4, 5)); n != 1 || !errors.Is(err, lfq.ErrWouldBlock) {
+			t.Fatalf("round %d: EnqueueBatch on nearly full: got (%d, %v), want (1, ErrWouldBlock)", round, n, err)
+		}
+		for want := 1; want <= 4; want++ {
+			got, err := q.Dequeue()
+			if err != nil || got != want {
+				t.Fatalf("round %d: Dequeue: got (%d, %v), want (%d, nil)", round, got, err, want)
+			}
+		}
+		if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+			t.Fatalf("round %d: Dequeue on empty: got %v, want ErrWouldBlock", round, err)
+		}
+	}
+}
+
+// TestMPMCEnqueueBatchConcurrent runs batching producers against plain
+// consumers and verifies every element arrives exactly once.
+func TestMPMCEnqueueBatchConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const producers, perProducer, batch = 4, 4000, 8
+	q := lfq.NewMPMC[int](64)
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vals := make([]int, perProducer)
+			for i := range vals {
+				vals[i] = p*perProducer + i
+			}
+			items := ptrs(vals...)
+			for len(items) > 0 {
+				n, _ := q.EnqueueBatch(items[:min(batch, len(items))])
+				items = items[n:]
+				if n == 0 {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	seen := make([]bool, producers*perProducer)
+	for received := 0; received < len(seen); {
+		v, err := q.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if seen[v] {
+			t.Fatalf("duplicate element %d", v)
+		}
+		seen[v] = true
+		received++
+	}
+	wg.Wait()
+}
+
+// BenchmarkMPMCEnqueueBatch compares per-element Enqueue against
+// EnqueueBatch with 8 producers.
+func BenchmarkMPMCEnqueueBatch(b *testing.B) {
+	for _, size := range []int{4, 8, 16} {
+		items := ptrs(make([]int, size)...)
+
+		b.Run(fmt.Sprintf("batch=%d/Enqueue", size), func(b *testing.B) {
+			q := lfq.NewMPMC[int](4096)
+			b.SetParallelism(8)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					for _, it := range items {
+						q.Enqueue(it)
+					}
+					for range items {
+						q.Dequeue()
+					}
+				}
+			})
+		})
+
+		b.Run(fmt.Sprintf("batch=%d/EnqueueBatch", size), func(b *testing.B) {
+			q := lfq.NewMPMC[int](4096)
+			b.SetParallelism(8)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.EnqueueBatch(items)
+					for range items {
+						q.Dequeue()
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestDequeueMany tests partial batches, FIFO order, and wrap-around for
+// each queue offering DequeueMany.
+func TestDequeueMany(t *testing.T) {
+	type manyQueue interface {
+		lfq.Queue[int]
+		DequeueMany(dst []*int) (int, error)
+	}
+	queues := []struct {
+		name string
+		q    manyQueue
+	}{
+		{"SPSC", lfq.NewSPSC[int](4)},
+		{"SPSCCompact", lfq.NewSPSCCompact[int](4)},
+		{"MPSC", lfq.NewMPSC[int](4)},
+		{"MPMC", lfq.NewMPMC[int](4)},
+	}
+	for _, c := range queues {
+		t.Run(c.name, func(t *testing.T) {
+			if n, err := c.q.DequeueMany(nil); n != 0 || err != nil {
+				t.Fatalf("DequeueMany(nil): got (%d, %v), want (0, nil)", n, err)
+			}
+			dst := make([]*int, 2)
+			for round := range 5 {
+				for i := range 3 {
+					v := round*10 + i
+					c.q.Enqueue(&v)
+				}
+				if n, err := c.q.DequeueMany(dst); n != 2 || err != nil || *dst[0] != round*10 || *dst[1] != round*10+1 {
+					t.Fatalf("round %d: DequeueMany: got (%d, %v)", round, n, err)
+				}
+				if n, err := c.q.DequeueMany(dst); n != 1 || err != nil || *dst[0] != round*10+2 {
+					t.Fatalf("round %d: DequeueMany remainder: got (%d, %v)", round, n, err)
+				}
+				if n, err := c.q.DequeueMany(dst); n != 0 || !errors.Is(err, lfq.ErrEmpty) {
+					t.Fatalf("round %d: DequeueMany on empty: got (%d, %v), want (0, ErrEmpty)", round, n, err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDequeueMany compares a loop of Dequeue calls against one
+// DequeueMany per batch of 16 on a single-threaded MPSC.
+func BenchmarkDequeueMany(b *testing.B) {
+	const batch = 16
+	items := ptrs(make([]int, batch)...)
+
+	b.Run("Dequeue", func(b *testing.B) {
+		q := lfq.NewMPSC[int](1024)
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, it := range items {
+				q.Enqueue(it)
+			}
+			for range batch {
+				q.Dequeue()
+			}
+		}
+	})
+	b.Run("DequeueMany", func(b *testing.B) {
+		q := lfq.NewMPSC[int](1024)
+		dst := make([]*int, batch)
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, it := range items {
+				q.Enqueue(it)
+			}
+			q.DequeueMany(dst)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package bench runs comparative lfq benchmarks in a fixed order and
+// compares their results with a saved baseline.
+//
+// A regression check captures a baseline, makes a change and compares:
+//
+//	go test -run '^$' -bench '^BenchmarkStandard$' ./bench -args -bench.save=base.json
+//	# ... change the code ...
+//	go test -run '^$' -bench '^BenchmarkStandard$' -v ./bench -args -bench.baseline=base.json
+//
+// The second run logs, shown with -v, a [ComparisonTable] of every case's
+// ns/op against the baseline. Results are from one process on one
+// machine; compare runs from the same machine, preferably with -count and
+// -benchtime large enough for the noise to settle.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+// BenchmarkCase is one benchmark of a suite.
+type BenchmarkCase struct {
+	Name string
+
+	// Setup prepares fresh state before each measured run, outside the
+	// timer. It may be nil.
+	Setup func()
+
+	// Ops performs n operations on the state Setup prepared.
+	Ops func(n int)
+}
+
+// BenchmarkSuite runs benchmark cases as sub-benchmarks in a fixed order
+// and records their results.
+type BenchmarkSuite struct {
+	// Cases run when Run is given none.
+	Cases []BenchmarkCase
+}
+
+// Standard returns the suite of [StandardCases] at capacity 1024.
+func Standard() *BenchmarkSuite {
+	return &BenchmarkSuite{Cases: StandardCases(1024)}
+}
+
+// Run runs cases, or s.Cases if cases is nil, as sub-benchmarks of b in
+// order, and returns the ns/op each reported. Cases skipped by the -bench
+// filter are absent from the results.
+func (s *BenchmarkSuite) Run(b *testing.B, cases []BenchmarkCase) *BenchmarkResults {
+	if cases == nil {
+		cases = s.Cases
+	}
+	res := &BenchmarkResults{}
+	for _, c := range cases {
+		var r Result
+		ran := b.Run(c.Name, func(b *testing.B) {
+			if c.Setup != nil {
+				c.Setup()
+			}
+			b.ResetTimer()
+			c.Ops(b.N)
+			b.StopTimer()
+			// The last run, with the largest b.N, overwrites earlier ones
+			r = Result{Name: c.Name, N: b.N, NsPerOp: float64(b.Elapsed().Nanoseconds()) / float64(b.N)}
+		})
+		if ran && r.N > 0 {
+			res.Results = append(res.Results, r)
+		}
+	}
+	return res
+}
+
+// Result is the outcome of one benchmark case.
+type Result struct {
+	Name    string  `json:"name"`
+	N       int     `json:"n"`
+	NsPerOp float64 `json:"ns_per_op"`
+}
+
+// BenchmarkResults holds the results of a suite run, in run order.
+type BenchmarkResults struct {
+	Results []Result `json:"results"`
+}
+
+// Save writes the results to w as JSON, to serve as a baseline.
+func (r *BenchmarkResults) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Load reads results written by Save.
+func Load(rd io.Reader) (*BenchmarkResults, error) {
+	var r BenchmarkResults
+	if err := json.NewDecoder(rd).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Compare returns the change of each result relative to baseline, in the
+// order of r. Cases missing from baseline have a NaN delta.
+func (r *BenchmarkResults) Compare(baseline *BenchmarkResults) ComparisonTable {
+	base := make(map[string]float64, len(baseline.Results))
+	for _, b := range baseline.Results {
+		base[b.Name] = b.NsPerOp
+	}
+	t := ComparisonTable{Rows: make([]ComparisonRow, 0, len(r.Results))}
+	for _, c := range r.Results {
+		row := ComparisonRow{Name: c.Name, Base: math.NaN(), Current: c.NsPerOp, Delta: math.NaN()}
+		if b, ok := base[c.Name]; ok {
+			row.Base = b
+			if b > 0 {
+				row.Delta = (c.NsPerOp - b) / b * 100
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// ComparisonRow compares one case's ns/op with its baseline.
+type ComparisonRow struct {
+	Name    string
+	Base    float64 // Baseline ns/op, NaN if absent
+	Current float64 // ns/op
+	Delta   float64 // Change in percent; negative is faster
+}
+
+// ComparisonTable is the result of [BenchmarkResults.Compare].
+type ComparisonTable struct {
+	Rows []ComparisonRow
+}
+
+// Regressions returns the rows slower than their baseline by more than
+// threshold percent.
+func (t ComparisonTable) Regressions(threshold float64) []ComparisonRow {
+	var out []ComparisonRow
+	for _, row := range t.Rows {
+		if row.Delta > threshold {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// String formats the table with one aligned row per case:
+//
+//	name              base ns/op  ns/op   delta
+//	MPMC/Generic/4P4C 120.0       110.4   -8.0%
+func (t ComparisonTable) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "name\tbase ns/op\tns/op\tdelta")
+	for _, row := range t.Rows {
+		base, delta := "-", "new"
+		if !math.IsNaN(row.Base) {
+			base = fmt.Sprintf("%.1f", row.Base)
+		}
+		if !math.IsNaN(row.Delta) {
+			delta = fmt.Sprintf("%+.1f%%", row.Delta)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.1f\t%s\n", row.Name, base, row.Current, delta)
+	}
+	w.Flush()
+	return sb.String()
+}
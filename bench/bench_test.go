@@ -0,0 +1,137 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package bench_test
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/bench"
+)
+
+var (
+	saveTo   = flag.String("bench.save", "", "write BenchmarkStandard results to this file")
+	baseline = flag.String("bench.baseline", "", "compare BenchmarkStandard results with this file")
+)
+
+// BenchmarkStandard runs the standard suite. See the package
+// documentation for capturing and comparing a baseline.
+func BenchmarkStandard(b *testing.B) {
+	res := bench.Standard().Run(b, nil)
+	if *saveTo != "" {
+		f, err := os.Create(*saveTo)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer f.Close()
+		if err := res.Save(f); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if *baseline != "" {
+		f, err := os.Open(*baseline)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer f.Close()
+		base, err := bench.Load(f)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Logf("\n%s", res.Compare(base))
+	}
+}
+
+func TestStandardCases(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	cases := bench.StandardCases(64)
+	names := map[string]bool{}
+	for _, c := range cases {
+		if names[c.Name] {
+			t.Fatalf("duplicate case %q", c.Name)
+		}
+		names[c.Name] = true
+	}
+	for _, want := range []string{"SPSC/Generic/1P1C", "MPSC/Ptr/Compact/8P1C", "SPMC/Indirect/1P4C", "MPMC/Generic/8P8C"} {
+		if !names[want] {
+			t.Fatalf("missing case %q", want)
+		}
+	}
+	if names["SPSC/Generic/4P4C"] || names["SPSC/Generic/Compact/1P1C"] {
+		t.Fatal("SPSC case with more than one producer or Compact")
+	}
+	// Ops returns only once every element has been dequeued
+	for _, c := range cases {
+		c.Setup()
+		c.Ops(1000)
+	}
+}
+
+// TestSuiteRun verifies Run reports every case in order.
+func TestSuiteRun(t *testing.T) {
+	var calls []string
+	cases := []bench.BenchmarkCase{
+		{Name: "b", Ops: func(n int) { calls = append(calls, "b") }},
+		{Name: "a", Setup: func() { calls = append(calls, "setup") }, Ops: func(n int) {}},
+	}
+	var res *bench.BenchmarkResults
+	testing.Benchmark(func(b *testing.B) {
+		res = (&bench.BenchmarkSuite{Cases: cases}).Run(b, nil)
+	})
+	if len(res.Results) != 2 || res.Results[0].Name != "b" || res.Results[1].Name != "a" {
+		t.Fatalf("results: got %+v, want cases b then a", res.Results)
+	}
+	if calls[0] != "b" || !strings.Contains(strings.Join(calls, ","), "setup") {
+		t.Fatalf("calls: got %v", calls)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := &bench.BenchmarkResults{Results: []bench.Result{
+		{Name: "x", N: 1, NsPerOp: 100},
+		{Name: "y", N: 1, NsPerOp: 50},
+	}}
+	var buf bytes.Buffer
+	if err := base.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := bench.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cur := &bench.BenchmarkResults{Results: []bench.Result{
+		{Name: "y", N: 1, NsPerOp: 60},
+		{Name: "x", N: 1, NsPerOp: 90},
+		{Name: "z", N: 1, NsPerOp: 10},
+	}}
+	table := cur.Compare(loaded)
+	want := []struct {
+		name  string
+		delta float64
+	}{{"y", 20}, {"x", -10}, {"z", math.NaN()}}
+	for i, w := range want {
+		row := table.Rows[i]
+		if row.Name != w.name || !(row.Delta == w.delta || math.IsNaN(w.delta) && math.IsNaN(row.Delta)) {
+			t.Fatalf("row %d: got %+v, want %s with delta %v", i, row, w.name, w.delta)
+		}
+	}
+	if r := table.Regressions(5); len(r) != 1 || r[0].Name != "y" {
+		t.Fatalf("Regressions(5): got %+v, want [y]", r)
+	}
+	s := table.String()
+	for _, sub := range []string{"+20.0%", "-10.0%", "new"} {
+		if !strings.Contains(s, sub) {
+			t.Fatalf("String missing %q:\n%s", sub, s)
+		}
+	}
+}
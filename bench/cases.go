@@ -0,0 +1,172 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package bench
+
+import (
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// Concurrency is a number of producer and consumer goroutines.
+type Concurrency struct {
+	Producers, Consumers int
+}
+
+func (c Concurrency) String() string {
+	return strconv.Itoa(c.Producers) + "P" + strconv.Itoa(c.Consumers) + "C"
+}
+
+// StandardConcurrency lists the concurrency levels of StandardCases.
+var StandardConcurrency = []Concurrency{{1, 1}, {4, 4}, {8, 8}}
+
+// StandardCases returns a case for every queue the Builder creates, at
+// the given capacity and at each of StandardConcurrency: the four
+// topologies, the Generic, Indirect and Ptr flavors, and FAA and Compact
+// algorithms. Single-producer and single-consumer queues clamp their
+// side to one goroutine, which leaves SPSC with 1P1C only.
+//
+// Case names are "<topology>/<flavor>[/Compact]/<P>P<C>C", e.g.
+// "MPMC/Indirect/Compact/4P4C". An operation is one element passed from
+// a producer to a consumer.
+func StandardCases(capacity int) []BenchmarkCase {
+	var cases []BenchmarkCase
+	for _, topo := range []struct {
+		name   string
+		sp, sc bool
+	}{{"SPSC", true, true}, {"MPSC", false, true}, {"SPMC", true, false}, {"MPMC", false, false}} {
+		var levels []Concurrency
+		for _, c := range StandardConcurrency {
+			if topo.sp {
+				c.Producers = 1
+			}
+			if topo.sc {
+				c.Consumers = 1
+			}
+			if !slices.Contains(levels, c) {
+				levels = append(levels, c)
+			}
+		}
+		for _, flavor := range []string{"Generic", "Indirect", "Ptr"} {
+			for _, compact := range []bool{false, true} {
+				if compact && topo.sp && topo.sc {
+					continue // SPSC ignores Compact
+				}
+				name := topo.name + "/" + flavor
+				if compact {
+					name += "/Compact"
+				}
+				for _, c := range levels {
+					cases = append(cases, queueCase(name+"/"+c.String(), c, func() queueOps {
+						b := lfq.New(capacity)
+						if topo.sp {
+							b.SingleProducer()
+						}
+						if topo.sc {
+							b.SingleConsumer()
+						}
+						if compact {
+							b.Compact()
+						}
+						return newQueueOps(b, flavor)
+					}))
+				}
+			}
+		}
+	}
+	return cases
+}
+
+// queueOps tries one enqueue or dequeue on a queue of any flavor.
+type queueOps struct {
+	enqueue func() bool
+	dequeue func() bool
+}
+
+// payload is the element Ptr queues carry.
+var payload int
+
+func newQueueOps(b *lfq.Builder, flavor string) queueOps {
+	switch flavor {
+	case "Indirect":
+		q := b.BuildIndirect()
+		return queueOps{
+			enqueue: func() bool { return q.Enqueue(1) == nil },
+			dequeue: func() bool { _, err := q.Dequeue(); return err == nil },
+		}
+	case "Ptr":
+		q := b.BuildPtr()
+		p := unsafe.Pointer(&payload)
+		return queueOps{
+			enqueue: func() bool { return q.Enqueue(p) == nil },
+			dequeue: func() bool { _, err := q.Dequeue(); return err == nil },
+		}
+	default:
+		q := lfq.Build[int](b)
+		v := 1
+		return queueOps{
+			enqueue: func() bool { return q.Enqueue(&v) == nil },
+			dequeue: func() bool { _, err := q.Dequeue(); return err == nil },
+		}
+	}
+}
+
+// queueCase builds a fresh queue in Setup and passes n elements from
+// c.Producers to c.Consumers in Ops.
+func queueCase(name string, c Concurrency, build func() queueOps) BenchmarkCase {
+	var q queueOps
+	return BenchmarkCase{
+		Name:  name,
+		Setup: func() { q = build() },
+		Ops:   func(n int) { transfer(q, n, c) },
+	}
+}
+
+// transfer enqueues n elements split over c.Producers goroutines and
+// dequeues them with c.Consumers goroutines, yielding when the queue is
+// full or empty so that oversubscribed runs make progress.
+func transfer(q queueOps, n int, c Concurrency) {
+	const flushEvery = 64
+	var wg sync.WaitGroup
+	var consumed atomic.Int64
+	for i := range c.Producers {
+		share := n / c.Producers
+		if i < n%c.Producers {
+			share++
+		}
+		wg.Go(func() {
+			for range share {
+				for !q.enqueue() {
+					runtime.Gosched()
+				}
+			}
+		})
+	}
+	for range c.Consumers {
+		wg.Go(func() {
+			local := int64(0)
+			for {
+				if q.dequeue() {
+					if local++; local == flushEvery {
+						consumed.Add(local)
+						local = 0
+					}
+					continue
+				}
+				if consumed.Add(local) >= int64(n) {
+					return
+				}
+				local = 0
+				runtime.Gosched()
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -41,6 +41,39 @@ func BenchmarkSPSCIndirect_SingleOp(b *testing.B) {
 	}
 }
 
+// BenchmarkSPSCIndirect_EnqueueMany compares EnqueueMany against individual
+// Enqueue calls for 1024 items.
+func BenchmarkSPSCIndirect_EnqueueMany(b *testing.B) {
+	vals := make([]uintptr, 1024)
+	for i := range vals {
+		vals[i] = uintptr(i)
+	}
+
+	b.Run("Enqueue", func(b *testing.B) {
+		q := lfq.NewSPSCIndirect(1024)
+		b.ResetTimer()
+		for range b.N {
+			for _, v := range vals {
+				q.Enqueue(v)
+			}
+			for range vals {
+				q.Dequeue()
+			}
+		}
+	})
+
+	b.Run("EnqueueMany", func(b *testing.B) {
+		q := lfq.NewSPSCIndirect(1024)
+		b.ResetTimer()
+		for range b.N {
+			q.EnqueueMany(vals)
+			for range vals {
+				q.Dequeue()
+			}
+		}
+	})
+}
+
 func BenchmarkSPSCPtr_SingleOp(b *testing.B) {
 	q := lfq.NewSPSCPtr(1024)
 	val := 42
@@ -0,0 +1,113 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// BenchmarkAllocationRate measures allocations of one Enqueue+Dequeue
+// round trip per queue flavor, single-threaded. A flavor allocating more
+// than its expected count fails the benchmark.
+//
+// Expected allocations per round trip:
+//
+//	Generic[int]     0  value copied into the slot
+//	Generic[string]  0  header copied; the backing bytes already exist
+//	Indirect         0  uintptr, invisible to the GC
+//	Ptr              0  pointer allocated once by the caller
+//
+// Generic slots holding reference types (string, pointers, slices) are
+// scanned by the GC, unlike int and Indirect slots, but storing them does
+// not allocate.
+//
+//	go test -run=^$ -bench=AllocationRate -benchmem
+func BenchmarkAllocationRate(b *testing.B) {
+	const capacity = 1024
+	s := "payload"
+	obj := new(int)
+
+	variants := []struct {
+		name   string
+		allocs float64
+		op     func()
+	}{
+		{"MPMC[int]", 0, roundTrip(lfq.NewMPMC[int](capacity), 42)},
+		{"SPSC[int]", 0, roundTrip(lfq.NewSPSC[int](capacity), 42)},
+		{"MPMC[string]", 0, roundTrip(lfq.NewMPMC[string](capacity), s)},
+		{"SPSC[string]", 0, roundTrip(lfq.NewSPSC[string](capacity), s)},
+		{"MPMCIndirect", 0, roundTripIndirect(lfq.NewMPMCIndirect(capacity))},
+		{"SPSCIndirect", 0, roundTripIndirect(lfq.NewSPSCIndirect(capacity))},
+		{"MPMCPtr", 0, roundTripPtr(lfq.NewMPMCPtr(capacity), unsafe.Pointer(obj))},
+		{"SPSCPtr", 0, roundTripPtr(lfq.NewSPSCPtr(capacity), unsafe.Pointer(obj))},
+	}
+
+	for _, v := range variants {
+		b.Run(v.name, func(b *testing.B) {
+			if got := testing.AllocsPerRun(100, v.op); got > v.allocs {
+				b.Fatalf("allocs per round trip: got %v, want <= %v", got, v.allocs)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				v.op()
+			}
+		})
+	}
+}
+
+func roundTrip[T any](q lfq.Queue[T], v T) func() {
+	return func() {
+		q.Enqueue(&v)
+		q.Dequeue()
+	}
+}
+
+func roundTripIndirect(q lfq.QueueIndirect) func() {
+	return func() {
+		q.Enqueue(42)
+		q.Dequeue()
+	}
+}
+
+func roundTripPtr(q lfq.QueuePtr, p unsafe.Pointer) func() {
+	return func() {
+		q.Enqueue(p)
+		q.Dequeue()
+	}
+}
+
+// BenchmarkDequeueInto compares Dequeue with DequeueInto for a 256-byte
+// array element, whose copy out through Dequeue's return value DequeueInto
+// avoids by writing the slot straight into a caller variable.
+//
+//	go test -run=^$ -bench=DequeueInto -benchmem
+func BenchmarkDequeueInto(b *testing.B) {
+	type buf = [256]byte
+	var elem buf
+
+	b.Run("Dequeue", func(b *testing.B) {
+		q := lfq.NewMPMC[buf](1024)
+		b.ReportAllocs()
+		for b.Loop() {
+			q.Enqueue(&elem)
+			v, _ := q.Dequeue()
+			elem[0] = v[0]
+		}
+	})
+	b.Run("DequeueInto", func(b *testing.B) {
+		q := lfq.NewMPMC[buf](1024)
+		var dst buf
+		b.ReportAllocs()
+		for b.Loop() {
+			q.Enqueue(&elem)
+			q.DequeueInto(&dst)
+			elem[0] = dst[0]
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+// Head-to-head comparison of the three algorithm families at identical
+// capacity and concurrency. Sub-benchmark names form a table:
+//
+//	BenchmarkAlgorithmComparison/MPMC/cap=1024/pxc=4x4/algo=FAA
+//
+// so that benchstat output groups FAA, Compact and Seq side by side:
+//
+//	go test -run=^$ -bench=AlgorithmComparison -count=10 | benchstat -col /algo -
+
+package lfq_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/spin"
+)
+
+// cmpQueue adapts generic and indirect queues to a common uintptr workload.
+type cmpQueue interface {
+	Enqueue(v uintptr) error
+	Dequeue() (uintptr, error)
+}
+
+// cmpGeneric adapts a Queue[uintptr] to cmpQueue.
+type cmpGeneric struct {
+	q lfq.Queue[uintptr]
+}
+
+func (a cmpGeneric) Enqueue(v uintptr) error   { return a.q.Enqueue(&v) }
+func (a cmpGeneric) Dequeue() (uintptr, error) { return a.q.Dequeue() }
+
+type cmpAlgo struct {
+	name string
+	make func(capacity int) cmpQueue
+}
+
+var cmpCaps = []int{64, 256, 1024, 4096}
+
+// runProducerConsumer moves b.N items from producers to consumers.
+func runProducerConsumer(b *testing.B, q cmpQueue, producers, consumers int) {
+	runProducerConsumerOn(b, q, producers, consumers, nil, nil)
+}
+
+// runProducerConsumerOn is runProducerConsumer with per-goroutine setup:
+// each producer calls onProducer and each consumer calls onConsumer, if
+// non-nil, before the timer starts.
+func runProducerConsumerOn(b *testing.B, q cmpQueue, producers, consumers int, onProducer, onConsumer func()) {
+	perProducer := max(b.N/producers, 1)
+	total := int64(perProducer * producers)
+	var consumed atomix.Int64
+	var ready, wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for range consumers {
+		ready.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if onConsumer != nil {
+				onConsumer()
+			}
+			ready.Done()
+			<-start
+			sw := spin.Wait{}
+			for consumed.Load() < total {
+				if _, err := q.Dequeue(); err == nil {
+					consumed.Add(1)
+					sw.Reset()
+				} else {
+					sw.Once()
+				}
+			}
+		}()
+	}
+	for p := range producers {
+		ready.Add(1)
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if onProducer != nil {
+				onProducer()
+			}
+			ready.Done()
+			<-start
+			sw := spin.Wait{}
+			base := uintptr(id * perProducer)
+			for i := range perProducer {
+				for q.Enqueue(base+uintptr(i)) != nil {
+					sw.Once()
+				}
+				sw.Reset()
+			}
+		}(p)
+	}
+	ready.Wait()
+	b.ResetTimer()
+	close(start)
+	wg.Wait()
+}
+
+func runComparison(b *testing.B, algos []cmpAlgo, shapes [][2]int) {
+	for _, c := range cmpCaps {
+		for _, s := range shapes {
+			for _, a := range algos {
+				name := fmt.Sprintf("cap=%d/pxc=%dx%d/algo=%s", c, s[0], s[1], a.name)
+				b.Run(name, func(b *testing.B) {
+					runProducerConsumer(b, a.make(c), s[0], s[1])
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkAlgorithmComparison(b *testing.B) {
+	b.Run("SPSC", func(b *testing.B) {
+		runComparison(b, []cmpAlgo{
+			{"Lamport", func(c int) cmpQueue { return cmpGeneric{lfq.NewSPSC[uintptr](c)} }},
+			{"Seq", func(c int) cmpQueue { return cmpGeneric{lfq.NewSPSCCompact[uintptr](c)} }},
+		}, [][2]int{{1, 1}})
+	})
+
+	b.Run("MPMC", func(b *testing.B) {
+		runComparison(b, []cmpAlgo{
+			{"FAA", func(c int) cmpQueue { return cmpGeneric{lfq.NewMPMC[uintptr](c)} }},
+			{"Compact", func(c int) cmpQueue { return lfq.NewMPMCCompactIndirect(c) }},
+			{"Seq", func(c int) cmpQueue { return cmpGeneric{lfq.NewMPMCSeq[uintptr](c)} }},
+		}, [][2]int{{1, 1}, {2, 2}, {4, 4}, {8, 8}})
+	})
+
+	b.Run("MPSC", func(b *testing.B) {
+		runComparison(b, []cmpAlgo{
+			{"FAA", func(c int) cmpQueue { return cmpGeneric{lfq.NewMPSC[uintptr](c)} }},
+			{"Compact", func(c int) cmpQueue { return lfq.NewMPSCCompactIndirect(c) }},
+			{"Seq", func(c int) cmpQueue { return cmpGeneric{lfq.NewMPSCSeq[uintptr](c)} }},
+		}, [][2]int{{1, 1}, {2, 1}, {4, 1}, {8, 1}})
+	})
+
+	b.Run("SPMC", func(b *testing.B) {
+		runComparison(b, []cmpAlgo{
+			{"FAA", func(c int) cmpQueue { return cmpGeneric{lfq.NewSPMC[uintptr](c)} }},
+			{"Compact", func(c int) cmpQueue { return lfq.NewSPMCCompactIndirect(c) }},
+			{"Seq", func(c int) cmpQueue { return cmpGeneric{lfq.NewSPMCSeq[uintptr](c)} }},
+		}, [][2]int{{1, 1}, {1, 2}, {1, 4}, {1, 8}})
+	})
+}
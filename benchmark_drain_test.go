@@ -0,0 +1,146 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// drainBatch is the number of items each drain benchmark iteration moves.
+const drainBatch = 1024
+
+// drainQueue is a generic FAA queue with drain mode.
+type drainQueue interface {
+	lfq.Queue[int]
+	lfq.Drainer
+}
+
+// benchmarkConsumeAll fills a fresh queue with drainBatch items outside
+// the timer, then times dequeuing all of them, after a Drain call if
+// drain is set. Drain mode is permanent, hence the fresh queue per
+// iteration. Reports ns per dequeue, including the Drain call.
+func benchmarkConsumeAll(b *testing.B, newQueue func() drainQueue, drain bool) {
+	for range b.N {
+		b.StopTimer()
+		q := newQueue()
+		for i := range drainBatch {
+			if err := q.Enqueue(&i); err != nil {
+				b.Fatalf("Enqueue(%d): %v", i, err)
+			}
+		}
+		b.StartTimer()
+
+		if drain {
+			q.Drain()
+		}
+		for range drainBatch {
+			if _, err := q.Dequeue(); err != nil {
+				b.Fatalf("Dequeue: %v", err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*drainBatch), "ns/dequeue")
+}
+
+// drainCases lists the FAA queues that have a drain mode.
+var drainCases = []struct {
+	name     string
+	newQueue func() drainQueue
+}{
+	{"MPMC", func() drainQueue { return lfq.NewMPMC[int](drainBatch) }},
+	{"MPSC", func() drainQueue { return lfq.NewMPSC[int](drainBatch) }},
+	{"SPMC", func() drainQueue { return lfq.NewSPMC[int](drainBatch) }},
+}
+
+// BenchmarkDrainMode_MPMC dequeues 1024 items after Drain. Compare with
+// BenchmarkNormalMode_MPMC: drain mode skips the threshold check on every
+// dequeue, but that check is one well-predicted branch on a non-empty
+// queue, so expect the two to be close.
+//
+//	go test -run=^$ -bench='DrainMode|NormalMode|DrainCall' -benchmem
+func BenchmarkDrainMode_MPMC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[0].newQueue, true)
+}
+
+// BenchmarkNormalMode_MPMC dequeues 1024 items without Drain.
+func BenchmarkNormalMode_MPMC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[0].newQueue, false)
+}
+
+// BenchmarkDrainMode_MPSC dequeues 1024 items after Drain.
+func BenchmarkDrainMode_MPSC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[1].newQueue, true)
+}
+
+// BenchmarkNormalMode_MPSC dequeues 1024 items without Drain.
+func BenchmarkNormalMode_MPSC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[1].newQueue, false)
+}
+
+// BenchmarkDrainMode_SPMC dequeues 1024 items after Drain.
+func BenchmarkDrainMode_SPMC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[2].newQueue, true)
+}
+
+// BenchmarkNormalMode_SPMC dequeues 1024 items without Drain.
+func BenchmarkNormalMode_SPMC(b *testing.B) {
+	benchmarkConsumeAll(b, drainCases[2].newQueue, false)
+}
+
+// BenchmarkDrainCall measures the Drain call on its own, the setup cost
+// included in the DrainMode figures.
+func BenchmarkDrainCall(b *testing.B) {
+	for _, tc := range drainCases {
+		b.Run(tc.name, func(b *testing.B) {
+			q := tc.newQueue()
+			for range b.N {
+				q.Drain()
+			}
+		})
+	}
+}
+
+// BenchmarkDrain_FAA_vs_Compact measures a Dequeue on an empty queue.
+//
+// An FAA queue behaves differently depending on how it got empty. After
+// natural exhaustion the threshold has run out and Dequeue returns at the
+// threshold check. After Drain the check is skipped, so every empty
+// Dequeue claims a position with FAA, repairs its slot and pulls tail
+// along. Compact queues have no threshold; an empty Dequeue reads one slot
+// sequence and returns. Calling Drain is therefore worthwhile when items
+// remain, and costly for consumers that keep polling an empty queue.
+func BenchmarkDrain_FAA_vs_Compact(b *testing.B) {
+	b.Run("FAA/AfterDrain", func(b *testing.B) {
+		q := lfq.NewMPMC[int](drainBatch)
+		q.Drain()
+		for range b.N {
+			q.Dequeue()
+		}
+	})
+	b.Run("FAA/AfterExhaustion", func(b *testing.B) {
+		q := lfq.NewMPMC[int](drainBatch)
+		for range 4 * drainBatch {
+			q.Dequeue()
+		}
+		b.ResetTimer()
+		for range b.N {
+			q.Dequeue()
+		}
+	})
+	b.Run("Compact/Empty", func(b *testing.B) {
+		q := lfq.NewMPMCSeq[int](drainBatch)
+		for range b.N {
+			q.Dequeue()
+		}
+	})
+	b.Run("CompactIndirect/Empty", func(b *testing.B) {
+		q := lfq.NewMPMCCompactIndirect(drainBatch)
+		for range b.N {
+			q.Dequeue()
+		}
+	})
+}
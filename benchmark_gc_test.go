@@ -0,0 +1,63 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// gcVariants are the queue constructors compared for GC impact.
+var gcVariants = []struct {
+	name string
+	make func(capacity int) any
+}{
+	{"MPMC", func(c int) any { return lfq.NewMPMC[int](c) }},
+	{"MPMCSeq", func(c int) any { return lfq.NewMPMCSeq[int](c) }},
+	{"MPSC", func(c int) any { return lfq.NewMPSC[int](c) }},
+}
+
+// BenchmarkQueueCreationAndGC measures the cost of keeping many queues
+// alive: allocation per queue, and the GC pause of a forced collection
+// with all of them live.
+//
+//	go test -run=^$ -bench=QueueCreationAndGC -benchmem
+func BenchmarkQueueCreationAndGC(b *testing.B) {
+	const capacity = 64
+
+	for _, v := range gcVariants {
+		for _, n := range []int{100, 1000, 10000} {
+			b.Run(fmt.Sprintf("%s/live=%d", v.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				var before, after runtime.MemStats
+				var pause uint64
+				for range b.N {
+					live := make([]any, n)
+					runtime.ReadMemStats(&before)
+					for i := range live {
+						live[i] = v.make(capacity)
+					}
+					runtime.GC()
+					runtime.ReadMemStats(&after)
+					pause += after.PauseTotalNs - before.PauseTotalNs
+					runtime.KeepAlive(live)
+				}
+				b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/float64(n), "B/queue")
+				b.ReportMetric(float64(pause)/float64(b.N), "gc-pause-ns")
+			})
+		}
+
+		// Churn measures allocation rate when queues are short-lived
+		b.Run(v.name+"/churn", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				runtime.KeepAlive(v.make(capacity))
+			}
+		})
+	}
+}
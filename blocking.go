@@ -0,0 +1,114 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"sync"
+)
+
+// BlockingMPMC is a mutex-based MPMC queue whose operations block instead
+// of returning ErrWouldBlock.
+//
+// Enqueue waits on a condition variable while the queue is full and
+// Dequeue while it is empty; each side signals the other. Waiters sleep
+// rather than spin, trading latency and throughput for CPU efficiency in
+// batch workloads. It is not lock-free: every operation takes the mutex,
+// which serializes producers and consumers across cores and scales worse
+// than MPMC as they are added; BenchmarkBlockingMPMC measures the
+// difference. Its simplicity also makes it a reference
+// implementation for testing and a fallback where atomics are
+// unavailable.
+//
+// Memory: n slots for capacity n
+type BlockingMPMC[T any] struct {
+	mu       sync.Mutex
+	notFull  sync.Cond
+	notEmpty sync.Cond
+	buffer   []T
+	head     int // Next slot to dequeue
+	count    int // Elements in the queue
+}
+
+// NewBlockingMPMC creates a new blocking MPMC queue.
+// Capacity rounds up to the next power of 2.
+func NewBlockingMPMC[T any](capacity int) *BlockingMPMC[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewBlockingMPMC", "capacity", capacity, 2))
+	}
+	q := &BlockingMPMC[T]{buffer: make([]T, roundToPow2(capacity))}
+	q.notFull.L = &q.mu
+	q.notEmpty.L = &q.mu
+	return q
+}
+
+// Enqueue adds an element, blocking while the queue is full.
+// It always returns nil; the error keeps the Producer signature.
+func (q *BlockingMPMC[T]) Enqueue(elem *T) error {
+	q.mu.Lock()
+	for q.count == len(q.buffer) {
+		q.notFull.Wait()
+	}
+	q.buffer[(q.head+q.count)&(len(q.buffer)-1)] = *elem
+	q.count++
+	q.mu.Unlock()
+	q.notEmpty.Signal()
+	return nil
+}
+
+// Dequeue removes and returns an element, blocking while the queue is
+// empty. It always returns a nil error; the error keeps the Consumer
+// signature.
+func (q *BlockingMPMC[T]) Dequeue() (T, error) {
+	q.mu.Lock()
+	for q.count == 0 {
+		q.notEmpty.Wait()
+	}
+	return q.take(), nil
+}
+
+// DequeueCtx is like Dequeue but gives up when ctx is done, returning
+// ctx.Err().
+func (q *BlockingMPMC[T]) DequeueCtx(ctx context.Context) (T, error) {
+	// Wake the waiters on cancellation; each re-checks its own context.
+	// Broadcasting under the mutex ensures a waiter that saw ctx live is
+	// already waiting and cannot miss the wake-up.
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		q.notEmpty.Broadcast()
+		q.mu.Unlock()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	for q.count == 0 {
+		if err := ctx.Err(); err != nil {
+			q.mu.Unlock()
+			var zero T
+			return zero, err
+		}
+		q.notEmpty.Wait()
+	}
+	return q.take(), nil
+}
+
+// take removes the head element and unlocks q.mu, which must be held with
+// the queue non-empty.
+func (q *BlockingMPMC[T]) take() T {
+	slot := &q.buffer[q.head]
+	elem := *slot
+	var zero T
+	*slot = zero
+	q.head = (q.head + 1) & (len(q.buffer) - 1)
+	q.count--
+	q.mu.Unlock()
+	q.notFull.Signal()
+	return elem
+}
+
+// Cap returns the queue capacity.
+func (q *BlockingMPMC[T]) Cap() int {
+	return len(q.buffer)
+}
@@ -0,0 +1,150 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/spin"
+)
+
+// TestBlockingMPMC tests FIFO order and that a full queue blocks Enqueue
+// until a Dequeue makes room.
+func TestBlockingMPMC(t *testing.T) {
+	q := lfq.NewBlockingMPMC[int](2)
+	if q.Cap() != 2 {
+		t.Fatalf("Cap: got %d, want 2", q.Cap())
+	}
+	for i := range 2 {
+		q.Enqueue(&i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		v := 2
+		q.Enqueue(&v)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Enqueue on full queue did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	for i := range 3 {
+		if got, err := q.Dequeue(); err != nil || got != i {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, i)
+		}
+	}
+	<-done
+}
+
+// TestBlockingMPMCDequeueCtx verifies DequeueCtx gives up on an empty
+// queue when its context ends and otherwise receives a later element.
+func TestBlockingMPMCDequeueCtx(t *testing.T) {
+	q := lfq.NewBlockingMPMC[int](4)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.DequeueCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("DequeueCtx on empty: got %v, want DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		v := 7
+		q.Enqueue(&v)
+	}()
+	if got, err := q.DequeueCtx(context.Background()); err != nil || got != 7 {
+		t.Fatalf("DequeueCtx: got (%d, %v), want (7, nil)", got, err)
+	}
+}
+
+// TestBlockingMPMCConcurrent verifies every element arrives exactly once
+// through a small queue shared by blocking producers and consumers.
+func TestBlockingMPMCConcurrent(t *testing.T) {
+	const workers, per = 4, 2000
+	q := lfq.NewBlockingMPMC[int](8)
+	seen := make([]int, workers*per)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for p := range workers {
+		wg.Go(func() {
+			for i := range per {
+				v := p*per + i
+				q.Enqueue(&v)
+			}
+		})
+		wg.Go(func() {
+			for range per {
+				v, _ := q.Dequeue()
+				mu.Lock()
+				seen[v]++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("element %d seen %d times", v, n)
+		}
+	}
+}
+
+// BenchmarkBlockingMPMC compares BlockingMPMC with MPMC, whose producers
+// and consumers spin on ErrWouldBlock, with 4 producers and 4 consumers.
+func BenchmarkBlockingMPMC(b *testing.B) {
+	const workers = 4
+	run := func(b *testing.B, enqueue func(*int), dequeue func()) {
+		per := max(1, b.N/workers)
+		b.ResetTimer()
+		var wg sync.WaitGroup
+		wg.Add(2 * workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+				for i := range per {
+					enqueue(&i)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for range per {
+					dequeue()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	b.Run("MPMC", func(b *testing.B) {
+		q := lfq.NewMPMC[int](1024)
+		run(b, func(v *int) {
+			sw := spin.Wait{}
+			for q.Enqueue(v) != nil {
+				sw.Once()
+			}
+		}, func() {
+			sw := spin.Wait{}
+			for {
+				if _, err := q.Dequeue(); err == nil {
+					return
+				}
+				sw.Once()
+			}
+		})
+	})
+	b.Run("BlockingMPMC", func(b *testing.B) {
+		q := lfq.NewBlockingMPMC[int](1024)
+		run(b, func(v *int) { q.Enqueue(v) }, func() { q.Dequeue() })
+	})
+}
@@ -6,6 +6,7 @@ package lfq_test
 
 import (
 	"testing"
+	"unsafe"
 
 	"code.hybscloud.com/lfq"
 )
@@ -269,3 +270,147 @@ func TestBuilderPanicPtrMPMCWithConstraint(t *testing.T) {
 	}()
 	lfq.New(7).SingleConsumer().BuildPtrMPMC()
 }
+
+// =============================================================================
+// Builder Combination Tests
+// =============================================================================
+
+// builtQueue adapts any queue flavor to int round trips.
+type builtQueue struct {
+	enqueue func(v int) error
+	dequeue func() (int, error)
+	cap     func() int
+}
+
+func genericBuilt(q lfq.Queue[int]) builtQueue {
+	return builtQueue{func(v int) error { return q.Enqueue(&v) }, q.Dequeue, q.Cap}
+}
+
+func indirectBuilt(q lfq.QueueIndirect) builtQueue {
+	return builtQueue{
+		func(v int) error { return q.Enqueue(uintptr(v)) },
+		func() (int, error) { v, err := q.Dequeue(); return int(v), err },
+		q.Cap,
+	}
+}
+
+// ptrBuilt passes pointers into vals, which must outlive the queue use.
+func ptrBuilt(q lfq.QueuePtr, vals []int) builtQueue {
+	return builtQueue{
+		func(v int) error { vals[v-1] = v; return q.Enqueue(unsafe.Pointer(&vals[v-1])) },
+		func() (int, error) {
+			p, err := q.Dequeue()
+			if err != nil {
+				return 0, err
+			}
+			return *(*int)(p), nil
+		},
+		q.Cap,
+	}
+}
+
+// TestBuilderAllCombinations builds every pattern × flavor × compact mode
+// (4 × 3 × 2) through the typed builders and checks capacity and FIFO.
+func TestBuilderAllCombinations(t *testing.T) {
+	vals := make([]int, 4)
+	build := map[string]map[string]func(*lfq.Builder) builtQueue{
+		"Generic": {
+			"SPSC": func(b *lfq.Builder) builtQueue { return genericBuilt(lfq.BuildSPSC[int](b)) },
+			"MPSC": func(b *lfq.Builder) builtQueue { return genericBuilt(lfq.BuildMPSC[int](b)) },
+			"SPMC": func(b *lfq.Builder) builtQueue { return genericBuilt(lfq.BuildSPMC[int](b)) },
+			"MPMC": func(b *lfq.Builder) builtQueue { return genericBuilt(lfq.BuildMPMC[int](b)) },
+		},
+		"Indirect": {
+			"SPSC": func(b *lfq.Builder) builtQueue { return indirectBuilt(b.BuildIndirectSPSC()) },
+			"MPSC": func(b *lfq.Builder) builtQueue { return indirectBuilt(b.BuildIndirectMPSC()) },
+			"SPMC": func(b *lfq.Builder) builtQueue { return indirectBuilt(b.BuildIndirectSPMC()) },
+			"MPMC": func(b *lfq.Builder) builtQueue { return indirectBuilt(b.BuildIndirectMPMC()) },
+		},
+		"Ptr": {
+			"SPSC": func(b *lfq.Builder) builtQueue { return ptrBuilt(b.BuildPtrSPSC(), vals) },
+			"MPSC": func(b *lfq.Builder) builtQueue { return ptrBuilt(b.BuildPtrMPSC(), vals) },
+			"SPMC": func(b *lfq.Builder) builtQueue { return ptrBuilt(b.BuildPtrSPMC(), vals) },
+			"MPMC": func(b *lfq.Builder) builtQueue { return ptrBuilt(b.BuildPtrMPMC(), vals) },
+		},
+	}
+
+	for _, pattern := range []string{"SPSC", "MPSC", "SPMC", "MPMC"} {
+		for _, flavor := range []string{"Generic", "Indirect", "Ptr"} {
+			for _, compact := range []bool{false, true} {
+				name := pattern + "/" + flavor
+				if compact {
+					name += "/Compact"
+				}
+				t.Run(name, func(t *testing.T) {
+					q := build[flavor][pattern](builderFor(pattern, compact))
+					if q.cap() != 8 {
+						t.Fatalf("Cap: got %d, want 8", q.cap())
+					}
+					for i := 1; i <= 4; i++ {
+						if err := q.enqueue(i); err != nil {
+							t.Fatalf("Enqueue(%d): %v", i, err)
+						}
+					}
+					for i := 1; i <= 4; i++ {
+						v, err := q.dequeue()
+						if err != nil {
+							t.Fatalf("Dequeue(%d): %v", i, err)
+						}
+						if v != i {
+							t.Fatalf("Dequeue(%d): got %d", i, v)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+// builderFor returns a builder constrained for pattern.
+func builderFor(pattern string, compact bool) *lfq.Builder {
+	b := lfq.New(7)
+	switch pattern {
+	case "SPSC":
+		b.SingleProducer().SingleConsumer()
+	case "MPSC":
+		b.SingleConsumer()
+	case "SPMC":
+		b.SingleProducer()
+	}
+	if compact {
+		b.Compact()
+	}
+	return b
+}
+
+// TestBuilderPanicPtrConstraints verifies each Ptr builder panics for
+// every pattern but its own, with and without Compact.
+func TestBuilderPanicPtrConstraints(t *testing.T) {
+	builders := map[string]func(*lfq.Builder){
+		"SPSC": func(b *lfq.Builder) { b.BuildPtrSPSC() },
+		"MPSC": func(b *lfq.Builder) { b.BuildPtrMPSC() },
+		"SPMC": func(b *lfq.Builder) { b.BuildPtrSPMC() },
+		"MPMC": func(b *lfq.Builder) { b.BuildPtrMPMC() },
+	}
+	patterns := []string{"SPSC", "MPSC", "SPMC", "MPMC"}
+
+	for _, target := range patterns {
+		for _, pattern := range patterns {
+			for _, compact := range []bool{false, true} {
+				name := "BuildPtr" + target + "/" + pattern
+				if compact {
+					name += "/Compact"
+				}
+				t.Run(name, func(t *testing.T) {
+					defer func() {
+						r := recover()
+						if wantPanic := pattern != target; (r != nil) != wantPanic {
+							t.Fatalf("panic: got %v, want panic %v", r, wantPanic)
+						}
+					}()
+					builders[target](builderFor(pattern, compact))
+				})
+			}
+		}
+	}
+}
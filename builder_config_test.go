@@ -0,0 +1,83 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+func TestBuilderConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *lfq.Builder
+		want lfq.QueueConfig
+	}{
+		{"MPMC", lfq.New(1000),
+			lfq.QueueConfig{Capacity: 1024, Algorithm: "FAA", Flavor: "Generic"}},
+		{"MPSC", lfq.New(1024).SingleConsumer(),
+			lfq.QueueConfig{Capacity: 1024, Algorithm: "FAA", SingleConsumer: true, Flavor: "Generic"}},
+		{"SPMC", lfq.New(1024).SingleProducer(),
+			lfq.QueueConfig{Capacity: 1024, Algorithm: "FAA", SingleProducer: true, Flavor: "Generic"}},
+		{"SPSC", lfq.New(3).SingleProducer().SingleConsumer(),
+			lfq.QueueConfig{Capacity: 4, Algorithm: "Lamport", SingleProducer: true, SingleConsumer: true, Flavor: "Generic"}},
+		{"SPSCCompact", lfq.New(8).SingleProducer().SingleConsumer().Compact(),
+			lfq.QueueConfig{Capacity: 8, Algorithm: "Lamport", Compact: true, SingleProducer: true, SingleConsumer: true, Flavor: "Generic"}},
+		{"MPMCCompact", lfq.New(100).Compact(),
+			lfq.QueueConfig{Capacity: 128, Algorithm: "Seq", Compact: true, Flavor: "Generic"}},
+		{"MPMCCompactExact", lfq.New(100).Compact().ExactCapacity(),
+			lfq.QueueConfig{Capacity: 100, Algorithm: "Seq", Compact: true, Flavor: "Generic"}},
+		{"MPMCExactIgnored", lfq.New(100).ExactCapacity(),
+			lfq.QueueConfig{Capacity: 128, Algorithm: "FAA", Flavor: "Generic"}},
+		{"MPSCIndirect", lfq.New(64).SingleConsumer().Indirect(),
+			lfq.QueueConfig{Capacity: 64, Algorithm: "FAA", SingleConsumer: true, Flavor: "Indirect"}},
+		{"SPSCDropOldest", lfq.New(64).SingleProducer().SingleConsumer().WithOverflowPolicy(lfq.OverflowDropOldest, nil),
+			lfq.QueueConfig{Capacity: 64, Algorithm: "FAA", SingleProducer: true, Flavor: "Generic"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Config(); got != tt.want {
+				t.Fatalf("Config:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuilderConfigMatchesBuild verifies that Config predicts the
+// capacity of the built queue.
+func TestBuilderConfigMatchesBuild(t *testing.T) {
+	for _, b := range []*lfq.Builder{
+		lfq.New(1000),
+		lfq.New(1000).SingleConsumer(),
+		lfq.New(1000).SingleProducer().SingleConsumer(),
+		lfq.New(100).Compact().ExactCapacity(),
+		lfq.New(100).SingleProducer().Compact().ExactCapacity(),
+	} {
+		want := b.Config().Capacity
+		if got := lfq.Build[int](b).Cap(); got != want {
+			t.Errorf("%s: Cap got %d, want %d", b.Describe(), got, want)
+		}
+	}
+}
+
+func TestBuilderDescribe(t *testing.T) {
+	tests := []struct {
+		b    *lfq.Builder
+		want string
+	}{
+		{lfq.New(1024), "MPMC FAA Generic cap=1024"},
+		{lfq.New(1024).Compact(), "MPMC Seq Generic cap=1024 compact"},
+		{lfq.New(16).SingleProducer().SingleConsumer(), "SPSC Lamport Generic cap=16"},
+		{lfq.New(16).SingleConsumer().Indirect(), "MPSC FAA Indirect cap=16"},
+		{lfq.New(16).SingleProducer().Compact(), "SPMC Seq Generic cap=16 compact"},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Describe(); got != tt.want {
+			t.Errorf("Describe: got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// Builder constraint flags for FuzzBuilder.
+const (
+	fuzzSP uint8 = 1 << iota
+	fuzzSC
+	fuzzCompact
+	fuzzExact
+	fuzzIndirect
+)
+
+// fuzzBuildMethods lists the Build entry points FuzzBuilder calls, with
+// the topology each requires: "" for the auto-selecting methods.
+var fuzzBuildMethods = []struct {
+	name     string
+	topology string
+}{
+	{"Build", ""},
+	{"BuildSPSC", "SPSC"},
+	{"BuildMPSC", "MPSC"},
+	{"BuildSPMC", "SPMC"},
+	{"BuildMPMC", "MPMC"},
+	{"BuildIndirect", ""},
+	{"BuildIndirectSPSC", "SPSC"},
+	{"BuildIndirectMPSC", "MPSC"},
+	{"BuildIndirectSPMC", "SPMC"},
+	{"BuildIndirectMPMC", "MPMC"},
+	{"BuildPtr", ""},
+	{"BuildPtrSPSC", "SPSC"},
+	{"BuildPtrMPSC", "MPSC"},
+	{"BuildPtrSPMC", "SPMC"},
+	{"BuildPtrMPMC", "MPMC"},
+}
+
+// fuzzMaxBuild bounds the capacities FuzzBuilder allocates queues for.
+// Larger valid capacities only exercise New and Config.
+const fuzzMaxBuild = 1 << 12
+
+func fuzzTopology(flags uint8) string {
+	return [2][2]string{{"MPMC", "MPSC"}, {"SPMC", "SPSC"}}[b2i(flags&fuzzSP != 0)][b2i(flags&fuzzSC != 0)]
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// fuzzRoundTrip builds a queue with method m and checks one element
+// survives an Enqueue and Dequeue.
+func fuzzRoundTrip(t *testing.T, b *lfq.Builder, m string) {
+	var v uintptr = 42
+	check := func(got uintptr, err error) {
+		t.Helper()
+		if err != nil || got != v {
+			t.Fatalf("%s: Dequeue: got (%d, %v), want (%d, nil)", m, got, err, v)
+		}
+	}
+	generic := func(q lfq.Queue[uintptr]) {
+		t.Helper()
+		if err := q.Enqueue(&v); err != nil {
+			t.Fatalf("%s: Enqueue: %v", m, err)
+		}
+		check(q.Dequeue())
+	}
+	indirect := func(q lfq.QueueIndirect) {
+		t.Helper()
+		if err := q.Enqueue(v); err != nil {
+			t.Fatalf("%s: Enqueue: %v", m, err)
+		}
+		check(q.Dequeue())
+	}
+	ptr := func(q lfq.QueuePtr) {
+		t.Helper()
+		if err := q.Enqueue(unsafe.Pointer(&v)); err != nil {
+			t.Fatalf("%s: Enqueue: %v", m, err)
+		}
+		p, err := q.Dequeue()
+		if err != nil || p != unsafe.Pointer(&v) {
+			t.Fatalf("%s: Dequeue: got (%p, %v), want (%p, nil)", m, p, err, &v)
+		}
+	}
+
+	switch m {
+	case "Build":
+		generic(lfq.Build[uintptr](b))
+	case "BuildSPSC":
+		generic(lfq.BuildSPSC[uintptr](b))
+	case "BuildMPSC":
+		generic(lfq.BuildMPSC[uintptr](b))
+	case "BuildSPMC":
+		generic(lfq.BuildSPMC[uintptr](b))
+	case "BuildMPMC":
+		generic(lfq.BuildMPMC[uintptr](b))
+	case "BuildIndirect":
+		indirect(b.BuildIndirect())
+	case "BuildIndirectSPSC":
+		indirect(b.BuildIndirectSPSC())
+	case "BuildIndirectMPSC":
+		indirect(b.BuildIndirectMPSC())
+	case "BuildIndirectSPMC":
+		indirect(b.BuildIndirectSPMC())
+	case "BuildIndirectMPMC":
+		indirect(b.BuildIndirectMPMC())
+	case "BuildPtr":
+		ptr(b.BuildPtr())
+	case "BuildPtrSPSC":
+		ptr(b.BuildPtrSPSC())
+	case "BuildPtrMPSC":
+		ptr(b.BuildPtrMPSC())
+	case "BuildPtrSPMC":
+		ptr(b.BuildPtrSPMC())
+	case "BuildPtrMPMC":
+		ptr(b.BuildPtrMPMC())
+	}
+}
+
+// recovered runs fn and returns the value it panicked with, if any.
+func recovered(fn func()) (r any) {
+	defer func() { r = recover() }()
+	fn()
+	return nil
+}
+
+// FuzzBuilder builds queues from random capacities, constraint flags and
+// Build methods. It checks that New and the Build methods panic exactly
+// when their documented constraints are violated, and that every queue
+// built passes an element through Enqueue and Dequeue.
+//
+// The seed corpus holds the 12 valid topology and flavor combinations
+// and a selection of invalid ones. Run longer with
+//
+//	go test -run '^$' -fuzz '^FuzzBuilder$' -fuzztime 30s .
+func FuzzBuilder(f *testing.F) {
+	topologies := map[string]uint8{"SPSC": fuzzSP | fuzzSC, "MPSC": fuzzSC, "SPMC": fuzzSP, "MPMC": 0}
+	for i, m := range fuzzBuildMethods {
+		if m.topology != "" {
+			f.Add(1024, topologies[m.topology], uint8(i))
+			f.Add(100, topologies[m.topology]|fuzzCompact|fuzzExact, uint8(i))
+		}
+	}
+	// Invalid: wrong topology, capacity out of range
+	f.Add(64, fuzzSP, uint8(1))        // BuildSPSC without SingleConsumer
+	f.Add(64, fuzzSP|fuzzSC, uint8(4)) // BuildMPMC with constraints
+	f.Add(64, uint8(0), uint8(7))      // BuildIndirectMPSC without SingleConsumer
+	f.Add(64, fuzzSC, uint8(13))       // BuildPtrSPMC on MPSC
+	for _, c := range []int{0, 1, 2, 3, 4, 5, 1000, -1, math.MinInt, math.MaxInt, lfq.MaxCapacity} {
+		f.Add(c, fuzzCompact, uint8(0))
+	}
+
+	f.Fuzz(func(t *testing.T, capacity int, flags uint8, method uint8) {
+		m := fuzzBuildMethods[int(method)%len(fuzzBuildMethods)]
+
+		var b *lfq.Builder
+		r := recovered(func() { b = lfq.New(capacity) })
+		if wantPanic := capacity < 2 || capacity > lfq.MaxCapacity; wantPanic != (r != nil) {
+			t.Fatalf("New(%d): panic %v, want panic %v", capacity, r, wantPanic)
+		}
+		if r != nil {
+			return
+		}
+
+		if flags&fuzzSP != 0 {
+			b.SingleProducer()
+		}
+		if flags&fuzzSC != 0 {
+			b.SingleConsumer()
+		}
+		if flags&fuzzCompact != 0 {
+			b.Compact()
+		}
+		if flags&fuzzExact != 0 {
+			b.ExactCapacity()
+		}
+		if flags&fuzzIndirect != 0 && m.name == "Build" {
+			b.Indirect()
+		}
+		cfg := b.Config()
+		_ = b.Describe()
+		if capacity > fuzzMaxBuild {
+			return
+		}
+
+		wantPanic := m.topology != "" && m.topology != fuzzTopology(flags)
+		r = recovered(func() { fuzzRoundTrip(t, b, m.name) })
+		if wantPanic != (r != nil) {
+			t.Fatalf("%s on %s: panic %v, want panic %v", m.name, fuzzTopology(flags), r, wantPanic)
+		}
+		if r == nil && cfg.Capacity < capacity {
+			t.Fatalf("Config().Capacity: got %d, want >= %d", cfg.Capacity, capacity)
+		}
+	})
+}
+
+func TestNewCapacityRange(t *testing.T) {
+	if r := recovered(func() { lfq.New(lfq.MaxCapacity) }); r != nil {
+		t.Fatalf("New(MaxCapacity): panic %v", r)
+	}
+	r := recovered(func() { lfq.New(math.MaxInt) })
+	want := fmt.Sprintf("lfq: New capacity %d > maximum %d; see https://pkg.go.dev/code.hybscloud.com/lfq#New", math.MaxInt, lfq.MaxCapacity)
+	if r != want {
+		t.Fatalf("New(MaxInt): panic %v, want %q", r, want)
+	}
+}
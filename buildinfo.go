@@ -0,0 +1,44 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo describes the build of the running binary, for tests and
+// diagnostics that depend on build conditions.
+type BuildInfo struct {
+	RaceEnabled     bool   // Race detector active; same as [RaceEnabled]
+	GOARCH          string // Target architecture, as runtime.GOARCH
+	CGOEnabled      bool   // CGO_ENABLED=1 recorded in the binary's build settings
+	Version         string // Go toolchain version, as runtime.Version()
+	ChecksumEnabled bool   // Built with lfq_checksum; see [MPMC]
+}
+
+var buildInfo BuildInfo
+
+func init() {
+	buildInfo = BuildInfo{
+		RaceEnabled:     RaceEnabled,
+		GOARCH:          runtime.GOARCH,
+		Version:         runtime.Version(),
+		ChecksumEnabled: checksumSlots,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key == "CGO_ENABLED" {
+				buildInfo.CGOEnabled = s.Value == "1"
+			}
+		}
+	}
+}
+
+// GetBuildInfo returns the build conditions of the running binary.
+// CGOEnabled is false if the binary carries no build settings.
+func GetBuildInfo() BuildInfo {
+	return buildInfo
+}
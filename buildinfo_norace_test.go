@@ -0,0 +1,10 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+package lfq_test
+
+// raceBuild is set by build tag, independently of lfq.RaceEnabled.
+const raceBuild = false
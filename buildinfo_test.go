@@ -0,0 +1,26 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+func TestGetBuildInfo(t *testing.T) {
+	bi := lfq.GetBuildInfo()
+	if bi.GOARCH != runtime.GOARCH {
+		t.Errorf("GOARCH: got %q, want %q", bi.GOARCH, runtime.GOARCH)
+	}
+	if bi.RaceEnabled != raceBuild {
+		t.Errorf("RaceEnabled: got %v, want %v", bi.RaceEnabled, raceBuild)
+	}
+	if bi.Version != runtime.Version() {
+		t.Errorf("Version: got %q, want %q", bi.Version, runtime.Version())
+	}
+	t.Logf("%+v", bi)
+}
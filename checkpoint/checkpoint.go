@@ -0,0 +1,118 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package checkpoint saves the contents of a single-consumer lfq queue
+// with encoding/gob and restores them into another queue, for save-state
+// debugging and for migrating queued work across restarts.
+//
+// Encode drains the queue, so the checkpoint holds every item exactly
+// once and in FIFO order; Decode enqueues the items into an empty queue.
+// Both run on the consumer side: stop the producers first, or items they
+// enqueue during Encode may be missed or land in the checkpoint out of
+// order with later ones. Items must round-trip through encoding/gob.
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+
+	"code.hybscloud.com/lfq"
+)
+
+var (
+	// ErrNotEmpty is returned by Decode for a queue that holds items.
+	ErrNotEmpty = errors.New("checkpoint: queue is not empty")
+
+	// ErrTooLarge is returned by Decode when the checkpoint holds more
+	// items than the queue's capacity.
+	ErrTooLarge = errors.New("checkpoint: checkpoint exceeds queue capacity")
+)
+
+// Queue is a single-consumer queue whose contents can be checkpointed,
+// such as lfq.SPSC[T] or lfq.MPSC[T]. Snapshot lets Decode tell whether
+// the queue is empty without consuming from it.
+type Queue[T any] interface {
+	lfq.Queue[T]
+	Snapshot() []T
+}
+
+// Encode removes every item from q and returns them gob-encoded.
+//
+// Encode checks that T is gob-encodable before dequeuing anything. If
+// encoding the items still fails, e.g. for an interface value of an
+// unregistered type, they are enqueued back into q and the error is
+// returned; items enqueued concurrently by producers then precede them.
+func Encode[T any](q Queue[T]) ([]byte, error) {
+	if err := gob.NewEncoder(io.Discard).Encode([]T{}); err != nil {
+		return nil, err
+	}
+	var items []T
+	for {
+		v, err := q.Dequeue()
+		if err != nil {
+			break
+		}
+		items = append(items, v)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(items); err != nil {
+		return nil, errors.Join(err, restore(q, items))
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode enqueues the items encoded in data into q in their original
+// order. It returns ErrNotEmpty if q holds any items and ErrTooLarge if
+// they would not fit; in both cases, and if data does not decode, q is
+// left unchanged.
+func Decode[T any](q Queue[T], data []byte) error {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	if len(q.Snapshot()) != 0 {
+		return ErrNotEmpty
+	}
+	if len(items) > q.Cap() {
+		return ErrTooLarge
+	}
+	return restore(q, items)
+}
+
+// restore enqueues items into q, stopping at the first error.
+func restore[T any](q Queue[T], items []T) error {
+	for i := range items {
+		if err := q.Enqueue(&items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Gob adapts a queue to gob.GobEncoder and gob.GobDecoder, so that its
+// contents can be saved as part of a larger gob-encoded state:
+//
+//	type State struct {
+//	    Offset int
+//	    Jobs   *checkpoint.Gob[Job]
+//	}
+//	err := gob.NewEncoder(w).Encode(State{Offset: off, Jobs: &checkpoint.Gob[Job]{Q: jobs}})
+//
+// To restore, set Q to an empty queue before decoding into the Gob.
+type Gob[T any] struct {
+	Q Queue[T]
+}
+
+// GobEncode drains Q. See Encode.
+func (g *Gob[T]) GobEncode() ([]byte, error) {
+	return Encode(g.Q)
+}
+
+// GobDecode enqueues the decoded items into Q. See Decode.
+func (g *Gob[T]) GobDecode(data []byte) error {
+	return Decode(g.Q, data)
+}
@@ -0,0 +1,169 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkpoint_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/checkpoint"
+)
+
+type job struct {
+	ID   int
+	Name string
+}
+
+func fill(t *testing.T, q lfq.Queue[job], n int) {
+	t.Helper()
+	for i := range n {
+		j := job{i, "job"}
+		if err := q.Enqueue(&j); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+}
+
+func drain(q lfq.Queue[job]) []job {
+	var out []job
+	for {
+		j, err := q.Dequeue()
+		if err != nil {
+			return out
+		}
+		out = append(out, j)
+	}
+}
+
+// TestRoundTrip encodes a queue of 100 items and decodes it into a fresh
+// queue, which must hold the same items in the same order.
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst checkpoint.Queue[job]
+	}{
+		{"SPSC", lfq.NewSPSC[job](128), lfq.NewSPSC[job](128)},
+		{"MPSC", lfq.NewMPSC[job](128), lfq.NewMPSC[job](128)},
+		{"MPSCToSPSC", lfq.NewMPSC[job](128), lfq.NewSPSC[job](256)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill(t, tt.src, 100)
+			want := tt.src.Snapshot()
+
+			data, err := checkpoint.Encode(tt.src)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if _, err := tt.src.Dequeue(); !lfq.IsEmpty(err) {
+				t.Fatalf("source after Encode: got %v, want ErrEmpty", err)
+			}
+			if err := checkpoint.Decode(tt.dst, data); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got := drain(tt.dst); !slices.Equal(got, want) {
+				t.Fatalf("decoded items: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeNonEmpty(t *testing.T) {
+	src := lfq.NewSPSC[job](8)
+	fill(t, src, 3)
+	data, err := checkpoint.Encode(src)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	dst := lfq.NewSPSC[job](8)
+	j := job{ID: 99}
+	dst.Enqueue(&j)
+	if err := checkpoint.Decode(dst, data); !errors.Is(err, checkpoint.ErrNotEmpty) {
+		t.Fatalf("Decode into non-empty queue: got %v, want ErrNotEmpty", err)
+	}
+	if got := drain(dst); !slices.Equal(got, []job{j}) {
+		t.Fatalf("queue after failed Decode: got %v, want %v", got, []job{j})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	src := lfq.NewSPSC[job](16)
+	fill(t, src, 10)
+	data, err := checkpoint.Encode(src)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := checkpoint.Decode(lfq.NewSPSC[job](8), data); !errors.Is(err, checkpoint.ErrTooLarge) {
+		t.Fatalf("Decode 10 items into cap 8: got %v, want ErrTooLarge", err)
+	}
+	dst := lfq.NewSPSC[job](16)
+	if err := checkpoint.Decode(dst, data[:len(data)/2]); err == nil {
+		t.Fatal("Decode truncated data: got nil error")
+	}
+	if got := dst.Snapshot(); len(got) != 0 {
+		t.Fatalf("queue after failed Decode: got %v, want empty", got)
+	}
+}
+
+// TestEncodeUnencodable verifies Encode rejects a type gob cannot encode
+// without draining the queue.
+func TestEncodeUnencodable(t *testing.T) {
+	q := lfq.NewSPSC[chan int](4)
+	c := make(chan int)
+	q.Enqueue(&c)
+	if _, err := checkpoint.Encode(q); err == nil {
+		t.Fatal("Encode of chan int: got nil error")
+	}
+	if got, err := q.Dequeue(); err != nil || got != c {
+		t.Fatalf("Dequeue after failed Encode: got (%v, %v), want (%v, nil)", got, err, c)
+	}
+}
+
+// TestEncodeRestores verifies the items are put back when gob fails on
+// a value rather than on the type.
+func TestEncodeRestores(t *testing.T) {
+	type unregistered struct{ X int }
+	q := lfq.NewMPSC[any](4)
+	for _, v := range []any{1, unregistered{2}} {
+		q.Enqueue(&v)
+	}
+	if _, err := checkpoint.Encode(q); err == nil {
+		t.Fatal("Encode of unregistered interface value: got nil error")
+	}
+	if got := q.Snapshot(); len(got) != 2 || got[0] != 1 || got[1] != (unregistered{2}) {
+		t.Fatalf("queue after failed Encode: got %v, want [1 {2}]", got)
+	}
+}
+
+func TestGob(t *testing.T) {
+	type state struct {
+		Offset int
+		Jobs   *checkpoint.Gob[job]
+	}
+	src := lfq.NewMPSC[job](8)
+	fill(t, src, 5)
+	want := src.Snapshot()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state{42, &checkpoint.Gob[job]{Q: src}}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	dst := lfq.NewMPSC[job](8)
+	got := state{Jobs: &checkpoint.Gob[job]{Q: dst}}
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Offset != 42 {
+		t.Fatalf("Offset: got %d, want 42", got.Offset)
+	}
+	if items := drain(dst); !slices.Equal(items, want) {
+		t.Fatalf("decoded items: got %v, want %v", items, want)
+	}
+}
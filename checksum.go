@@ -0,0 +1,38 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"hash/crc32"
+	"strconv"
+	"unsafe"
+)
+
+// sumOf returns the CRC32 of the bytes of *v.
+func sumOf[T any](v *T) uint32 {
+	return crc32.ChecksumIEEE(unsafe.Slice((*byte)(unsafe.Pointer(v)), unsafe.Sizeof(*v)))
+}
+
+// sealSlot records the checksum of the element just stored in a slot.
+func sealSlot[T any](sum *slotSum, data *T) {
+	if checksumSlots {
+		sum.set(sumOf(data))
+	}
+}
+
+// verifySlot panics if the element in a slot no longer matches the
+// checksum recorded when it was stored.
+func verifySlot[T any](typ string, pos uint64, sum *slotSum, data *T) {
+	if !checksumSlots {
+		return
+	}
+	want, got := sum.get(), sumOf(data)
+	if want != got {
+		panic("lfq: " + typ + " checksum mismatch at position " + strconv.FormatUint(pos, 10) +
+			": stored " + strconv.FormatUint(uint64(want), 16) +
+			", computed " + strconv.FormatUint(uint64(got), 16) +
+			"; the slot was corrupted after Enqueue")
+	}
+}
@@ -0,0 +1,16 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !lfq_checksum
+
+package lfq
+
+// checksumSlots is false: slots carry no checksum.
+const checksumSlots = false
+
+// slotSum is empty, so slots keep their size.
+type slotSum struct{}
+
+func (s *slotSum) set(uint32)  {}
+func (s *slotSum) get() uint32 { return 0 }
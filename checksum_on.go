@@ -0,0 +1,17 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build lfq_checksum
+
+package lfq
+
+// checksumSlots makes MPMC slots carry a CRC32 of their element,
+// verified on dequeue.
+const checksumSlots = true
+
+// slotSum holds a slot's checksum.
+type slotSum uint32
+
+func (s *slotSum) set(v uint32) { *s = slotSum(v) }
+func (s *slotSum) get() uint32  { return uint32(*s) }
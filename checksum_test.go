@@ -0,0 +1,58 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build lfq_checksum
+
+package lfq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestChecksumDetectsCorruption overwrites a stored element behind the
+// queue's back and verifies that Dequeue panics.
+func TestChecksumDetectsCorruption(t *testing.T) {
+	if !lfq.GetBuildInfo().ChecksumEnabled {
+		t.Fatal("ChecksumEnabled: got false under lfq_checksum")
+	}
+
+	q := lfq.NewMPMC[uint64](4)
+	v := uint64(42)
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// Position 0 lives in slot 0; flip a bit of its data in place
+	buffer := reflect.ValueOf(q).Elem().FieldByName("buffer")
+	data := buffer.Index(0).FieldByName("data")
+	*(*uint64)(data.Addr().UnsafePointer()) ^= 1
+
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if !strings.Contains(msg, "checksum mismatch") {
+			t.Fatalf("Dequeue: got panic %v, want checksum mismatch", r)
+		}
+	}()
+	q.Dequeue()
+}
+
+// TestChecksumIntact verifies that undisturbed elements pass the check.
+func TestChecksumIntact(t *testing.T) {
+	q := lfq.NewMPMC[string](4)
+	for range 3 {
+		for _, s := range []string{"a", "bb", "ccc"} {
+			q.Enqueue(&s)
+		}
+		for _, want := range []string{"a", "bb", "ccc"} {
+			if got, err := q.Dequeue(); err != nil || got != want {
+				t.Fatalf("Dequeue: got (%q, %v), want (%q, nil)", got, err, want)
+			}
+		}
+	}
+}
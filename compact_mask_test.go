@@ -0,0 +1,96 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// maskedQueue is implemented by the Compact indirect queues.
+type maskedQueue interface {
+	lfq.QueueIndirect
+	EnqueueMasked(elem uintptr) error
+	DequeueMasked() (uintptr, error)
+}
+
+func maskedQueues(mask uintptr) map[string]maskedQueue {
+	b := func() *lfq.Builder { return lfq.New(8).Compact().WithUpperBitMask(mask) }
+	return map[string]maskedQueue{
+		"MPMC": b().BuildIndirect().(*lfq.MPMCCompactIndirect),
+		"MPSC": b().SingleConsumer().BuildIndirect().(*lfq.MPSCCompactIndirect),
+		"SPMC": b().SingleProducer().BuildIndirect().(*lfq.SPMCCompactIndirect),
+	}
+}
+
+// TestEnqueueMaskedRoundTrip tests that tagged values come back intact.
+func TestEnqueueMaskedRoundTrip(t *testing.T) {
+	const mask = 1<<63 | 1<<62 | 1<<61
+	values := []uintptr{
+		0,
+		0x1234,
+		0xffff_8000_0000_1000, // Sign-extended kernel-style address
+		0xc000_0000_0000_0042, // Bits 63 and 62 set, 61 clear
+		0x2000_0000_0000_0007, // Only bit 61 set
+		0xe000_0000_0000_0000, // All reserved bits set
+	}
+	for name, q := range maskedQueues(mask) {
+		t.Run(name, func(t *testing.T) {
+			for round := range 3 {
+				for _, v := range values {
+					if err := q.EnqueueMasked(v); err != nil {
+						t.Fatalf("round %d: EnqueueMasked(%#x): %v", round, v, err)
+					}
+				}
+				for _, want := range values {
+					got, err := q.DequeueMasked()
+					if err != nil || got != want {
+						t.Fatalf("round %d: DequeueMasked: got (%#x, %v), want (%#x, nil)", round, got, err, want)
+					}
+				}
+			}
+			if _, err := q.DequeueMasked(); !lfq.IsEmpty(err) {
+				t.Fatalf("DequeueMasked on empty: got %v, want ErrEmpty", err)
+			}
+		})
+	}
+}
+
+// TestUpperBitMaskPanics tests the panics guarding the reserved bits.
+func TestUpperBitMaskPanics(t *testing.T) {
+	expectPanic := func(t *testing.T, want string, f func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			msg, _ := recover().(string)
+			if !strings.Contains(msg, want) {
+				t.Fatalf("panic: got %q, want it to contain %q", msg, want)
+			}
+		}()
+		f()
+	}
+
+	for name, q := range maskedQueues(1<<63 | 1<<62) {
+		t.Run(name, func(t *testing.T) {
+			expectPanic(t, "sets reserved bits 0x4000000000000000", func() { q.Enqueue(1<<62 | 5) })
+			expectPanic(t, "differs from tag bit 62", func() { q.EnqueueMasked(1<<63 | 5) })
+		})
+	}
+	t.Run("DefaultMask", func(t *testing.T) {
+		q := lfq.NewMPMCCompactIndirect(8)
+		expectPanic(t, "exceeds 63-bit limit", func() { q.EnqueueMasked(1 << 63) })
+		if err := q.EnqueueMasked(1 << 62); err != nil {
+			t.Fatalf("EnqueueMasked(1<<62): %v", err)
+		}
+		if v, _ := q.DequeueMasked(); v != 1<<62 {
+			t.Fatalf("DequeueMasked: got %#x, want %#x", v, uintptr(1<<62))
+		}
+	})
+	t.Run("MaskWithoutBit63", func(t *testing.T) {
+		expectPanic(t, "must include bit 63", func() { lfq.New(8).WithUpperBitMask(1 << 62) })
+	})
+}
@@ -6,6 +6,7 @@ package lfq_test
 
 import (
 	"errors"
+	"fmt"
 	"slices"
 	"sync"
 	"testing"
@@ -64,6 +65,49 @@ func TestIsNonFailure(t *testing.T) {
 	}
 }
 
+// TestIsFullIsEmpty tests ErrFull and ErrEmpty classification and their
+// equality with ErrWouldBlock.
+func TestIsFullIsEmpty(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantFull  bool
+		wantEmpty bool
+	}{
+		{"nil", nil, false, false},
+		{"ErrFull", lfq.ErrFull, true, true},
+		{"ErrEmpty", lfq.ErrEmpty, true, true},
+		{"wrapped ErrFull", fmt.Errorf("enqueue: %w", lfq.ErrFull), true, true},
+		{"other error", errors.New("other"), false, false},
+	}
+
+	for tt := range slices.Values(tests) {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lfq.IsFull(tt.err); got != tt.wantFull {
+				t.Errorf("IsFull(%v) = %v, want %v", tt.err, got, tt.wantFull)
+			}
+			if got := lfq.IsEmpty(tt.err); got != tt.wantEmpty {
+				t.Errorf("IsEmpty(%v) = %v, want %v", tt.err, got, tt.wantEmpty)
+			}
+			if got := lfq.IsWouldBlock(tt.err); got != tt.wantFull {
+				t.Errorf("IsWouldBlock(%v) = %v, want %v", tt.err, got, tt.wantFull)
+			}
+		})
+	}
+
+	q := lfq.NewSPSC[int](2)
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Errorf("Dequeue on empty: got %v, want ErrEmpty", err)
+	}
+	for i := range 2 {
+		q.Enqueue(&i)
+	}
+	v := 2
+	if err := q.Enqueue(&v); !lfq.IsFull(err) || err != lfq.ErrWouldBlock {
+		t.Errorf("Enqueue on full: got %v, want ErrFull == ErrWouldBlock", err)
+	}
+}
+
 // =============================================================================
 // Generic Seq Variants - Basic Operations
 // =============================================================================
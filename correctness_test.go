@@ -1228,3 +1228,124 @@ func TestThresholdExhaustion(t *testing.T) {
 		t.Logf("Threshold exhausted after %d ErrWouldBlock returns", wouldBlockCount)
 	})
 }
+
+// TestThresholdResetPath drives the threshold to the reset boundary and
+// verifies that a single Enqueue restores dequeue progress without Drain.
+//
+// Each empty Dequeue spends one unit of the 3n-1 budget; after 3n-1 of
+// them the threshold sits at 0, and the next one takes it negative, after
+// which Dequeue returns ErrWouldBlock without touching the slots. A
+// successful Enqueue resets the budget to 3n-1. MPSC has no threshold and
+// runs as a control.
+func TestThresholdResetPath(t *testing.T) {
+	const cap = 4
+	const thresholdBudget = 3*cap - 1
+
+	var ptrs [cap * 8]int
+	type ops struct {
+		enqueue func(i int) error
+		dequeue func() (int, error)
+	}
+	generic := func(q lfq.Queue[int]) ops {
+		return ops{
+			enqueue: func(i int) error { return q.Enqueue(&i) },
+			dequeue: q.Dequeue,
+		}
+	}
+	indirect := func(q lfq.QueueIndirect) ops {
+		return ops{
+			enqueue: func(i int) error { return q.Enqueue(uintptr(i)) },
+			dequeue: func() (int, error) {
+				v, err := q.Dequeue()
+				return int(v), err
+			},
+		}
+	}
+	ptr := func(q lfq.QueuePtr) ops {
+		return ops{
+			enqueue: func(i int) error {
+				ptrs[i%len(ptrs)] = i
+				return q.Enqueue(unsafe.Pointer(&ptrs[i%len(ptrs)]))
+			},
+			dequeue: func() (int, error) {
+				p, err := q.Dequeue()
+				if err != nil {
+					return 0, err
+				}
+				return *(*int)(p), nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		q    ops
+	}{
+		{"MPMC", generic(lfq.NewMPMC[int](cap))},
+		{"MPSC", generic(lfq.NewMPSC[int](cap))},
+		{"SPMC", generic(lfq.NewSPMC[int](cap))},
+		{"MPMCIndirect", indirect(lfq.NewMPMCIndirect(cap))},
+		{"MPSCIndirect", indirect(lfq.NewMPSCIndirect(cap))},
+		{"SPMCIndirect", indirect(lfq.NewSPMCIndirect(cap))},
+		{"MPMCPtr", ptr(lfq.NewMPMCPtr(cap))},
+		{"MPSCPtr", ptr(lfq.NewMPSCPtr(cap))},
+		{"SPMCPtr", ptr(lfq.NewSPMCPtr(cap))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			next := 0
+			for round := range 4 {
+				// (1) Fill to capacity, then empty the queue
+				for range cap {
+					if err := q.enqueue(next); err != nil {
+						t.Fatalf("round %d: Enqueue(%d): %v", round, next, err)
+					}
+					next++
+				}
+				if err := q.enqueue(next); err != lfq.ErrWouldBlock {
+					t.Fatalf("round %d: Enqueue on full queue: got %v, want ErrWouldBlock", round, err)
+				}
+				for i := range cap {
+					want := next - cap + i
+					if v, err := q.dequeue(); err != nil || v != want {
+						t.Fatalf("round %d: Dequeue: got (%d, %v), want (%d, nil)", round, v, err, want)
+					}
+				}
+
+				// (2) Spend the budget down to 0, then past it
+				for i := range thresholdBudget + 1 {
+					if _, err := q.dequeue(); err != lfq.ErrWouldBlock {
+						t.Fatalf("round %d: empty Dequeue %d: got %v, want ErrWouldBlock", round, i, err)
+					}
+				}
+				if _, err := q.dequeue(); err != lfq.ErrWouldBlock {
+					t.Fatalf("round %d: Dequeue with exhausted threshold: got %v, want ErrWouldBlock", round, err)
+				}
+
+				// (3) One Enqueue resets the threshold
+				if err := q.enqueue(next); err != nil {
+					t.Fatalf("round %d: Enqueue after exhaustion: %v", round, err)
+				}
+
+				// (4) Dequeue succeeds without Drain, and keeps succeeding
+				if v, err := q.dequeue(); err != nil || v != next {
+					t.Fatalf("round %d: Dequeue after reset: got (%d, %v), want (%d, nil)", round, v, err, next)
+				}
+				next++
+				for i := range cap / 2 {
+					if err := q.enqueue(next + i); err != nil {
+						t.Fatalf("round %d: Enqueue(%d): %v", round, next+i, err)
+					}
+				}
+				for i := range cap / 2 {
+					if v, err := q.dequeue(); err != nil || v != next+i {
+						t.Fatalf("round %d: Dequeue: got (%d, %v), want (%d, nil)", round, v, err, next+i)
+					}
+				}
+				next += cap / 2
+			}
+		})
+	}
+}
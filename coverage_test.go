@@ -257,41 +257,94 @@ func testFullEmptyPtr(t *testing.T, enq func(int) error, deq func() (unsafe.Poin
 // Panic Tests (Consolidated)
 // =============================================================================
 
-// TestPanicOnSmallCapacityAllTypes tests that all queue constructors panic for capacity < 2.
+// TestPanicOnSmallCapacityAllTypes tests that all queue constructors panic for
+// capacity < 2 with a message naming the constructor, the bad value, and
+// its documentation.
 func TestPanicOnSmallCapacityAllTypes(t *testing.T) {
 	constructors := []struct {
-		name string
-		fn   func()
+		name     string
+		ctor     string
+		capacity int
+		fn       func()
 	}{
-		{"Builder_New", func() { lfq.New(1) }},
-		{"MPSC", func() { lfq.NewMPSC[int](1) }},
-		{"SPMC", func() { lfq.NewSPMC[int](1) }},
-		{"MPMC", func() { lfq.NewMPMC[int](1) }},
-		{"SPSCIndirect_Zero", func() { lfq.NewSPSCIndirect(0) }},
-		{"SPSCPtr_Negative", func() { lfq.NewSPSCPtr(-1) }},
-		{"MPSCIndirect", func() { lfq.NewMPSCIndirect(1) }},
-		{"MPSCPtr", func() { lfq.NewMPSCPtr(1) }},
-		{"SPMCIndirect", func() { lfq.NewSPMCIndirect(1) }},
-		{"SPMCPtr", func() { lfq.NewSPMCPtr(1) }},
-		{"MPMCIndirect", func() { lfq.NewMPMCIndirect(1) }},
-		{"MPMCPtr", func() { lfq.NewMPMCPtr(1) }},
-		{"MPMCCompactIndirect", func() { lfq.NewMPMCCompactIndirect(1) }},
-		{"MPSCCompactIndirect", func() { lfq.NewMPSCCompactIndirect(1) }},
-		{"SPMCCompactIndirect", func() { lfq.NewSPMCCompactIndirect(1) }},
+		{"Builder_New", "New", 1, func() { lfq.New(1) }},
+		{"MPSC", "NewMPSC", 1, func() { lfq.NewMPSC[int](1) }},
+		{"SPMC", "NewSPMC", 1, func() { lfq.NewSPMC[int](1) }},
+		{"MPMC", "NewMPMC", 1, func() { lfq.NewMPMC[int](1) }},
+		{"SPSCIndirect_Zero", "NewSPSCIndirect", 0, func() { lfq.NewSPSCIndirect(0) }},
+		{"SPSCPtr_Negative", "NewSPSCPtr", -1, func() { lfq.NewSPSCPtr(-1) }},
+		{"MPSCIndirect", "NewMPSCIndirect", 1, func() { lfq.NewMPSCIndirect(1) }},
+		{"MPSCPtr", "NewMPSCPtr", 1, func() { lfq.NewMPSCPtr(1) }},
+		{"SPMCIndirect", "NewSPMCIndirect", 1, func() { lfq.NewSPMCIndirect(1) }},
+		{"SPMCPtr", "NewSPMCPtr", 1, func() { lfq.NewSPMCPtr(1) }},
+		{"MPMCIndirect", "NewMPMCIndirect", 1, func() { lfq.NewMPMCIndirect(1) }},
+		{"MPMCPtr", "NewMPMCPtr", 1, func() { lfq.NewMPMCPtr(1) }},
+		{"MPMCCompactIndirect", "NewMPMCCompactIndirect", 1, func() { lfq.NewMPMCCompactIndirect(1) }},
+		{"MPSCCompactIndirect", "NewMPSCCompactIndirect", 1, func() { lfq.NewMPSCCompactIndirect(1) }},
+		{"SPMCCompactIndirect", "NewSPMCCompactIndirect", 1, func() { lfq.NewSPMCCompactIndirect(1) }},
 	}
 
 	for c := range slices.Values(constructors) {
 		t.Run(c.name, func(t *testing.T) {
 			defer func() {
-				if r := recover(); r == nil {
+				r := recover()
+				if r == nil {
 					t.Fatal("expected panic for capacity < 2")
 				}
+				want := fmt.Sprintf("lfq: %s capacity %d < minimum 2; see https://pkg.go.dev/code.hybscloud.com/lfq#%s",
+					c.ctor, c.capacity, c.ctor)
+				if r != want {
+					t.Fatalf("panic: got %q, want %q", r, want)
+				}
 			}()
 			c.fn()
 		})
 	}
 }
 
+// TestPanicArguments tests that argument checks outside the constructors
+// panic with a message naming the function, the bad value, and its
+// documentation.
+func TestPanicArguments(t *testing.T) {
+	const doc = "; see https://pkg.go.dev/code.hybscloud.com/lfq#"
+	one := []lfq.Queue[int]{lfq.NewMPMC[int](2)}
+	tests := []struct {
+		name string
+		want string
+		fn   func()
+	}{
+		{"HighWaterMark", "lfq: MPMC.HighWaterMark threshold 1.5 outside (0, 1]" + doc + "MPMC.HighWaterMark",
+			func() { lfq.NewMPMC[int](8).HighWaterMark(1.5) }},
+		{"AdaptiveThreshold", "lfq: Builder.WithAdaptiveThreshold alpha 0 outside (0, 1]" + doc + "Builder.WithAdaptiveThreshold",
+			func() { lfq.New(8).WithAdaptiveThreshold(0) }},
+		{"Interval", "lfq: AutoMPMC.SetGOMAXPROCSChangeInterval d 0 < minimum 1" + doc + "AutoMPMC.SetGOMAXPROCSChangeInterval",
+			func() { lfq.NewAutoMPMC[int](8).SetGOMAXPROCSChangeInterval(0) }},
+		{"HashRouterQueues", "lfq: NewConsistentHashRouter len(queues) 0 < minimum 1" + doc + "NewConsistentHashRouter",
+			func() { lfq.NewConsistentHashRouter[int](nil, func(*int) uint64 { return 0 }) }},
+		{"HashRouterFn", "lfq: NewConsistentHashRouter requires a hash function" + doc + "NewConsistentHashRouter",
+			func() { lfq.NewConsistentHashRouter(one, nil) }},
+		{"Weights", "lfq: NewWeightedMultiplexer len(weights) 2 != len(queues) 1" + doc + "NewWeightedMultiplexer",
+			func() { lfq.NewWeightedMultiplexer(one, []int{1, 1}) }},
+		{"CommitWrite", "lfq: SPSCRingWindow.CommitWrite n 1 > maximum 0" + doc + "SPSCRingWindow.CommitWrite",
+			func() { lfq.NewSPSCRingWindow[int](8).CommitWrite(1) }},
+		{"AffineBatch", "lfq: NewAffineMPSC batch 16 > maximum 8" + doc + "NewAffineMPSC",
+			func() { lfq.NewAffineMPSC[int](8, 16) }},
+		{"Priority", "lfq: MPMCPriorityAged.EnqueueWithPriority priority 2 > maximum 1" + doc + "MPMCPriorityAged.EnqueueWithPriority",
+			func() { v := 1; lfq.NewMPMCPriorityAged[int](8, 2, 4).EnqueueWithPriority(&v, 2) }},
+		{"BuildSPSCOverflow", "lfq: BuildSPSC does not support overflow policies" + doc + "BuildSPSC",
+			func() {
+				lfq.BuildSPSC[int](lfq.New(8).SingleProducer().SingleConsumer().WithOverflowPolicy(lfq.OverflowDrop, nil))
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := recovered(tt.fn); r != tt.want {
+				t.Fatalf("panic: got %v, want %q", r, tt.want)
+			}
+		})
+	}
+}
+
 // TestPanicBuildSPSC tests that BuildSPSC panics without proper constraints.
 func TestPanicBuildSPSC(t *testing.T) {
 	tests := []struct {
@@ -500,25 +553,77 @@ func TestTypedBuildersWithoutCompact(t *testing.T) {
 func TestPanicCompact63Bit(t *testing.T) {
 	constructors := []struct {
 		name string
+		typ  string
 		fn   func()
 	}{
-		{"MPMCCompact", func() { lfq.NewMPMCCompactIndirect(4).Enqueue(1 << 63) }},
-		{"MPSCCompact", func() { lfq.NewMPSCCompactIndirect(4).Enqueue(1 << 63) }},
-		{"SPMCCompact", func() { lfq.NewSPMCCompactIndirect(4).Enqueue(1 << 63) }},
+		{"MPMCCompact", "MPMCCompactIndirect", func() { lfq.NewMPMCCompactIndirect(4).Enqueue(1 << 63) }},
+		{"MPSCCompact", "MPSCCompactIndirect", func() { lfq.NewMPSCCompactIndirect(4).Enqueue(1 << 63) }},
+		{"SPMCCompact", "SPMCCompactIndirect", func() { lfq.NewSPMCCompactIndirect(4).Enqueue(1 << 63) }},
 	}
 
 	for c := range slices.Values(constructors) {
 		t.Run(c.name, func(t *testing.T) {
 			defer func() {
-				if r := recover(); r == nil {
+				r := recover()
+				if r == nil {
 					t.Fatal("expected panic for 63-bit value")
 				}
+				want := "lfq: " + c.typ + ": value 9223372036854775808 exceeds 63-bit limit (bit 63 is reserved for the empty flag)"
+				if r != want {
+					t.Fatalf("panic: got %q, want %q", r, want)
+				}
 			}()
 			c.fn()
 		})
 	}
 }
 
+// TestPanicPtrEnqueueNil tests that Ptr queues reject nil in Enqueue and
+// accept it in EnqueueNilable.
+func TestPanicPtrEnqueueNil(t *testing.T) {
+	type nilableQueue interface {
+		lfq.QueuePtr
+		EnqueueNilable(elem unsafe.Pointer) error
+	}
+	queues := []struct {
+		name string
+		q    nilableQueue
+	}{
+		{"MPMCPtr", lfq.NewMPMCPtr(4)},
+		{"MPMCPtrSeq", lfq.NewMPMCPtrSeq(4)},
+		{"MPSCPtr", lfq.NewMPSCPtr(4)},
+		{"MPSCPtrSeq", lfq.NewMPSCPtrSeq(4)},
+		{"SPMCPtr", lfq.NewSPMCPtr(4)},
+		{"SPMCPtrSeq", lfq.NewSPMCPtrSeq(4)},
+		{"SPSCPtr", lfq.NewSPSCPtr(4)},
+	}
+
+	for c := range slices.Values(queues) {
+		t.Run(c.name, func(t *testing.T) {
+			func() {
+				defer func() {
+					want := "lfq: " + c.name + ".Enqueue: nil pointer not allowed"
+					if r := recover(); r != want {
+						t.Fatalf("panic: got %v, want %q", r, want)
+					}
+				}()
+				c.q.Enqueue(nil)
+			}()
+
+			if err := c.q.EnqueueNilable(nil); err != nil {
+				t.Fatalf("EnqueueNilable(nil): %v", err)
+			}
+			ptr, err := c.q.Dequeue()
+			if err != nil {
+				t.Fatalf("Dequeue: %v", err)
+			}
+			if ptr != nil {
+				t.Fatalf("Dequeue: got %v, want nil", ptr)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // Capacity Rounding Tests
 // =============================================================================
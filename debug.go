@@ -0,0 +1,129 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"strconv"
+
+	"code.hybscloud.com/atomix"
+)
+
+// DebugSnapshot is the internal index state of an FAA-based queue, for
+// diagnosing threshold exhaustion or livelock.
+//
+// Fields are read with separate relaxed loads while the queue may be in
+// use, so a snapshot is not a consistent view: Head can exceed Tail, for
+// example, while consumers overshoot an empty queue. Threshold is 0 for
+// queues without one (MPSC).
+type DebugSnapshot struct {
+	Head      uint64
+	Tail      uint64
+	Threshold int64
+	Draining  bool
+	Cap       int
+}
+
+// String formats the snapshot as "head=H tail=T threshold=N draining=B cap=C".
+func (s DebugSnapshot) String() string {
+	return "head=" + strconv.FormatUint(s.Head, 10) +
+		" tail=" + strconv.FormatUint(s.Tail, 10) +
+		" threshold=" + strconv.FormatInt(s.Threshold, 10) +
+		" draining=" + strconv.FormatBool(s.Draining) +
+		" cap=" + strconv.Itoa(s.Cap)
+}
+
+// debugState reads a DebugSnapshot; threshold is nil for queues without one.
+func debugState(head, tail *atomix.Uint64, threshold *atomix.Int64, state *lifecycle, capacity uint64) DebugSnapshot {
+	s := DebugSnapshot{
+		Head:     head.LoadRelaxed(),
+		Tail:     tail.LoadRelaxed(),
+		Draining: state.draining(),
+		Cap:      int(capacity),
+	}
+	if threshold != nil {
+		s.Threshold = threshold.LoadRelaxed()
+	}
+	return s
+}
+
+// checkFAA panics if q breaks an invariant of the FAA queues: producers
+// never run more than the 2n physical slots ahead of consumers, and the
+// threshold never exceeds its reset value 3n-1, or the ceiling of an
+// adaptive threshold. Deferred in every Enqueue and Dequeue under the
+// lfq_debug build tag.
+func checkFAA(typ string, q interface{ DebugState() DebugSnapshot }) {
+	s := q.DebugState()
+	n := int64(s.Cap)
+	ceiling, bound := 3*n-1, "3*cap-1"
+	if t, ok := q.(interface{ thresholdTuner() *thresholdTuner }); ok && t.thresholdTuner() != nil {
+		ceiling, bound = t.thresholdTuner().ceiling(), "its adaptive ceiling"
+	}
+	switch {
+	case int64(s.Tail-s.Head) > 2*n:
+		panic("lfq: " + typ + " invariant violated: tail-head exceeds 2*cap (" + s.String() + ")")
+	case s.Threshold > ceiling:
+		panic("lfq: " + typ + " invariant violated: threshold exceeds " + bound + " (" + s.String() + ")")
+	}
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *MPMC[T]) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, &q.threshold, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *MPMC[T]) DebugString() string {
+	return "MPMC " + q.DebugState().String()
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *MPSC[T]) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, nil, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *MPSC[T]) DebugString() string {
+	return "MPSC " + q.DebugState().String()
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *SPMC[T]) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, &q.threshold, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *SPMC[T]) DebugString() string {
+	return "SPMC " + q.DebugState().String()
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *MPMCIndirect) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, &q.threshold, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *MPMCIndirect) DebugString() string {
+	return "MPMCIndirect " + q.DebugState().String()
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *MPSCIndirect) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, nil, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *MPSCIndirect) DebugString() string {
+	return "MPSCIndirect " + q.DebugState().String()
+}
+
+// DebugState returns the queue's index state. See [DebugSnapshot].
+func (q *SPMCIndirect) DebugState() DebugSnapshot {
+	return debugState(&q.head, &q.tail, &q.threshold, &q.state, q.capacity)
+}
+
+// DebugString formats DebugState for logs.
+func (q *SPMCIndirect) DebugString() string {
+	return "SPMCIndirect " + q.DebugState().String()
+}
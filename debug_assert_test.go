@@ -0,0 +1,50 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build lfq_debug
+
+package lfq
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDebugAssert corrupts queue indices and verifies the lfq_debug
+// invariant checks panic after the next operation.
+func TestDebugAssert(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func() func()
+		want    string
+	}{
+		{"MPMC tail", func() func() {
+			q := NewMPMC[int](4)
+			q.tail.StoreRelaxed(100)
+			return func() { q.Dequeue() }
+		}, "MPMC invariant violated: tail-head exceeds 2*cap"},
+		{"MPMC threshold", func() func() {
+			q := NewMPMC[int](4)
+			q.threshold.StoreRelaxed(1000)
+			return func() { q.Dequeue() }
+		}, "MPMC invariant violated: threshold exceeds 3*cap-1"},
+		{"MPSCIndirect tail", func() func() {
+			q := NewMPSCIndirect(4)
+			q.tail.StoreRelaxed(100)
+			return func() { q.Enqueue(1) }
+		}, "MPSCIndirect invariant violated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.corrupt()
+			defer func() {
+				r, _ := recover().(string)
+				if !strings.Contains(r, tt.want) {
+					t.Fatalf("panic: got %q, want %q", r, tt.want)
+				}
+			}()
+			op()
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestDebugState tests the index snapshot of FAA queues after a few
+// operations and its string form.
+func TestDebugState(t *testing.T) {
+	q := lfq.NewMPMC[int](8)
+	for i := range 3 {
+		q.Enqueue(&i)
+	}
+	q.Dequeue()
+	q.Drain()
+
+	want := lfq.DebugSnapshot{Head: 1, Tail: 3, Threshold: 3*8 - 1, Draining: true, Cap: 8}
+	if got := q.DebugState(); got != want {
+		t.Fatalf("DebugState: got %+v, want %+v", got, want)
+	}
+	if got, want := q.DebugString(), "MPMC head=1 tail=3 threshold=23 draining=true cap=8"; got != want {
+		t.Fatalf("DebugString: got %q, want %q", got, want)
+	}
+
+	mpsc := lfq.NewMPSCIndirect(4)
+	mpsc.Enqueue(1)
+	if got, want := mpsc.DebugString(), "MPSCIndirect head=0 tail=1 threshold=0 draining=false cap=4"; got != want {
+		t.Fatalf("DebugString: got %q, want %q", got, want)
+	}
+}
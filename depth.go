@@ -0,0 +1,878 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// depther is implemented by every queue in this package.
+// depth reports an advisory element count: under concurrent access the
+// result may be stale by the time the caller observes it.
+type depther interface {
+	depth() int
+}
+
+// indexDepth returns tail-head clamped to [0, capacity]. It backs the
+// exported Len, Empty and Full methods, which are best-effort: the
+// two loads are not one atomic snapshot, so under concurrent use the
+// result may be stale by the time the caller observes it.
+//
+// head is loaded before tail so that a monotonically advancing tail
+// cannot make the difference negative. FAA dequeuers may still move
+// head past tail transiently, which the clamp absorbs.
+func indexDepth(head, tail, capacity uint64) int {
+	if tail <= head {
+		return 0
+	}
+	if d := tail - head; d < capacity {
+		return int(d)
+	}
+	return int(capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSC[T]) depth() int {
+	if spscCompact {
+		return q.compact.depth()
+	}
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.mask+1)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCCompact[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCCompact[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCCompact[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCCompact[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.mask+1)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCCoalescing[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCCoalescing[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCCoalescing[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCCoalescing[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.mask+1)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.mask+1)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCPtr) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCPtr) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCPtr) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCPtr) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.mask+1)
+}
+
+// Len returns the number of elements: tail minus head, clamped to
+// [0, Cap()]. The two indices are loaded one after the other rather than
+// as one snapshot, so under concurrent use the result is an estimate that
+// may be stale by the time the caller observes it. It suits monitoring and
+// backpressure; track counts in application logic when they must be exact.
+func (q *MPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMC[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCPtr) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCPtr) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCPtr) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCPtr) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCSeq[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCSeq[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCSeq[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCSeq[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCIndirectSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCIndirectSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCIndirectSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCIndirectSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCPtrSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCPtrSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCPtrSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCPtrSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCCompactIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCCompactIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCCompactIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCCompactIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSC[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCPtr) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCPtr) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCPtr) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCPtr) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCSeq[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCSeq[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCSeq[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCSeq[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCIndirectSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCIndirectSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCIndirectSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCIndirectSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCPtrSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCPtrSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCPtrSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCPtrSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPSCCompactIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPSCCompactIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPSCCompactIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPSCCompactIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMC[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCPtr) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCPtr) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCPtr) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCPtr) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCSeq[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCSeq[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCSeq[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCSeq[T]) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCIndirectSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCIndirectSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCIndirectSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCIndirectSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCPtrSeq) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCPtrSeq) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCPtrSeq) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCPtrSeq) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPMCCompactIndirect) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPMCCompactIndirect) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPMCCompactIndirect) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPMCCompactIndirect) depth() int {
+	return indexDepth(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity)
+}
+
+// sumDepth adds the depths of qs, for queues built from several inner
+// queues.
+func sumDepth[Q depther](qs []Q) int {
+	d := 0
+	for _, q := range qs {
+		d += q.depth()
+	}
+	return d
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCIndirectPureGo) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCIndirectPureGo) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCIndirectPureGo) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCIndirectPureGo) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *SPSCBlocking[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCBlocking[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCBlocking[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCBlocking[T]) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements, counting expired elements not yet
+// dropped by Dequeue. See [MPMC.Len].
+func (q *SPSCExpiring[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *SPSCExpiring[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *SPSCExpiring[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *SPSCExpiring[T]) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *AnyQueue) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *AnyQueue) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *AnyQueue) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *AnyQueue) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *MPMCOrdered[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCOrdered[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCOrdered[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCOrdered[T]) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements. See [MPMC.Len].
+func (q *TimestampedMPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *TimestampedMPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *TimestampedMPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *TimestampedMPMC[T]) depth() int {
+	return q.q.depth()
+}
+
+// Len estimates the number of elements in the shared queue, not counting
+// elements staged by producers. See [MPMC.Len].
+func (q *AffineMPSC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *AffineMPSC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *AffineMPSC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *AffineMPSC[T]) depth() int {
+	return q.q.depth()
+}
+
+// Len returns the number of elements. The count is exact when read, as
+// BlockingMPMC holds its lock.
+func (q *BlockingMPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *BlockingMPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *BlockingMPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *BlockingMPMC[T]) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
+
+// Len estimates the number of elements across all shards. See [MPMC.Len].
+func (q *MPMCMultiBuffer[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCMultiBuffer[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCMultiBuffer[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCMultiBuffer[T]) depth() int {
+	return min(sumDepth(q.subs), q.Cap())
+}
+
+// Len estimates the number of elements across all priority levels. See
+// [MPMC.Len].
+func (q *MPMCPriorityAged[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCPriorityAged[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCPriorityAged[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCPriorityAged[T]) depth() int {
+	return min(sumDepth(q.levels), q.Cap())
+}
+
+// Len estimates the number of elements across the current and retiring
+// generations. See [MPMC.Len].
+func (q *AdaptiveMPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *AdaptiveMPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *AdaptiveMPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *AdaptiveMPMC[T]) depth() int {
+	d := sumDepth(q.cur.Load().shards)
+	if p := q.prev.Load(); p != nil {
+		d += sumDepth(p.shards)
+	}
+	return min(d, q.Cap())
+}
+
+// Len estimates the number of elements across the current and retiring
+// queues. See [MPMC.Len].
+func (q *AutoMPMC[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *AutoMPMC[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *AutoMPMC[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *AutoMPMC[T]) depth() int {
+	d := q.cur.Load().q.depth()
+	if p := q.prev.Load(); p != nil {
+		d += p.q.depth()
+	}
+	return min(d, q.Cap())
+}
+
+// Len estimates the number of elements across the live segments. See
+// [MPMC.Len].
+func (q *MPMCLazy[T]) Len() int {
+	return q.depth()
+}
+
+// Empty reports whether Len is 0.
+func (q *MPMCLazy[T]) Empty() bool {
+	return q.depth() == 0
+}
+
+// Full reports whether Len equals Cap.
+func (q *MPMCLazy[T]) Full() bool {
+	return q.depth() == q.Cap()
+}
+
+func (q *MPMCLazy[T]) depth() int {
+	d := 0
+	for s := q.cur.Load(); s != nil; s = s.older.Load() {
+		d += s.q.depth()
+	}
+	return min(d, q.capacity)
+}
+
+// depthMarks records the highest and lowest depth observed by a queue
+// built WithDepthTracking. Queues hold a nil *depthMarks otherwise, and
+// the read methods below then report the values of an empty history.
+// Both marks live on their own cache lines, apart from head and tail.
+type depthMarks struct {
+	_   pad
+	max atomix.Int64 // Highest depth seen after a successful Enqueue
+	_   padShort
+	min atomix.Int64 // Lowest depth seen after a successful Dequeue
+	_   padShort
+}
+
+// newDepthMarks returns marks for an empty queue of capacity n.
+func newDepthMarks(n uint64) *depthMarks {
+	m := &depthMarks{}
+	m.resetMin(n)
+	return m
+}
+
+// maxDepth returns the highest depth recorded, or 0 without tracking.
+func (m *depthMarks) maxDepth() int {
+	if m == nil {
+		return 0
+	}
+	return int(m.max.LoadRelaxed())
+}
+
+// minDepth returns the lowest depth recorded, or n without tracking.
+func (m *depthMarks) minDepth(n uint64) int {
+	if m == nil {
+		return int(n)
+	}
+	return int(m.min.LoadRelaxed())
+}
+
+func (m *depthMarks) resetMax() {
+	if m != nil {
+		m.max.StoreRelaxed(0)
+	}
+}
+
+func (m *depthMarks) resetMin(n uint64) {
+	if m != nil {
+		m.min.StoreRelaxed(int64(n))
+	}
+}
+
+// raise records d if it exceeds the current maximum.
+func (m *depthMarks) raise(d int) {
+	for {
+		cur := m.max.LoadRelaxed()
+		if int64(d) <= cur || m.max.CompareAndSwapRelaxed(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+// lower records d if it is below the current minimum.
+func (m *depthMarks) lower(d int) {
+	for {
+		cur := m.min.LoadRelaxed()
+		if int64(d) >= cur || m.min.CompareAndSwapRelaxed(cur, int64(d)) {
+			return
+		}
+	}
+}
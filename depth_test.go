@@ -0,0 +1,291 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// depthTracker is implemented by the FAA-based generic queues.
+type depthTracker interface {
+	lfq.Queue[int]
+	MaxDepth() int
+	ResetMaxDepth()
+	MinDepth() int
+	ResetMinDepth()
+}
+
+// TestDepthWatermarks fills each queue to capacity, drains it to empty,
+// and checks the observed occupancy range.
+func TestDepthWatermarks(t *testing.T) {
+	tests := []struct {
+		name string
+		q    depthTracker
+	}{
+		{"MPMC", lfq.Unrecorded(lfq.BuildMPMC[int](lfq.New(8).WithDepthTracking())).(depthTracker)},
+		{"MPSC", lfq.Unrecorded(lfq.BuildMPSC[int](lfq.New(8).SingleConsumer().WithDepthTracking())).(depthTracker)},
+		{"SPMC", lfq.Unrecorded(lfq.BuildSPMC[int](lfq.New(8).SingleProducer().WithDepthTracking())).(depthTracker)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			if q.MaxDepth() != 0 || q.MinDepth() != q.Cap() {
+				t.Fatalf("initial: got (max=%d, min=%d), want (0, %d)", q.MaxDepth(), q.MinDepth(), q.Cap())
+			}
+
+			for i := range q.Cap() {
+				if err := q.Enqueue(&i); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+			for i := range q.Cap() {
+				if _, err := q.Dequeue(); err != nil {
+					t.Fatalf("Dequeue(%d): %v", i, err)
+				}
+			}
+
+			if q.MaxDepth() != q.Cap() {
+				t.Fatalf("MaxDepth: got %d, want %d", q.MaxDepth(), q.Cap())
+			}
+			if q.MinDepth() != 0 {
+				t.Fatalf("MinDepth: got %d, want 0", q.MinDepth())
+			}
+
+			// Marks survive Drain
+			if d, ok := q.(lfq.Drainer); ok {
+				d.Drain()
+			}
+			if q.MaxDepth() != q.Cap() || q.MinDepth() != 0 {
+				t.Fatalf("after Drain: got (max=%d, min=%d)", q.MaxDepth(), q.MinDepth())
+			}
+
+			q.ResetMaxDepth()
+			q.ResetMinDepth()
+			if q.MaxDepth() != 0 || q.MinDepth() != q.Cap() {
+				t.Fatalf("after reset: got (max=%d, min=%d), want (0, %d)", q.MaxDepth(), q.MinDepth(), q.Cap())
+			}
+		})
+	}
+}
+
+// TestDepthWatermarksDisabled verifies queues built without depth
+// tracking report an empty history and ignore resets.
+func TestDepthWatermarksDisabled(t *testing.T) {
+	for _, q := range []depthTracker{lfq.NewMPMC[int](8), lfq.NewMPSC[int](8), lfq.NewSPMC[int](8)} {
+		for i := range q.Cap() {
+			q.Enqueue(&i)
+		}
+		q.Dequeue()
+		q.ResetMaxDepth()
+		q.ResetMinDepth()
+		if q.MaxDepth() != 0 || q.MinDepth() != q.Cap() {
+			t.Fatalf("%T: got (max=%d, min=%d), want (0, %d)", q, q.MaxDepth(), q.MinDepth(), q.Cap())
+		}
+	}
+}
+
+// TestEnqueueAndDepth verifies the depth returned by EnqueueAndDepth and
+// DequeueAndDepth tracks the fill level exactly when uncontended.
+func TestEnqueueAndDepth(t *testing.T) {
+	q := lfq.NewSPSC[int](8)
+	for i := range q.Cap() {
+		d, err := q.EnqueueAndDepth(&i)
+		if err != nil {
+			t.Fatalf("EnqueueAndDepth(%d): %v", i, err)
+		}
+		if d != i+1 {
+			t.Fatalf("EnqueueAndDepth(%d): depth %d, want %d", i, d, i+1)
+		}
+	}
+	v := 99
+	if d, err := q.EnqueueAndDepth(&v); !errors.Is(err, lfq.ErrWouldBlock) || d != q.Cap() {
+		t.Fatalf("EnqueueAndDepth on full: got (%d, %v), want (%d, ErrWouldBlock)", d, err, q.Cap())
+	}
+	for i := range q.Cap() {
+		val, d, err := q.DequeueAndDepth()
+		if err != nil || val != i {
+			t.Fatalf("DequeueAndDepth(%d): got (%d, %v), want %d", i, val, err, i)
+		}
+		if d != q.Cap()-i-1 {
+			t.Fatalf("DequeueAndDepth(%d): depth %d, want %d", i, d, q.Cap()-i-1)
+		}
+	}
+
+	m := lfq.NewMPSC[int](4)
+	for i := range 3 {
+		if d, err := m.EnqueueAndDepth(&i); err != nil || d != i+1 {
+			t.Fatalf("MPSC EnqueueAndDepth(%d): got (%d, %v), want %d", i, d, err, i+1)
+		}
+	}
+	if _, d, err := m.DequeueAndDepth(); err != nil || d != 2 {
+		t.Fatalf("MPSC DequeueAndDepth: got (%d, %v), want 2", d, err)
+	}
+}
+
+// lenQueue is implemented by every queue with Len.
+type lenQueue interface {
+	Len() int
+	Cap() int
+	Empty() bool
+	Full() bool
+}
+
+// TestLen fills queues of each flavor to capacity and drains them,
+// checking Len, Empty and Full are exact at every step when uncontended.
+func TestLen(t *testing.T) {
+	check := func(t *testing.T, q lenQueue, enqueue func(int) error, dequeue func() error) {
+		if got := q.Len(); got != 0 || !q.Empty() || q.Full() {
+			t.Fatalf("empty: got (Len=%d, Empty=%v, Full=%v), want (0, true, false)",
+				got, q.Empty(), q.Full())
+		}
+		n := q.Cap()
+		for i := range n {
+			if err := enqueue(i + 1); err != nil {
+				t.Fatalf("Enqueue(%d): %v", i+1, err)
+			}
+			if got := q.Len(); got != i+1 {
+				t.Fatalf("Len after %d enqueues: got %d", i+1, got)
+			}
+			if q.Empty() || q.Full() != (i+1 == n) {
+				t.Fatalf("after %d enqueues: got (Empty=%v, Full=%v)", i+1, q.Empty(), q.Full())
+			}
+		}
+		for i := range n {
+			if err := dequeue(); err != nil {
+				t.Fatalf("Dequeue(%d): %v", i, err)
+			}
+			if got := q.Len(); got != n-i-1 {
+				t.Fatalf("Len after %d dequeues: got %d, want %d", i+1, got, n-i-1)
+			}
+			if q.Full() || q.Empty() != (i+1 == n) {
+				t.Fatalf("after %d dequeues: got (Empty=%v, Full=%v)", i+1, q.Empty(), q.Full())
+			}
+		}
+	}
+
+	ctx := context.Background()
+	for _, c := range ctxQueues() {
+		t.Run(c.name, func(t *testing.T) {
+			check(t, c.q, func(v int) error { return c.enqueue(ctx, v) },
+				func() error { _, err := c.dequeue(ctx); return err })
+		})
+	}
+	for name, q := range maskedQueues(1 << 63) {
+		t.Run(name+"CompactIndirect", func(t *testing.T) {
+			check(t, q.(lenQueue), func(v int) error { return q.Enqueue(uintptr(v)) },
+				func() error { _, err := q.Dequeue(); return err })
+		})
+	}
+
+	// Queues whose Enqueue or Dequeue differ from Queue[int]
+	generic := func(q lfq.Queue[int]) (func(int) error, func() error) {
+		return func(v int) error { return q.Enqueue(&v) },
+			func() error { _, err := q.Dequeue(); return err }
+	}
+	adaptive, auto := lfq.NewAdaptiveMPMC[int](8), lfq.NewAutoMPMC[int](8)
+	defer adaptive.StopMonitor()
+	defer auto.StopMonitor()
+	affine, anyQ, ordered := lfq.NewAffineMPSC[int](8, 1), lfq.NewAnyMPMC(8), lfq.NewMPMCOrdered[int](8)
+	affineP, orderedP := affine.NewProducer(), ordered.OpenProducer()
+	lazy, priority := lfq.NewMPMCLazy[int](8), lfq.NewMPMCPriorityAged[int](8, 2, 4)
+	stamped, pureGo := lfq.NewTimestampedMPMC[int](8), lfq.NewSPSCIndirectPureGo(8)
+	for _, c := range []struct {
+		name    string
+		q       lenQueue
+		enqueue func(int) error
+		dequeue func() error
+	}{
+		{"AdaptiveMPMC", adaptive, nil, nil},
+		{"AutoMPMC", auto, nil, nil},
+		{"BlockingMPMC", lfq.NewBlockingMPMC[int](8), nil, nil},
+		{"MPMCMultiBuffer", lfq.NewMPMCMultiBuffer[int](8, 2), nil, nil},
+		{"SPSCBlocking", lfq.NewSPSCBlocking[int](8), nil, nil},
+		{"SPSCExpiring", lfq.NewSPSCExpiring[int](8, time.Hour), nil, nil},
+		{"AffineMPSC", affine, func(v int) error { return affineP.Enqueue(&v) },
+			func() error { _, err := affine.Dequeue(); return err }},
+		{"AnyQueue", anyQ, func(v int) error { return anyQ.Enqueue(v) },
+			func() error { _, err := anyQ.Dequeue(); return err }},
+		{"MPMCOrdered", ordered, func(v int) error { return orderedP.Enqueue(&v) },
+			func() error { _, _, _, err := ordered.Dequeue(); return err }},
+		{"MPMCLazy", lazy, func(v int) error {
+			// Growth runs in the background: wait for the next segment
+			for lazy.Enqueue(&v) != nil {
+				runtime.Gosched()
+			}
+			return nil
+		}, func() error { _, err := lazy.Dequeue(); return err }},
+		{"MPMCPriorityAged", priority, func(v int) error { return priority.EnqueueWithPriority(&v, 0) },
+			func() error { _, err := priority.Dequeue(); return err }},
+		{"SPSCIndirectPureGo", pureGo, func(v int) error { return pureGo.Enqueue(uintptr(v)) },
+			func() error { _, err := pureGo.Dequeue(); return err }},
+		{"TimestampedMPMC", stamped, func(v int) error { return stamped.Enqueue(&v) },
+			func() error { _, err := stamped.Dequeue(); return err }},
+	} {
+		if c.enqueue == nil {
+			c.enqueue, c.dequeue = generic(c.q.(lfq.Queue[int]))
+		}
+		t.Run(c.name, func(t *testing.T) {
+			check(t, c.q, c.enqueue, c.dequeue)
+		})
+	}
+}
+
+// TestLenConcurrent samples Len while producers and consumers run and
+// checks every sample lies in [0, Cap()].
+func TestLenConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	q := lfq.NewMPMC[int](16)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if q.Enqueue(&i) != nil {
+					runtime.Gosched()
+				}
+			}
+		})
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := q.Dequeue(); err != nil {
+					runtime.Gosched()
+				}
+			}
+		})
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		n := q.Len()
+		if n < 0 || n > q.Cap() {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("Len: got %d, want within [0, %d]", n, q.Cap())
+		}
+		runtime.Gosched()
+	}
+	close(stop)
+	wg.Wait()
+}
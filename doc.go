@@ -235,6 +235,8 @@
 //
 // Queues return [ErrWouldBlock] when operations cannot proceed. This error
 // is sourced from [code.hybscloud.com/iox] for ecosystem consistency.
+// Enqueue returns it as [ErrFull] and Dequeue as [ErrEmpty]; both names
+// denote the same value, so comparisons with ErrWouldBlock still hold.
 //
 //	// Retry loop with backoff
 //	backoff := iox.Backoff{}
@@ -267,9 +269,18 @@
 //
 // Minimum capacity is 2 (already a power of 2). Panic if capacity < 2.
 //
-// Length is intentionally not provided because accurate counts in lock-free
-// algorithms require expensive cross-core synchronization. Track counts in
-// application logic when needed.
+// An exact length would need head and tail to be read as one atomic
+// snapshot, which costs cross-core synchronization on every operation.
+// Every queue type instead provides Len, an estimate for monitoring and
+// backpressure: it loads head, then tail, and returns their difference
+// clamped to [0, Cap()]. Concurrent operations may change the count while
+// it is read, so the result can be stale by the time it is used. The Empty
+// and Full methods are the same estimate compared with 0 and Cap(); they
+// suit skipping work or reporting backpressure, but an operation can still
+// fail after Full or Empty returned false, so always check the error
+// Enqueue or Dequeue returns. The package-level IsEmpty and IsFull
+// classify such an error and do not inspect any queue. Track counts in
+// application logic when they must be exact.
 //
 // # Thread Safety
 //
@@ -288,6 +299,8 @@
 // FAA-based queues (MPMC, SPMC, MPSC) include a threshold mechanism to prevent
 // livelock. This mechanism may cause Dequeue to return [ErrWouldBlock] even when
 // items remain, waiting for producer activity to reset the threshold.
+// [Builder.WithAdaptiveThreshold] raises the value the threshold is reset
+// to while consumers are often blocked.
 //
 // For graceful shutdown scenarios where producers have finished but consumers
 // need to drain remaining items, use the [Drainer] interface:
@@ -310,6 +323,24 @@
 // SPSC queues do not implement [Drainer] as they have no threshold mechanism.
 // The type assertion naturally handles this case.
 //
+// # Memory Profiling
+//
+// Heap profiles attribute a queue's slot arrays to the stack that built
+// it, under lfq.New* frames such as lfq.NewMPMC. To compare the queues
+// built in different places, label them with [Builder.WithHeapProfile]:
+// each label gets a pprof profile named "lfq.<label>" that lists its live
+// queues by build stack. With net/http/pprof imported:
+//
+//	q := lfq.BuildMPMC[Job](lfq.New(1 << 20).WithHeapProfile("jobs"))
+//
+//	go tool pprof -top http://localhost:6060/debug/pprof/lfq.jobs
+//	go tool pprof -sample_index=inuse_space -focus 'lfq\.New' \
+//	    http://localhost:6060/debug/pprof/heap
+//
+// The first command counts the live "jobs" queues; the second shows the
+// bytes held by all slot arrays, and -peek can then split them by caller.
+// [Builder.WithMemStats] logs each queue's size when it is collected.
+//
 // # Race Detection
 //
 // Go's race detector is not designed for lock-free algorithm verification.
@@ -320,7 +351,9 @@
 // Lock-free queues use sequence numbers with acquire-release semantics to
 // protect non-atomic data fields. These algorithms are correct, but the race
 // detector may report false positives because it cannot track synchronization
-// provided by atomic operations on separate variables.
+// provided by atomic operations on separate variables. The source file
+// memmodel.go traces, for each queue type, which store-release and
+// load-acquire pair orders a producer's writes before a consumer's reads.
 //
 // For lock-free algorithm correctness verification, use:
 //   - Formal verification tools (TLA+, SPIN)
@@ -0,0 +1,79 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"sync/atomic"
+
+	"code.hybscloud.com/iox"
+	"code.hybscloud.com/spin"
+)
+
+// DoubleBuffer alternates two SPSC queues between one producer and one
+// consumer.
+//
+// The producer fills the active buffer while the consumer drains the
+// pending one. SwapBuffers hands the active buffer to the consumer and
+// gives the producer the drained one, as in rendering or audio pipelines
+// where a frame is published as a whole.
+type DoubleBuffer[T any] struct {
+	_       pad
+	pending atomic.Uint64 // Index of the consumer's buffer; producer writes the other
+	_       pad
+	busy    atomic.Bool // Consumer is inside Dequeue
+	_       pad
+	bufs    [2]*SPSC[T]
+}
+
+// NewDoubleBuffer creates a double buffer of two SPSC queues.
+// Capacity (per buffer) rounds up to the next power of 2.
+func NewDoubleBuffer[T any](capacity int) *DoubleBuffer[T] {
+	return &DoubleBuffer[T]{
+		bufs: [2]*SPSC[T]{NewSPSC[T](capacity), NewSPSC[T](capacity)},
+	}
+}
+
+// Enqueue adds an element to the active buffer (producer only).
+// Returns ErrWouldBlock if the active buffer is full.
+func (b *DoubleBuffer[T]) Enqueue(elem *T) error {
+	return b.bufs[b.pending.Load()^1].Enqueue(elem)
+}
+
+// Dequeue removes an element from the pending buffer (consumer only).
+// Returns ErrWouldBlock if the pending buffer is empty.
+func (b *DoubleBuffer[T]) Dequeue() (T, error) {
+	b.busy.Store(true)
+	elem, err := b.bufs[b.pending.Load()].Dequeue()
+	b.busy.Store(false)
+	return elem, err
+}
+
+// SwapBuffers publishes the active buffer to the consumer and makes the
+// drained pending buffer active (producer only).
+//
+// Blocks until the consumer has drained the pending buffer. The busy flag
+// and the pending index use sync/atomic, which is sequentially consistent:
+// a Dequeue sets busy before it loads the index, and SwapBuffers stores
+// the index before it loads busy, so either the Dequeue sees the new index
+// or the producer sees busy and waits out the Dequeue before it writes to
+// the old buffer again. The relaxed atomix operations cannot order this
+// store-then-load handshake.
+func (b *DoubleBuffer[T]) SwapBuffers() {
+	i := b.pending.Load()
+	ba := iox.Backoff{}
+	for b.bufs[i].depth() != 0 {
+		ba.Wait()
+	}
+	b.pending.Store(i ^ 1)
+	sw := spin.Wait{}
+	for b.busy.Load() {
+		sw.Once()
+	}
+}
+
+// Cap returns the capacity of each buffer.
+func (b *DoubleBuffer[T]) Cap() int {
+	return b.bufs[0].Cap()
+}
@@ -0,0 +1,70 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestDoubleBufferSequential verifies elements become visible to the
+// consumer only after SwapBuffers.
+func TestDoubleBufferSequential(t *testing.T) {
+	b := lfq.NewDoubleBuffer[int](4)
+	for i := range 4 {
+		if err := b.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	if _, err := b.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue before swap: got %v, want ErrWouldBlock", err)
+	}
+
+	b.SwapBuffers()
+	for i := range 4 {
+		v, err := b.Dequeue()
+		if err != nil || v != i {
+			t.Fatalf("Dequeue(%d): got (%d, %v), want %d", i, v, err, i)
+		}
+	}
+}
+
+// TestDoubleBufferConcurrent has a producer write two batches of 1024
+// items, swapping after each, while the consumer verifies FIFO order.
+func TestDoubleBufferConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const batch, batches = 1024, 2
+	b := lfq.NewDoubleBuffer[int](batch)
+
+	go func() {
+		for n := range batches {
+			for i := range batch {
+				v := n*batch + i
+				if err := b.Enqueue(&v); err != nil {
+					panic(err)
+				}
+			}
+			b.SwapBuffers()
+		}
+	}()
+
+	for want := 0; want < batch*batches; {
+		v, err := b.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if v != want {
+			t.Fatalf("Dequeue: got %d, want %d", v, want)
+		}
+		want++
+	}
+}
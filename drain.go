@@ -0,0 +1,173 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code.hybscloud.com/iox"
+)
+
+var errDrainWorkers = errors.New("lfq: workers must be >= 1")
+
+// DrainStats reports what a drain request observed.
+type DrainStats struct {
+	SignaledAt  time.Time // When drain mode was requested
+	ActivatedAt time.Time // When drain mode was published to consumers
+
+	// Unreclaimable estimates how far dequeuers had exhausted the livelock
+	// threshold when drain was requested: max(0, -threshold). A positive
+	// value means consumers were giving up early, so elements may have
+	// been left behind before drain mode let them through. It is advisory.
+	Unreclaimable int
+}
+
+// parallelDrain signals drain mode on d, then runs workers goroutines that
+// pass every remaining element to process until the queue reports empty.
+//
+// After Drain no producer enqueues, and drain mode lets Dequeue bypass the
+// threshold, so ErrWouldBlock from a worker means the queue is empty.
+func parallelDrain[T any](d Drainer, dequeue func() (T, error), workers int, process func(T)) error {
+	if workers < 1 {
+		return errDrainWorkers
+	}
+	d.Drain()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				elem, err := dequeue()
+				if err != nil {
+					return
+				}
+				process(elem)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// ParallelDrain drains the queue using workers goroutines.
+//
+// It calls Drain, then each worker dequeues until the queue is empty,
+// passing elements to process. process is called concurrently and must be
+// safe for concurrent use. The caller must ensure no Enqueue is in flight.
+// Returns an error if workers < 1.
+func (q *MPMC[T]) ParallelDrain(workers int, process func(T)) error {
+	return parallelDrain(q, q.Dequeue, workers, process)
+}
+
+// ParallelDrain drains the queue using workers goroutines.
+// See [MPMC.ParallelDrain].
+func (q *SPMC[T]) ParallelDrain(workers int, process func(T)) error {
+	return parallelDrain(q, q.Dequeue, workers, process)
+}
+
+// ParallelDrainIndirect drains the queue using workers goroutines.
+// See [MPMC.ParallelDrain].
+func (q *MPMCIndirect) ParallelDrainIndirect(workers int, process func(uintptr)) error {
+	return parallelDrain(q, q.Dequeue, workers, process)
+}
+
+// ParallelDrainIndirect drains the queue using workers goroutines.
+// See [MPMC.ParallelDrain].
+func (q *SPMCIndirect) ParallelDrainIndirect(workers int, process func(uintptr)) error {
+	return parallelDrain(q, q.Dequeue, workers, process)
+}
+
+// drainN dequeues up to n elements, backing off while the queue is empty,
+// until n are collected, the deadline passes or ctx is done. A zero
+// deadline means none. Backoff sleeps are capped at the time remaining.
+func drainN[T any](ctx context.Context, dequeue func() (T, error), n int, deadline time.Time) []T {
+	if n <= 0 {
+		return nil
+	}
+	out := make([]T, 0, n)
+	ba := iox.Backoff{}
+	for len(out) < n {
+		elem, err := dequeue()
+		if err == nil {
+			out = append(out, elem)
+			ba.Reset()
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				break
+			}
+			ba.SetMax(min(remaining, iox.DefaultBackoffMax))
+		}
+		ba.Wait()
+	}
+	return out
+}
+
+// DrainNWithTimeout dequeues up to n elements and returns them in order.
+//
+// Unlike a loop that stops at the first ErrWouldBlock, it keeps polling
+// with [iox.Backoff] while the queue is empty, so a consumer racing slow
+// producers does not return early. It gives up once waitFor has elapsed
+// since the call and returns what it collected.
+func (q *MPMC[T]) DrainNWithTimeout(n int, waitFor time.Duration) []T {
+	return drainN(context.Background(), q.Dequeue, n, time.Now().Add(waitFor))
+}
+
+// DrainNCtx is like DrainNWithTimeout but waits until ctx is done instead
+// of for a fixed duration. Without a ctx deadline, cancellation is noticed
+// after the current backoff sleep, at most 100ms.
+func (q *MPMC[T]) DrainNCtx(ctx context.Context, n int) []T {
+	deadline, _ := ctx.Deadline()
+	return drainN(ctx, q.Dequeue, n, deadline)
+}
+
+// DrainNWithTimeout dequeues up to n elements, waiting up to waitFor for
+// producers. See [MPMC.DrainNWithTimeout].
+func (q *MPSC[T]) DrainNWithTimeout(n int, waitFor time.Duration) []T {
+	return drainN(context.Background(), q.Dequeue, n, time.Now().Add(waitFor))
+}
+
+// DrainNCtx dequeues up to n elements, waiting until ctx is done.
+// See [MPMC.DrainNCtx].
+func (q *MPSC[T]) DrainNCtx(ctx context.Context, n int) []T {
+	deadline, _ := ctx.Deadline()
+	return drainN(ctx, q.Dequeue, n, deadline)
+}
+
+// DrainNWithTimeout dequeues up to n elements, waiting up to waitFor for
+// producers. See [MPMC.DrainNWithTimeout].
+func (q *SPMC[T]) DrainNWithTimeout(n int, waitFor time.Duration) []T {
+	return drainN(context.Background(), q.Dequeue, n, time.Now().Add(waitFor))
+}
+
+// DrainNCtx dequeues up to n elements, waiting until ctx is done.
+// See [MPMC.DrainNCtx].
+func (q *SPMC[T]) DrainNCtx(ctx context.Context, n int) []T {
+	deadline, _ := ctx.Deadline()
+	return drainN(ctx, q.Dequeue, n, deadline)
+}
+
+// DrainNWithTimeout dequeues up to n elements (consumer only), waiting up
+// to waitFor for the producer. See [MPMC.DrainNWithTimeout].
+func (q *SPSC[T]) DrainNWithTimeout(n int, waitFor time.Duration) []T {
+	return drainN(context.Background(), q.Dequeue, n, time.Now().Add(waitFor))
+}
+
+// DrainNCtx dequeues up to n elements (consumer only), waiting until ctx
+// is done. See [MPMC.DrainNCtx].
+func (q *SPSC[T]) DrainNCtx(ctx context.Context, n int) []T {
+	deadline, _ := ctx.Deadline()
+	return drainN(ctx, q.Dequeue, n, deadline)
+}
@@ -0,0 +1,192 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+// TestParallelDrain drains 1000 items with 4 workers and verifies each
+// item is processed exactly once.
+func TestParallelDrain(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const n = 1000
+	type drainer interface {
+		lfq.Queue[int]
+		ParallelDrain(workers int, process func(int)) error
+	}
+	tests := []struct {
+		name string
+		q    drainer
+	}{
+		{"MPMC", lfq.NewMPMC[int](n)},
+		{"SPMC", lfq.NewSPMC[int](n)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range n {
+				if err := tt.q.Enqueue(&i); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+
+			seen := make([]atomix.Int32, n)
+			if err := tt.q.ParallelDrain(4, func(v int) { seen[v].Add(1) }); err != nil {
+				t.Fatalf("ParallelDrain: %v", err)
+			}
+			for i := range seen {
+				if c := seen[i].Load(); c != 1 {
+					t.Fatalf("item %d processed %d times, want 1", i, c)
+				}
+			}
+		})
+	}
+
+	q := lfq.NewMPMCIndirect(n)
+	for i := range n {
+		q.Enqueue(uintptr(i))
+	}
+	var count atomix.Int64
+	if err := q.ParallelDrainIndirect(4, func(uintptr) { count.Add(1) }); err != nil {
+		t.Fatalf("ParallelDrainIndirect: %v", err)
+	}
+	if count.Load() != n {
+		t.Fatalf("ParallelDrainIndirect: processed %d, want %d", count.Load(), n)
+	}
+
+	if err := lfq.NewSPMC[int](4).ParallelDrain(0, func(int) {}); err == nil {
+		t.Fatal("ParallelDrain(0): got nil error")
+	}
+}
+
+// TestDrainWithStats exhausts the threshold with empty dequeues and
+// verifies DrainWithStats reports it.
+func TestDrainWithStats(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+	if s := q.DrainWithStats(); s.Unreclaimable != 0 {
+		t.Fatalf("Unreclaimable on fresh queue: got %d, want 0", s.Unreclaimable)
+	}
+
+	q = lfq.NewMPMC[int](4)
+	for range 32 {
+		q.Dequeue()
+	}
+	s := q.DrainWithStats()
+	if s.Unreclaimable <= 0 {
+		t.Fatalf("Unreclaimable: got %d, want > 0", s.Unreclaimable)
+	}
+	if s.SignaledAt.IsZero() || s.ActivatedAt.Before(s.SignaledAt) {
+		t.Fatalf("timestamps: SignaledAt=%v ActivatedAt=%v", s.SignaledAt, s.ActivatedAt)
+	}
+
+	v := 1
+	q.Enqueue(&v)
+	if got, err := q.Dequeue(); err != nil || got != 1 {
+		t.Fatalf("Dequeue after drain: got (%d, %v), want (1, nil)", got, err)
+	}
+}
+
+// TestDrainNWithTimeout has a producer enqueue 100 items 1ms apart while
+// the consumer calls DrainNWithTimeout(100, ...), and verifies that all
+// items arrive in order despite the queue running empty between them.
+func TestDrainNWithTimeout(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const n = 100
+	type drainNQueue interface {
+		lfq.Queue[int]
+		DrainNWithTimeout(n int, waitFor time.Duration) []int
+	}
+	tests := []struct {
+		name string
+		q    drainNQueue
+	}{
+		{"MPMC", lfq.NewMPMC[int](16)},
+		{"MPSC", lfq.NewMPSC[int](16)},
+		{"SPMC", lfq.NewSPMC[int](16)},
+		{"SPSC", lfq.NewSPSC[int](16)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			go func() {
+				for i := range n {
+					time.Sleep(time.Millisecond)
+					for tt.q.Enqueue(&i) != nil {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}()
+
+			// 100 × 1ms of production; the margin absorbs timer slack on
+			// loaded CI machines
+			got := tt.q.DrainNWithTimeout(n, time.Second)
+			if len(got) != n {
+				t.Fatalf("DrainNWithTimeout: got %d items, want %d", len(got), n)
+			}
+			for i, v := range got {
+				if v != i {
+					t.Fatalf("item %d: got %d, want %d", i, v, i)
+				}
+			}
+		})
+	}
+}
+
+// TestDrainNWithTimeoutExpires verifies that DrainNWithTimeout returns the
+// items available once waitFor elapses.
+func TestDrainNWithTimeoutExpires(t *testing.T) {
+	q := lfq.NewMPMC[int](8)
+	for i := range 3 {
+		q.Enqueue(&i)
+	}
+	start := time.Now()
+	got := q.DrainNWithTimeout(10, 20*time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("returned after %v, want >= 20ms", elapsed)
+	}
+	if len(got) != 3 {
+		t.Fatalf("DrainNWithTimeout: got %v, want 3 items", got)
+	}
+	if got := q.DrainNWithTimeout(0, time.Second); got != nil {
+		t.Fatalf("DrainNWithTimeout(0): got %v, want nil", got)
+	}
+}
+
+// TestDrainNCtx verifies that DrainNCtx stops at cancellation and at the
+// ctx deadline.
+func TestDrainNCtx(t *testing.T) {
+	q := lfq.NewSPSC[int](8)
+	for i := range 2 {
+		q.Enqueue(&i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := q.DrainNCtx(ctx, 5); len(got) != 2 {
+		t.Fatalf("DrainNCtx with canceled ctx: got %v, want 2 items", got)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if got := q.DrainNCtx(ctx, 5); len(got) != 0 {
+		t.Fatalf("DrainNCtx on empty queue: got %v, want none", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("DrainNCtx returned after %v, want about 20ms", elapsed)
+	}
+}
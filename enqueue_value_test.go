@@ -0,0 +1,102 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestEnqueueValue tests that EnqueueValue round-trips values in FIFO
+// order, reports ErrFull and does not allocate.
+func TestEnqueueValue(t *testing.T) {
+	const capacity = 8
+	spsc := lfq.NewSPSC[int](capacity)
+	mpsc := lfq.NewMPSC[int](capacity)
+	spmc := lfq.NewSPMC[int](capacity)
+	mpmc := lfq.NewMPMC[int](capacity)
+
+	tests := []struct {
+		name    string
+		enqueue func(int) error
+		q       lfq.Queue[int]
+	}{
+		{"SPSC", spsc.EnqueueValue, spsc},
+		{"MPSC", mpsc.EnqueueValue, mpsc},
+		{"SPMC", spmc.EnqueueValue, spmc},
+		{"MPMC", mpmc.EnqueueValue, mpmc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range capacity {
+				if err := tt.enqueue(i * 10); err != nil {
+					t.Fatalf("EnqueueValue(%d): %v", i*10, err)
+				}
+			}
+			if err := tt.enqueue(-1); !lfq.IsFull(err) {
+				t.Fatalf("EnqueueValue on full queue: got %v, want ErrFull", err)
+			}
+			for i := range capacity {
+				v, err := tt.q.Dequeue()
+				if err != nil || v != i*10 {
+					t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", v, err, i*10)
+				}
+			}
+
+			allocs := testing.AllocsPerRun(100, func() {
+				tt.enqueue(42)
+				tt.q.Dequeue()
+			})
+			if allocs != 0 {
+				t.Fatalf("allocs per EnqueueValue+Dequeue: got %v, want 0", allocs)
+			}
+		})
+	}
+}
+
+// BenchmarkEnqueueValue compares EnqueueValue(42) with Enqueue(&v) for
+// T=int. Both are expected to report 0 allocs/op.
+//
+//	go test -run=^$ -bench=EnqueueValue -benchmem
+func BenchmarkEnqueueValue(b *testing.B) {
+	const capacity = 1024
+
+	b.Run("MPMC/Value", func(b *testing.B) {
+		q := lfq.NewMPMC[int](capacity)
+		b.ReportAllocs()
+		for range b.N {
+			q.EnqueueValue(42)
+			q.Dequeue()
+		}
+	})
+	b.Run("MPMC/Pointer", func(b *testing.B) {
+		q := lfq.NewMPMC[int](capacity)
+		b.ReportAllocs()
+		for range b.N {
+			v := 42
+			q.Enqueue(&v)
+			q.Dequeue()
+		}
+	})
+	b.Run("SPSC/Value", func(b *testing.B) {
+		q := lfq.NewSPSC[int](capacity)
+		b.ReportAllocs()
+		for range b.N {
+			q.EnqueueValue(42)
+			q.Dequeue()
+		}
+	})
+	b.Run("SPSC/Pointer", func(b *testing.B) {
+		q := lfq.NewSPSC[int](capacity)
+		b.ReportAllocs()
+		for range b.N {
+			v := 42
+			q.Enqueue(&v)
+			q.Dequeue()
+		}
+	})
+}
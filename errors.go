@@ -4,7 +4,12 @@
 
 package lfq
 
-import "code.hybscloud.com/iox"
+import (
+	"errors"
+	"strconv"
+
+	"code.hybscloud.com/iox"
+)
 
 // ErrWouldBlock indicates the operation cannot proceed immediately.
 //
@@ -34,12 +39,57 @@ import "code.hybscloud.com/iox"
 //	}
 var ErrWouldBlock = iox.ErrWouldBlock
 
+// ErrFull is returned by Enqueue when the queue is full.
+//
+// ErrFull and ErrEmpty name the two causes of ErrWouldBlock at their
+// return sites; all three are the same value, so existing comparisons
+// with ErrWouldBlock keep working. An error does not record which
+// operation produced it: the caller tells a full queue from an empty one
+// by the operation it called.
+var ErrFull = ErrWouldBlock
+
+// ErrEmpty is returned by Dequeue when the queue is empty.
+// It is the same value as ErrWouldBlock; see [ErrFull].
+var ErrEmpty = ErrWouldBlock
+
+// ErrTimeout is returned when a bounded wait, such as
+// [MPMC.WaitForInflight], expires before its condition holds.
+var ErrTimeout = errors.New("lfq: timeout")
+
+// ErrUnknownTopic is returned by [TopicRouter.Enqueue] for a topic that
+// was not created.
+var ErrUnknownTopic = errors.New("lfq: unknown topic")
+
+// ErrDraining is returned by Enqueue on a queue in [StateDraining].
+var ErrDraining = errors.New("lfq: queue is draining")
+
+// ErrClosed is returned by Enqueue on a queue in [StateClosed], by
+// Dequeue once a closed queue is empty, and by Close on a queue already
+// closed.
+var ErrClosed = errors.New("lfq: queue is closed")
+
 // IsWouldBlock reports whether err indicates the operation would block.
 // Delegates to [iox.IsWouldBlock] for wrapped error support.
 func IsWouldBlock(err error) bool {
 	return iox.IsWouldBlock(err)
 }
 
+// IsFull reports whether err is ErrFull, i.e. an Enqueue found the queue
+// full. Since ErrFull is ErrWouldBlock, it is true for ErrEmpty as well.
+// To estimate whether a queue is full without an Enqueue, use its Full
+// method.
+func IsFull(err error) bool {
+	return errors.Is(err, ErrFull)
+}
+
+// IsEmpty reports whether err is ErrEmpty, i.e. a Dequeue found the queue
+// empty. Since ErrEmpty is ErrWouldBlock, it is true for ErrFull as well.
+// To estimate whether a queue is empty without a Dequeue, use its Empty
+// method.
+func IsEmpty(err error) bool {
+	return errors.Is(err, ErrEmpty)
+}
+
 // IsSemantic reports whether err is a control flow signal (not a failure).
 // Delegates to [iox.IsSemantic].
 func IsSemantic(err error) bool {
@@ -52,3 +102,73 @@ func IsSemantic(err error) bool {
 func IsNonFailure(err error) bool {
 	return iox.IsNonFailure(err)
 }
+
+// docURL prefixes package documentation anchors in panic messages.
+const docURL = "https://pkg.go.dev/code.hybscloud.com/lfq#"
+
+// belowMinimum returns the panic message for an argument of fn below its
+// minimum, e.g. "lfq: NewMPMC capacity 1 < minimum 2; see <doc link>".
+func belowMinimum(fn, arg string, got, minimum int) string {
+	return "lfq: " + fn + " " + arg + " " + strconv.Itoa(got) +
+		" < minimum " + strconv.Itoa(minimum) + "; see " + docURL + fn
+}
+
+// aboveMaximum returns the panic message for an argument of fn above its
+// maximum, e.g. "lfq: New capacity 9223372036854775807 > maximum
+// 4611686018427387904; see <doc link>".
+func aboveMaximum(fn, arg string, got, maximum int) string {
+	return "lfq: " + fn + " " + arg + " " + strconv.Itoa(got) +
+		" > maximum " + strconv.Itoa(maximum) + "; see " + docURL + fn
+}
+
+// outsideUnitInterval returns the panic message for a fraction argument
+// of fn outside (0, 1].
+func outsideUnitInterval(fn, arg string, got float64) string {
+	return "lfq: " + fn + " " + arg + " " + strconv.FormatFloat(got, 'g', -1, 64) +
+		" outside (0, 1]; see " + docURL + fn
+}
+
+// lengthMismatch returns the panic message for slice arguments of fn
+// whose lengths must match, e.g. "lfq: NewWeightedMultiplexer
+// len(weights) 2 != len(queues) 3; see <doc link>".
+func lengthMismatch(fn, arg string, got int, other string, want int) string {
+	return "lfq: " + fn + " " + arg + " " + strconv.Itoa(got) +
+		" != " + other + " " + strconv.Itoa(want) + "; see " + docURL + fn
+}
+
+// requires returns the panic message for fn called without something it
+// needs, e.g. "lfq: NewConsistentHashRouter requires a hash function;
+// see <doc link>".
+func requires(fn, what string) string {
+	return "lfq: " + fn + " requires " + what + "; see " + docURL + fn
+}
+
+// unsupported returns the panic message for a configuration fn rejects.
+func unsupported(fn, what string) string {
+	return "lfq: " + fn + " does not support " + what + "; see " + docURL + fn
+}
+
+// exceeds63Bits returns the panic message for a value that does not fit
+// a compact queue, whose slots reserve bit 63.
+func exceeds63Bits(typ string, elem uintptr) string {
+	return "lfq: " + typ + ": value " + strconv.FormatUint(uint64(elem), 10) +
+		" exceeds 63-bit limit (bit 63 is reserved for the empty flag)"
+}
+
+// reservedBitsSet returns the panic message for a value that has bits of
+// a compact queue's reserved mask set. The default mask reports as
+// exceeds63Bits.
+func reservedBitsSet(typ string, elem, mask uintptr) string {
+	if mask == 1<<63 {
+		return exceeds63Bits(typ, elem)
+	}
+	return "lfq: " + typ + ": value 0x" + strconv.FormatUint(uint64(elem), 16) +
+		" sets reserved bits 0x" + strconv.FormatUint(uint64(elem&mask), 16) +
+		" (mask 0x" + strconv.FormatUint(uint64(mask), 16) + "); see " + docURL + "Builder.WithUpperBitMask"
+}
+
+// nilPointer returns the panic message for a nil element passed to the
+// Enqueue method of pointer queue typ.
+func nilPointer(typ string) string {
+	return "lfq: " + typ + ".Enqueue: nil pointer not allowed"
+}
@@ -0,0 +1,34 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// exactMod returns n if n is not a power of 2, else 0.
+//
+// Seq and CompactIndirect queues locate slots and rounds purely from
+// position counters, so any slot count works. A zero mod keeps the
+// bitmask fast path for power-of-2 capacities; otherwise the hot path
+// pays an integer division.
+func exactMod(n uint64) uint64 {
+	if n&(n-1) == 0 {
+		return 0
+	}
+	return n
+}
+
+// slotIndex returns pos mod capacity.
+func slotIndex(pos, mask, mod uint64) uint64 {
+	if mod == 0 {
+		return pos & mask
+	}
+	return pos % mod
+}
+
+// slotRound returns pos / capacity.
+func slotRound(pos, order, mod uint64) uint64 {
+	if mod == 0 {
+		return pos >> order
+	}
+	return pos / mod
+}
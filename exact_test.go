@@ -0,0 +1,173 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+var exactCaps = []int{3, 5, 6, 7, 100, 1000}
+
+// TestExactCapacity verifies Cap() and FIFO order across several rounds
+// for Seq and CompactIndirect queues built with non-power-of-2 capacity.
+func TestExactCapacity(t *testing.T) {
+	if c := lfq.BuildMPMC[int](lfq.New(100).Compact().ExactCapacity()).Cap(); c != 100 {
+		t.Fatalf("MPMCSeq Cap: got %d, want 100", c)
+	}
+	// Without Compact the FAA queue still rounds up
+	if c := lfq.BuildMPMC[int](lfq.New(100).ExactCapacity()).Cap(); c != 128 {
+		t.Fatalf("MPMC Cap: got %d, want 128", c)
+	}
+
+	for _, n := range exactCaps {
+		generic := map[string]lfq.Queue[int]{
+			"MPMCSeq": lfq.BuildMPMC[int](lfq.New(n).Compact().ExactCapacity()),
+			"MPSCSeq": lfq.BuildMPSC[int](lfq.New(n).SingleConsumer().Compact().ExactCapacity()),
+			"SPMCSeq": lfq.BuildSPMC[int](lfq.New(n).SingleProducer().Compact().ExactCapacity()),
+		}
+		for name, q := range generic {
+			t.Run(fmt.Sprintf("%s/%d", name, n), func(t *testing.T) {
+				if q.Cap() != n {
+					t.Fatalf("Cap: got %d, want %d", q.Cap(), n)
+				}
+				next := 0
+				for round := range 5 {
+					for i := range n {
+						v := round*n + i
+						if err := q.Enqueue(&v); err != nil {
+							t.Fatalf("round %d: Enqueue(%d): %v", round, i, err)
+						}
+					}
+					v := -1
+					if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+						t.Fatalf("round %d: Enqueue on full: got %v, want ErrWouldBlock", round, err)
+					}
+					for i := range n {
+						got, err := q.Dequeue()
+						if err != nil || got != next {
+							t.Fatalf("round %d: Dequeue(%d): got (%d, %v), want %d", round, i, got, err, next)
+						}
+						next++
+					}
+					if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+						t.Fatalf("round %d: Dequeue on empty: got %v, want ErrWouldBlock", round, err)
+					}
+				}
+			})
+		}
+
+		indirect := map[string]lfq.QueueIndirect{
+			"MPMCCompactIndirect": lfq.New(n).Compact().ExactCapacity().BuildIndirectMPMC(),
+			"MPSCCompactIndirect": lfq.New(n).SingleConsumer().Compact().ExactCapacity().BuildIndirectMPSC(),
+			"SPMCCompactIndirect": lfq.New(n).SingleProducer().Compact().ExactCapacity().BuildIndirectSPMC(),
+		}
+		for name, q := range indirect {
+			t.Run(fmt.Sprintf("%s/%d", name, n), func(t *testing.T) {
+				if q.Cap() != n {
+					t.Fatalf("Cap: got %d, want %d", q.Cap(), n)
+				}
+				next := uintptr(0)
+				for round := range 5 {
+					for i := range n {
+						if err := q.Enqueue(uintptr(round*n + i)); err != nil {
+							t.Fatalf("round %d: Enqueue(%d): %v", round, i, err)
+						}
+					}
+					if err := q.Enqueue(0); !errors.Is(err, lfq.ErrWouldBlock) {
+						t.Fatalf("round %d: Enqueue on full: got %v, want ErrWouldBlock", round, err)
+					}
+					for i := range n {
+						got, err := q.Dequeue()
+						if err != nil || got != next {
+							t.Fatalf("round %d: Dequeue(%d): got (%d, %v), want %d", round, i, got, err, next)
+						}
+						next++
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestExactCapacityConcurrent runs producers and consumers against a
+// non-power-of-2 MPMCSeq and checks every value is delivered once.
+func TestExactCapacityConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: CAS-based algorithm uses cross-variable memory ordering")
+	}
+
+	const producers, perProducer = 4, 5000
+	q := lfq.BuildMPMC[int](lfq.New(100).Compact().ExactCapacity())
+	seen := make([]atomix.Int32, producers*perProducer)
+	var consumed atomix.Int64
+	var wg sync.WaitGroup
+
+	for p := range producers {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			for i := range perProducer {
+				v := id*perProducer + i
+				for q.Enqueue(&v) != nil {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < producers*perProducer {
+				if v, err := q.Dequeue(); err == nil {
+					seen[v].Add(1)
+					consumed.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range seen {
+		if c := seen[i].Load(); c != 1 {
+			t.Fatalf("value %d delivered %d times, want 1", i, c)
+		}
+	}
+}
+
+// BenchmarkExactCapacity compares modulo indexing at cap=100 and cap=1000
+// against bitmask indexing at the next power of 2.
+func BenchmarkExactCapacity(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		for _, exact := range []bool{false, true} {
+			bl := lfq.New(n).Compact()
+			name := fmt.Sprintf("Cap%d/Bitmask", n)
+			if exact {
+				bl.ExactCapacity()
+				name = fmt.Sprintf("Cap%d/Modulo", n)
+			}
+			b.Run(name+"/MPMCSeq", func(b *testing.B) {
+				q := lfq.BuildMPMC[int](bl)
+				for i := range b.N {
+					q.Enqueue(&i)
+					q.Dequeue()
+				}
+			})
+			b.Run(name+"/MPMCCompactIndirect", func(b *testing.B) {
+				q := bl.BuildIndirectMPMC()
+				for i := range b.N {
+					q.Enqueue(uintptr(i))
+					q.Dequeue()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"errors"
+	"strconv"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/spin"
+)
+
+// ErrTooManyConsumers is returned by FairMPMC.RegisterConsumer and
+// SPMCBroadcast.RegisterConsumer when every consumer ID is taken.
+var ErrTooManyConsumers = errors.New("lfq: too many consumers")
+
+// ConsumerID identifies a registered FairMPMC consumer.
+type ConsumerID int
+
+// FairMPMC is a multi-producer multi-consumer queue that splits elements
+// evenly across a fixed number of consumers.
+//
+// Producers claim positions with the MPMCSeq slot protocol. Consumers do
+// not compete: the element at position p belongs to consumer p mod
+// maxConsumers, and each consumer walks its own positions with a private
+// cursor. Over any run of enqueues, the shares of any two consumers
+// differ by at most one element.
+//
+// Every consumer ID must be served for the queue to make progress: an
+// element assigned to an idle consumer occupies its slot until that
+// consumer dequeues it. UnregisterConsumer releases an ID so another
+// goroutine can take over its share; the cursor is kept.
+//
+// Memory: n slots (16+ bytes per slot) plus one cursor per consumer
+type FairMPMC[T any] struct {
+	_        pad
+	tail     atomix.Uint64 // Producer index
+	_        pad
+	lanes    []fairLane
+	buffer   []fairSlot[T]
+	mask     uint64
+	capacity uint64
+	stride   uint64 // maxConsumers
+}
+
+type fairSlot[T any] struct {
+	seq  atomix.Uint64
+	data T
+	_    padShort // Pad to cache line
+}
+
+type fairLane struct {
+	registered atomix.Bool
+	cursor     uint64 // Next position, owned by the registered consumer
+	_          padShort
+}
+
+// NewFairMPMC creates a new fair MPMC queue for up to maxConsumers
+// consumers. Capacity rounds up to the next power of 2.
+// Panics if maxConsumers < 1.
+func NewFairMPMC[T any](capacity, maxConsumers int) *FairMPMC[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewFairMPMC", "capacity", capacity, 2))
+	}
+	if maxConsumers < 1 {
+		panic(belowMinimum("NewFairMPMC", "maxConsumers", maxConsumers, 1))
+	}
+
+	n := uint64(roundToPow2(capacity))
+	q := &FairMPMC[T]{
+		lanes:    make([]fairLane, maxConsumers),
+		buffer:   make([]fairSlot[T], n),
+		mask:     n - 1,
+		capacity: n,
+		stride:   uint64(maxConsumers),
+	}
+	for i := uint64(0); i < n; i++ {
+		q.buffer[i].seq.StoreRelaxed(i)
+	}
+	for i := range q.lanes {
+		q.lanes[i].cursor = uint64(i)
+	}
+	return q
+}
+
+// RegisterConsumer claims a free consumer ID.
+// Returns ErrTooManyConsumers if all maxConsumers IDs are in use.
+func (q *FairMPMC[T]) RegisterConsumer() (ConsumerID, error) {
+	for i := range q.lanes {
+		if q.lanes[i].registered.CompareAndSwapAcqRel(false, true) {
+			return ConsumerID(i), nil
+		}
+	}
+	return -1, ErrTooManyConsumers
+}
+
+// UnregisterConsumer releases id. The caller must not use id afterwards.
+func (q *FairMPMC[T]) UnregisterConsumer(id ConsumerID) {
+	q.lane(id).registered.StoreRelease(false)
+}
+
+// lane returns the lane of a registered consumer, panicking otherwise.
+func (q *FairMPMC[T]) lane(id ConsumerID) *fairLane {
+	if id < 0 || int(id) >= len(q.lanes) || !q.lanes[id].registered.LoadAcquire() {
+		panic("lfq: FairMPMC: consumer " + strconv.Itoa(int(id)) + " not registered")
+	}
+	return &q.lanes[id]
+}
+
+// Enqueue adds an element to the queue.
+// Returns ErrFull if the queue is full.
+func (q *FairMPMC[T]) Enqueue(elem *T) error {
+	sw := spin.Wait{}
+	for {
+		tail := q.tail.LoadAcquire()
+		slot := &q.buffer[tail&q.mask]
+		diff := int64(slot.seq.LoadAcquire()) - int64(tail)
+
+		if diff == 0 {
+			if q.tail.CompareAndSwapAcqRel(tail, tail+1) {
+				slot.data = *elem
+				slot.seq.StoreRelease(tail + 1)
+				return nil
+			}
+		} else if diff < 0 {
+			return ErrFull
+		}
+		sw.Once()
+	}
+}
+
+// Dequeue removes and returns the next element assigned to consumer id.
+// Only the goroutine holding id may call it.
+// Returns (zero-value, ErrWouldBlock) if no element is ready for id.
+// Panics if id is not registered.
+func (q *FairMPMC[T]) Dequeue(id ConsumerID) (T, error) {
+	l := q.lane(id)
+	pos := l.cursor
+	slot := &q.buffer[pos&q.mask]
+	if slot.seq.LoadAcquire() != pos+1 {
+		var zero T
+		return zero, ErrEmpty
+	}
+
+	elem := slot.data
+	var zero T
+	slot.data = zero
+	slot.seq.StoreRelease(pos + q.capacity)
+	l.cursor = pos + q.stride
+	return elem, nil
+}
+
+// MaxConsumers returns the number of consumer IDs.
+func (q *FairMPMC[T]) MaxConsumers() int {
+	return int(q.stride)
+}
+
+// Cap returns the queue capacity.
+func (q *FairMPMC[T]) Cap() int {
+	return int(q.capacity)
+}
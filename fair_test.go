@@ -0,0 +1,127 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+// TestFairMPMCShares enqueues 1000 items and verifies 4 consumers each
+// receive exactly 250.
+func TestFairMPMCShares(t *testing.T) {
+	q := lfq.NewFairMPMC[int](1024, 4)
+	ids := make([]lfq.ConsumerID, 4)
+	for i := range ids {
+		id, err := q.RegisterConsumer()
+		if err != nil {
+			t.Fatalf("RegisterConsumer: %v", err)
+		}
+		ids[i] = id
+	}
+	if _, err := q.RegisterConsumer(); !errors.Is(err, lfq.ErrTooManyConsumers) {
+		t.Fatalf("RegisterConsumer beyond max: got %v, want ErrTooManyConsumers", err)
+	}
+
+	for i := range 1000 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for _, id := range ids {
+		n := 0
+		for {
+			v, err := q.Dequeue(id)
+			if err != nil {
+				break
+			}
+			if v%4 != int(id) {
+				t.Fatalf("consumer %d: got element %d", id, v)
+			}
+			n++
+		}
+		if n != 250 {
+			t.Fatalf("consumer %d: got %d elements, want 250", id, n)
+		}
+	}
+}
+
+// TestFairMPMCRegistration tests ID reuse and the unregistered panic.
+func TestFairMPMCRegistration(t *testing.T) {
+	q := lfq.NewFairMPMC[int](4, 2)
+	a, _ := q.RegisterConsumer()
+	q.RegisterConsumer()
+	q.UnregisterConsumer(a)
+	if id, err := q.RegisterConsumer(); err != nil || id != a {
+		t.Fatalf("RegisterConsumer after release: got (%d, %v), want (%d, nil)", id, err, a)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Dequeue with unregistered ID did not panic")
+		}
+	}()
+	q.UnregisterConsumer(a)
+	q.Dequeue(a)
+}
+
+// TestFairMPMCConcurrent runs producers and registered consumers
+// concurrently and verifies each consumer's share.
+func TestFairMPMCConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const producers, perProducer, consumers = 4, 1000, 4
+	q := lfq.NewFairMPMC[int](64, consumers)
+
+	var wg sync.WaitGroup
+	var received atomix.Int64
+	counts := make([]int, consumers)
+	for range consumers {
+		id, err := q.RegisterConsumer()
+		if err != nil {
+			t.Fatalf("RegisterConsumer: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer q.UnregisterConsumer(id)
+			for received.Load() < producers*perProducer {
+				if _, err := q.Dequeue(id); err == nil {
+					counts[id]++
+					received.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				if q.Enqueue(&i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id, n := range counts {
+		if n != producers*perProducer/consumers {
+			t.Fatalf("consumer %d: got %d elements, want %d", id, n, producers*perProducer/consumers)
+		}
+	}
+}
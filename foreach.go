@@ -0,0 +1,377 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"unsafe"
+)
+
+// forEachDrained passes each dequeued element to process until dequeue
+// reports empty.
+func forEachDrained[T any](dequeue func() (T, error), process func(T)) {
+	for {
+		elem, err := dequeue()
+		if err != nil {
+			return
+		}
+		process(elem)
+	}
+}
+
+// forEachDrainedCtx is forEachDrained that also stops when ctx is done,
+// returning ctx.Err(). ctx is checked before each dequeue.
+func forEachDrainedCtx[T any](ctx context.Context, dequeue func() (T, error), process func(T)) error {
+	done := ctx.Done()
+	for {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		elem, err := dequeue()
+		if err != nil {
+			return nil
+		}
+		process(elem)
+	}
+}
+
+// ForEachDrained is the shutdown drain loop: it calls Drain, then passes
+// every remaining element to process in dequeue order until the queue is
+// empty. Drain lifts the livelock threshold, so no element is left behind
+// because of earlier failed dequeues. The caller must ensure producers
+// have stopped.
+func (q *MPMC[T]) ForEachDrained(process func(T)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is like ForEachDrained but stops early when ctx is
+// done, returning ctx.Err(). It returns nil once the queue is empty.
+func (q *MPMC[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *MPSC[T]) ForEachDrained(process func(T)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSC[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *SPMC[T]) ForEachDrained(process func(T)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMC[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPSC[T]) ForEachDrained(process func(T)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPSC[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPSCCompact[T]) ForEachDrained(process func(T)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPSCCompact[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPMCSeq[T]) ForEachDrained(process func(T)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCSeq[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPSCSeq[T]) ForEachDrained(process func(T)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCSeq[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrained passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPMCSeq[T]) ForEachDrained(process func(T)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedCtx is ForEachDrained that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCSeq[T]) ForEachDrainedCtx(ctx context.Context, process func(T)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *MPMCIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *MPSCIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *SPMCIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPSCIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPSCIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPMCIndirectSeq) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCIndirectSeq) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPSCIndirectSeq) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCIndirectSeq) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPMCIndirectSeq) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCIndirectSeq) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPMCCompactIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCCompactIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPSCCompactIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCCompactIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedIndirect passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPMCCompactIndirect) ForEachDrainedIndirect(process func(uintptr)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedIndirectCtx is ForEachDrainedIndirect that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCCompactIndirect) ForEachDrainedIndirectCtx(ctx context.Context, process func(uintptr)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *MPMCPtr) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCPtr) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *MPSCPtr) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCPtr) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr calls Drain and passes every remaining element
+// to process. See [MPMC.ForEachDrained].
+func (q *SPMCPtr) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	q.Drain()
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCPtr) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	q.Drain()
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPSCPtr) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPSCPtr) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPMCPtrSeq) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPMCPtrSeq) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *MPSCPtrSeq) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *MPSCPtrSeq) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
+
+// ForEachDrainedPtr passes every element to process until the
+// queue is empty; the queue has no threshold to lift, so it does not
+// need Drain. See [MPMC.ForEachDrained].
+func (q *SPMCPtrSeq) ForEachDrainedPtr(process func(unsafe.Pointer)) {
+	forEachDrained(q.Dequeue, process)
+}
+
+// ForEachDrainedPtrCtx is ForEachDrainedPtr that stops early
+// when ctx is done. See [MPMC.ForEachDrainedCtx].
+func (q *SPMCPtrSeq) ForEachDrainedPtrCtx(ctx context.Context, process func(unsafe.Pointer)) error {
+	return forEachDrainedCtx(ctx, q.Dequeue, process)
+}
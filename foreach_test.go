@@ -0,0 +1,143 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestForEachDrained verifies that ForEachDrained on an MPMC holding 100
+// items calls process exactly 100 times, in FIFO order, even after failed
+// dequeues have exhausted the threshold.
+func TestForEachDrained(t *testing.T) {
+	const n = 100
+	q := lfq.NewMPMC[int](128)
+	for range 4 * q.Cap() {
+		q.Dequeue()
+	}
+	for i := range n {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	calls := 0
+	q.ForEachDrained(func(v int) {
+		if v != calls {
+			t.Fatalf("process call %d: got %d", calls, v)
+		}
+		calls++
+	})
+	if calls != n {
+		t.Fatalf("process calls: got %d, want %d", calls, n)
+	}
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue after ForEachDrained: got %v, want ErrEmpty", err)
+	}
+}
+
+// TestForEachDrainedVariants runs the generic, Indirect and Ptr forms on
+// queues with and without a threshold.
+func TestForEachDrainedVariants(t *testing.T) {
+	const n = 10
+	generic := map[string]interface {
+		lfq.Queue[int]
+		ForEachDrained(func(int))
+	}{
+		"SPSC":    lfq.NewSPSC[int](16),
+		"MPSC":    lfq.NewMPSC[int](16),
+		"SPMC":    lfq.NewSPMC[int](16),
+		"MPMCSeq": lfq.NewMPMCSeq[int](16),
+	}
+	for name, q := range generic {
+		for i := range n {
+			q.Enqueue(&i)
+		}
+		calls := 0
+		q.ForEachDrained(func(int) { calls++ })
+		if calls != n {
+			t.Errorf("%s: process calls: got %d, want %d", name, calls, n)
+		}
+	}
+
+	indirect := map[string]interface {
+		lfq.QueueIndirect
+		ForEachDrainedIndirect(func(uintptr))
+	}{
+		"SPSCIndirect":        lfq.NewSPSCIndirect(16),
+		"MPMCIndirect":        lfq.NewMPMCIndirect(16),
+		"MPMCCompactIndirect": lfq.NewMPMCCompactIndirect(16),
+	}
+	for name, q := range indirect {
+		for i := range n {
+			q.Enqueue(uintptr(i))
+		}
+		var sum uintptr
+		q.ForEachDrainedIndirect(func(v uintptr) { sum += v })
+		if sum != n*(n-1)/2 {
+			t.Errorf("%s: sum: got %d, want %d", name, sum, n*(n-1)/2)
+		}
+	}
+
+	var items [n]int
+	ptr := map[string]interface {
+		lfq.QueuePtr
+		ForEachDrainedPtr(func(unsafe.Pointer))
+	}{
+		"SPSCPtr": lfq.NewSPSCPtr(16),
+		"MPMCPtr": lfq.NewMPMCPtr(16),
+	}
+	for name, q := range ptr {
+		for i := range items {
+			q.Enqueue(unsafe.Pointer(&items[i]))
+		}
+		calls := 0
+		q.ForEachDrainedPtr(func(p unsafe.Pointer) {
+			if p != unsafe.Pointer(&items[calls]) {
+				t.Errorf("%s: process call %d: wrong pointer", name, calls)
+			}
+			calls++
+		})
+		if calls != n {
+			t.Errorf("%s: process calls: got %d, want %d", name, calls, n)
+		}
+	}
+}
+
+// TestForEachDrainedCtx verifies that cancellation stops the loop and
+// leaves the remaining items queued.
+func TestForEachDrainedCtx(t *testing.T) {
+	q := lfq.NewMPMC[int](16)
+	for i := range 10 {
+		q.Enqueue(&i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := q.ForEachDrainedCtx(ctx, func(int) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("ForEachDrainedCtx: got %v, want context.Canceled", err)
+	}
+	if calls != 3 {
+		t.Fatalf("process calls: got %d, want 3", calls)
+	}
+
+	rest := 0
+	if err := q.ForEachDrainedCtx(context.Background(), func(int) { rest++ }); err != nil {
+		t.Fatalf("ForEachDrainedCtx: %v", err)
+	}
+	if rest != 7 {
+		t.Fatalf("remaining items: got %d, want 7", rest)
+	}
+}
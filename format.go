@@ -0,0 +1,256 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "strconv"
+
+// String describes the queue the builder would create.
+//
+// The format is "<Topology>[<Algorithm>, cap=<n>, compact=<bool>]", e.g.
+// "MPMC[FAA, cap=4096, compact=false]". The reported capacity is the
+// rounded power of 2 the queue will actually have.
+func (b *Builder) String() string {
+	topo, algo := b.describe()
+	c := roundToPow2(b.opts.capacity)
+	if algo == "CAS" {
+		c = int(b.compactSlots())
+	}
+	return topo + "[" + algo +
+		", cap=" + strconv.Itoa(c) +
+		", compact=" + strconv.FormatBool(b.opts.compact) + "]"
+}
+
+// GoString returns Go source that reconstructs the builder configuration.
+func (b *Builder) GoString() string {
+	s := "lfq.New(" + strconv.Itoa(b.opts.capacity) + ")"
+	if b.opts.singleProducer {
+		s += ".SingleProducer()"
+	}
+	if b.opts.singleConsumer {
+		s += ".SingleConsumer()"
+	}
+	if b.opts.compact {
+		s += ".Compact()"
+	}
+	if b.opts.exact {
+		s += ".ExactCapacity()"
+	}
+	if b.opts.indirect {
+		s += ".Indirect()"
+	}
+	if b.opts.sampleRate > 0 {
+		s += ".WithThroughputSampleRate(" + strconv.Itoa(b.opts.sampleRate) + ")"
+	}
+	return s
+}
+
+// describe returns the topology and algorithm family selected by Build.
+func (b *Builder) describe() (topo, algo string) {
+	switch {
+	case b.opts.singleProducer && b.opts.singleConsumer:
+		return "SPSC", "Lamport"
+	case b.opts.singleProducer:
+		topo = "SPMC"
+	case b.opts.singleConsumer:
+		topo = "MPSC"
+	default:
+		topo = "MPMC"
+	}
+	if b.opts.compact {
+		return topo, "CAS"
+	}
+	return topo, "FAA"
+}
+
+// formatQueue returns "<name>[cap=<n>]".
+func formatQueue(name string, capacity int) string {
+	return name + "[cap=" + strconv.Itoa(capacity) + "]"
+}
+
+// goStringQueue returns a constructor call such as "lfq.NewMPMC[T](1024)".
+// The type parameter is erased at runtime, so generic queues use T.
+func goStringQueue(name string, generic bool, capacity int) string {
+	s := "lfq.New" + name
+	if generic {
+		s += "[T]"
+	}
+	return s + "(" + strconv.Itoa(capacity) + ")"
+}
+
+// String returns a description such as "SPSC[cap=1024]".
+func (q *SPSC[T]) String() string { return formatQueue("SPSC", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPSC[T]) GoString() string { return goStringQueue("SPSC", true, q.Cap()) }
+
+// String returns a description such as "SPSCCompact[cap=1024]".
+func (q *SPSCCompact[T]) String() string { return formatQueue("SPSCCompact", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPSCCompact[T]) GoString() string { return goStringQueue("SPSCCompact", true, q.Cap()) }
+
+// String returns a description such as "SPSCCoalescing[cap=1024]".
+func (q *SPSCCoalescing[T]) String() string { return formatQueue("SPSCCoalescing", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPSCCoalescing[T]) GoString() string {
+	return goStringQueue("SPSCCoalescing", true, q.Cap())
+}
+
+// String returns a description such as "SPSCIndirect[cap=1024]".
+func (q *SPSCIndirect) String() string { return formatQueue("SPSCIndirect", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPSCIndirect) GoString() string { return goStringQueue("SPSCIndirect", false, q.Cap()) }
+
+// String returns a description such as "SPSCPtr[cap=1024]".
+func (q *SPSCPtr) String() string { return formatQueue("SPSCPtr", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPSCPtr) GoString() string { return goStringQueue("SPSCPtr", false, q.Cap()) }
+
+// String returns a description such as "MPMC[cap=1024]".
+func (q *MPMC[T]) String() string { return formatQueue("MPMC", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMC[T]) GoString() string { return goStringQueue("MPMC", true, q.Cap()) }
+
+// String returns a description such as "MPMCIndirect[cap=1024]".
+func (q *MPMCIndirect) String() string { return formatQueue("MPMCIndirect", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCIndirect) GoString() string { return goStringQueue("MPMCIndirect", false, q.Cap()) }
+
+// String returns a description such as "MPMCPtr[cap=1024]".
+func (q *MPMCPtr) String() string { return formatQueue("MPMCPtr", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCPtr) GoString() string { return goStringQueue("MPMCPtr", false, q.Cap()) }
+
+// String returns a description such as "MPMCSeq[cap=1024]".
+func (q *MPMCSeq[T]) String() string { return formatQueue("MPMCSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCSeq[T]) GoString() string { return goStringQueue("MPMCSeq", true, q.Cap()) }
+
+// String returns a description such as "MPMCIndirectSeq[cap=1024]".
+func (q *MPMCIndirectSeq) String() string { return formatQueue("MPMCIndirectSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCIndirectSeq) GoString() string {
+	return goStringQueue("MPMCIndirectSeq", false, q.Cap())
+}
+
+// String returns a description such as "MPMCPtrSeq[cap=1024]".
+func (q *MPMCPtrSeq) String() string { return formatQueue("MPMCPtrSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCPtrSeq) GoString() string { return goStringQueue("MPMCPtrSeq", false, q.Cap()) }
+
+// String returns a description such as "MPMCCompactIndirect[cap=1024]".
+func (q *MPMCCompactIndirect) String() string {
+	return formatQueue("MPMCCompactIndirect", q.Cap())
+}
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPMCCompactIndirect) GoString() string {
+	return goStringQueue("MPMCCompactIndirect", false, q.Cap())
+}
+
+// String returns a description such as "MPSC[cap=1024]".
+func (q *MPSC[T]) String() string { return formatQueue("MPSC", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSC[T]) GoString() string { return goStringQueue("MPSC", true, q.Cap()) }
+
+// String returns a description such as "MPSCIndirect[cap=1024]".
+func (q *MPSCIndirect) String() string { return formatQueue("MPSCIndirect", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCIndirect) GoString() string { return goStringQueue("MPSCIndirect", false, q.Cap()) }
+
+// String returns a description such as "MPSCPtr[cap=1024]".
+func (q *MPSCPtr) String() string { return formatQueue("MPSCPtr", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCPtr) GoString() string { return goStringQueue("MPSCPtr", false, q.Cap()) }
+
+// String returns a description such as "MPSCSeq[cap=1024]".
+func (q *MPSCSeq[T]) String() string { return formatQueue("MPSCSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCSeq[T]) GoString() string { return goStringQueue("MPSCSeq", true, q.Cap()) }
+
+// String returns a description such as "MPSCIndirectSeq[cap=1024]".
+func (q *MPSCIndirectSeq) String() string { return formatQueue("MPSCIndirectSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCIndirectSeq) GoString() string {
+	return goStringQueue("MPSCIndirectSeq", false, q.Cap())
+}
+
+// String returns a description such as "MPSCPtrSeq[cap=1024]".
+func (q *MPSCPtrSeq) String() string { return formatQueue("MPSCPtrSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCPtrSeq) GoString() string { return goStringQueue("MPSCPtrSeq", false, q.Cap()) }
+
+// String returns a description such as "MPSCCompactIndirect[cap=1024]".
+func (q *MPSCCompactIndirect) String() string {
+	return formatQueue("MPSCCompactIndirect", q.Cap())
+}
+
+// GoString returns Go source that reconstructs the queue.
+func (q *MPSCCompactIndirect) GoString() string {
+	return goStringQueue("MPSCCompactIndirect", false, q.Cap())
+}
+
+// String returns a description such as "SPMC[cap=1024]".
+func (q *SPMC[T]) String() string { return formatQueue("SPMC", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMC[T]) GoString() string { return goStringQueue("SPMC", true, q.Cap()) }
+
+// String returns a description such as "SPMCIndirect[cap=1024]".
+func (q *SPMCIndirect) String() string { return formatQueue("SPMCIndirect", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCIndirect) GoString() string { return goStringQueue("SPMCIndirect", false, q.Cap()) }
+
+// String returns a description such as "SPMCPtr[cap=1024]".
+func (q *SPMCPtr) String() string { return formatQueue("SPMCPtr", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCPtr) GoString() string { return goStringQueue("SPMCPtr", false, q.Cap()) }
+
+// String returns a description such as "SPMCSeq[cap=1024]".
+func (q *SPMCSeq[T]) String() string { return formatQueue("SPMCSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCSeq[T]) GoString() string { return goStringQueue("SPMCSeq", true, q.Cap()) }
+
+// String returns a description such as "SPMCIndirectSeq[cap=1024]".
+func (q *SPMCIndirectSeq) String() string { return formatQueue("SPMCIndirectSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCIndirectSeq) GoString() string {
+	return goStringQueue("SPMCIndirectSeq", false, q.Cap())
+}
+
+// String returns a description such as "SPMCPtrSeq[cap=1024]".
+func (q *SPMCPtrSeq) String() string { return formatQueue("SPMCPtrSeq", q.Cap()) }
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCPtrSeq) GoString() string { return goStringQueue("SPMCPtrSeq", false, q.Cap()) }
+
+// String returns a description such as "SPMCCompactIndirect[cap=1024]".
+func (q *SPMCCompactIndirect) String() string {
+	return formatQueue("SPMCCompactIndirect", q.Cap())
+}
+
+// GoString returns Go source that reconstructs the queue.
+func (q *SPMCCompactIndirect) GoString() string {
+	return goStringQueue("SPMCCompactIndirect", false, q.Cap())
+}
@@ -0,0 +1,91 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestQueueString verifies String and GoString on every concrete queue type.
+func TestQueueString(t *testing.T) {
+	tests := []struct {
+		name string
+		q    any
+	}{
+		{"SPSC", lfq.NewSPSC[int](1024)},
+		{"SPSCCompact", lfq.NewSPSCCompact[int](1024)},
+		{"SPSCCoalescing", lfq.NewSPSCCoalescing[int](1024)},
+		{"SPSCIndirect", lfq.NewSPSCIndirect(1024)},
+		{"SPSCPtr", lfq.NewSPSCPtr(1024)},
+		{"MPMC", lfq.NewMPMC[int](1024)},
+		{"MPMCIndirect", lfq.NewMPMCIndirect(1024)},
+		{"MPMCPtr", lfq.NewMPMCPtr(1024)},
+		{"MPMCSeq", lfq.NewMPMCSeq[int](1024)},
+		{"MPMCIndirectSeq", lfq.NewMPMCIndirectSeq(1024)},
+		{"MPMCPtrSeq", lfq.NewMPMCPtrSeq(1024)},
+		{"MPMCCompactIndirect", lfq.NewMPMCCompactIndirect(1024)},
+		{"MPSC", lfq.NewMPSC[int](1024)},
+		{"MPSCIndirect", lfq.NewMPSCIndirect(1024)},
+		{"MPSCPtr", lfq.NewMPSCPtr(1024)},
+		{"MPSCSeq", lfq.NewMPSCSeq[int](1024)},
+		{"MPSCIndirectSeq", lfq.NewMPSCIndirectSeq(1024)},
+		{"MPSCPtrSeq", lfq.NewMPSCPtrSeq(1024)},
+		{"MPSCCompactIndirect", lfq.NewMPSCCompactIndirect(1024)},
+		{"SPMC", lfq.NewSPMC[int](1024)},
+		{"SPMCIndirect", lfq.NewSPMCIndirect(1024)},
+		{"SPMCPtr", lfq.NewSPMCPtr(1024)},
+		{"SPMCSeq", lfq.NewSPMCSeq[int](1024)},
+		{"SPMCIndirectSeq", lfq.NewSPMCIndirectSeq(1024)},
+		{"SPMCPtrSeq", lfq.NewSPMCPtrSeq(1024)},
+		{"SPMCCompactIndirect", lfq.NewSPMCCompactIndirect(1024)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fmt.Sprint(tt.q)
+			if s != tt.name+"[cap=1024]" {
+				t.Fatalf("String: got %q, want %q", s, tt.name+"[cap=1024]")
+			}
+
+			gs := fmt.Sprintf("%#v", tt.q)
+			if !strings.HasPrefix(gs, "lfq.New"+tt.name) || !strings.HasSuffix(gs, "(1024)") {
+				t.Fatalf("GoString: got %q", gs)
+			}
+		})
+	}
+
+	if gs := fmt.Sprintf("%#v", lfq.NewMPMC[int](1024)); gs != "lfq.NewMPMC[T](1024)" {
+		t.Fatalf("GoString: got %q, want %q", gs, "lfq.NewMPMC[T](1024)")
+	}
+}
+
+// TestBuilderString verifies the builder description for each topology.
+func TestBuilderString(t *testing.T) {
+	tests := []struct {
+		b    *lfq.Builder
+		want string
+		gs   string
+	}{
+		{lfq.New(4096), "MPMC[FAA, cap=4096, compact=false]", "lfq.New(4096)"},
+		{lfq.New(1000).Compact(), "MPMC[CAS, cap=1024, compact=true]", "lfq.New(1000).Compact()"},
+		{lfq.New(64).SingleConsumer(), "MPSC[FAA, cap=64, compact=false]", "lfq.New(64).SingleConsumer()"},
+		{lfq.New(64).SingleProducer().Compact(), "SPMC[CAS, cap=64, compact=true]", "lfq.New(64).SingleProducer().Compact()"},
+		{lfq.New(64).SingleProducer().SingleConsumer(), "SPSC[Lamport, cap=64, compact=false]", "lfq.New(64).SingleProducer().SingleConsumer()"},
+		{lfq.New(64).WithThroughputSampleRate(8), "MPMC[FAA, cap=64, compact=false]", "lfq.New(64).WithThroughputSampleRate(8)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.b.String(); got != tt.want {
+			t.Errorf("String: got %q, want %q", got, tt.want)
+		}
+		if got := fmt.Sprintf("%#v", tt.b); got != tt.gs {
+			t.Errorf("GoString: got %q, want %q", got, tt.gs)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package lfq
+
+import "runtime"
+
+// futexWait yields instead of sleeping where futexes are unavailable.
+func futexWait(addr *uint32, val uint32) {
+	runtime.Gosched()
+}
+
+// futexWake is a no-op: waiters poll via futexWait.
+func futexWake(addr *uint32) {}
@@ -0,0 +1,73 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// enqueueTracked enqueues a large heap object and returns a channel that
+// is closed once the collector has reclaimed it.
+//
+//go:noinline
+func enqueueTracked(t *testing.T, q lfq.QueuePtr) <-chan struct{} {
+	collected := make(chan struct{})
+	obj := new([1 << 16]byte)
+	runtime.AddCleanup(obj, func(ch chan struct{}) { close(ch) }, collected)
+	if err := q.Enqueue(unsafe.Pointer(obj)); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return collected
+}
+
+// collectedAfterGC runs the collector a few times and reports whether
+// collected was closed.
+func collectedAfterGC(collected <-chan struct{}) bool {
+	for range 5 {
+		runtime.GC()
+		select {
+		case <-collected:
+			return true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return false
+}
+
+// TestWithGCSafe dequeues a pointer to a large object, drops it, forces
+// GC and checks whether the object was collected while the queue is still
+// alive: always with WithGCSafe, and without it only for Ptr queues whose
+// slots the collector does not trace.
+func TestWithGCSafe(t *testing.T) {
+	tests := []struct {
+		name string
+		b    func() *lfq.Builder
+		want bool
+	}{
+		{"SPSC", func() *lfq.Builder { return lfq.New(8).SingleProducer().SingleConsumer() }, false},
+		{"SPSC/GCSafe", func() *lfq.Builder { return lfq.New(8).SingleProducer().SingleConsumer().WithGCSafe() }, true},
+		{"MPMC", func() *lfq.Builder { return lfq.New(8) }, true},
+		{"MPMC/GCSafe", func() *lfq.Builder { return lfq.New(8).WithGCSafe() }, true},
+		{"MPSC/Compact", func() *lfq.Builder { return lfq.New(8).SingleConsumer().Compact() }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.b().BuildPtr()
+			collected := enqueueTracked(t, q)
+			if _, err := q.Dequeue(); err != nil {
+				t.Fatalf("Dequeue: %v", err)
+			}
+			if got := collectedAfterGC(collected); got != tt.want {
+				t.Fatalf("collected after Dequeue: got %v, want %v", got, tt.want)
+			}
+			runtime.KeepAlive(q)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"sync/atomic"
+
+	"code.hybscloud.com/iox"
+)
+
+// generation counts the producers enqueueing into one generation of a
+// queue that replaces its backing queue at run time: AdaptiveMPMC,
+// AutoMPMC and MPMCLazy. Each generation type embeds it.
+//
+// A producer registers with the current generation before enqueueing and
+// re-checks that it is still current; the migrator swaps in the next
+// generation and waits until no producer is registered with the old one.
+// The swap and the counter are sequentially consistent, so either the
+// producer sees the new generation or the migrator waits for it. Once
+// the wait ends the old generation receives no more elements and is
+// drained, letting consumers empty it past the livelock threshold.
+//
+// The old generation keeps its elements while the new one fills, so
+// during a migration the live generations together can hold the
+// capacity of both. AdaptiveMPMC and AutoMPMC allow this; MPMCLazy bounds
+// it with a shared element count.
+type generation struct {
+	active atomic.Int64 // Producers currently enqueueing into this generation
+	_      pad
+}
+
+func (g *generation) gen() *generation { return g }
+
+// migratable is a pointer to a generation type.
+type migratable[G any] interface {
+	*G
+	gen() *generation
+	drain() // Called once no producer can reach the generation
+}
+
+// enterGeneration registers a producer with the generation cur points to
+// and returns it. The producer calls leave when its enqueue is done.
+func enterGeneration[G any, P migratable[G]](cur *atomic.Pointer[G]) P {
+	for {
+		g := cur.Load()
+		P(g).gen().active.Add(1)
+		// Re-check after registering: replaceGeneration swaps cur
+		// before waiting on active, so a producer seeing its generation
+		// still current is covered by that wait
+		if cur.Load() == g {
+			return g
+		}
+		P(g).gen().active.Add(-1)
+	}
+}
+
+// leave unregisters a producer that entered g.
+func (g *generation) leave() {
+	g.active.Add(-1)
+}
+
+// idle reports whether no producer is enqueueing into g.
+func (g *generation) idle() bool {
+	return g.active.Load() == 0
+}
+
+// replaceGeneration makes next current, waits until no producer is
+// enqueueing into the generation it replaced, drains that generation and
+// returns it.
+func replaceGeneration[G any, P migratable[G]](cur *atomic.Pointer[G], next P) P {
+	old := P(cur.Swap(next))
+	ba := iox.Backoff{}
+	for !old.gen().idle() {
+		ba.Wait()
+	}
+	old.drain()
+	return old
+}
@@ -0,0 +1,70 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// ConsistentHashRouter distributes elements over several queues by key,
+// so that elements with the same key reach the same queue and can be
+// processed in order by that queue's consumer, with no coordinator
+// between producers.
+//
+// Enqueue sends an element to queues[hashFn(elem) % len(queues)], its
+// home queue. If that queue is full it tries the following queues in
+// ring order, wrapping around, so a burst on one key does not fail while
+// other queues have room; such spilled elements lose the ordering and
+// locality of their key. The producer rules of each underlying queue
+// apply to Enqueue and the consumer rules to DequeueFrom.
+type ConsistentHashRouter[T any] struct {
+	queues []Queue[T]
+	hashFn func(*T) uint64
+}
+
+// NewConsistentHashRouter creates a router over queues that keys elements
+// with hashFn. Panics if no queues are given or hashFn is nil.
+func NewConsistentHashRouter[T any](queues []Queue[T], hashFn func(*T) uint64) *ConsistentHashRouter[T] {
+	if len(queues) == 0 {
+		panic(belowMinimum("NewConsistentHashRouter", "len(queues)", len(queues), 1))
+	}
+	if hashFn == nil {
+		panic(requires("NewConsistentHashRouter", "a hash function"))
+	}
+	return &ConsistentHashRouter[T]{queues: queues, hashFn: hashFn}
+}
+
+// Route returns the index of the home queue of elem.
+func (r *ConsistentHashRouter[T]) Route(elem *T) int {
+	return int(r.hashFn(elem) % uint64(len(r.queues)))
+}
+
+// Enqueue adds elem to its home queue, or to the next queue in ring order
+// with room. Returns ErrFull if every queue is full.
+func (r *ConsistentHashRouter[T]) Enqueue(elem *T) error {
+	_, err := r.EnqueueIdx(elem)
+	return err
+}
+
+// EnqueueIdx is like Enqueue but also returns the index of the queue that
+// received elem, or -1 with ErrFull.
+func (r *ConsistentHashRouter[T]) EnqueueIdx(elem *T) (int, error) {
+	n := len(r.queues)
+	home := r.Route(elem)
+	for i := range n {
+		idx := (home + i) % n
+		if r.queues[idx].Enqueue(elem) == nil {
+			return idx, nil
+		}
+	}
+	return -1, ErrFull
+}
+
+// DequeueFrom removes and returns an element from queues[idx].
+// Returns ErrEmpty if that queue is empty. Panics if idx is out of range.
+func (r *ConsistentHashRouter[T]) DequeueFrom(idx int) (T, error) {
+	return r.queues[idx].Dequeue()
+}
+
+// Len returns the number of queues.
+func (r *ConsistentHashRouter[T]) Len() int {
+	return len(r.queues)
+}
@@ -0,0 +1,118 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+type keyed struct {
+	key, seq int
+}
+
+func keyHash(e *keyed) uint64 {
+	// Spread small keys; the router takes the result modulo len(queues)
+	h := uint64(e.key) * 0x9E3779B97F4A7C15
+	return h ^ h>>32
+}
+
+func newRouterQueues(n, capacity int) []lfq.Queue[keyed] {
+	qs := make([]lfq.Queue[keyed], n)
+	for i := range qs {
+		qs[i] = lfq.NewMPSC[keyed](capacity)
+	}
+	return qs
+}
+
+// TestConsistentHashRouter routes 1000 keys, twice each, through four
+// queues with room for all of them and verifies every element reaches its
+// home queue, so both elements of a key arrive in the same queue in order.
+func TestConsistentHashRouter(t *testing.T) {
+	r := lfq.NewConsistentHashRouter(newRouterQueues(4, 1024), keyHash)
+	for seq := range 2 {
+		for key := range 1000 {
+			e := keyed{key, seq}
+			idx, err := r.EnqueueIdx(&e)
+			if err != nil {
+				t.Fatalf("EnqueueIdx(%d): %v", key, err)
+			}
+			if want := r.Route(&e); idx != want {
+				t.Fatalf("key %d went to queue %d, want home queue %d", key, idx, want)
+			}
+		}
+	}
+
+	queueOf := map[int]int{}
+	lastSeq := map[int]int{}
+	counts := make([]int, r.Len())
+	for idx := range r.Len() {
+		for {
+			e, err := r.DequeueFrom(idx)
+			if err != nil {
+				break
+			}
+			counts[idx]++
+			if q, ok := queueOf[e.key]; ok {
+				if q != idx {
+					t.Fatalf("key %d in queues %d and %d", e.key, q, idx)
+				}
+				if e.seq <= lastSeq[e.key] {
+					t.Fatalf("key %d: seq %d after %d", e.key, e.seq, lastSeq[e.key])
+				}
+			}
+			queueOf[e.key], lastSeq[e.key] = idx, e.seq
+		}
+	}
+	if len(queueOf) != 1000 {
+		t.Fatalf("distinct keys dequeued: got %d, want 1000", len(queueOf))
+	}
+	for idx, n := range counts {
+		if n == 0 {
+			t.Fatalf("queue %d received no elements: %v", idx, counts)
+		}
+	}
+}
+
+// TestConsistentHashRouterSpill verifies a full home queue spills to the
+// next queue in ring order, and ErrFull once all are full.
+func TestConsistentHashRouterSpill(t *testing.T) {
+	r := lfq.NewConsistentHashRouter(newRouterQueues(3, 2), func(e *keyed) uint64 { return uint64(e.key) })
+	want := []int{2, 2, 0, 0, 1, 1}
+	for i, w := range want {
+		e := keyed{key: 5, seq: i} // 5 % 3 == 2
+		if idx, err := r.EnqueueIdx(&e); err != nil || idx != w {
+			t.Fatalf("EnqueueIdx #%d: got (%d, %v), want (%d, nil)", i, idx, err, w)
+		}
+	}
+	e := keyed{key: 5}
+	if err := r.Enqueue(&e); !lfq.IsFull(err) {
+		t.Fatalf("Enqueue with all queues full: got %v, want ErrFull", err)
+	}
+	if got, err := r.DequeueFrom(0); err != nil || got.seq != 2 {
+		t.Fatalf("DequeueFrom(0): got (%+v, %v), want seq 2", got, err)
+	}
+}
+
+func TestConsistentHashRouterPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"NoQueues", func() { lfq.NewConsistentHashRouter[keyed](nil, keyHash) }},
+		{"NilHash", func() { lfq.NewConsistentHashRouter(newRouterQueues(2, 4), nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"time"
+
+	"code.hybscloud.com/iox"
+)
+
+// An FAA producer claims a position by incrementing tail and only then
+// writes its slot and publishes it by advancing the slot cycle. Between the
+// two, the position is counted by tail but invisible to consumers. Such a
+// position is recognizable: its slot still carries the cycle the producer
+// expects to find, p/capacity. A producer that found any other cycle gave
+// up the position, and consumers repair positions behind head.
+
+// inflight counts positions in [head, tail) whose slot is claimed but not
+// yet published. cycle returns the current cycle of the slot for position p.
+func inflight(head, tail, capacity, size uint64, cycle func(p uint64) uint64) int {
+	if tail <= head {
+		return 0
+	}
+	// Positions more than one ring behind tail were repaired or reused
+	head = max(head, tail-min(tail, size))
+	n := 0
+	for p := head; p < tail; p++ {
+		if cycle(p) == p/capacity {
+			n++
+		}
+	}
+	return n
+}
+
+// waitForInflight polls count until it reports zero or timeout expires.
+func waitForInflight(count func() int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	ba := iox.Backoff{}
+	ba.SetBase(10 * time.Microsecond)
+	for count() != 0 {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return ErrTimeout
+		}
+		ba.SetMax(min(remaining, time.Millisecond))
+		ba.Wait()
+	}
+	return nil
+}
+
+// Inflight returns the number of Enqueue calls that have claimed a
+// position but not yet published their element. The count is a snapshot
+// and advisory under concurrent operations.
+func (q *MPMC[T]) Inflight() int {
+	return inflight(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity, q.size,
+		func(p uint64) uint64 { return q.buffer[p&q.mask].cycle.LoadAcquire() })
+}
+
+// WaitForInflight waits until no Enqueue is between claiming a position
+// and publishing its element, so that a following Drain sees every
+// element whose Enqueue has started. Call it after producers stop issuing
+// new Enqueues. Returns ErrTimeout if in-flight operations remain after
+// timeout.
+func (q *MPMC[T]) WaitForInflight(timeout time.Duration) error {
+	return waitForInflight(q.Inflight, timeout)
+}
+
+// Inflight returns the number of Enqueue calls that have claimed a
+// position but not yet published their element. See [MPMC.Inflight].
+func (q *MPSC[T]) Inflight() int {
+	return inflight(q.head.LoadAcquire(), q.tail.LoadAcquire(), q.capacity, q.size,
+		func(p uint64) uint64 { return q.buffer[p&q.mask].cycle.LoadAcquire() })
+}
+
+// WaitForInflight waits until every started Enqueue has published its
+// element, or returns ErrTimeout. See [MPMC.WaitForInflight].
+func (q *MPSC[T]) WaitForInflight(timeout time.Duration) error {
+	return waitForInflight(q.Inflight, timeout)
+}
@@ -0,0 +1,118 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+// inflightQueue is implemented by the FAA queues with generic slots.
+type inflightQueue interface {
+	lfq.Queue[int]
+	Inflight() int
+	WaitForInflight(timeout time.Duration) error
+}
+
+// stalledEnqueue reproduces an Enqueue paused between its FAA on tail and
+// the publication of its slot, and returns a function that completes it
+// with value v.
+func stalledEnqueue(q inflightQueue, v int) (publish func()) {
+	qv := reflect.ValueOf(q).Elem()
+	tail := (*atomix.Uint64)(qv.FieldByName("tail").Addr().UnsafePointer())
+	capacity := qv.FieldByName("capacity").Uint()
+	mask := qv.FieldByName("mask").Uint()
+
+	pos := tail.Add(1) - 1
+	slot := qv.FieldByName("buffer").Index(int(pos & mask))
+	return func() {
+		*(*int)(slot.FieldByName("data").Addr().UnsafePointer()) = v
+		cycle := (*atomix.Uint64)(slot.FieldByName("cycle").Addr().UnsafePointer())
+		cycle.StoreRelease(pos/capacity + 1)
+	}
+}
+
+// TestWaitForInflight stalls an Enqueue mid-flight, verifies that
+// WaitForInflight blocks until it completes, and that the element is then
+// visible to a draining consumer.
+func TestWaitForInflight(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	tests := []struct {
+		name string
+		q    inflightQueue
+	}{
+		{"MPMC", lfq.NewMPMC[int](4)},
+		{"MPSC", lfq.NewMPSC[int](4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			for round := range 3 {
+				v := round * 10
+				if err := q.Enqueue(&v); err != nil {
+					t.Fatalf("Enqueue: %v", err)
+				}
+				if n := q.Inflight(); n != 0 {
+					t.Fatalf("Inflight after Enqueue: got %d, want 0", n)
+				}
+
+				publish := stalledEnqueue(q, v+1)
+				if n := q.Inflight(); n != 1 {
+					t.Fatalf("Inflight with stalled Enqueue: got %d, want 1", n)
+				}
+
+				done := make(chan error, 1)
+				go func() { done <- q.WaitForInflight(time.Second) }()
+				select {
+				case err := <-done:
+					t.Fatalf("WaitForInflight returned %v before the Enqueue completed", err)
+				case <-time.After(20 * time.Millisecond):
+				}
+
+				publish()
+				if err := <-done; err != nil {
+					t.Fatalf("WaitForInflight: %v", err)
+				}
+				if n := q.Inflight(); n != 0 {
+					t.Fatalf("Inflight after completion: got %d, want 0", n)
+				}
+				if lfq.GetBuildInfo().ChecksumEnabled {
+					// publish bypasses sealing; dequeuing would panic
+					return
+				}
+				for _, want := range []int{v, v + 1} {
+					if got, err := q.Dequeue(); err != nil || got != want {
+						t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestWaitForInflightTimeout verifies that an Enqueue that never
+// completes makes WaitForInflight return ErrTimeout.
+func TestWaitForInflightTimeout(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+	if err := q.WaitForInflight(0); err != nil {
+		t.Fatalf("WaitForInflight on idle queue: %v", err)
+	}
+
+	stalledEnqueue(q, 1)
+	start := time.Now()
+	if err := q.WaitForInflight(20 * time.Millisecond); err != lfq.ErrTimeout {
+		t.Fatalf("WaitForInflight: got %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("WaitForInflight returned after %v, want >= 20ms", elapsed)
+	}
+}
@@ -137,3 +137,71 @@ spsc_deq_slow:
     MOVQ    $0, elem+8(FP)
     MOVQ    $1, err+16(FP)
     RET
+
+// func SPSCEnqueue2(q uintptr, a, b uintptr) int
+//
+// Arguments (Go ABI internal):
+//   q+0(FP)  = pointer to SPSCIndirect struct
+//   a+8(FP)  = first value to enqueue
+//   b+16(FP) = second value to enqueue
+//
+// Returns:
+//   ret+24(FP) = 0 on success, 1 if fewer than 2 slots are free,
+//                2 if the two slots would straddle the end of the buffer
+//
+// Both values are written with a single 16-byte MOVOU. PUNPCKLQDQ packs
+// them into one register using SSE2 only, which is baseline on amd64.
+//
+TEXT ·SPSCEnqueue2(SB), NOSPLIT, $0-32
+    MOVQ    q+0(FP), DI          // DI = q (struct pointer)
+    MOVQ    a+8(FP), SI          // SI = a
+    MOVQ    b+16(FP), R9         // R9 = b
+
+    MOVQ    TAIL_OFF(DI), AX     // AX = tail
+    MOVQ    CACHED_HEAD(DI), BX  // BX = cachedHead
+    MOVQ    MASK_OFF(DI), CX     // CX = mask
+
+    // Fast path: if tail + 1 - cachedHead > mask, need to reload head
+    LEAQ    1(AX), DX            // DX = tail + 1
+    SUBQ    BX, DX               // DX = tail + 1 - cachedHead
+    CMPQ    DX, CX               // compare with mask
+    JA      spsc_enq2_slow       // if fewer than 2 free, slow path
+
+spsc_enq2_store:
+    MOVQ    AX, DX               // DX = tail
+    ANDQ    CX, DX               // DX = tail & mask = idx
+    CMPQ    DX, CX               // idx == mask: second slot wraps
+    JEQ     spsc_enq2_split
+
+    // buffer[idx], buffer[idx+1] = a, b
+    MOVQ    BUFFER_PTR(DI), R8   // R8 = buffer.ptr
+    LEAQ    (R8)(DX*8), R8       // R8 = &buffer[idx]
+    MOVQ    SI, X0               // X0 = {a, 0}
+    MOVQ    R9, X1               // X1 = {b, 0}
+    PUNPCKLQDQ X1, X0            // X0 = {a, b}
+    MOVOU   X0, (R8)             // 16-byte store
+
+    // q.tail.StoreRelease(tail + 2)
+    ADDQ    $2, AX
+    MOVQ    AX, TAIL_OFF(DI)
+
+    MOVQ    $0, ret+24(FP)
+    RET
+
+spsc_enq2_slow:
+    // Reload actual head: cachedHead = q.head.LoadAcquire()
+    MOVQ    HEAD_OFF(DI), BX     // BX = head (fresh load)
+    MOVQ    BX, CACHED_HEAD(DI)  // update cachedHead
+
+    // Recheck: if tail + 1 - cachedHead > mask, fewer than 2 slots free
+    LEAQ    1(AX), DX
+    SUBQ    BX, DX
+    CMPQ    DX, CX
+    JBE     spsc_enq2_store
+
+    MOVQ    $1, ret+24(FP)
+    RET
+
+spsc_enq2_split:
+    MOVQ    $2, ret+24(FP)
+    RET
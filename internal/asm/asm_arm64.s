@@ -136,3 +136,71 @@ spsc_deq_slow_arm64:
 	MOVD	$1, R8
 	MOVD	R8, err+16(FP)
 	RET
+
+// func SPSCEnqueue2(q uintptr, a, b uintptr) int
+//
+// Arguments (Go ABI internal):
+//   q+0(FP)  = pointer to SPSCIndirect struct
+//   a+8(FP)  = first value to enqueue
+//   b+16(FP) = second value to enqueue
+//
+// Returns:
+//   ret+24(FP) = 0 on success, 1 if fewer than 2 slots are free,
+//                2 if the two slots would straddle the end of the buffer
+//
+// Both values are written with a single STP (store pair).
+//
+TEXT ·SPSCEnqueue2(SB), NOSPLIT, $0-32
+	MOVD	q+0(FP), R0          // R0 = q (struct pointer)
+	MOVD	a+8(FP), R1          // R1 = a
+	MOVD	b+16(FP), R8         // R8 = b
+
+	MOVD	TAIL_OFF(R0), R2     // R2 = tail
+	MOVD	CACHED_HEAD(R0), R3  // R3 = cachedHead
+	MOVD	MASK_OFF(R0), R4     // R4 = mask
+
+	// Fast path: if tail + 1 - cachedHead > mask, need to reload head
+	ADD	$1, R2, R5           // R5 = tail + 1
+	SUB	R3, R5, R5           // R5 = tail + 1 - cachedHead
+	CMP	R4, R5
+	BHI	spsc_enq2_slow_arm64 // if fewer than 2 free, slow path
+
+spsc_enq2_store_arm64:
+	AND	R4, R2, R5           // R5 = tail & mask = idx
+	CMP	R4, R5               // idx == mask: second slot wraps
+	BEQ	spsc_enq2_split_arm64
+
+	// buffer[idx], buffer[idx+1] = a, b
+	MOVD	BUFFER_PTR(R0), R6   // R6 = buffer.ptr
+	LSL	$3, R5               // R5 = idx * 8
+	ADD	R5, R6, R6           // R6 = &buffer[idx]
+	STP	(R1, R8), (R6)       // 16-byte store pair
+
+	// q.tail.StoreRelease(tail + 2)
+	ADD	$2, R2, R2
+	ADD	$TAIL_OFF, R0, R7    // R7 = &q.tail
+	STLR	R2, (R7)             // store-release tail
+
+	MOVD	ZR, ret+24(FP)
+	RET
+
+spsc_enq2_slow_arm64:
+	// Reload actual head: cachedHead = q.head.LoadAcquire()
+	ADD	$HEAD_OFF, R0, R7    // R7 = &q.head
+	LDAR	(R7), R3             // R3 = head (load-acquire)
+	MOVD	R3, CACHED_HEAD(R0)  // update cachedHead
+
+	// Recheck: if tail + 1 - cachedHead > mask, fewer than 2 slots free
+	ADD	$1, R2, R5
+	SUB	R3, R5, R5
+	CMP	R4, R5
+	BLS	spsc_enq2_store_arm64
+
+	MOVD	$1, R9
+	MOVD	R9, ret+24(FP)
+	RET
+
+spsc_enq2_split_arm64:
+	MOVD	$2, R9
+	MOVD	R9, ret+24(FP)
+	RET
@@ -191,3 +191,28 @@ func BenchmarkSPSCIndirectAsmEnqueue(b *testing.B) {
 		}
 	}
 }
+
+func TestSPSCEnqueue2Asm(t *testing.T) {
+	q := lfq.NewSPSCIndirect(4)
+	qptr := uintptr(unsafe.Pointer(q))
+
+	if ret := asm.SPSCEnqueue2(qptr, 10, 11); ret != 0 {
+		t.Fatalf("Enqueue2 at idx 0: got %d, want 0", ret)
+	}
+	if ret := asm.SPSCEnqueue(qptr, 12); ret != 0 {
+		t.Fatalf("Enqueue at idx 2: got %d, want 0", ret)
+	}
+	// Tail at idx 3: one slot free, and it is the last one
+	if ret := asm.SPSCEnqueue2(qptr, 13, 14); ret != 1 {
+		t.Fatalf("Enqueue2 with 1 free: got %d, want 1", ret)
+	}
+	for want := uintptr(10); want < 12; want++ {
+		if elem, err := asm.SPSCDequeue(qptr); err != 0 || elem != want {
+			t.Fatalf("Dequeue: got (%d, %d), want (%d, 0)", elem, err, want)
+		}
+	}
+	// Three slots free, but idx 3 is the last one
+	if ret := asm.SPSCEnqueue2(qptr, 13, 14); ret != 2 {
+		t.Fatalf("Enqueue2 at boundary: got %d, want 2", ret)
+	}
+}
@@ -49,3 +49,16 @@ func SPSCEnqueue(q uintptr, elem uintptr) int
 //go:nosplit
 //go:noescape
 func SPSCDequeue(q uintptr) (elem uintptr, err int)
+
+// SPSCEnqueue2 enqueues a and b into two consecutive slots with a single
+// 16-byte store.
+//
+// Returns:
+//   - 0 on success
+//   - 1 if fewer than 2 slots are free (ErrWouldBlock)
+//   - 2 if the slots would straddle the end of the buffer; nothing is
+//     written and the caller enqueues the values one at a time
+//
+//go:nosplit
+//go:noescape
+func SPSCEnqueue2(q uintptr, a, b uintptr) int
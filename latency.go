@@ -0,0 +1,115 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// latencyHistogram records how long elements wait in a queue.
+//
+// Enqueue stores a monotonic timestamp for the slot before publishing it;
+// Dequeue reads it after claiming the slot, so the slot's cycle orders
+// the two like the element itself. The wait is counted in the first
+// bucket whose bound it does not exceed, or in an overflow bucket that
+// also tracks the largest wait it has seen.
+type latencyHistogram struct {
+	bounds []time.Duration // Ascending upper bounds
+	counts []atomix.Uint64 // One per bound, plus overflow
+	over   atomix.Int64    // Largest wait past the last bound
+	stamps []atomix.Int64  // Enqueue time per physical slot
+}
+
+func newLatencyHistogram(bounds []time.Duration, slots uint64) *latencyHistogram {
+	return &latencyHistogram{
+		bounds: bounds,
+		counts: make([]atomix.Uint64, len(bounds)+1),
+		stamps: make([]atomix.Int64, slots),
+	}
+}
+
+// stamp records the enqueue time of the element going into slot i.
+func (h *latencyHistogram) stamp(i uint64) {
+	h.stamps[i].StoreRelaxed(monotime())
+}
+
+// observe records the wait of the element leaving slot i.
+func (h *latencyHistogram) observe(i uint64) {
+	d := time.Duration(monotime() - h.stamps[i].LoadRelaxed())
+	b, _ := slices.BinarySearch(h.bounds, d)
+	h.counts[b].AddRelaxed(1)
+	if b < len(h.bounds) {
+		return
+	}
+	for {
+		m := h.over.LoadRelaxed()
+		if int64(d) <= m || h.over.CompareAndSwapRelaxed(m, int64(d)) {
+			return
+		}
+	}
+}
+
+// percentile returns the bound of the bucket holding the p-quantile wait.
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	var total uint64
+	for i := range h.counts {
+		total += h.counts[i].LoadRelaxed()
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := max(uint64(math.Ceil(min(max(p, 0), 1)*float64(total))), 1)
+	var seen uint64
+	for i := range h.bounds {
+		seen += h.counts[i].LoadRelaxed()
+		if seen >= rank {
+			return h.bounds[i]
+		}
+	}
+	return max(time.Duration(h.over.LoadRelaxed()), h.bounds[len(h.bounds)-1])
+}
+
+// validLatencyBuckets returns a copy of buckets, panicking unless they are
+// positive and strictly ascending.
+func validLatencyBuckets(buckets []time.Duration) []time.Duration {
+	if len(buckets) == 0 {
+		panic(belowMinimum("Builder.WithLatencyHistogram", "len(buckets)", 0, 1))
+	}
+	for i, b := range buckets {
+		if b <= 0 || i > 0 && b <= buckets[i-1] {
+			panic("lfq: Builder.WithLatencyHistogram: buckets must be positive and strictly ascending; see " + docURL + "Builder.WithLatencyHistogram")
+		}
+	}
+	return slices.Clone(buckets)
+}
+
+// LatencyPercentile returns an upper estimate of the p-quantile time
+// elements spent in the queue, for p in [0, 1], such as 0.99 for p99.
+//
+// The result is the bound of the histogram bucket holding the quantile.
+// Past the last bound it is the largest wait observed. It returns 0 if the
+// queue was not built with [Builder.WithLatencyHistogram] or nothing has
+// been dequeued yet.
+func (q *MPMC[T]) LatencyPercentile(p float64) time.Duration {
+	lat := q.obs.lat()
+	if lat == nil {
+		return 0
+	}
+	return lat.percentile(p)
+}
+
+// LatencyPercentile returns an upper estimate of the p-quantile time
+// elements spent in the queue. See [MPMC.LatencyPercentile].
+func (q *MPSC[T]) LatencyPercentile(p float64) time.Duration {
+	lat := q.obs.lat()
+	if lat == nil {
+		return 0
+	}
+	return lat.percentile(p)
+}
@@ -0,0 +1,121 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+type latencyQueue interface {
+	lfq.Queue[int]
+	LatencyPercentile(p float64) time.Duration
+}
+
+// TestLatencyHistogram holds elements for 10ms between Enqueue and
+// Dequeue and verifies LatencyPercentile(0.99) reports at least 10ms.
+func TestLatencyHistogram(t *testing.T) {
+	buckets := []time.Duration{time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond}
+	tests := []struct {
+		name string
+		q    latencyQueue
+	}{
+		{"MPMC", lfq.Unrecorded(lfq.BuildMPMC[int](lfq.New(16).WithLatencyHistogram(buckets))).(latencyQueue)},
+		{"MPSC", lfq.Unrecorded(lfq.BuildMPSC[int](lfq.New(16).SingleConsumer().WithLatencyHistogram(buckets))).(latencyQueue)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.LatencyPercentile(0.99); got != 0 {
+				t.Fatalf("LatencyPercentile before any Dequeue: got %v, want 0", got)
+			}
+			for i := range 10 {
+				if err := tt.q.Enqueue(&i); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			for i := range 10 {
+				if got, err := tt.q.Dequeue(); err != nil || got != i {
+					t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, i)
+				}
+			}
+			if got := tt.q.LatencyPercentile(0.99); got < 10*time.Millisecond {
+				t.Fatalf("LatencyPercentile(0.99): got %v, want >= 10ms", got)
+			}
+		})
+	}
+}
+
+// TestLatencyHistogramOverflow verifies a quantile past the last bucket
+// reports the largest wait observed.
+func TestLatencyHistogramOverflow(t *testing.T) {
+	q := lfq.Unrecorded(lfq.BuildMPMC[int](lfq.New(4).WithLatencyHistogram([]time.Duration{time.Microsecond}))).(*lfq.MPMC[int])
+	v := 1
+	q.Enqueue(&v)
+	time.Sleep(2 * time.Millisecond)
+	q.Dequeue()
+	if got := q.LatencyPercentile(1); got < 2*time.Millisecond {
+		t.Fatalf("LatencyPercentile(1): got %v, want >= 2ms", got)
+	}
+}
+
+func TestLatencyHistogramDisabled(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+	v := 1
+	q.Enqueue(&v)
+	q.Dequeue()
+	if got := q.LatencyPercentile(0.5); got != 0 {
+		t.Fatalf("LatencyPercentile without histogram: got %v, want 0", got)
+	}
+}
+
+func TestWithLatencyHistogramPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []time.Duration
+	}{
+		{"Empty", nil},
+		{"Zero", []time.Duration{0, time.Millisecond}},
+		{"Descending", []time.Duration{time.Millisecond, time.Microsecond}},
+		{"Duplicate", []time.Duration{time.Millisecond, time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			lfq.New(4).WithLatencyHistogram(tt.buckets)
+		})
+	}
+}
+
+// BenchmarkLatencyHistogram measures an Enqueue/Dequeue pair on MPMC
+// without a histogram and with 5, 10 and 20 buckets.
+func BenchmarkLatencyHistogram(b *testing.B) {
+	for _, n := range []int{0, 5, 10, 20} {
+		b.Run("Buckets="+strconv.Itoa(n), func(b *testing.B) {
+			builder := lfq.New(1024)
+			if n > 0 {
+				buckets := make([]time.Duration, n)
+				for i := range buckets {
+					buckets[i] = time.Microsecond << i
+				}
+				builder.WithLatencyHistogram(buckets)
+			}
+			q := lfq.BuildMPMC[int](builder)
+			v := 1
+			b.ResetTimer()
+			for range b.N {
+				q.Enqueue(&v)
+				q.Dequeue()
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+
+	"code.hybscloud.com/atomix"
+)
+
+// QueueState is a stage in the lifecycle of a queue that supports Drain.
+//
+// A queue starts Active. BeginDrain moves it to Draining, where producers
+// are refused but consumers take the remaining elements without the
+// livelock threshold. Close moves it to Closed from either state, after
+// which consumers also learn from Dequeue when nothing is left. States
+// never go back.
+//
+// Drain does not change the state: it remains the consumer-side hint of
+// [Drainer], lifting the threshold while leaving Enqueue to the caller's
+// discipline.
+//
+// The state is checked once at the start of Enqueue, so an Enqueue that
+// began before BeginDrain or Close may still complete after it; as with
+// Drain, producers should be stopped before the queue is drained.
+type QueueState int32
+
+const (
+	// StateActive accepts Enqueue and Dequeue.
+	StateActive QueueState = iota
+	// StateDraining refuses Enqueue with ErrDraining.
+	StateDraining
+	// StateClosed refuses Enqueue with ErrClosed, and Dequeue returns
+	// ErrClosed once the queue is empty.
+	StateClosed
+)
+
+// String returns "active", "draining" or "closed".
+func (s QueueState) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateDraining:
+		return "draining"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// lifecycle holds a QueueState and the Drain hint. Producers check it on
+// every Enqueue, so it takes the place of a plain drain flag on its own
+// cache line.
+type lifecycle struct {
+	v atomix.Int32 // QueueState in the low bits, drainHint above them
+}
+
+// drainHint is set by Drain, independently of the state.
+const drainHint = 1 << 8
+
+func (l *lifecycle) load() QueueState {
+	return QueueState(l.v.LoadAcquire() &^ drainHint)
+}
+
+// drain sets the Drain hint; the state is left as it is.
+func (l *lifecycle) drain() {
+	l.set(func(v int32) int32 { return v | drainHint })
+}
+
+// beginDrain moves Active to Draining; other states are left as they are.
+func (l *lifecycle) beginDrain() {
+	l.set(func(v int32) int32 {
+		if QueueState(v&^drainHint) == StateActive {
+			return v&drainHint | int32(StateDraining)
+		}
+		return v
+	})
+}
+
+// close moves Active or Draining to Closed.
+// Returns ErrClosed if the queue was already closed.
+func (l *lifecycle) close() error {
+	prev := l.set(func(v int32) int32 { return v&drainHint | int32(StateClosed) })
+	if QueueState(prev&^drainHint) == StateClosed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// set replaces the value v with f(v) and returns the previous value.
+func (l *lifecycle) set(f func(int32) int32) int32 {
+	for {
+		v := l.v.LoadAcquire()
+		if l.v.CompareAndSwapAcqRel(v, f(v)) {
+			return v
+		}
+	}
+}
+
+// draining reports whether Dequeue may bypass the threshold, which holds
+// after Drain and in every state past Active.
+func (l *lifecycle) draining() bool {
+	return l.v.LoadAcquire() != 0
+}
+
+// enqueueErr returns the error Enqueue reports in the current state, or
+// nil while Active.
+func (l *lifecycle) enqueueErr() error {
+	switch l.load() {
+	case StateActive:
+		return nil
+	case StateDraining:
+		return ErrDraining
+	}
+	return ErrClosed
+}
+
+// emptyErr returns the error Dequeue reports on an empty queue.
+func (l *lifecycle) emptyErr() error {
+	if l.load() == StateClosed {
+		return ErrClosed
+	}
+	return ErrEmpty
+}
+
+// waitFor blocks until the state is target, following DefaultSpinPolicy,
+// or ctx is done, in which case it returns ctx.Err(). Since states never
+// go back, it returns ErrClosed once the queue has closed without passing
+// through target.
+func (l *lifecycle) waitFor(ctx context.Context, target QueueState) error {
+	done := ctx.Done()
+	s := newSpinner(nil)
+	for {
+		cur := l.load()
+		if cur == target {
+			return nil
+		}
+		if cur > target {
+			return ErrClosed
+		}
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		s.wait()
+	}
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPMC[T]) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining, where Enqueue
+// returns ErrDraining. Like Drain, it lets Dequeue bypass the livelock
+// threshold so that consumers take every remaining element.
+func (q *MPMC[T]) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed, from StateActive or
+// StateDraining. Enqueue then returns ErrClosed, and Dequeue returns
+// ErrClosed instead of ErrEmpty once the remaining elements are taken.
+// Like Drain, it lets Dequeue bypass the livelock threshold.
+// Returns ErrClosed if the queue was already closed.
+func (q *MPMC[T]) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done,
+// in which case it returns ctx.Err(). It polls following
+// [DefaultSpinPolicy]. Returns ErrClosed if the queue closed without
+// reaching target, e.g. waiting for StateDraining on a queue closed
+// directly from StateActive.
+func (q *MPMC[T]) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPSC[T]) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *MPSC[T]) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *MPSC[T]) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *MPSC[T]) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *SPMC[T]) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *SPMC[T]) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *SPMC[T]) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *SPMC[T]) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPMCIndirect) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *MPMCIndirect) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *MPMCIndirect) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *MPMCIndirect) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPSCIndirect) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *MPSCIndirect) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *MPSCIndirect) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *MPSCIndirect) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *SPMCIndirect) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *SPMCIndirect) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *SPMCIndirect) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *SPMCIndirect) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPMCPtr) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *MPMCPtr) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *MPMCPtr) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *MPMCPtr) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *MPSCPtr) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *MPSCPtr) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *MPSCPtr) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *MPSCPtr) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
+
+// State returns the queue's lifecycle state.
+func (q *SPMCPtr) State() QueueState {
+	return q.state.load()
+}
+
+// BeginDrain moves an Active queue to StateDraining. See
+// [MPMC.BeginDrain].
+func (q *SPMCPtr) BeginDrain() {
+	q.state.beginDrain()
+}
+
+// Close moves the queue to StateClosed. See [MPMC.Close].
+func (q *SPMCPtr) Close() error {
+	return q.state.close()
+}
+
+// WaitForState blocks until the queue is in state target or ctx is done.
+// See [MPMC.WaitForState].
+func (q *SPMCPtr) WaitForState(ctx context.Context, target QueueState) error {
+	return q.state.waitFor(ctx, target)
+}
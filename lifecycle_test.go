@@ -0,0 +1,260 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// lifecycleQueue is implemented by every queue with a QueueState.
+type lifecycleQueue interface {
+	lfq.Drainer
+	BeginDrain()
+	Close() error
+	State() lfq.QueueState
+	WaitForState(ctx context.Context, target lfq.QueueState) error
+}
+
+type lifecycleCase struct {
+	name    string
+	new     func() lifecycleQueue
+	enqueue func(q lifecycleQueue, v int) error
+	dequeue func(q lifecycleQueue) (int, error)
+}
+
+var lifecycleValues [8]int
+
+func lifecycleCases() []lifecycleCase {
+	generic := func(name string, newQ func() lfq.Queue[int]) lifecycleCase {
+		return lifecycleCase{
+			name: name,
+			new:  func() lifecycleQueue { return newQ().(lifecycleQueue) },
+			enqueue: func(q lifecycleQueue, v int) error {
+				return q.(lfq.Queue[int]).Enqueue(&v)
+			},
+			dequeue: func(q lifecycleQueue) (int, error) {
+				return q.(lfq.Queue[int]).Dequeue()
+			},
+		}
+	}
+	indirect := func(name string, newQ func() lfq.QueueIndirect) lifecycleCase {
+		return lifecycleCase{
+			name: name,
+			new:  func() lifecycleQueue { return newQ().(lifecycleQueue) },
+			enqueue: func(q lifecycleQueue, v int) error {
+				return q.(lfq.QueueIndirect).Enqueue(uintptr(v))
+			},
+			dequeue: func(q lifecycleQueue) (int, error) {
+				v, err := q.(lfq.QueueIndirect).Dequeue()
+				return int(v), err
+			},
+		}
+	}
+	ptr := func(name string, newQ func() lfq.QueuePtr) lifecycleCase {
+		return lifecycleCase{
+			name: name,
+			new:  func() lifecycleQueue { return newQ().(lifecycleQueue) },
+			enqueue: func(q lifecycleQueue, v int) error {
+				lifecycleValues[v] = v
+				return q.(lfq.QueuePtr).Enqueue(unsafe.Pointer(&lifecycleValues[v]))
+			},
+			dequeue: func(q lifecycleQueue) (int, error) {
+				p, err := q.(lfq.QueuePtr).Dequeue()
+				if err != nil {
+					return 0, err
+				}
+				return *(*int)(p), nil
+			},
+		}
+	}
+	return []lifecycleCase{
+		generic("MPMC", func() lfq.Queue[int] { return lfq.NewMPMC[int](4) }),
+		generic("MPSC", func() lfq.Queue[int] { return lfq.NewMPSC[int](4) }),
+		generic("SPMC", func() lfq.Queue[int] { return lfq.NewSPMC[int](4) }),
+		indirect("MPMCIndirect", func() lfq.QueueIndirect { return lfq.NewMPMCIndirect(4) }),
+		indirect("MPSCIndirect", func() lfq.QueueIndirect { return lfq.NewMPSCIndirect(4) }),
+		indirect("SPMCIndirect", func() lfq.QueueIndirect { return lfq.NewSPMCIndirect(4) }),
+		ptr("MPMCPtr", func() lfq.QueuePtr { return lfq.NewMPMCPtr(4) }),
+		ptr("MPSCPtr", func() lfq.QueuePtr { return lfq.NewMPSCPtr(4) }),
+		ptr("SPMCPtr", func() lfq.QueuePtr { return lfq.NewSPMCPtr(4) }),
+	}
+}
+
+// TestQueueStateTransitions walks each queue through the valid
+// transitions Active→Draining→Closed and Active→Closed, checks the
+// Enqueue and Dequeue results in every state, and verifies the invalid
+// transitions and the Drain hint leave the state unchanged.
+func TestQueueStateTransitions(t *testing.T) {
+	for _, tc := range lifecycleCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			expect := func(q lifecycleQueue, want lfq.QueueState) {
+				t.Helper()
+				if got := q.State(); got != want {
+					t.Fatalf("State: got %v, want %v", got, want)
+				}
+			}
+
+			t.Run("ActiveDrainingClosed", func(t *testing.T) {
+				q := tc.new()
+				expect(q, lfq.StateActive)
+				if err := tc.enqueue(q, 1); err != nil {
+					t.Fatalf("Enqueue while active: %v", err)
+				}
+				if err := tc.enqueue(q, 2); err != nil {
+					t.Fatalf("Enqueue while active: %v", err)
+				}
+
+				q.BeginDrain()
+				expect(q, lfq.StateDraining)
+				if err := tc.enqueue(q, 3); !errors.Is(err, lfq.ErrDraining) {
+					t.Fatalf("Enqueue while draining: got %v, want ErrDraining", err)
+				}
+				if v, err := tc.dequeue(q); err != nil || v != 1 {
+					t.Fatalf("Dequeue while draining: got (%d, %v), want (1, nil)", v, err)
+				}
+
+				// Draining→Draining: BeginDrain again is a no-op
+				q.BeginDrain()
+				expect(q, lfq.StateDraining)
+
+				if err := q.Close(); err != nil {
+					t.Fatalf("Close while draining: %v", err)
+				}
+				expect(q, lfq.StateClosed)
+				if err := tc.enqueue(q, 3); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("Enqueue while closed: got %v, want ErrClosed", err)
+				}
+				if v, err := tc.dequeue(q); err != nil || v != 2 {
+					t.Fatalf("Dequeue while closed: got (%d, %v), want (2, nil)", v, err)
+				}
+				if _, err := tc.dequeue(q); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("Dequeue on closed empty queue: got %v, want ErrClosed", err)
+				}
+			})
+
+			t.Run("ActiveClosed", func(t *testing.T) {
+				q := tc.new()
+				if err := tc.enqueue(q, 1); err != nil {
+					t.Fatalf("Enqueue while active: %v", err)
+				}
+				if err := q.Close(); err != nil {
+					t.Fatalf("Close while active: %v", err)
+				}
+				expect(q, lfq.StateClosed)
+				if err := tc.enqueue(q, 2); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("Enqueue while closed: got %v, want ErrClosed", err)
+				}
+				if v, err := tc.dequeue(q); err != nil || v != 1 {
+					t.Fatalf("Dequeue while closed: got (%d, %v), want (1, nil)", v, err)
+				}
+				if _, err := tc.dequeue(q); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("Dequeue on closed empty queue: got %v, want ErrClosed", err)
+				}
+			})
+
+			t.Run("Invalid", func(t *testing.T) {
+				q := tc.new()
+				if _, err := tc.dequeue(q); !errors.Is(err, lfq.ErrEmpty) {
+					t.Fatalf("Dequeue on active empty queue: got %v, want ErrEmpty", err)
+				}
+				q.Close()
+
+				// Closed→Closed
+				if err := q.Close(); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("second Close: got %v, want ErrClosed", err)
+				}
+				expect(q, lfq.StateClosed)
+
+				// Closed→Draining
+				q.BeginDrain()
+				expect(q, lfq.StateClosed)
+				if err := tc.enqueue(q, 1); !errors.Is(err, lfq.ErrClosed) {
+					t.Fatalf("Enqueue after BeginDrain on closed queue: got %v, want ErrClosed", err)
+				}
+			})
+
+			// Drain is only the consumer-side hint: producers keep going
+			t.Run("DrainHint", func(t *testing.T) {
+				q := tc.new()
+				q.Drain()
+				expect(q, lfq.StateActive)
+				if err := tc.enqueue(q, 1); err != nil {
+					t.Fatalf("Enqueue after Drain: %v", err)
+				}
+				if v, err := tc.dequeue(q); err != nil || v != 1 {
+					t.Fatalf("Dequeue after Drain: got (%d, %v), want (1, nil)", v, err)
+				}
+				q.BeginDrain()
+				expect(q, lfq.StateDraining)
+				if err := q.Close(); err != nil {
+					t.Fatalf("Close after Drain and BeginDrain: %v", err)
+				}
+				expect(q, lfq.StateClosed)
+			})
+		})
+	}
+}
+
+// TestWaitForState verifies WaitForState returns at once for the current
+// state, wakes when another goroutine makes the transition, reports
+// ErrClosed for a state the queue skipped, and honors ctx.
+func TestWaitForState(t *testing.T) {
+	for _, tc := range lifecycleCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			q := tc.new()
+			if err := q.WaitForState(ctx, lfq.StateActive); err != nil {
+				t.Fatalf("WaitForState(active) on active queue: %v", err)
+			}
+
+			go func(q lifecycleQueue) {
+				time.Sleep(10 * time.Millisecond)
+				q.BeginDrain()
+				time.Sleep(10 * time.Millisecond)
+				q.Close()
+			}(q)
+			if err := q.WaitForState(ctx, lfq.StateClosed); err != nil {
+				t.Fatalf("WaitForState(closed): %v", err)
+			}
+
+			q = tc.new()
+			q.Close()
+			if err := q.WaitForState(ctx, lfq.StateDraining); !errors.Is(err, lfq.ErrClosed) {
+				t.Fatalf("WaitForState(draining) on closed queue: got %v, want ErrClosed", err)
+			}
+
+			q = tc.new()
+			tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			if err := q.WaitForState(tctx, lfq.StateDraining); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("WaitForState with expired ctx: got %v, want DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestQueueStateString(t *testing.T) {
+	tests := []struct {
+		s    lfq.QueueState
+		want string
+	}{
+		{lfq.StateActive, "active"},
+		{lfq.StateDraining, "draining"},
+		{lfq.StateClosed, "closed"},
+		{lfq.QueueState(-1), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("QueueState(%d).String(): got %q, want %q", int32(tt.s), got, tt.want)
+		}
+	}
+}
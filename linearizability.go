@@ -0,0 +1,181 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"code.hybscloud.com/atomix"
+)
+
+// LinearizabilityRecorder instruments a queue for integration testing.
+//
+// It records every successful Enqueue and Dequeue in a history, stamping
+// each with logical times taken before the call and after it returns.
+// Check then verifies that no element was dequeued more often than it was
+// enqueued, and that no Dequeue returned before the matching Enqueue
+// started.
+//
+// Every operation takes a lock to append to the history, serializing the
+// instrumented queue; use it in tests only.
+//
+// Elements are compared with ==, so T must be comparable; otherwise
+// recording panics.
+type LinearizabilityRecorder[T any] struct {
+	q     Queue[T]
+	clock atomix.Uint64
+	mu    sync.Mutex
+	enqs  []linOp
+	deqs  []linOp
+}
+
+// linOp is a recorded operation: its element and the logical times at
+// which it was invoked and returned.
+type linOp struct {
+	elem          any
+	invoke, reply uint64
+}
+
+// NewLinearizabilityRecorder wraps q in a recorder with an empty history.
+func NewLinearizabilityRecorder[T any](q Queue[T]) *LinearizabilityRecorder[T] {
+	return &LinearizabilityRecorder[T]{q: q}
+}
+
+// Enqueue adds an element to the underlying queue and records it on
+// success.
+func (r *LinearizabilityRecorder[T]) Enqueue(elem *T) error {
+	invoke := r.clock.AddAcqRel(1)
+	v := *elem
+	if err := r.q.Enqueue(elem); err != nil {
+		return err
+	}
+	reply := r.clock.AddAcqRel(1)
+	r.mu.Lock()
+	r.enqs = append(r.enqs, linOp{elem: v, invoke: invoke, reply: reply})
+	r.mu.Unlock()
+	return nil
+}
+
+// Dequeue removes an element from the underlying queue and records it on
+// success.
+func (r *LinearizabilityRecorder[T]) Dequeue() (T, error) {
+	invoke := r.clock.AddAcqRel(1)
+	elem, err := r.q.Dequeue()
+	if err != nil {
+		return elem, err
+	}
+	reply := r.clock.AddAcqRel(1)
+	r.mu.Lock()
+	r.deqs = append(r.deqs, linOp{elem: elem, invoke: invoke, reply: reply})
+	r.mu.Unlock()
+	return elem, nil
+}
+
+// Cap returns the capacity of the underlying queue.
+func (r *LinearizabilityRecorder[T]) Cap() int {
+	return r.q.Cap()
+}
+
+// Unwrap returns the underlying queue.
+func (r *LinearizabilityRecorder[T]) Unwrap() Queue[T] {
+	return r.q
+}
+
+// Check verifies the history recorded so far. It returns nil if the
+// history is consistent, or an error describing every violation found.
+// Call it once operations have quiesced.
+func (r *LinearizabilityRecorder[T]) Check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Match each Dequeue, in reply order, to the earliest-invoked unmatched
+	// Enqueue of an equal element
+	pending := make(map[any][]linOp)
+	for _, op := range r.enqs {
+		pending[op.elem] = append(pending[op.elem], op)
+	}
+	for _, ops := range pending {
+		slices.SortFunc(ops, func(a, b linOp) int { return cmp.Compare(a.invoke, b.invoke) })
+	}
+	deqs := slices.Clone(r.deqs)
+	slices.SortFunc(deqs, func(a, b linOp) int { return cmp.Compare(a.reply, b.reply) })
+
+	var errs []error
+	for _, d := range deqs {
+		ops := pending[d.elem]
+		if len(ops) == 0 {
+			errs = append(errs, fmt.Errorf("lfq: element %v dequeued more often than enqueued", d.elem))
+			continue
+		}
+		if e := ops[0]; e.invoke > d.reply {
+			errs = append(errs, fmt.Errorf("lfq: element %v dequeued at %d before its enqueue started at %d", d.elem, d.reply, e.invoke))
+		}
+		pending[d.elem] = ops[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// recorders holds the Check methods of queues instrumented by the
+// lfq_assert_linearizability build tag.
+var recorders struct {
+	sync.Mutex
+	checks []func() error
+}
+
+// recordBuilt wraps a queue built by a Builder in a registered recorder
+// when the lfq_assert_linearizability tag is set and T is comparable.
+// Queues from the New constructors are never wrapped: they return
+// concrete types, which a recorder cannot stand in for.
+func recordBuilt[T any](q Queue[T]) Queue[T] {
+	if !assertLinearizability || !reflect.TypeFor[T]().Comparable() {
+		return q
+	}
+	r := NewLinearizabilityRecorder(q)
+	recorders.Lock()
+	recorders.checks = append(recorders.checks, r.Check)
+	recorders.Unlock()
+	return r
+}
+
+// Unrecorded returns the queue a LinearizabilityRecorder wraps, or q
+// itself if it is not a recorder. Code that type-asserts a queue from
+// Build to its concrete type should go through Unrecorded so that it
+// keeps working under the lfq_assert_linearizability tag:
+//
+//	q := lfq.Unrecorded(lfq.BuildMPMC[int](b)).(*lfq.MPMC[int])
+func Unrecorded[T any](q Queue[T]) Queue[T] {
+	if r, ok := q.(*LinearizabilityRecorder[T]); ok {
+		return r.Unwrap()
+	}
+	return q
+}
+
+// AssertLinearizability checks the histories of all queues built since the
+// previous call and reports violations through t, which is typically a
+// *testing.T. Call it from a test or TestMain after the queues quiesce.
+//
+// Recorded queues stay reachable until it checks them. Without the
+// lfq_assert_linearizability build tag no queue is recorded and it
+// reports nothing.
+func AssertLinearizability(t interface {
+	Helper()
+	Errorf(format string, args ...any)
+}) {
+	t.Helper()
+	recorders.Lock()
+	checks := recorders.checks
+	recorders.checks = nil
+	recorders.Unlock()
+	for _, check := range checks {
+		if err := check(); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+}
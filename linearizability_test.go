@@ -0,0 +1,101 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestLinearizabilityRecorder verifies a correct queue under concurrent
+// producers and consumers yields a consistent history.
+func TestLinearizabilityRecorder(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const producers, perProducer = 4, 1000
+	r := lfq.NewLinearizabilityRecorder[int](lfq.NewMPMC[int](64))
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProducer {
+				v := p*perProducer + i
+				for r.Enqueue(&v) != nil {
+				}
+			}
+		})
+	}
+	var mu sync.Mutex
+	got := 0
+	for range producers {
+		wg.Go(func() {
+			for {
+				mu.Lock()
+				if got == producers*perProducer {
+					mu.Unlock()
+					return
+				}
+				mu.Unlock()
+				if _, err := r.Dequeue(); err == nil {
+					mu.Lock()
+					got++
+					mu.Unlock()
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := r.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+}
+
+// duplicatingQueue is a broken queue that returns its last element twice.
+type duplicatingQueue struct {
+	lfq.Queue[int]
+	last  int
+	twice bool
+}
+
+func (q *duplicatingQueue) Dequeue() (int, error) {
+	if q.twice {
+		q.twice = false
+		return q.last, nil
+	}
+	v, err := q.Queue.Dequeue()
+	q.last, q.twice = v, err == nil
+	return v, err
+}
+
+func TestLinearizabilityRecorderDetectsDuplicate(t *testing.T) {
+	r := lfq.NewLinearizabilityRecorder[int](&duplicatingQueue{Queue: lfq.NewSPSC[int](4)})
+	v := 7
+	r.Enqueue(&v)
+	r.Dequeue()
+	r.Dequeue()
+
+	err := r.Check()
+	if err == nil || !strings.Contains(err.Error(), "dequeued more often than enqueued") {
+		t.Fatalf("Check: got %v, want duplicate dequeue", err)
+	}
+}
+
+// TestAssertLinearizability runs a built queue through AssertLinearizability,
+// which checks its history under the lfq_assert_linearizability tag.
+func TestAssertLinearizability(t *testing.T) {
+	q := lfq.BuildMPMC[int](lfq.New(8))
+	for i := range 8 {
+		q.Enqueue(&i)
+	}
+	for range 8 {
+		q.Dequeue()
+	}
+	lfq.AssertLinearizability(t)
+}
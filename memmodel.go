@@ -0,0 +1,120 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// This file documents, for each queue family, the atomic operations that
+// order a producer's writes to an element before the consumer's reads of
+// it. It contains no code; memmodel_test.go checks each chain by passing
+// elements whose fields are written non-atomically and must be seen
+// whole by the consumer.
+//
+// In the chains below, "A → B" means A is sequenced before B in one
+// goroutine, and "X (release) ⇒ Y (acquire)" means Y reads the value X
+// stored, so everything before X happens before everything after Y.
+//
+// # SPSC, SPSCPtr, SPSCIndirect
+//
+// Lamport ring with head and tail indices.
+//
+//	producer: buffer[tail&mask] = *elem (plain)
+//	          → tail.StoreRelease(tail+1)
+//	consumer: tail.LoadAcquire() (into cachedTail, only when stale)
+//	          → *dst = buffer[head&mask] (plain)
+//
+// tail.StoreRelease ⇒ tail.LoadAcquire publishes the element. A cached
+// tail was itself loaded with acquire, so reading slots below it needs
+// no further synchronization.
+//
+// The reverse chain lets the producer reuse the slot:
+//
+//	consumer: read buffer[head&mask]; clear it → head.StoreRelease(head+1)
+//	producer: head.LoadAcquire() (into cachedHead) → overwrite the slot
+//
+// The SPSCIndirect assembly path performs the same stores with the
+// platform's release store and the same loads with its acquire load.
+// SPSCPtr with [Builder.WithGCSafe] clears the slot before the head store,
+// so the clear is ordered like the read.
+//
+// # SPSCCompact
+//
+// Each slot carries a sequence number instead of shared indices.
+//
+//	producer: slot.seq.LoadAcquire() == tail → slot.data = *elem (plain)
+//	          → slot.seq.StoreRelease(tail+1)
+//	consumer: slot.seq.LoadAcquire() == head+1 → *dst = slot.data (plain)
+//	          → slot.seq.StoreRelease(head+capacity)
+//
+// The producer's acquire of head+capacity, stored by the previous lap's
+// consumer, orders that consumer's read before the overwrite.
+//
+// # MPMC, MPSC, SPMC (FAA)
+//
+// SCQ over 2n slots. A position is claimed by q.tail.AddAcqRel (MPMC,
+// MPSC) or a plain tail store (SPMC, one producer); the claim only
+// decides ownership and publishes nothing.
+//
+//	producer: slot.cycle.LoadAcquire() == cycle → slot.data = *elem (plain)
+//	          → slot.cycle.StoreRelease(cycle+1)
+//	consumer: head.AddAcqRel(1) → slot.cycle.LoadAcquire() == cycle+1
+//	          → *dst = slot.data (plain)
+//	          → slot.cycle.StoreRelease(next lap's cycle)
+//
+// slot.cycle is the synchronizing variable in both directions: the
+// consumer's release of the next cycle is what the next lap's producer
+// acquires before overwriting slot.data. The checksum (lfq_checksum) and
+// the latency timestamp are written before the producer's cycle release
+// and read after the consumer's cycle acquire, so they ride the same
+// chain. A consumer that finds the slot not yet published advances the
+// cycle with CompareAndSwapAcqRel and reads no data.
+//
+// # MPMCIndirect, MPSCIndirect, SPMCIndirect, MPMCPtr, MPSCPtr, SPMCPtr
+//
+// The value and the slot's cycle share one 128-bit word, so there is no
+// separate plain write to order:
+//
+//	producer: entry.LoadAcquire() → entry.CompareAndSwapAcqRel(
+//	          {cycle, old}, {cycle+1, value})
+//	consumer: entry.LoadAcquire() == {cycle+1, value}
+//	          → entry.CompareAndSwapAcqRel({cycle+1, value}, {next, 0})
+//
+// What this chain orders is the caller's data behind the value: writes to
+// a pool entry selected by an Indirect index, or to the object an
+// unsafe.Pointer refers to, made before Enqueue happen before the reads
+// a consumer makes after Dequeue returns that value.
+//
+// # MPMCSeq, MPSCSeq, SPMCSeq and the IndirectSeq and PtrSeq variants
+//
+// Vyukov's bounded queue over n slots.
+//
+//	producer: slot.seq.LoadAcquire() == tail
+//	          → tail.CompareAndSwapAcqRel(tail, tail+1)
+//	          → slot.data = *elem (plain)
+//	          → slot.seq.StoreRelease(tail+1)
+//	consumer: slot.seq.LoadAcquire() == head+1
+//	          → head.CompareAndSwapAcqRel(head, head+1)
+//	          → *dst = slot.data (plain)
+//	          → slot.seq.StoreRelease(head+capacity)
+//
+// As with FAA, the index CAS only assigns ownership; slot.seq carries
+// the data in both directions. The 128-bit IndirectSeq and PtrSeq
+// variants keep seq and value in one word and follow the Indirect chain.
+//
+// # MPMCCompactIndirect, MPSCCompactIndirect, SPMCCompactIndirect
+//
+// Each slot is a single atomic word holding the value and the bits that
+// mark it full.
+//
+//	producer: buffer[i].CompareAndSwapAcqRel(empty, value)
+//	consumer: buffer[i].LoadAcquire() == value
+//	          → buffer[i].CompareAndSwapAcqRel(value, empty)
+//
+// As with the 128-bit queues, the chain orders the caller's data behind
+// the value rather than a separate slot field.
+//
+// # Wrappers
+//
+// Queues built on these types, such as [SPSCBlocking], [AffineMPSC] and
+// the split handles, inherit the chain of the queue they wrap. Wake-ups
+// through a futex or channel add edges but are not needed for the data.
@@ -0,0 +1,236 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+// These tests check the happens-before chains documented in memmodel.go.
+// The race detector cannot see the orderings they rely on, so they are
+// excluded from race testing.
+
+package lfq_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+const (
+	mmItems    = 1 << 15
+	mmCapacity = 64 // Small, so that every slot is reused many times
+)
+
+// mmPayload is written with plain stores by the producer. A consumer
+// that reads it before the producer's writes are visible sees a torn or
+// zero payload, which valid rejects.
+type mmPayload struct {
+	seq, scaled, inverted uint64
+}
+
+func mmMake(i int) mmPayload {
+	s := uint64(i)
+	return mmPayload{s, s * 0x9E3779B97F4A7C15, ^s}
+}
+
+func (p mmPayload) valid() bool {
+	return p == mmMake(int(p.seq))
+}
+
+// mmQueue adapts a queue so that Enqueue publishes payload i and Dequeue
+// returns the payload a consumer observes.
+type mmQueue struct {
+	enqueue func(i int) error
+	dequeue func() (mmPayload, error)
+}
+
+func mmGeneric(q lfq.Queue[mmPayload]) mmQueue {
+	return mmQueue{
+		enqueue: func(i int) error {
+			p := mmMake(i)
+			return q.Enqueue(&p)
+		},
+		dequeue: q.Dequeue,
+	}
+}
+
+// mmIndirect passes indices into a pool the producer fills before
+// Enqueue; the consumer reads the entry after Dequeue.
+func mmIndirect(q lfq.QueueIndirect) mmQueue {
+	pool := make([]mmPayload, mmItems)
+	return mmQueue{
+		enqueue: func(i int) error {
+			pool[i] = mmMake(i)
+			return q.Enqueue(uintptr(i))
+		},
+		dequeue: func() (mmPayload, error) {
+			i, err := q.Dequeue()
+			if err != nil {
+				return mmPayload{}, err
+			}
+			return pool[i], nil
+		},
+	}
+}
+
+// mmPtr passes pointers into a pool, which the closures keep reachable
+// while the queue holds them.
+func mmPtr(q lfq.QueuePtr) mmQueue {
+	pool := make([]mmPayload, mmItems)
+	return mmQueue{
+		enqueue: func(i int) error {
+			pool[i] = mmMake(i)
+			return q.Enqueue(unsafe.Pointer(&pool[i]))
+		},
+		dequeue: func() (mmPayload, error) {
+			p, err := q.Dequeue()
+			if err != nil {
+				return mmPayload{}, err
+			}
+			return *(*mmPayload)(p), nil
+		},
+	}
+}
+
+// runMemModel moves mmItems payloads from producers to consumers and
+// checks that every payload arrives whole, exactly once.
+func runMemModel(t *testing.T, q mmQueue, producers, consumers int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	var received atomix.Int64
+	var failed atomix.Bool
+	seen := make([][]uint64, consumers)
+	bad := make([]*mmPayload, consumers)
+
+	for c := range consumers {
+		wg.Go(func() {
+			for received.Load() < mmItems && !failed.Load() {
+				p, err := q.dequeue()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				received.Add(1)
+				if !p.valid() {
+					bad[c] = &p
+					failed.Store(true)
+					return
+				}
+				seen[c] = append(seen[c], p.seq)
+			}
+		})
+	}
+	for p := range producers {
+		wg.Go(func() {
+			for i := p; i < mmItems; i += producers {
+				for q.enqueue(i) != nil {
+					if failed.Load() {
+						return
+					}
+					runtime.Gosched()
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	for c, p := range bad {
+		if p != nil {
+			t.Fatalf("consumer %d observed inconsistent payload %+v", c, *p)
+		}
+	}
+	counts := make([]int, mmItems)
+	for c := range consumers {
+		for _, s := range seen[c] {
+			counts[s]++
+		}
+	}
+	for i, n := range counts {
+		if n != 1 {
+			t.Fatalf("payload %d received %d times, want 1", i, n)
+		}
+	}
+}
+
+type mmCase struct {
+	name                 string
+	q                    mmQueue
+	producers, consumers int
+}
+
+func runMemModelCases(t *testing.T, tests []mmCase) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMemModel(t, tt.q, tt.producers, tt.consumers)
+		})
+	}
+}
+
+// TestHappensBeforeSPSC checks the Lamport ring chain: tail.StoreRelease
+// by the producer, tail.LoadAcquire by the consumer.
+func TestHappensBeforeSPSC(t *testing.T) {
+	runMemModelCases(t, []mmCase{
+		{"SPSC", mmGeneric(lfq.NewSPSC[mmPayload](mmCapacity)), 1, 1},
+		{"SPSCPtr", mmPtr(lfq.NewSPSCPtr(mmCapacity)), 1, 1},
+		{"SPSCIndirect", mmIndirect(lfq.NewSPSCIndirect(mmCapacity)), 1, 1},
+		{"SPSCIndirectPureGo", mmIndirect(lfq.NewSPSCIndirectPureGo(mmCapacity)), 1, 1},
+	})
+}
+
+// TestHappensBeforeSPSCCompact checks the per-slot sequence chain of
+// SPSCCompact.
+func TestHappensBeforeSPSCCompact(t *testing.T) {
+	runMemModel(t, mmGeneric(lfq.NewSPSCCompact[mmPayload](mmCapacity)), 1, 1)
+}
+
+// TestHappensBeforeFAA checks the slot cycle chain of the FAA queues.
+func TestHappensBeforeFAA(t *testing.T) {
+	runMemModelCases(t, []mmCase{
+		{"MPSC", mmGeneric(lfq.NewMPSC[mmPayload](mmCapacity)), 4, 1},
+		{"SPMC", mmGeneric(lfq.NewSPMC[mmPayload](mmCapacity)), 1, 4},
+		{"MPMC", mmGeneric(lfq.NewMPMC[mmPayload](mmCapacity)), 4, 4},
+	})
+}
+
+// TestHappensBefore128 checks that the 128-bit CAS publishing an index
+// or pointer also orders the data it refers to.
+func TestHappensBefore128(t *testing.T) {
+	runMemModelCases(t, []mmCase{
+		{"MPSCIndirect", mmIndirect(lfq.NewMPSCIndirect(mmCapacity)), 4, 1},
+		{"SPMCIndirect", mmIndirect(lfq.NewSPMCIndirect(mmCapacity)), 1, 4},
+		{"MPMCIndirect", mmIndirect(lfq.NewMPMCIndirect(mmCapacity)), 4, 4},
+		{"MPSCPtr", mmPtr(lfq.NewMPSCPtr(mmCapacity)), 4, 1},
+		{"SPMCPtr", mmPtr(lfq.NewSPMCPtr(mmCapacity)), 1, 4},
+		{"MPMCPtr", mmPtr(lfq.NewMPMCPtr(mmCapacity)), 4, 4},
+	})
+}
+
+// TestHappensBeforeSeq checks the per-slot sequence chain of the Seq
+// queues.
+func TestHappensBeforeSeq(t *testing.T) {
+	runMemModelCases(t, []mmCase{
+		{"MPSCSeq", mmGeneric(lfq.NewMPSCSeq[mmPayload](mmCapacity)), 4, 1},
+		{"SPMCSeq", mmGeneric(lfq.NewSPMCSeq[mmPayload](mmCapacity)), 1, 4},
+		{"MPMCSeq", mmGeneric(lfq.NewMPMCSeq[mmPayload](mmCapacity)), 4, 4},
+		{"MPSCIndirectSeq", mmIndirect(lfq.NewMPSCIndirectSeq(mmCapacity)), 4, 1},
+		{"SPMCIndirectSeq", mmIndirect(lfq.NewSPMCIndirectSeq(mmCapacity)), 1, 4},
+		{"MPMCIndirectSeq", mmIndirect(lfq.NewMPMCIndirectSeq(mmCapacity)), 4, 4},
+		{"MPSCPtrSeq", mmPtr(lfq.NewMPSCPtrSeq(mmCapacity)), 4, 1},
+		{"SPMCPtrSeq", mmPtr(lfq.NewSPMCPtrSeq(mmCapacity)), 1, 4},
+		{"MPMCPtrSeq", mmPtr(lfq.NewMPMCPtrSeq(mmCapacity)), 4, 4},
+	})
+}
+
+// TestHappensBeforeCompactIndirect checks the single-word slot chain of
+// the CompactIndirect queues.
+func TestHappensBeforeCompactIndirect(t *testing.T) {
+	runMemModelCases(t, []mmCase{
+		{"MPSCCompactIndirect", mmIndirect(lfq.NewMPSCCompactIndirect(mmCapacity)), 4, 1},
+		{"SPMCCompactIndirect", mmIndirect(lfq.NewSPMCCompactIndirect(mmCapacity)), 1, 4},
+		{"MPMCCompactIndirect", mmIndirect(lfq.NewMPMCCompactIndirect(mmCapacity)), 4, 4},
+	})
+}
@@ -0,0 +1,83 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"unsafe"
+)
+
+// heapProfilesMu serializes creating the lfq.<label> profiles, since
+// pprof.NewProfile panics on a name that already exists.
+var heapProfilesMu sync.Mutex
+
+// heapProfile returns the profile for label, creating it on first use.
+func heapProfile(label string) *pprof.Profile {
+	name := "lfq." + label
+	heapProfilesMu.Lock()
+	defer heapProfilesMu.Unlock()
+	if p := pprof.Lookup(name); p != nil {
+		return p
+	}
+	return pprof.NewProfile(name)
+}
+
+// queueMem is the accounting record of one tracked queue. It stands in
+// for the queue in its profile, which keeps its entries reachable.
+type queueMem struct {
+	queue string
+	label string
+	bytes uintptr
+	prof  *pprof.Profile // Nil without WithHeapProfile
+	log   bool
+}
+
+// trackMemory registers q, a pointer or an interface holding one, for
+// the memory accounting b requests, and arranges for it to be undone
+// when q is collected.
+func trackMemory[Q any](b *Builder, q Q) {
+	v := reflect.ValueOf(q)
+	for v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return
+	}
+	m := &queueMem{
+		queue: fmt.Sprint(q),
+		label: b.opts.heapLabel,
+		bytes: queueBytes(v),
+		log:   b.opts.memStats,
+	}
+	if m.label != "" {
+		m.prof = heapProfile(m.label)
+		m.prof.Add(m, 2)
+	}
+	runtime.AddCleanup((*byte)(v.UnsafePointer()), (*queueMem).release, m)
+}
+
+// release runs once the queue is collected.
+func (m *queueMem) release() {
+	if m.prof != nil {
+		m.prof.Remove(m)
+	}
+	if m.log {
+		slog.Info("lfq: queue freed", "queue", m.queue, "label", m.label,
+			"alloc_bytes", m.bytes, "freed_bytes", m.bytes)
+	}
+}
+
+// queueBytes returns the size of the struct v points to plus the slot
+// arrays it and the queues it delegates to hold.
+func queueBytes(v reflect.Value) uintptr {
+	n := v.Type().Elem().Size()
+	forEachSlice(v, 2, func(_ unsafe.Pointer, size uintptr) { n += size })
+	return n
+}
@@ -0,0 +1,119 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// collectUntil runs the garbage collector until cond holds or a second
+// has passed, since cleanups run asynchronously after collection.
+func collectUntil(cond func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		runtime.GC()
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestWithHeapProfile(t *testing.T) {
+	b := lfq.New(1024).WithHeapProfile("test_heap")
+	q1 := lfq.BuildMPMC[int](b)
+	q2 := b.BuildIndirect()
+
+	p := pprof.Lookup("lfq.test_heap")
+	if p == nil {
+		t.Fatal(`profile "lfq.test_heap" not registered`)
+	}
+	if got := p.Count(); got != 2 {
+		t.Fatalf("profile count: got %d, want 2", got)
+	}
+	var buf bytes.Buffer
+	p.WriteTo(&buf, 1)
+	if !strings.Contains(buf.String(), "TestWithHeapProfile") {
+		t.Fatalf("profile does not record the build stack:\n%s", buf.String())
+	}
+
+	runtime.KeepAlive(q1)
+	runtime.KeepAlive(q2)
+	// Under lfq_assert_linearizability the recorder of q1 holds it until checked
+	lfq.AssertLinearizability(t)
+	if !collectUntil(func() bool { return p.Count() == 0 }) {
+		t.Fatalf("profile count after collection: got %d, want 0", p.Count())
+	}
+}
+
+func TestWithHeapProfileEmptyLabel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	lfq.New(8).WithHeapProfile("")
+}
+
+// memStatsHandler collects the attributes of "lfq: queue freed" records.
+type memStatsHandler struct {
+	mu    sync.Mutex
+	attrs []map[string]slog.Value
+}
+
+func (h *memStatsHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *memStatsHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *memStatsHandler) WithGroup(string) slog.Handler            { return h }
+func (h *memStatsHandler) Handle(_ context.Context, r slog.Record) error {
+	if r.Message != "lfq: queue freed" {
+		return nil
+	}
+	m := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	h.mu.Lock()
+	h.attrs = append(h.attrs, m)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *memStatsHandler) records() []map[string]slog.Value {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.attrs
+}
+
+func TestWithMemStats(t *testing.T) {
+	h := &memStatsHandler{}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	q := lfq.BuildSPSC[int64](lfq.New(1024).SingleProducer().SingleConsumer().WithMemStats())
+	runtime.KeepAlive(q)
+	if !collectUntil(func() bool { return len(h.records()) > 0 }) {
+		t.Fatal("no record logged after the queue was collected")
+	}
+	r := h.records()[0]
+	if got := r["queue"].String(); got != "SPSC[cap=1024]" {
+		t.Fatalf("queue: got %q, want %q", got, "SPSC[cap=1024]")
+	}
+	// The slot array alone is 1024 int64s
+	if got := r["alloc_bytes"].Uint64(); got < 8*1024 || got != r["freed_bytes"].Uint64() {
+		t.Fatalf("alloc_bytes %d, freed_bytes %d: want equal and >= 8192", got, r["freed_bytes"].Uint64())
+	}
+}
@@ -5,6 +5,10 @@
 package lfq
 
 import (
+	"context"
+	"sync/atomic"
+	"time"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -19,6 +23,11 @@ import (
 // Cycle-based slot validation provides ABA safety: each slot tracks which
 // "cycle" (round) it belongs to via cycle = position / capacity.
 //
+// Built with the lfq_checksum tag, each slot also stores a CRC32 of its
+// element, and Dequeue panics if the element no longer matches it. This
+// is a debugging aid for memory corruption: it enlarges every slot and
+// hashes each element twice.
+//
 // Memory: 2n slots for capacity n (16+ bytes per slot)
 type MPMC[T any] struct {
 	_         pad
@@ -28,16 +37,20 @@ type MPMC[T any] struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention for dequeue
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []mpmcSlot[T]
-	capacity  uint64 // n (usable capacity)
-	size      uint64 // 2n (physical slots)
-	mask      uint64 // 2n - 1
+	capacity  uint64                     // n (usable capacity)
+	size      uint64                     // 2n (physical slots)
+	mask      uint64                     // 2n - 1
+	tune      *thresholdTuner            // Nil unless built WithAdaptiveThreshold
+	obs       observersRef               // Nil unless a hook is enabled
+	policy    atomic.Pointer[SpinPolicy] // Nil uses DefaultSpinPolicy
 }
 
 type mpmcSlot[T any] struct {
 	cycle atomix.Uint64 // Round number for this slot
+	sum   slotSum       // CRC32 of data with lfq_checksum, else empty
 	data  T
 	_     padShort // Pad to cache line
 }
@@ -47,7 +60,7 @@ type mpmcSlot[T any] struct {
 // Physical slot count is 2n for capacity n (SCQ requirement).
 func NewMPMC[T any](capacity int) *MPMC[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMC", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -74,14 +87,21 @@ func NewMPMC[T any](capacity int) *MPMC[T] {
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPMC[T]) Enqueue(elem *T) error {
+	if debugAssert {
+		defer checkFAA("MPMC", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadAcquire()
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			q.obs.full()
+			return ErrFull
 		}
 
 		myTail := q.tail.AddAcqRel(1) - 1
@@ -93,34 +113,205 @@ func (q *MPMC[T]) Enqueue(elem *T) error {
 
 		if slotCycle == expectedCycle {
 			slot.data = *elem
+			sealSlot(&slot.sum, &slot.data)
+			// The latency stamp must precede the publishing store, and
+			// the other hooks follow it
+			if o := q.obs.load(); o != nil {
+				o.stamp(myTail & q.mask)
+				slot.cycle.StoreRelease(expectedCycle + 1)
+				q.threshold.StoreRelaxed(q.thresholdLimit())
+				o.enqueued(q, 1)
+				return nil
+			}
 			slot.cycle.StoreRelease(expectedCycle + 1)
-			q.threshold.StoreRelaxed(3*int64(q.capacity) - 1)
+			q.threshold.StoreRelaxed(q.thresholdLimit())
 			return nil
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			q.obs.full()
+			return ErrFull // Queue full
 		}
 
 		sw.Once()
 	}
 }
 
+// EnqueueValue adds a copy of val. It suits small element types, where
+// passing by value is simpler than Enqueue(&v); val does not escape, so
+// the call does not allocate.
+func (q *MPMC[T]) EnqueueValue(val T) error {
+	return q.Enqueue(&val)
+}
+
+// EnqueueBatch adds the elements pointed to by items in order.
+// Returns the number enqueued, and ErrFull if any remained.
+//
+// A single FAA claims a contiguous range of positions for the whole
+// batch, so concurrent producers contend on the tail once per batch
+// rather than once per element. The claim is capped by the free space
+// observed beforehand. If a claimed slot turns out to be unavailable, the
+// rest of the range is abandoned; consumers skip abandoned positions as
+// they do after a failed Enqueue.
+func (q *MPMC[T]) EnqueueBatch(items []*T) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return 0, err
+	}
+	tail := q.tail.LoadAcquire()
+	head := q.head.LoadAcquire()
+	if tail >= head+q.capacity {
+		q.obs.full()
+		return 0, ErrFull
+	}
+	k := min(uint64(len(items)), head+q.capacity-tail)
+
+	o := q.obs.load()
+	base := q.tail.AddAcqRel(k) - k
+	n := 0
+	for ; uint64(n) < k; n++ {
+		pos := base + uint64(n)
+		slot := &q.buffer[pos&q.mask]
+		expectedCycle := pos / q.capacity
+		if slot.cycle.LoadAcquire() != expectedCycle {
+			break
+		}
+		slot.data = *items[n]
+		sealSlot(&slot.sum, &slot.data)
+		if o != nil {
+			o.stamp(pos & q.mask)
+		}
+		slot.cycle.StoreRelease(expectedCycle + 1)
+	}
+
+	if n > 0 {
+		q.threshold.StoreRelaxed(q.thresholdLimit())
+		if o != nil {
+			o.enqueued(q, uint64(n))
+		}
+	}
+	if n < len(items) {
+		if uint64(n) < k {
+			q.obs.full()
+		}
+		return n, ErrFull
+	}
+	return n, nil
+}
+
 // Drain signals that no more enqueues will occur.
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *MPMC[T]) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
+}
+
+// DrainWithStats is Drain that also reports timing and the threshold
+// exhaustion observed at the time of the call.
+//
+// Drain itself keeps its signature so that MPMC satisfies [Drainer].
+func (q *MPMC[T]) DrainWithStats() DrainStats {
+	s := DrainStats{
+		SignaledAt:    time.Now(),
+		Unreclaimable: int(max(0, -q.threshold.LoadAcquire())),
+	}
+	q.Drain()
+	s.ActivatedAt = time.Now()
+	return s
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *MPMC[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *MPMC[T]) DequeueInto(dst *T) error {
+	if debugAssert {
+		defer checkFAA("MPMC", q)
+	}
+	slot, pos, err := q.claim()
+	if q.tune != nil {
+		q.tune.observe(err)
+	}
+	if err != nil {
+		return err
+	}
+	verifySlot("MPMC", pos, &slot.sum, &slot.data)
+	*dst = slot.data
+	var zero T
+	slot.data = zero
+	if o := q.obs.load(); o != nil {
+		o.observe(pos & q.mask)
+		slot.cycle.StoreRelease((pos + q.size) / q.capacity)
+		o.dequeued(q, 1)
+		return nil
+	}
+	slot.cycle.StoreRelease((pos + q.size) / q.capacity)
+	return nil
+}
+
+// DequeueMany removes up to len(dst) elements without copying them,
+// setting dst[i] to point at each element in its slot, and returns the
+// number removed. Returns (0, ErrEmpty) if the queue is empty.
+//
+// The pointers alias slots that are already released to producers, and
+// other consumers do not serialize with the caller's reads: an
+// Enqueue may overwrite an element while the caller still reads it, a
+// data race. Use DequeueMany on MPMC only while no producer is active,
+// such as when draining the queue after Drain. Slots are not cleared, so
+// the elements stay reachable until overwritten.
+func (q *MPMC[T]) DequeueMany(dst []*T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	o := q.obs.load()
+	n := 0
+	for n < len(dst) {
+		slot, pos, err := q.claim()
+		if err != nil {
+			break
+		}
+		verifySlot("MPMC", pos, &slot.sum, &slot.data)
+		if o != nil {
+			o.observe(pos & q.mask)
+		}
+		dst[n] = &slot.data
+		slot.cycle.StoreRelease((pos + q.size) / q.capacity)
+		n++
+	}
+	if n == 0 {
+		err := q.state.emptyErr()
+		if q.tune != nil {
+			q.tune.observe(err)
+		}
+		return 0, err
+	}
+	if q.tune != nil {
+		q.tune.observe(nil)
+	}
+	if o != nil {
+		o.dequeued(q, uint64(n))
+	}
+	return n, nil
+}
+
+// claim takes ownership of the slot at the next filled position and
+// returns it with the position. The caller reads the slot, then releases
+// it to producers by advancing its cycle.
+func (q *MPMC[T]) claim() (*mpmcSlot[T], uint64, error) {
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		var zero T
-		return zero, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		q.obs.empty()
+		return nil, 0, ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -132,12 +323,7 @@ func (q *MPMC[T]) Dequeue() (T, error) {
 		slotCycle := slot.cycle.LoadAcquire()
 
 		if slotCycle == expectedCycle {
-			elem := slot.data
-			var zero T
-			slot.data = zero
-			nextEnqCycle := (myHead + q.size) / q.capacity
-			slot.cycle.StoreRelease(nextEnqCycle)
-			return elem, nil
+			return slot, myHead, nil
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
@@ -149,12 +335,12 @@ func (q *MPMC[T]) Dequeue() (T, error) {
 			if tail <= myHead+1 {
 				q.catchup(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				var zero T
-				return zero, ErrWouldBlock
+				q.obs.empty()
+				return nil, 0, q.state.emptyErr()
 			}
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				var zero T
-				return zero, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				q.obs.empty()
+				return nil, 0, ErrEmpty
 			}
 		}
 		sw.Once()
@@ -171,7 +357,129 @@ func (q *MPMC[T]) catchup(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is like Enqueue but waits while the queue is full, following
+// the queue's SpinPolicy, until it succeeds or ctx is done, in which case
+// it returns ctx.Err(). Queue types without SetSpinPolicy follow
+// DefaultSpinPolicy.
+func (q *MPMC[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, q.policy.Load(), func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is like Dequeue but waits while the queue is empty, following
+// the queue's SpinPolicy, until an element arrives or ctx is done, in which
+// case it returns ctx.Err().
+func (q *MPMC[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, q.policy.Load(), func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// SetSpinPolicy sets how EnqueueCtx and DequeueCtx wait. Calls already
+// waiting keep the policy they started with.
+func (q *MPMC[T]) SetSpinPolicy(p SpinPolicy) {
+	q.policy.Store(&p)
+}
+
 // Cap returns the queue capacity.
 func (q *MPMC[T]) Cap() int {
 	return int(q.capacity)
 }
+
+// Throughput returns the queue's rate tracker, or nil unless the queue
+// was built with [Builder.WithThroughputSampleRate].
+func (q *MPMC[T]) Throughput() *ThroughputTracker {
+	return q.obs.tput()
+}
+
+// ThresholdLimit returns the value each Enqueue resets the livelock
+// threshold to: 3n-1 for capacity n, or the current tuned value if the
+// queue was built with [Builder.WithAdaptiveThreshold].
+func (q *MPMC[T]) ThresholdLimit() int {
+	return int(q.thresholdLimit())
+}
+
+func (q *MPMC[T]) thresholdLimit() int64 {
+	if q.tune != nil {
+		return q.tune.limit.Load()
+	}
+	return 3*int64(q.capacity) - 1
+}
+
+func (q *MPMC[T]) thresholdTuner() *thresholdTuner {
+	return q.tune
+}
+
+// MaxDepth returns the highest depth observed after a successful Enqueue,
+// or 0 unless the queue was built with [Builder.WithDepthTracking].
+// The value is advisory and survives Drain until ResetMaxDepth is called.
+func (q *MPMC[T]) MaxDepth() int {
+	return q.obs.marks().maxDepth()
+}
+
+// ResetMaxDepth clears the maximum depth watermark.
+func (q *MPMC[T]) ResetMaxDepth() {
+	q.obs.marks().resetMax()
+}
+
+// MinDepth returns the lowest depth observed after a successful Dequeue,
+// or Cap() before the first Dequeue and unless the queue was built with
+// [Builder.WithDepthTracking]. The value is advisory and survives Drain
+// until ResetMinDepth is called.
+func (q *MPMC[T]) MinDepth() int {
+	return q.obs.marks().minDepth(q.capacity)
+}
+
+// ResetMinDepth resets the minimum depth watermark to Cap().
+func (q *MPMC[T]) ResetMinDepth() {
+	q.obs.marks().resetMin(q.capacity)
+}
+
+// BackpressureChannel returns a channel that receives when a Dequeue
+// succeeds after a producer found the queue full.
+//
+// A blocked producer retries Enqueue after receiving from the channel.
+// The signal is a hint: it is dropped if no one is listening, so callers
+// should also bound their wait with a timeout or context.
+//
+// The queue tracks notifications only from the first call to this method,
+// CapacitySignalChannel, HighWaterMark or LowWaterMark, so that queues
+// without listeners pay nothing for them. Request the channels before
+// starting producers and consumers.
+func (q *MPMC[T]) BackpressureChannel() <-chan struct{} {
+	return q.obs.get().sig.get().notFull
+}
+
+// CapacitySignalChannel returns a channel that receives when an Enqueue
+// succeeds after a consumer found the queue empty.
+//
+// Sleeping consumers select on it to wake when work arrives. Like
+// BackpressureChannel, the signal is a hint and may be dropped, and only
+// transitions after the channel was first requested are signaled.
+func (q *MPMC[T]) CapacitySignalChannel() <-chan struct{} {
+	return q.obs.get().sig.get().notEmpty
+}
+
+// HighWaterMark sets the high watermark to threshold, a fraction of
+// capacity in (0, 1], and returns a channel that receives when an Enqueue
+// brings the depth to that level.
+//
+// The signal is edge-triggered: it fires once per crossing and re-arms
+// when a Dequeue takes the depth back below the level. Producers use it
+// to slow down before the queue is full. Panics if threshold is out of
+// range.
+func (q *MPMC[T]) HighWaterMark(threshold float64) <-chan struct{} {
+	checkWatermark("MPMC.HighWaterMark", threshold)
+	return q.obs.get().sig.get().setHigh(threshold, q.capacity)
+}
+
+// LowWaterMark sets the low watermark to threshold, a fraction of
+// capacity in (0, 1], and returns a channel that receives when a Dequeue
+// brings the depth down to that level after it was above it.
+// Panics if threshold is out of range.
+func (q *MPMC[T]) LowWaterMark(threshold float64) <-chan struct{} {
+	checkWatermark("MPMC.LowWaterMark", threshold)
+	return q.obs.get().sig.get().setLow(threshold, q.capacity)
+}
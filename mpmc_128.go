@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -20,6 +21,10 @@ import (
 // Entry format: [lo=cycle | hi=value]
 //
 // Memory: 2n slots, 16 bytes per slot (cycle + value in single Uint128)
+//
+// Layout: tail, head, threshold and draining are separated by 64-byte
+// pads, one cache line each, so producer and consumer FAAs do not
+// false-share.
 type MPMCIndirect struct {
 	_         pad
 	tail      atomix.Uint64 // Producer index (FAA)
@@ -28,7 +33,7 @@ type MPMCIndirect struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []mpmc128Slot
 	capacity  uint64 // n (usable capacity)
@@ -46,7 +51,7 @@ type mpmc128Slot struct {
 // Physical slot count is 2n for capacity n.
 func NewMPMCIndirect(capacity int) *MPMCIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCIndirect", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -72,18 +77,24 @@ func NewMPMCIndirect(capacity int) *MPMCIndirect {
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *MPMCIndirect) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPMCIndirect) Enqueue(elem uintptr) error {
+	if debugAssert {
+		defer checkFAA("MPMCIndirect", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadAcquire()
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		myTail := q.tail.AddAcqRel(1) - 1
@@ -101,7 +112,7 @@ func (q *MPMCIndirect) Enqueue(elem uintptr) error {
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			return ErrFull // Queue full
 		}
 
 		sw.Once()
@@ -109,12 +120,15 @@ func (q *MPMCIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPMCIndirect) Dequeue() (uintptr, error) {
+	if debugAssert {
+		defer checkFAA("MPMCIndirect", q)
+	}
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		return 0, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		return 0, ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -141,10 +155,10 @@ func (q *MPMCIndirect) Dequeue() (uintptr, error) {
 			if tail <= myHead+1 {
 				q.catchup(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				return 0, ErrWouldBlock
+				return 0, q.state.emptyErr()
 			}
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				return 0, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				return 0, ErrEmpty
 			}
 		}
 
@@ -162,6 +176,21 @@ func (q *MPMCIndirect) catchup(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCIndirect) Cap() int {
 	return int(q.capacity)
@@ -183,7 +212,7 @@ type MPMCPtr struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []mpmc128Slot // Reuse same slot type
 	capacity  uint64        // n (usable capacity)
@@ -195,7 +224,7 @@ type MPMCPtr struct {
 // Capacity rounds up to the next power of 2.
 func NewMPMCPtr(capacity int) *MPMCPtr {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCPtr", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -222,18 +251,31 @@ func NewMPMCPtr(capacity int) *MPMCPtr {
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *MPMCPtr) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *MPMCPtr) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("MPMCPtr"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *MPMCPtr) EnqueueNilable(elem unsafe.Pointer) error {
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadAcquire()
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		myTail := q.tail.AddAcqRel(1) - 1
@@ -251,7 +293,7 @@ func (q *MPMCPtr) Enqueue(elem unsafe.Pointer) error {
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			return ErrFull // Queue full
 		}
 
 		sw.Once()
@@ -259,12 +301,12 @@ func (q *MPMCPtr) Enqueue(elem unsafe.Pointer) error {
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *MPMCPtr) Dequeue() (unsafe.Pointer, error) {
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		return nil, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		return nil, ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -291,10 +333,10 @@ func (q *MPMCPtr) Dequeue() (unsafe.Pointer, error) {
 			if tail <= myHead+1 {
 				q.catchupPtr(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				return nil, ErrWouldBlock
+				return nil, q.state.emptyErr()
 			}
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				return nil, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				return nil, ErrEmpty
 			}
 		}
 
@@ -312,6 +354,21 @@ func (q *MPMCPtr) catchupPtr(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCPtr) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCPtr) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCPtr) Cap() int {
 	return int(q.capacity)
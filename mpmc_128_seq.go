@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -43,7 +44,7 @@ type mpmc128SeqSlot struct {
 // This is the Compact variant. Use NewMPMCIndirect for the default FAA-based implementation.
 func NewMPMCIndirectSeq(capacity int) *MPMCIndirectSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCIndirectSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -62,7 +63,7 @@ func NewMPMCIndirectSeq(capacity int) *MPMCIndirectSeq {
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPMCIndirectSeq) Enqueue(elem uintptr) error {
 	sw := spin.Wait{}
 	for {
@@ -81,7 +82,7 @@ func (q *MPMCIndirectSeq) Enqueue(elem uintptr) error {
 			}
 		} else if diff < 0 {
 			// Queue is full (slot from old round not yet consumed)
-			return ErrWouldBlock
+			return ErrFull
 		}
 		// diff > 0: another producer succeeded, retry with fresh tail
 		sw.Once()
@@ -89,7 +90,7 @@ func (q *MPMCIndirectSeq) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPMCIndirectSeq) Dequeue() (uintptr, error) {
 	sw := spin.Wait{}
 	for {
@@ -104,12 +105,27 @@ func (q *MPMCIndirectSeq) Dequeue() (uintptr, error) {
 				return uintptr(valHi), nil
 			}
 		} else if diff < 0 {
-			return 0, ErrWouldBlock
+			return 0, ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCIndirectSeq) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCIndirectSeq) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCIndirectSeq) Cap() int {
 	return int(q.capacity)
@@ -142,7 +158,7 @@ type MPMCPtrSeq struct {
 // This is the Compact variant. Use NewMPMCPtr for the default FAA-based implementation.
 func NewMPMCPtrSeq(capacity int) *MPMCPtrSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCPtrSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -160,8 +176,18 @@ func NewMPMCPtrSeq(capacity int) *MPMCPtrSeq {
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *MPMCPtrSeq) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("MPMCPtrSeq"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *MPMCPtrSeq) EnqueueNilable(elem unsafe.Pointer) error {
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
@@ -175,14 +201,14 @@ func (q *MPMCPtrSeq) Enqueue(elem unsafe.Pointer) error {
 				return nil
 			}
 		} else if diff < 0 {
-			return ErrWouldBlock
+			return ErrFull
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *MPMCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 	sw := spin.Wait{}
 	for {
@@ -197,12 +223,27 @@ func (q *MPMCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 				return *(*unsafe.Pointer)(unsafe.Pointer(&valHi)), nil
 			}
 		} else if diff < 0 {
-			return nil, ErrWouldBlock
+			return nil, ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCPtrSeq) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCPtrSeq) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCPtrSeq) Cap() int {
 	return int(q.capacity)
@@ -151,8 +151,8 @@ func TestMPMCPtr128BasicOperations(t *testing.T) {
 func TestMPMCPtr128NilPointer(t *testing.T) {
 	q := lfq.NewMPMCPtr(4)
 
-	// nil is a valid unsafe.Pointer value
-	if err := q.Enqueue(nil); err != nil {
+	// nil passes through EnqueueNilable
+	if err := q.EnqueueNilable(nil); err != nil {
 		t.Fatalf("enqueue nil: %v", err)
 	}
 
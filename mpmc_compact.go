@@ -5,6 +5,10 @@
 package lfq
 
 import (
+	"context"
+	"math/bits"
+	"strconv"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -12,6 +16,38 @@ import (
 // emptyFlag marks a slot as empty. The remaining 63 bits store the round number.
 const emptyFlag = 1 << 63
 
+// tagBit returns the highest bit of mask below bit 63, which carries bit
+// 63 of tagged values, or 0 if mask reserves only bit 63.
+func tagBit(mask uintptr) uintptr {
+	rest := uint64(mask &^ emptyFlag)
+	if rest == 0 {
+		return 0
+	}
+	return 1 << (bits.Len64(rest) - 1)
+}
+
+// stealTag drops bit 63 of a tagged value for storage in a compact slot.
+// Panics unless bit 63 equals the tag bit of mask.
+func stealTag(typ string, elem, mask uintptr) uintptr {
+	t := tagBit(mask)
+	if (elem&emptyFlag != 0) != (t != 0 && elem&t != 0) {
+		if t == 0 {
+			panic(exceeds63Bits(typ, elem))
+		}
+		panic("lfq: " + typ + ".EnqueueMasked: bit 63 of value 0x" + strconv.FormatUint(uint64(elem), 16) +
+			" differs from tag bit " + strconv.Itoa(bits.Len64(uint64(t))-1) + "; see " + docURL + "Builder.WithUpperBitMask")
+	}
+	return elem &^ emptyFlag
+}
+
+// restoreTag reverses stealTag.
+func restoreTag(elem, mask uintptr) uintptr {
+	if t := tagBit(mask); t != 0 && elem&t != 0 {
+		return elem | emptyFlag
+	}
+	return elem
+}
+
 // MPMCCompactIndirect is a compact MPMC queue for uintptr values.
 //
 // Uses round-based empty detection: empty slots store (emptyFlag | round),
@@ -28,7 +64,9 @@ type MPMCCompactIndirect struct {
 	buffer   []atomix.Uintptr
 	mask     uint64
 	capacity uint64
-	order    uint64 // log2(capacity) for round calculation
+	order    uint64  // log2(capacity) for round calculation
+	mod      uint64  // capacity if not a power of 2, else 0
+	reserved uintptr // Bits Enqueue rejects; always includes bit 63
 }
 
 // NewMPMCCompactIndirect creates a new compact MPMC queue.
@@ -36,10 +74,13 @@ type MPMCCompactIndirect struct {
 // Values are limited to 63 bits (high bit reserved for empty flag).
 func NewMPMCCompactIndirect(capacity int) *MPMCCompactIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCCompactIndirect", "capacity", capacity, 2))
 	}
+	return newMPMCCompactIndirect(uint64(roundToPow2(capacity)), emptyFlag)
+}
 
-	n := uint64(roundToPow2(capacity))
+// newMPMCCompactIndirect creates the queue with exactly n slots.
+func newMPMCCompactIndirect(n uint64, reserved uintptr) *MPMCCompactIndirect {
 	order := uint64(0)
 	for (1 << order) < n {
 		order++
@@ -50,6 +91,8 @@ func NewMPMCCompactIndirect(capacity int) *MPMCCompactIndirect {
 		mask:     n - 1,
 		capacity: n,
 		order:    order,
+		mod:      exactMod(n),
+		reserved: reserved,
 	}
 
 	for i := range q.buffer {
@@ -60,13 +103,32 @@ func NewMPMCCompactIndirect(capacity int) *MPMCCompactIndirect {
 }
 
 // Enqueue adds a value to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 // Values must fit in 63 bits (high bit must be 0).
 func (q *MPMCCompactIndirect) Enqueue(elem uintptr) error {
-	if elem&emptyFlag != 0 {
-		panic("lfq: value exceeds 63 bits")
+	if elem&q.reserved != 0 {
+		panic(reservedBitsSet("MPMCCompactIndirect", elem, q.reserved))
 	}
+	return q.enqueue(elem)
+}
+
+// EnqueueMasked adds a value whose reserved bits carry a tag. Bit 63 is
+// dropped before storing and restored by DequeueMasked from the highest
+// other reserved bit, so the two bits must be equal, as in sign-extended
+// addresses; other reserved bits are stored as they are. Panics if the
+// tag cannot be represented. See [Builder.WithUpperBitMask].
+func (q *MPMCCompactIndirect) EnqueueMasked(elem uintptr) error {
+	return q.enqueue(stealTag("MPMCCompactIndirect", elem, q.reserved))
+}
+
+// DequeueMasked removes a value added by EnqueueMasked and restores its
+// bit 63. Returns (0, ErrEmpty) if the queue is empty.
+func (q *MPMCCompactIndirect) DequeueMasked() (uintptr, error) {
+	elem, err := q.Dequeue()
+	return restoreTag(elem, q.reserved), err
+}
 
+func (q *MPMCCompactIndirect) enqueue(elem uintptr) error {
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
@@ -75,11 +137,11 @@ func (q *MPMCCompactIndirect) Enqueue(elem uintptr) error {
 			continue
 		}
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
-		idx := tail & q.mask
-		round := (tail >> q.order) & (emptyFlag - 1)
+		idx := slotIndex(tail, q.mask, q.mod)
+		round := slotRound(tail, q.order, q.mod) & (emptyFlag - 1)
 		expected := emptyFlag | uintptr(round)
 
 		if q.buffer[idx].CompareAndSwapAcqRel(expected, elem) {
@@ -92,22 +154,22 @@ func (q *MPMCCompactIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns a value from the queue.
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPMCCompactIndirect) Dequeue() (uintptr, error) {
 	sw := spin.Wait{}
 	for {
 		head := q.head.LoadAcquire()
 		tail := q.tail.LoadAcquire()
 
-		idx := head & q.mask
+		idx := slotIndex(head, q.mask, q.mod)
 		elem := q.buffer[idx].LoadAcquire()
 		if head != q.head.LoadAcquire() {
 			continue
 		}
 		if head >= tail {
-			return 0, ErrWouldBlock
+			return 0, ErrEmpty
 		}
-		nextRound := ((head >> q.order) + 1) & (emptyFlag - 1)
+		nextRound := (slotRound(head, q.order, q.mod) + 1) & (emptyFlag - 1)
 		nextEmpty := emptyFlag | uintptr(nextRound)
 		if elem == nextEmpty {
 			q.head.CompareAndSwapAcqRel(head, head+1)
@@ -127,6 +189,21 @@ func (q *MPMCCompactIndirect) Dequeue() (uintptr, error) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCCompactIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCCompactIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCCompactIndirect) Cap() int {
 	return int(q.capacity)
@@ -0,0 +1,158 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"sync/atomic"
+
+	"code.hybscloud.com/atomix"
+)
+
+// lazyGrowNum/lazyGrowDen is the fill ratio of the active segment that
+// triggers growth.
+const (
+	lazyGrowNum = 4
+	lazyGrowDen = 5
+)
+
+// MPMCLazy is an MPMC queue that defers allocating its capacity until it
+// is needed.
+//
+// It starts with a segment of Cap()/16 slots. When the active segment
+// reaches 80% full, a background goroutine allocates a segment twice its
+// size and makes it active; producers move to the new segment while
+// consumers finish the older ones, oldest first. Drained segments are
+// released, leaving a single segment of Cap() slots once the queue has
+// grown fully. A shared element count holds the live segments together to
+// Cap() elements while older segments drain.
+//
+// Expansion costs one allocation off the hot path, but producers that
+// fill the active segment before the new one is published see
+// ErrWouldBlock: expect a brief backpressure spike at each doubling.
+//
+// Ordering: FIFO within each segment, and across segments for a single
+// producer and consumer.
+//
+// Memory: 2n slots (MPMC) for the active segments, n growing to Cap()
+type MPMCLazy[T any] struct {
+	_        pad
+	cur      atomic.Pointer[lazySegment[T]] // Segment producers enqueue into
+	_        pad
+	count    atomix.Int64 // Elements across live segments, reserved before enqueue
+	_        pad
+	growing  atomic.Bool // A growth goroutine is running
+	_        pad
+	capacity int
+}
+
+type lazySegment[T any] struct {
+	generation
+	older atomic.Pointer[lazySegment[T]] // Previous segment, nil once drained
+	q     *MPMC[T]
+}
+
+func (s *lazySegment[T]) drain() {
+	s.q.Drain()
+}
+
+// NewMPMCLazy creates an MPMC queue of the given capacity whose slots are
+// allocated on demand. Capacity rounds up to the next power of 2.
+func NewMPMCLazy[T any](capacity int) *MPMCLazy[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewMPMCLazy", "capacity", capacity, 2))
+	}
+	n := roundToPow2(capacity)
+	q := &MPMCLazy[T]{capacity: n}
+	q.cur.Store(&lazySegment[T]{q: NewMPMC[T](max(2, n/16))})
+	return q
+}
+
+// Enqueue adds an element to the active segment.
+// Returns ErrWouldBlock if the queue holds Cap() elements or the active
+// segment is full.
+func (q *MPMCLazy[T]) Enqueue(elem *T) error {
+	if q.count.AddAcqRel(1) > int64(q.capacity) {
+		q.count.AddAcqRel(-1)
+		return ErrFull
+	}
+	s := enterGeneration(&q.cur)
+	err := s.q.Enqueue(elem)
+	s.leave()
+	if err != nil {
+		q.count.AddAcqRel(-1)
+	}
+	if err != nil || s.q.depth()*lazyGrowDen >= s.q.Cap()*lazyGrowNum {
+		q.grow(s)
+	}
+	return err
+}
+
+// Dequeue removes an element from the oldest non-empty segment.
+// Returns ErrEmpty if the queue is empty.
+func (q *MPMCLazy[T]) Dequeue() (T, error) {
+	// Each pass takes the oldest segment newer than stop, so every live
+	// segment is tried once, oldest first, without bounding the chain
+	var stop *lazySegment[T]
+	for {
+		var newer *lazySegment[T]
+		s := q.cur.Load()
+		for o := s.older.Load(); o != nil && o != stop; o = o.older.Load() {
+			newer, s = s, o
+		}
+		if elem, err := s.q.Dequeue(); err == nil {
+			q.count.AddAcqRel(-1)
+			return elem, nil
+		}
+		if newer == nil {
+			break
+		}
+		stop = s
+		// A segment no producer can reach is released once empty,
+		// keeping any older segment linked
+		if s.idle() && s.q.depth() == 0 {
+			if older := s.older.Load(); newer.older.CompareAndSwap(s, older) {
+				stop = older
+			}
+		}
+	}
+	var zero T
+	return zero, ErrEmpty
+}
+
+// grow starts a growth goroutine for segment s unless one is running or
+// s is already full size.
+func (q *MPMCLazy[T]) grow(s *lazySegment[T]) {
+	if s.q.Cap() >= q.capacity || q.growing.Load() || !q.growing.CompareAndSwap(false, true) {
+		return
+	}
+	go q.expand(s)
+}
+
+func (q *MPMCLazy[T]) expand(s *lazySegment[T]) {
+	defer q.growing.Store(false)
+	if q.cur.Load() != s {
+		return
+	}
+
+	size := min(2*s.q.Cap(), q.capacity)
+
+	next := &lazySegment[T]{q: NewMPMC[T](size)}
+	next.older.Store(s)
+	replaceGeneration(&q.cur, next)
+}
+
+// Cap returns the queue capacity, which segments grow toward.
+func (q *MPMCLazy[T]) Cap() int {
+	return q.capacity
+}
+
+// Allocated returns the total capacity of the live segments.
+func (q *MPMCLazy[T]) Allocated() int {
+	n := 0
+	for s := q.cur.Load(); s != nil; s = s.older.Load() {
+		n += s.q.Cap()
+	}
+	return n
+}
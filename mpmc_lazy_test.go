@@ -0,0 +1,62 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestMPMCLazyGrowth fills a lazily allocated queue well beyond its
+// initial segment and verifies FIFO order and segment release.
+func TestMPMCLazyGrowth(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const capacity = 65536
+	q := lfq.NewMPMCLazy[int](capacity)
+	if q.Cap() != capacity {
+		t.Fatalf("Cap: got %d, want %d", q.Cap(), capacity)
+	}
+	if got := q.Allocated(); got != capacity/16 {
+		t.Fatalf("Allocated: got %d, want %d", got, capacity/16)
+	}
+
+	for i := range capacity {
+		if q.Enqueue(&i) != nil {
+			retryWithTimeout(t, 5*time.Second, func() bool { return q.Enqueue(&i) == nil }, "enqueue after growth")
+		}
+	}
+	if got := q.Allocated(); got <= capacity/16 {
+		t.Fatalf("Allocated after fill: got %d, want > %d", got, capacity/16)
+	}
+	// Growth leaves room in the newest segment, but the queue holds Cap()
+	for range 3 {
+		v := -1
+		if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+			t.Fatalf("Enqueue beyond Cap: got %v, want ErrWouldBlock", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := range capacity {
+		v, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue(%d): %v", i, err)
+		}
+		if v != i {
+			t.Fatalf("Dequeue: got %d, want %d", v, i)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+	if got := q.Allocated(); got != capacity {
+		t.Fatalf("Allocated after drain: got %d, want %d", got, capacity)
+	}
+}
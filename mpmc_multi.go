@@ -0,0 +1,102 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// MPMCMultiBuffer is an MPMC queue spread over several independent MPMC
+// sub-queues, each with its own head, tail and threshold.
+//
+// Enqueue places an element in the least-full sub-queue and Dequeue takes
+// from the most-full one, keeping the sub-queues balanced while spreading
+// index contention across them. Ties are broken round-robin.
+//
+// Ordering: FIFO holds within a sub-queue only. Elements in different
+// sub-queues may be dequeued in either order, so the queue as a whole is
+// not FIFO.
+//
+// Memory: numBuffers × 2(cap/numBuffers) slots
+type MPMCMultiBuffer[T any] struct {
+	_    pad
+	next atomix.Uint64 // Round-robin start for tie-breaking
+	_    pad
+	subs []*MPMC[T]
+}
+
+// NewMPMCMultiBuffer creates a queue of numBuffers sub-queues, each with
+// capacity/numBuffers rounded up to the next power of 2.
+// Panics if numBuffers < 1 or capacity/numBuffers < 2.
+func NewMPMCMultiBuffer[T any](capacity, numBuffers int) *MPMCMultiBuffer[T] {
+	if numBuffers < 1 {
+		panic(belowMinimum("NewMPMCMultiBuffer", "numBuffers", numBuffers, 1))
+	}
+	if capacity/numBuffers < 2 {
+		panic(belowMinimum("NewMPMCMultiBuffer", "capacity per buffer", capacity/numBuffers, 2))
+	}
+
+	q := &MPMCMultiBuffer[T]{subs: make([]*MPMC[T], numBuffers)}
+	for i := range q.subs {
+		q.subs[i] = NewMPMC[T](capacity / numBuffers)
+	}
+	return q
+}
+
+// pick returns the index of the sub-queue with the lowest depth, or the
+// highest if most is set, scanning from a rotating start.
+func (q *MPMCMultiBuffer[T]) pick(most bool) int {
+	n := len(q.subs)
+	start := int(q.next.AddRelaxed(1) % uint64(n))
+	best, bestDepth := start, q.subs[start].depth()
+	for i := 1; i < n; i++ {
+		idx := (start + i) % n
+		d := q.subs[idx].depth()
+		if (most && d > bestDepth) || (!most && d < bestDepth) {
+			best, bestDepth = idx, d
+		}
+	}
+	return best
+}
+
+// Enqueue adds an element to the least-full sub-queue, falling back to
+// the others if it is full. Returns ErrWouldBlock if all are full.
+func (q *MPMCMultiBuffer[T]) Enqueue(elem *T) error {
+	first := q.pick(false)
+	for i := range q.subs {
+		if q.subs[(first+i)%len(q.subs)].Enqueue(elem) == nil {
+			return nil
+		}
+	}
+	return ErrFull
+}
+
+// Dequeue removes an element from the most-full sub-queue, falling back
+// to the others if it is empty. Returns ErrWouldBlock if all are empty.
+func (q *MPMCMultiBuffer[T]) Dequeue() (T, error) {
+	first := q.pick(true)
+	for i := range q.subs {
+		if elem, err := q.subs[(first+i)%len(q.subs)].Dequeue(); err == nil {
+			return elem, nil
+		}
+	}
+	var zero T
+	return zero, ErrEmpty
+}
+
+// Drain signals that no more enqueues will occur, on every sub-queue.
+func (q *MPMCMultiBuffer[T]) Drain() {
+	for _, s := range q.subs {
+		s.Drain()
+	}
+}
+
+// Buffers returns the number of sub-queues.
+func (q *MPMCMultiBuffer[T]) Buffers() int {
+	return len(q.subs)
+}
+
+// Cap returns the total capacity of all sub-queues.
+func (q *MPMCMultiBuffer[T]) Cap() int {
+	return len(q.subs) * q.subs[0].Cap()
+}
@@ -0,0 +1,58 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestMPMCMultiBufferBasic tests balancing, capacity and full/empty.
+func TestMPMCMultiBufferBasic(t *testing.T) {
+	q := lfq.NewMPMCMultiBuffer[int](16, 4)
+	if q.Cap() != 16 || q.Buffers() != 4 {
+		t.Fatalf("Cap, Buffers: got (%d, %d), want (16, 4)", q.Cap(), q.Buffers())
+	}
+
+	seen := make(map[int]bool)
+	for i := range 16 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	v := 99
+	if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+	for range 16 {
+		v, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+		if seen[v] {
+			t.Fatalf("duplicate element %d", v)
+		}
+		seen[v] = true
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestMPMCMultiBufferLinearizability runs the linearizability checker with
+// 4 producers and 4 consumers over 2 and 4 sub-queues.
+func TestMPMCMultiBufferLinearizability(t *testing.T) {
+	for _, buffers := range []int{2, 4} {
+		t.Run(fmt.Sprintf("buffers=%d", buffers), func(t *testing.T) {
+			q := lfq.NewMPMCMultiBuffer[int](256, buffers)
+			lt := &linearizabilityTest{t: t, numP: 4, numC: 4, itemsPerProd: 10000, timeout: 10 * time.Second}
+			lt.runGeneric(func(v int) error { return q.Enqueue(&v) }, q.Dequeue)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// MPMCOrdered is an MPMC queue that tags every element with its producer
+// and a per-producer sequence number.
+//
+// MPMC gives no ordering guarantee between consumers: two elements from
+// the same producer may be processed out of order when different
+// consumers dequeue them. The tags let consumers restore per-producer
+// order, for example by buffering elements until the next expected
+// sequence number arrives.
+//
+// Producers register with OpenProducer and enqueue through the returned
+// handle. Sequence numbers start at 0 and are gapless: a failed Enqueue
+// does not consume one.
+//
+// Memory: 2n slots (MPMC)
+type MPMCOrdered[T any] struct {
+	_         pad
+	producers atomix.Uint64 // Next producer ID
+	_         pad
+	q         *MPMC[producerItem[T]]
+}
+
+type producerItem[T any] struct {
+	producer uint64
+	seq      uint64
+	data     T
+}
+
+// ProducerHandle enqueues into an MPMCOrdered queue on behalf of one
+// producer. A handle is used by a single goroutine; its sequence order is
+// the order of its successful Enqueue calls.
+type ProducerHandle[T any] struct {
+	q   *MPMCOrdered[T]
+	id  uint64
+	seq atomix.Uint64 // Next sequence number
+}
+
+// NewMPMCOrdered creates a new ordered MPMC queue.
+// Capacity rounds up to the next power of 2.
+func NewMPMCOrdered[T any](capacity int) *MPMCOrdered[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewMPMCOrdered", "capacity", capacity, 2))
+	}
+	return &MPMCOrdered[T]{q: NewMPMC[producerItem[T]](capacity)}
+}
+
+// OpenProducer registers a new producer and returns its handle.
+// Producer IDs are assigned from 0 in registration order.
+func (q *MPMCOrdered[T]) OpenProducer() *ProducerHandle[T] {
+	return &ProducerHandle[T]{q: q, id: q.producers.AddAcqRel(1) - 1}
+}
+
+// Dequeue removes an element and returns it together with its producer ID
+// and per-producer sequence number.
+// Returns ErrEmpty if the queue is empty.
+func (q *MPMCOrdered[T]) Dequeue() (elem T, producerID uint64, seq uint64, err error) {
+	item, err := q.q.Dequeue()
+	if err != nil {
+		return elem, 0, 0, err
+	}
+	return item.data, item.producer, item.seq, nil
+}
+
+// Cap returns the queue capacity.
+func (q *MPMCOrdered[T]) Cap() int {
+	return q.q.Cap()
+}
+
+// ID returns the producer ID stamped on elements from this handle.
+func (h *ProducerHandle[T]) ID() uint64 {
+	return h.id
+}
+
+// Enqueue adds an element tagged with the next sequence number.
+// Returns ErrFull if the queue is full.
+func (h *ProducerHandle[T]) Enqueue(elem *T) error {
+	seq := h.seq.LoadRelaxed()
+	item := producerItem[T]{producer: h.id, seq: seq, data: *elem}
+	if err := h.q.q.Enqueue(&item); err != nil {
+		return err
+	}
+	h.seq.StoreRelaxed(seq + 1)
+	return nil
+}
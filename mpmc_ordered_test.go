@@ -0,0 +1,100 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestMPMCOrderedBasic tests producer IDs, gapless sequence numbers and
+// full/empty behavior.
+func TestMPMCOrderedBasic(t *testing.T) {
+	q := lfq.NewMPMCOrdered[string](2)
+	a, b := q.OpenProducer(), q.OpenProducer()
+	if a.ID() != 0 || b.ID() != 1 {
+		t.Fatalf("ID: got (%d, %d), want (0, 1)", a.ID(), b.ID())
+	}
+
+	x, y, z := "x", "y", "z"
+	if err := a.Enqueue(&x); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := b.Enqueue(&y); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	// A failed Enqueue must not consume a sequence number
+	if err := a.Enqueue(&z); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+
+	for _, want := range []struct {
+		v        string
+		producer uint64
+	}{{"x", 0}, {"y", 1}} {
+		v, p, s, err := q.Dequeue()
+		if err != nil || v != want.v || p != want.producer || s != 0 {
+			t.Fatalf("Dequeue: got (%q, %d, %d, %v), want (%q, %d, 0, nil)", v, p, s, err, want.v, want.producer)
+		}
+	}
+	if err := a.Enqueue(&z); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if v, p, s, err := q.Dequeue(); err != nil || v != "z" || p != 0 || s != 1 {
+		t.Fatalf("Dequeue: got (%q, %d, %d, %v), want (\"z\", 0, 1, nil)", v, p, s, err)
+	}
+	if _, _, _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestMPMCOrderedPerProducerOrder runs two producers concurrently and
+// verifies each producer's elements arrive with sequence 0, 1, 2, ...
+func TestMPMCOrderedPerProducerOrder(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const perProducer = 1000
+	q := lfq.NewMPMCOrdered[int](16)
+
+	var wg sync.WaitGroup
+	for range 2 {
+		h := q.OpenProducer()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				if h.Enqueue(&i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	var next [2]uint64
+	for received := 0; received < 2*perProducer; {
+		v, p, s, err := q.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if p > 1 {
+			t.Fatalf("Dequeue: producer ID %d out of range", p)
+		}
+		if s != next[p] || v != int(s) {
+			t.Fatalf("producer %d: got (seq %d, value %d), want seq %d", p, s, v, next[p])
+		}
+		next[p]++
+		received++
+	}
+	wg.Wait()
+}
@@ -5,6 +5,8 @@
 package lfq
 
 import (
+	"context"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -26,30 +28,62 @@ type MPMCSeq[T any] struct {
 	_        pad
 	head     atomix.Uint64 // Consumer index
 	_        pad
+	epoch    atomix.Uint64 // Incremented by Reset
+	_        pad
 	buffer   []mpmcSeqSlot[T]
 	mask     uint64
 	capacity uint64
+	mod      uint64 // capacity if not a power of 2, else 0
 }
 
 type mpmcSeqSlot[T any] struct {
-	seq  atomix.Uint64
+	seq  atomix.Uint64 // Epoch in the upper seqEpochShift bits, position below
 	data T
 	_    padShort // Pad to cache line
 }
 
+// seqEpochShift splits a slot sequence into the Reset epoch (upper 24 bits)
+// and the position (lower 40 bits). Positions are compared modulo 2^40,
+// which is exact while the capacity stays below 2^39.
+const (
+	seqEpochShift = 40
+	seqPosMask    = 1<<seqEpochShift - 1
+)
+
+// seqWord packs epoch and position into a slot sequence.
+func seqWord(epoch, pos uint64) uint64 {
+	return epoch<<seqEpochShift | pos&seqPosMask
+}
+
+// seqDiff returns the signed distance from pos to the position in seq.
+func seqDiff(seq, pos uint64) int64 {
+	return int64((seq-pos)<<(64-seqEpochShift)) >> (64 - seqEpochShift)
+}
+
+// seqStale reports whether seq was written in an epoch before epoch.
+// Epochs are compared modulo 2^24, so a caller holding an older epoch
+// never treats a newer element as stale.
+func seqStale(seq, epoch uint64) bool {
+	return int64((seq>>seqEpochShift-epoch)<<seqEpochShift) < 0
+}
+
 // NewMPMCSeq creates a new CAS-based MPMC queue.
 // Capacity rounds up to the next power of 2.
 // This is the Compact variant. Use NewMPMC for the default FAA-based implementation.
 func NewMPMCSeq[T any](capacity int) *MPMCSeq[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPMCSeq", "capacity", capacity, 2))
 	}
+	return newMPMCSeq[T](uint64(roundToPow2(capacity)))
+}
 
-	n := uint64(roundToPow2(capacity))
+// newMPMCSeq creates the queue with exactly n slots.
+func newMPMCSeq[T any](n uint64) *MPMCSeq[T] {
 	q := &MPMCSeq[T]{
 		buffer:   make([]mpmcSeqSlot[T], n),
 		mask:     n - 1,
 		capacity: n,
+		mod:      exactMod(n),
 	}
 
 	for i := uint64(0); i < n; i++ {
@@ -60,54 +94,128 @@ func NewMPMCSeq[T any](capacity int) *MPMCSeq[T] {
 }
 
 // Enqueue adds an element to the queue.
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPMCSeq[T]) Enqueue(elem *T) error {
+	epoch := q.epoch.LoadAcquire()
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
-		slot := &q.buffer[tail&q.mask]
+		slot := &q.buffer[slotIndex(tail, q.mask, q.mod)]
 		seq := slot.seq.LoadAcquire()
-		diff := int64(seq) - int64(tail)
+		diff := seqDiff(seq, tail)
 
 		if diff == 0 {
 			if q.tail.CompareAndSwapAcqRel(tail, tail+1) {
 				slot.data = *elem
-				slot.seq.StoreRelease(tail + 1)
+				slot.seq.StoreRelease(seqWord(epoch, tail+1))
 				return nil
 			}
 		} else if diff < 0 {
-			return ErrWouldBlock
+			// Full, unless elements from before a Reset hold the slots
+			if !seqStale(seq, epoch) || !q.discardStale(epoch) {
+				return ErrFull
+			}
+			sw = spin.Wait{}
+			continue
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element from the queue.
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *MPMCSeq[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *MPMCSeq[T]) DequeueInto(dst *T) error {
+	epoch := q.epoch.LoadAcquire()
 	sw := spin.Wait{}
 	for {
 		head := q.head.LoadAcquire()
-		slot := &q.buffer[head&q.mask]
+		slot := &q.buffer[slotIndex(head, q.mask, q.mod)]
 		seq := slot.seq.LoadAcquire()
-		diff := int64(seq) - int64(head+1)
+		diff := seqDiff(seq, head+1)
 
 		if diff == 0 {
 			if q.head.CompareAndSwapAcqRel(head, head+1) {
-				elem := slot.data
 				var zero T
+				stale := seqStale(seq, epoch)
+				if !stale {
+					*dst = slot.data
+				}
 				slot.data = zero
-				slot.seq.StoreRelease(head + q.capacity)
-				return elem, nil
+				slot.seq.StoreRelease(seqWord(epoch, head+q.capacity))
+				if stale {
+					// Written before the last Reset: discard and keep looking
+					sw = spin.Wait{}
+					continue
+				}
+				return nil
 			}
 		} else if diff < 0 {
-			var zero T
-			return zero, ErrWouldBlock
+			return ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// discardStale frees the head slot if it holds an element written before
+// the last Reset. Claiming the head index, as Dequeue does, keeps a
+// consumer that read the slot before the Reset from racing the discard.
+// Reports whether it made progress: false if the head slot holds a live
+// element or is still being released.
+func (q *MPMCSeq[T]) discardStale(epoch uint64) bool {
+	head := q.head.LoadAcquire()
+	slot := &q.buffer[slotIndex(head, q.mask, q.mod)]
+	seq := slot.seq.LoadAcquire()
+	if seqDiff(seq, head+1) != 0 || !seqStale(seq, epoch) {
+		// Another thread moved head first: let the caller look again
+		return q.head.LoadAcquire() != head
+	}
+	if q.head.CompareAndSwapAcqRel(head, head+1) {
+		var zero T
+		slot.data = zero
+		slot.seq.StoreRelease(seqWord(epoch, head+q.capacity))
+	}
+	return true
+}
+
+// Reset discards all elements currently in the queue.
+//
+// Reset is safe to call concurrently with Enqueue and Dequeue. It runs in
+// O(1): it advances the queue epoch carried in the upper bits of every
+// slot sequence. A slot stamped with an older epoch counts as free:
+// Dequeue discards it, and Enqueue discards it instead of reporting
+// ErrFull, so the capacity held by discarded elements is available at
+// once. Stale slots are claimed through the head index like any other,
+// so in-flight operations never observe a reinitialized slot.
+//
+// Elements enqueued concurrently with Reset may or may not be discarded.
+func (q *MPMCSeq[T]) Reset() {
+	q.epoch.AddAcqRel(1)
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPMCSeq[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPMCSeq[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPMCSeq[T]) Cap() int {
 	return int(q.capacity)
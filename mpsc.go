@@ -5,6 +5,9 @@
 package lfq
 
 import (
+	"context"
+	"sync/atomic"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -21,12 +24,16 @@ type MPSC[T any] struct {
 	_        pad
 	tail     atomix.Uint64 // Producer index (FAA)
 	_        pad
-	draining atomix.Bool // Drain mode: no more enqueues
+	state    lifecycle // Drain and Close state
+	_        pad
+	tokens   atomix.Uint64 // Last issued ProducerToken
 	_        pad
 	buffer   []mpscSlot[T]
-	capacity uint64 // n (usable capacity)
-	size     uint64 // 2n (physical slots)
-	mask     uint64 // 2n - 1
+	capacity uint64                     // n (usable capacity)
+	size     uint64                     // 2n (physical slots)
+	mask     uint64                     // 2n - 1
+	obs      observersRef               // Nil unless a hook is enabled
+	policy   atomic.Pointer[SpinPolicy] // Nil uses DefaultSpinPolicy
 }
 
 type mpscSlot[T any] struct {
@@ -39,7 +46,7 @@ type mpscSlot[T any] struct {
 // Capacity rounds up to the next power of 2.
 func NewMPSC[T any](capacity int) *MPSC[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSC", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -63,18 +70,25 @@ func NewMPSC[T any](capacity int) *MPSC[T] {
 // This is a hint for graceful shutdown — the caller ensures no further
 // enqueues will be attempted after calling Drain.
 func (q *MPSC[T]) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPSC[T]) Enqueue(elem *T) error {
+	if debugAssert {
+		defer checkFAA("MPSC", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadRelaxed()
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			q.obs.full()
+			return ErrFull
 		}
 
 		myTail := q.tail.AddAcqRel(1) - 1
@@ -86,20 +100,112 @@ func (q *MPSC[T]) Enqueue(elem *T) error {
 
 		if slotCycle == expectedCycle {
 			slot.data = *elem
+			if o := q.obs.load(); o != nil {
+				o.stamp(myTail & q.mask)
+				slot.cycle.StoreRelease(expectedCycle + 1)
+				o.enqueued(q, 1)
+				return nil
+			}
 			slot.cycle.StoreRelease(expectedCycle + 1)
 			return nil
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			q.obs.full()
+			return ErrFull // Queue full
 		}
 		sw.Once()
 	}
 }
 
+// ProducerToken identifies a logical producer of an MPSC, such as one
+// task run by a pooled goroutine. Obtain tokens from
+// [MPSC.NewProducerToken]; the zero value is not a valid token.
+//
+// Tokens are reserved for per-producer ordering. The queue does not yet
+// use them: EnqueueWithToken behaves like Enqueue.
+type ProducerToken struct {
+	id uint64
+}
+
+// EnqueueValue adds a copy of val (multiple producers safe), without the
+// caller taking an address. It does not allocate.
+func (q *MPSC[T]) EnqueueValue(val T) error {
+	return q.Enqueue(&val)
+}
+
+// NewProducerToken returns a token distinct from all others issued by q.
+// It costs one atomic increment.
+func (q *MPSC[T]) NewProducerToken() ProducerToken {
+	return ProducerToken{id: q.tokens.AddRelaxed(1)}
+}
+
+// EnqueueWithToken adds an element on behalf of the logical producer
+// identified by token. Calls with the same token from one goroutine are
+// ordered relative to each other, as with Enqueue.
+// Returns ErrFull if the queue is full.
+func (q *MPSC[T]) EnqueueWithToken(elem *T, token ProducerToken) error {
+	_ = token
+	return q.Enqueue(elem)
+}
+
+// enqueueRun adds a prefix of items with a single FAA claim and returns
+// its length, which is capped by the free space observed beforehand.
+//
+// Unlike the FAA in Enqueue, a claimed position is never abandoned: the
+// single consumer frees slots in position order, so a slot still holding
+// the previous round is released once the consumer catches up, and the
+// producer waits for it.
+func (q *MPSC[T]) enqueueRun(items []T) int {
+	if len(items) == 0 {
+		return 0
+	}
+	tail := q.tail.LoadAcquire()
+	head := q.head.LoadRelaxed()
+	if tail >= head+q.capacity {
+		q.obs.full()
+		return 0
+	}
+	k := min(uint64(len(items)), head+q.capacity-tail)
+
+	o := q.obs.load()
+	base := q.tail.AddAcqRel(k) - k
+	for i := range k {
+		pos := base + i
+		slot := &q.buffer[pos&q.mask]
+		expectedCycle := pos / q.capacity
+		sw := spin.Wait{}
+		for slot.cycle.LoadAcquire() != expectedCycle {
+			sw.Once()
+		}
+		slot.data = items[i]
+		if o != nil {
+			o.stamp(pos & q.mask)
+		}
+		slot.cycle.StoreRelease(expectedCycle + 1)
+	}
+
+	if o != nil {
+		o.enqueued(q, k)
+	}
+	return int(k)
+}
+
 // Dequeue removes and returns an element (single consumer only).
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *MPSC[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *MPSC[T]) DequeueInto(dst *T) error {
+	if debugAssert {
+		defer checkFAA("MPSC", q)
+	}
 	head := q.head.LoadRelaxed()
 	cycle := head / q.capacity
 	slot := &q.buffer[head&q.mask]
@@ -107,21 +213,211 @@ func (q *MPSC[T]) Dequeue() (T, error) {
 	slotCycle := slot.cycle.LoadAcquire()
 
 	if slotCycle != cycle+1 {
-		var zero T
-		return zero, ErrWouldBlock
+		q.obs.empty()
+		return q.state.emptyErr()
 	}
 
-	elem := slot.data
+	*dst = slot.data
 	var zero T
 	slot.data = zero
 	nextEnqCycle := (head + q.size) / q.capacity
+	if o := q.obs.load(); o != nil {
+		o.observe(head & q.mask)
+		slot.cycle.StoreRelease(nextEnqCycle)
+		q.head.StoreRelaxed(head + 1)
+		o.dequeued(q, 1)
+		return nil
+	}
 	slot.cycle.StoreRelease(nextEnqCycle)
 	q.head.StoreRelaxed(head + 1)
+	return nil
+}
+
+// DequeueMany removes up to len(dst) elements without copying them
+// (single consumer only), setting dst[i] to point at each element in its
+// slot, and returns the number removed.
+// Returns (0, ErrEmpty) if the queue is empty.
+//
+// The slots are released to producers on return, so the pointers stay
+// valid only while no Enqueue runs: a producer may overwrite an element
+// as soon as its slot comes around again. The single consumer makes the
+// pattern safe once producers have stopped, e.g. when draining after
+// Drain. Slots are not cleared, so the elements stay reachable until
+// overwritten.
+func (q *MPSC[T]) DequeueMany(dst []*T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	head := q.head.LoadRelaxed()
+	o := q.obs.load()
+	n := 0
+	for ; n < len(dst); n++ {
+		pos := head + uint64(n)
+		slot := &q.buffer[pos&q.mask]
+		if slot.cycle.LoadAcquire() != pos/q.capacity+1 {
+			break
+		}
+		if o != nil {
+			o.observe(pos & q.mask)
+		}
+		dst[n] = &slot.data
+		slot.cycle.StoreRelease((pos + q.size) / q.capacity)
+	}
+	if n == 0 {
+		q.obs.empty()
+		return 0, q.state.emptyErr()
+	}
+	q.head.StoreRelaxed(head + uint64(n))
+
+	if o != nil {
+		o.dequeued(q, uint64(n))
+	}
+	return n, nil
+}
 
-	return elem, nil
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSC[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, q.policy.Load(), func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSC[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, q.policy.Load(), func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// SetSpinPolicy sets how EnqueueCtx and DequeueCtx wait. Calls already
+// waiting keep the policy they started with.
+func (q *MPSC[T]) SetSpinPolicy(p SpinPolicy) {
+	q.policy.Store(&p)
 }
 
 // Cap returns the queue capacity.
 func (q *MPSC[T]) Cap() int {
 	return int(q.capacity)
 }
+
+// Snapshot returns a copy of the queued elements in FIFO order without
+// consuming them. It does not modify queue state.
+//
+// Only slots whose cycle marks them as filled are copied. Call it from the
+// consumer goroutine; concurrent Enqueue calls may produce an inconsistent
+// view, e.g. a slot claimed but not yet published is skipped.
+func (q *MPSC[T]) Snapshot() []T {
+	head := q.head.LoadRelaxed()
+	tail := q.tail.LoadAcquire()
+	out := make([]T, 0, min(tail-head, q.capacity))
+	for pos := head; pos < tail && pos < head+q.capacity; pos++ {
+		slot := &q.buffer[pos&q.mask]
+		if slot.cycle.LoadAcquire() == pos/q.capacity+1 {
+			out = append(out, slot.data)
+		}
+	}
+	return out
+}
+
+// EnqueueAndDepth adds an element and returns the queue depth observed
+// immediately after the operation.
+//
+// The depth is advisory: other producers and the consumer run
+// concurrently. On ErrWouldBlock the returned depth is the capacity.
+func (q *MPSC[T]) EnqueueAndDepth(elem *T) (int, error) {
+	if err := q.Enqueue(elem); err != nil {
+		return int(q.capacity), err
+	}
+	return q.depth(), nil
+}
+
+// DequeueAndDepth removes an element (single consumer only) and returns
+// the queue depth observed immediately after the operation.
+//
+// The depth is advisory. On ErrWouldBlock the returned depth is 0.
+func (q *MPSC[T]) DequeueAndDepth() (T, int, error) {
+	elem, err := q.Dequeue()
+	if err != nil {
+		return elem, 0, err
+	}
+	return elem, q.depth(), nil
+}
+
+// Throughput returns the queue's rate tracker, or nil unless the queue
+// was built with [Builder.WithThroughputSampleRate].
+func (q *MPSC[T]) Throughput() *ThroughputTracker {
+	return q.obs.tput()
+}
+
+// MaxDepth returns the highest depth observed after a successful Enqueue,
+// or 0 unless the queue was built with [Builder.WithDepthTracking].
+// The value is advisory and survives Drain until ResetMaxDepth is called.
+func (q *MPSC[T]) MaxDepth() int {
+	return q.obs.marks().maxDepth()
+}
+
+// ResetMaxDepth clears the maximum depth watermark.
+func (q *MPSC[T]) ResetMaxDepth() {
+	q.obs.marks().resetMax()
+}
+
+// MinDepth returns the lowest depth observed after a successful Dequeue,
+// or Cap() before the first Dequeue and unless the queue was built with
+// [Builder.WithDepthTracking]. The value is advisory and survives Drain
+// until ResetMinDepth is called.
+func (q *MPSC[T]) MinDepth() int {
+	return q.obs.marks().minDepth(q.capacity)
+}
+
+// ResetMinDepth resets the minimum depth watermark to Cap().
+func (q *MPSC[T]) ResetMinDepth() {
+	q.obs.marks().resetMin(q.capacity)
+}
+
+// BackpressureChannel returns a channel that receives when a Dequeue
+// succeeds after a producer found the queue full.
+//
+// A blocked producer retries Enqueue after receiving from the channel.
+// The signal is a hint: it is dropped if no one is listening, so callers
+// should also bound their wait with a timeout or context.
+//
+// The queue tracks notifications only from the first call to this method,
+// CapacitySignalChannel, HighWaterMark or LowWaterMark, so that queues
+// without listeners pay nothing for them. Request the channels before
+// starting producers and consumers.
+func (q *MPSC[T]) BackpressureChannel() <-chan struct{} {
+	return q.obs.get().sig.get().notFull
+}
+
+// CapacitySignalChannel returns a channel that receives when an Enqueue
+// succeeds after a consumer found the queue empty.
+//
+// Sleeping consumers select on it to wake when work arrives. Like
+// BackpressureChannel, the signal is a hint and may be dropped, and only
+// transitions after the channel was first requested are signaled.
+func (q *MPSC[T]) CapacitySignalChannel() <-chan struct{} {
+	return q.obs.get().sig.get().notEmpty
+}
+
+// HighWaterMark sets the high watermark to threshold, a fraction of
+// capacity in (0, 1], and returns a channel that receives when an Enqueue
+// brings the depth to that level.
+//
+// The signal is edge-triggered: it fires once per crossing and re-arms
+// when a Dequeue takes the depth back below the level. Producers use it
+// to slow down before the queue is full. Panics if threshold is out of
+// range.
+func (q *MPSC[T]) HighWaterMark(threshold float64) <-chan struct{} {
+	checkWatermark("MPSC.HighWaterMark", threshold)
+	return q.obs.get().sig.get().setHigh(threshold, q.capacity)
+}
+
+// LowWaterMark sets the low watermark to threshold, a fraction of
+// capacity in (0, 1], and returns a channel that receives when a Dequeue
+// brings the depth down to that level after it was above it.
+// Panics if threshold is out of range.
+func (q *MPSC[T]) LowWaterMark(threshold float64) <-chan struct{} {
+	checkWatermark("MPSC.LowWaterMark", threshold)
+	return q.obs.get().sig.get().setLow(threshold, q.capacity)
+}
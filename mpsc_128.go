@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -19,13 +20,16 @@ import (
 // Entry format: [lo=cycle | hi=value]
 //
 // Memory: 2n slots, 16 bytes per slot
+//
+// Layout: head, tail and draining each occupy their own cache line, so
+// producers hammering tail with FAA do not invalidate the consumer's head.
 type MPSCIndirect struct {
 	_        pad
 	head     atomix.Uint64 // Consumer index (single consumer writes, but producers read)
 	_        pad
 	tail     atomix.Uint64 // Producer index (FAA)
 	_        pad
-	draining atomix.Bool // Drain mode: no more enqueues
+	state    lifecycle // Drain and Close state
 	_        pad
 	buffer   []mpmc128Slot
 	capacity uint64
@@ -37,7 +41,7 @@ type MPSCIndirect struct {
 // Capacity rounds up to the next power of 2.
 func NewMPSCIndirect(capacity int) *MPSCIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCIndirect", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -64,19 +68,25 @@ func NewMPSCIndirect(capacity int) *MPSCIndirect {
 // This is a hint for graceful shutdown — the caller ensures no further
 // enqueues will be attempted after calling Drain.
 func (q *MPSCIndirect) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPSCIndirect) Enqueue(elem uintptr) error {
+	if debugAssert {
+		defer checkFAA("MPSCIndirect", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		// Early check: if queue appears full, don't waste a position
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadRelaxed() // Atomic read (written by consumer)
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		// FAA to blindly claim position (true SCQ)
@@ -96,7 +106,7 @@ func (q *MPSCIndirect) Enqueue(elem uintptr) error {
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			return ErrFull // Queue full
 		}
 
 		// slotCycle > expectedCycle or CAS failed: another producer used this slot
@@ -105,8 +115,11 @@ func (q *MPSCIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns an element (single consumer only).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPSCIndirect) Dequeue() (uintptr, error) {
+	if debugAssert {
+		defer checkFAA("MPSCIndirect", q)
+	}
 	head := q.head.LoadRelaxed()
 	cycle := head / q.capacity
 	slot := &q.buffer[head&q.mask]
@@ -114,7 +127,7 @@ func (q *MPSCIndirect) Dequeue() (uintptr, error) {
 	slotCycle, valHi := slot.entry.LoadAcquire()
 
 	if slotCycle != cycle+1 {
-		return 0, ErrWouldBlock
+		return 0, q.state.emptyErr()
 	}
 
 	nextEnqCycle := (head + q.size) / q.capacity
@@ -124,6 +137,39 @@ func (q *MPSCIndirect) Dequeue() (uintptr, error) {
 	return uintptr(valHi), nil
 }
 
+// SnapshotIndirect returns a copy of the queued values in FIFO order
+// without consuming them. It does not modify queue state.
+//
+// Call it from the consumer goroutine; concurrent Enqueue calls may
+// produce an inconsistent view.
+func (q *MPSCIndirect) SnapshotIndirect() []uintptr {
+	head := q.head.LoadRelaxed()
+	tail := q.tail.LoadAcquire()
+	out := make([]uintptr, 0, min(tail-head, q.capacity))
+	for pos := head; pos < tail && pos < head+q.capacity; pos++ {
+		cycle, val := q.buffer[pos&q.mask].entry.LoadAcquire()
+		if cycle == pos/q.capacity+1 {
+			out = append(out, uintptr(val))
+		}
+	}
+	return out
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPSCIndirect) Cap() int {
 	return int(q.capacity)
@@ -142,7 +188,7 @@ type MPSCPtr struct {
 	_        pad
 	tail     atomix.Uint64 // Producer index (FAA)
 	_        pad
-	draining atomix.Bool // Drain mode: no more enqueues
+	state    lifecycle // Drain and Close state
 	_        pad
 	buffer   []mpmc128Slot
 	capacity uint64
@@ -154,7 +200,7 @@ type MPSCPtr struct {
 // Capacity rounds up to the next power of 2.
 func NewMPSCPtr(capacity int) *MPSCPtr {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCPtr", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -178,18 +224,31 @@ func NewMPSCPtr(capacity int) *MPSCPtr {
 // This is a hint for graceful shutdown — the caller ensures no further
 // enqueues will be attempted after calling Drain.
 func (q *MPSCPtr) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *MPSCPtr) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("MPSCPtr"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *MPSCPtr) EnqueueNilable(elem unsafe.Pointer) error {
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadRelaxed()
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		myTail := q.tail.AddAcqRel(1) - 1
@@ -206,14 +265,14 @@ func (q *MPSCPtr) Enqueue(elem unsafe.Pointer) error {
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
-			return ErrWouldBlock // Queue full
+			return ErrFull // Queue full
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element (single consumer only).
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *MPSCPtr) Dequeue() (unsafe.Pointer, error) {
 	head := q.head.LoadRelaxed()
 	cycle := head / q.capacity
@@ -222,7 +281,7 @@ func (q *MPSCPtr) Dequeue() (unsafe.Pointer, error) {
 	slotCycle, valHi := slot.entry.LoadAcquire()
 
 	if slotCycle != cycle+1 {
-		return nil, ErrWouldBlock
+		return nil, q.state.emptyErr()
 	}
 
 	nextEnqCycle := (head + q.size) / q.capacity
@@ -232,6 +291,21 @@ func (q *MPSCPtr) Dequeue() (unsafe.Pointer, error) {
 	return *(*unsafe.Pointer)(unsafe.Pointer(&valHi)), nil
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCPtr) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCPtr) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPSCPtr) Cap() int {
 	return int(q.capacity)
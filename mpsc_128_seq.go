@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -33,7 +34,7 @@ type MPSCIndirectSeq struct {
 // Capacity rounds up to the next power of 2.
 func NewMPSCIndirectSeq(capacity int) *MPSCIndirectSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCIndirectSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -51,7 +52,7 @@ func NewMPSCIndirectSeq(capacity int) *MPSCIndirectSeq {
 }
 
 // Enqueue adds an element to the queue (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPSCIndirectSeq) Enqueue(elem uintptr) error {
 	sw := spin.Wait{}
 	for {
@@ -59,7 +60,7 @@ func (q *MPSCIndirectSeq) Enqueue(elem uintptr) error {
 		head := q.head.LoadAcquire()
 
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		slot := &q.buffer[tail&q.mask]
@@ -71,21 +72,21 @@ func (q *MPSCIndirectSeq) Enqueue(elem uintptr) error {
 				return nil
 			}
 		} else if seqLo < tail {
-			return ErrWouldBlock
+			return ErrFull
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element (single consumer only).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPSCIndirectSeq) Dequeue() (uintptr, error) {
 	head := q.head.LoadRelaxed()
 	slot := &q.buffer[head&q.mask]
 	seqLo, valHi := slot.entry.LoadAcquire()
 
 	if seqLo != head+1 {
-		return 0, ErrWouldBlock
+		return 0, ErrEmpty
 	}
 
 	slot.entry.StoreRelease(head+q.capacity, 0)
@@ -94,6 +95,21 @@ func (q *MPSCIndirectSeq) Dequeue() (uintptr, error) {
 	return uintptr(valHi), nil
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCIndirectSeq) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCIndirectSeq) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPSCIndirectSeq) Cap() int {
 	return int(q.capacity)
@@ -119,7 +135,7 @@ type MPSCPtrSeq struct {
 // Capacity rounds up to the next power of 2.
 func NewMPSCPtrSeq(capacity int) *MPSCPtrSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCPtrSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -137,15 +153,25 @@ func NewMPSCPtrSeq(capacity int) *MPSCPtrSeq {
 }
 
 // Enqueue adds an element (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *MPSCPtrSeq) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("MPSCPtrSeq"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *MPSCPtrSeq) EnqueueNilable(elem unsafe.Pointer) error {
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadAcquire()
 
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
 		slot := &q.buffer[tail&q.mask]
@@ -157,21 +183,21 @@ func (q *MPSCPtrSeq) Enqueue(elem unsafe.Pointer) error {
 				return nil
 			}
 		} else if seqLo < tail {
-			return ErrWouldBlock
+			return ErrFull
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element (single consumer only).
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *MPSCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 	head := q.head.LoadRelaxed()
 	slot := &q.buffer[head&q.mask]
 	seqLo, valHi := slot.entry.LoadAcquire()
 
 	if seqLo != head+1 {
-		return nil, ErrWouldBlock
+		return nil, ErrEmpty
 	}
 
 	slot.entry.StoreRelease(head+q.capacity, 0)
@@ -180,6 +206,21 @@ func (q *MPSCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 	return *(*unsafe.Pointer)(unsafe.Pointer(&valHi)), nil
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCPtrSeq) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCPtrSeq) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *MPSCPtrSeq) Cap() int {
 	return int(q.capacity)
@@ -5,6 +5,8 @@
 package lfq
 
 import (
+	"context"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -25,6 +27,8 @@ type MPSCCompactIndirect struct {
 	mask     uint64
 	capacity uint64
 	order    uint64
+	mod      uint64  // capacity if not a power of 2, else 0
+	reserved uintptr // Bits Enqueue rejects; always includes bit 63
 }
 
 // NewMPSCCompactIndirect creates a new compact MPSC queue.
@@ -32,10 +36,13 @@ type MPSCCompactIndirect struct {
 // Values are limited to 63 bits (high bit reserved for empty flag).
 func NewMPSCCompactIndirect(capacity int) *MPSCCompactIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCCompactIndirect", "capacity", capacity, 2))
 	}
+	return newMPSCCompactIndirect(uint64(roundToPow2(capacity)), emptyFlag)
+}
 
-	n := uint64(roundToPow2(capacity))
+// newMPSCCompactIndirect creates the queue with exactly n slots.
+func newMPSCCompactIndirect(n uint64, reserved uintptr) *MPSCCompactIndirect {
 	order := uint64(0)
 	for (1 << order) < n {
 		order++
@@ -46,6 +53,8 @@ func NewMPSCCompactIndirect(capacity int) *MPSCCompactIndirect {
 		mask:     n - 1,
 		capacity: n,
 		order:    order,
+		mod:      exactMod(n),
+		reserved: reserved,
 	}
 
 	for i := range q.buffer {
@@ -58,21 +67,40 @@ func NewMPSCCompactIndirect(capacity int) *MPSCCompactIndirect {
 // Enqueue adds a value (multiple producers safe).
 // Values must fit in 63 bits.
 func (q *MPSCCompactIndirect) Enqueue(elem uintptr) error {
-	if elem&emptyFlag != 0 {
-		panic("lfq: value exceeds 63 bits")
+	if elem&q.reserved != 0 {
+		panic(reservedBitsSet("MPSCCompactIndirect", elem, q.reserved))
 	}
+	return q.enqueue(elem)
+}
+
+// EnqueueMasked adds a value whose reserved bits carry a tag. Bit 63 is
+// dropped before storing and restored by DequeueMasked from the highest
+// other reserved bit, so the two bits must be equal, as in sign-extended
+// addresses; other reserved bits are stored as they are. Panics if the
+// tag cannot be represented. See [Builder.WithUpperBitMask].
+func (q *MPSCCompactIndirect) EnqueueMasked(elem uintptr) error {
+	return q.enqueue(stealTag("MPSCCompactIndirect", elem, q.reserved))
+}
 
+// DequeueMasked removes a value added by EnqueueMasked and restores its
+// bit 63. Returns (0, ErrEmpty) if the queue is empty.
+func (q *MPSCCompactIndirect) DequeueMasked() (uintptr, error) {
+	elem, err := q.Dequeue()
+	return restoreTag(elem, q.reserved), err
+}
+
+func (q *MPSCCompactIndirect) enqueue(elem uintptr) error {
 	sw := spin.Wait{}
 	for {
 		tail := q.tail.LoadAcquire()
 		head := q.head.LoadAcquire()
 
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
-		idx := tail & q.mask
-		round := (tail >> q.order) & (emptyFlag - 1)
+		idx := slotIndex(tail, q.mask, q.mod)
+		round := slotRound(tail, q.order, q.mod) & (emptyFlag - 1)
 		expected := emptyFlag | uintptr(round)
 
 		if q.buffer[idx].CompareAndSwapAcqRel(expected, elem) {
@@ -85,23 +113,23 @@ func (q *MPSCCompactIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns a value (single consumer only).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *MPSCCompactIndirect) Dequeue() (uintptr, error) {
 	head := q.head.LoadRelaxed()
 	tail := q.tail.LoadAcquire()
 
 	if head >= tail {
-		return 0, ErrWouldBlock
+		return 0, ErrEmpty
 	}
 
-	idx := head & q.mask
+	idx := slotIndex(head, q.mask, q.mod)
 	elem := q.buffer[idx].LoadAcquire()
 
-	nextRound := ((head >> q.order) + 1) & (emptyFlag - 1)
+	nextRound := (slotRound(head, q.order, q.mod) + 1) & (emptyFlag - 1)
 	nextEmpty := emptyFlag | uintptr(nextRound)
 
 	if elem&emptyFlag != 0 {
-		return 0, ErrWouldBlock
+		return 0, ErrEmpty
 	}
 
 	q.buffer[idx].StoreRelease(nextEmpty)
@@ -110,6 +138,21 @@ func (q *MPSCCompactIndirect) Dequeue() (uintptr, error) {
 	return elem, nil
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCCompactIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCCompactIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns queue capacity.
 func (q *MPSCCompactIndirect) Cap() int {
 	return int(q.capacity)
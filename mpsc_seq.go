@@ -5,6 +5,8 @@
 package lfq
 
 import (
+	"context"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -26,6 +28,7 @@ type MPSCSeq[T any] struct {
 	buffer   []mpscSeqSlot[T]
 	mask     uint64
 	capacity uint64
+	mod      uint64 // capacity if not a power of 2, else 0
 }
 
 type mpscSeqSlot[T any] struct {
@@ -39,14 +42,18 @@ type mpscSeqSlot[T any] struct {
 // This is the Compact variant. Use NewMPSC for the default FAA-based implementation.
 func NewMPSCSeq[T any](capacity int) *MPSCSeq[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewMPSCSeq", "capacity", capacity, 2))
 	}
+	return newMPSCSeq[T](uint64(roundToPow2(capacity)))
+}
 
-	n := uint64(roundToPow2(capacity))
+// newMPSCSeq creates the queue with exactly n slots.
+func newMPSCSeq[T any](n uint64) *MPSCSeq[T] {
 	q := &MPSCSeq[T]{
 		buffer:   make([]mpscSeqSlot[T], n),
 		mask:     n - 1,
 		capacity: n,
+		mod:      exactMod(n),
 	}
 
 	for i := uint64(0); i < n; i++ {
@@ -57,7 +64,7 @@ func NewMPSCSeq[T any](capacity int) *MPSCSeq[T] {
 }
 
 // Enqueue adds an element to the queue (multiple producers safe).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *MPSCSeq[T]) Enqueue(elem *T) error {
 	sw := spin.Wait{}
 	for {
@@ -65,10 +72,10 @@ func (q *MPSCSeq[T]) Enqueue(elem *T) error {
 		head := q.head.LoadAcquire()
 
 		if tail >= head+q.capacity {
-			return ErrWouldBlock
+			return ErrFull
 		}
 
-		slot := &q.buffer[tail&q.mask]
+		slot := &q.buffer[slotIndex(tail, q.mask, q.mod)]
 		seq := slot.seq.LoadAcquire()
 
 		if seq == tail {
@@ -78,31 +85,54 @@ func (q *MPSCSeq[T]) Enqueue(elem *T) error {
 				return nil
 			}
 		} else if seq < tail {
-			return ErrWouldBlock
+			return ErrFull
 		}
 		sw.Once()
 	}
 }
 
 // Dequeue removes and returns an element (single consumer only).
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *MPSCSeq[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *MPSCSeq[T]) DequeueInto(dst *T) error {
 	head := q.head.LoadRelaxed()
-	slot := &q.buffer[head&q.mask]
+	slot := &q.buffer[slotIndex(head, q.mask, q.mod)]
 	seq := slot.seq.LoadAcquire()
 
 	if seq != head+1 {
-		var zero T
-		return zero, ErrWouldBlock
+		return ErrEmpty
 	}
 
-	elem := slot.data
+	*dst = slot.data
 	var zero T
 	slot.data = zero
 	slot.seq.StoreRelease(head + q.capacity)
 	q.head.StoreRelease(head + 1)
 
-	return elem, nil
+	return nil
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *MPSCSeq[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *MPSCSeq[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
 }
 
 // Cap returns the queue capacity.
@@ -0,0 +1,144 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/iox"
+)
+
+// Multiplexer receives from whichever of several queues has data, in the
+// spirit of a select over channels.
+//
+// Each Dequeue polls the queues in round-robin order, starting one queue
+// later than the previous call, so a busy queue cannot starve the others.
+// The multiplexer dequeues on behalf of its caller: the consumer rules of
+// each underlying queue apply, so a multiplexer over SPSC or MPSC queues
+// must be used by a single goroutine.
+type Multiplexer[T any] struct {
+	next   atomix.Uint64 // Start index of the next poll
+	queues []Queue[T]
+}
+
+// NewMultiplexer creates a multiplexer over queues.
+// Panics if no queues are given.
+func NewMultiplexer[T any](queues ...Queue[T]) *Multiplexer[T] {
+	if len(queues) == 0 {
+		panic(belowMinimum("NewMultiplexer", "len(queues)", 0, 1))
+	}
+	return &Multiplexer[T]{queues: queues}
+}
+
+// Dequeue returns the first element found and the index of its source
+// queue. Returns ErrWouldBlock if every queue is empty.
+func (m *Multiplexer[T]) Dequeue() (T, int, error) {
+	n := uint64(len(m.queues))
+	start := m.next.AddRelaxed(1) - 1
+	for i := range n {
+		idx := int((start + i) % n)
+		if elem, err := m.queues[idx].Dequeue(); err == nil {
+			return elem, idx, nil
+		}
+	}
+	var zero T
+	return zero, -1, ErrEmpty
+}
+
+// DequeueCtx is like Dequeue but retries with backoff until an element is
+// available or ctx is done, in which case it returns ctx.Err().
+func (m *Multiplexer[T]) DequeueCtx(ctx context.Context) (T, int, error) {
+	ba := iox.Backoff{}
+	for {
+		elem, idx, err := m.Dequeue()
+		if err == nil {
+			return elem, idx, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return elem, -1, err
+		}
+		ba.Wait()
+	}
+}
+
+// Len returns the number of multiplexed queues.
+func (m *Multiplexer[T]) Len() int {
+	return len(m.queues)
+}
+
+// WeightedMultiplexer receives from several queues in weighted
+// round-robin order.
+//
+// A queue of weight w is polled w times per cycle, so when every queue has
+// data a queue of weight 3 delivers three times as many elements as a
+// queue of weight 1. Empty queues are skipped, and every queue is polled
+// at least once per cycle, so all sources make progress. The consumer
+// rules of the underlying queues apply as for Multiplexer.
+type WeightedMultiplexer[T any] struct {
+	next     atomix.Uint64 // Cycle position of the next poll
+	queues   []Queue[T]
+	schedule []int // One queue index per cycle position
+}
+
+// NewWeightedMultiplexer creates a multiplexer over queues, where
+// weights[i] is the number of polls queue i receives per cycle.
+// Panics if no queues are given, the lengths differ, or a weight is < 1.
+func NewWeightedMultiplexer[T any](queues []Queue[T], weights []int) *WeightedMultiplexer[T] {
+	if len(queues) == 0 {
+		panic(belowMinimum("NewWeightedMultiplexer", "len(queues)", 0, 1))
+	}
+	if len(weights) != len(queues) {
+		panic(lengthMismatch("NewWeightedMultiplexer", "len(weights)", len(weights), "len(queues)", len(queues)))
+	}
+	var schedule []int
+	for i, w := range weights {
+		if w < 1 {
+			panic(belowMinimum("NewWeightedMultiplexer", "weight", w, 1))
+		}
+		for range w {
+			schedule = append(schedule, i)
+		}
+	}
+	return &WeightedMultiplexer[T]{queues: queues, schedule: schedule}
+}
+
+// WeightedDequeue returns the first element found from the current cycle
+// position onward, and the index of its source queue. Returns
+// ErrWouldBlock if every queue is empty.
+func (m *WeightedMultiplexer[T]) WeightedDequeue() (T, int, error) {
+	n := uint64(len(m.schedule))
+	start := m.next.AddRelaxed(1) - 1
+	for i := range n {
+		idx := m.schedule[(start+i)%n]
+		if elem, err := m.queues[idx].Dequeue(); err == nil {
+			return elem, idx, nil
+		}
+	}
+	var zero T
+	return zero, -1, ErrEmpty
+}
+
+// WeightedDequeueCtx is like WeightedDequeue but retries with backoff
+// until an element is available or ctx is done, in which case it returns
+// ctx.Err().
+func (m *WeightedMultiplexer[T]) WeightedDequeueCtx(ctx context.Context) (T, int, error) {
+	ba := iox.Backoff{}
+	for {
+		elem, idx, err := m.WeightedDequeue()
+		if err == nil {
+			return elem, idx, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return elem, -1, err
+		}
+		ba.Wait()
+	}
+}
+
+// Len returns the number of multiplexed queues.
+func (m *WeightedMultiplexer[T]) Len() int {
+	return len(m.queues)
+}
@@ -0,0 +1,143 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestMultiplexer tests that Dequeue reports the source queue index.
+func TestMultiplexer(t *testing.T) {
+	qs := []*lfq.SPSC[int]{lfq.NewSPSC[int](4), lfq.NewSPSC[int](4), lfq.NewSPSC[int](4)}
+	m := lfq.NewMultiplexer[int](qs[0], qs[1], qs[2])
+
+	for i := range 3 {
+		v := 10 + i
+		qs[1].Enqueue(&v)
+	}
+	for i := range 3 {
+		v, idx, err := m.Dequeue()
+		if err != nil || idx != 1 || v != 10+i {
+			t.Fatalf("Dequeue: got (%d, %d, %v), want (%d, 1, nil)", v, idx, err, 10+i)
+		}
+	}
+	if _, idx, err := m.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) || idx != -1 {
+		t.Fatalf("Dequeue on empty: got (%d, %v), want (-1, ErrWouldBlock)", idx, err)
+	}
+}
+
+// TestMultiplexerRoundRobin tests that no queue is starved.
+func TestMultiplexerRoundRobin(t *testing.T) {
+	a, b := lfq.NewSPSC[int](8), lfq.NewSPSC[int](8)
+	m := lfq.NewMultiplexer[int](a, b)
+	for i := range 4 {
+		a.Enqueue(&i)
+		b.Enqueue(&i)
+	}
+
+	var counts [2]int
+	for range 4 {
+		_, idx, err := m.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+		counts[idx]++
+	}
+	if counts[0] != 2 || counts[1] != 2 {
+		t.Fatalf("source counts: got %v, want [2 2]", counts)
+	}
+}
+
+// TestMultiplexerDequeueCtx tests waiting for data and cancellation.
+func TestMultiplexerDequeueCtx(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	q := lfq.NewMPMC[int](4)
+	m := lfq.NewMultiplexer[int](lfq.NewMPMC[int](4), q)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		v := 7
+		q.Enqueue(&v)
+	}()
+	v, idx, err := m.DequeueCtx(context.Background())
+	if err != nil || idx != 1 || v != 7 {
+		t.Fatalf("DequeueCtx: got (%d, %d, %v), want (7, 1, nil)", v, idx, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.DequeueCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("DequeueCtx on empty: got %v, want DeadlineExceeded", err)
+	}
+}
+
+// TestWeightedMultiplexer tests that weights [3, 1, 1] deliver 3:1:1 while
+// every queue has data, and that empty queues are skipped.
+func TestWeightedMultiplexer(t *testing.T) {
+	qs := []lfq.Queue[int]{lfq.NewSPSC[int](64), lfq.NewSPSC[int](64), lfq.NewSPSC[int](64)}
+	m := lfq.NewWeightedMultiplexer(qs, []int{3, 1, 1})
+	// Queue 0 holds exactly its share of the first 50 dequeues
+	for i, n := range []int{30, 50, 50} {
+		for v := range n {
+			qs[i].Enqueue(&v)
+		}
+	}
+
+	var counts [3]int
+	for range 50 {
+		_, idx, err := m.WeightedDequeue()
+		if err != nil {
+			t.Fatalf("WeightedDequeue: %v", err)
+		}
+		counts[idx]++
+	}
+	if counts != [3]int{30, 10, 10} {
+		t.Fatalf("source counts: got %v, want [30 10 10]", counts)
+	}
+
+	// Queue 0 is now drained; the others still make progress
+	for range 80 {
+		if _, idx, err := m.WeightedDequeue(); err != nil || idx == 0 {
+			t.Fatalf("WeightedDequeue: got (%d, %v), want a non-empty queue", idx, err)
+		}
+	}
+	if _, idx, err := m.WeightedDequeue(); !lfq.IsEmpty(err) || idx != -1 {
+		t.Fatalf("WeightedDequeue on empty: got (%d, %v), want (-1, ErrEmpty)", idx, err)
+	}
+}
+
+// TestWeightedMultiplexerDequeueCtx tests waiting for data and cancellation.
+func TestWeightedMultiplexerDequeueCtx(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	q := lfq.NewMPMC[int](4)
+	m := lfq.NewWeightedMultiplexer([]lfq.Queue[int]{lfq.NewMPMC[int](4), q}, []int{2, 1})
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		v := 7
+		q.Enqueue(&v)
+	}()
+	v, idx, err := m.WeightedDequeueCtx(context.Background())
+	if err != nil || idx != 1 || v != 7 {
+		t.Fatalf("WeightedDequeueCtx: got (%d, %d, %v), want (7, 1, nil)", v, idx, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.WeightedDequeueCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WeightedDequeueCtx on empty: got %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,103 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && !race
+
+// Simulated NUMA placement: producers and consumers are pinned to disjoint
+// CPU groups so that every handoff crosses between the groups' caches.
+// The allowed CPUs are split into a low and a high half, which on typical
+// two-socket machines follows the socket boundary. Compare with the Local
+// benchmarks, which pin both sides to the same group:
+//
+//	go test -run=^$ -bench='NUMA(Local|Simulated)' -count=10 | benchstat -
+
+package lfq_test
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// cpuSet mirrors the kernel cpu_set_t (1024 CPUs).
+type cpuSet [16]uint64
+
+func (s *cpuSet) set(cpu int)      { s[cpu/64] |= 1 << (cpu % 64) }
+func (s *cpuSet) has(cpu int) bool { return s[cpu/64]&(1<<(cpu%64)) != 0 }
+
+// numaGroups splits the CPUs this process may run on into two halves.
+// ok is false if fewer than two CPUs are available.
+func numaGroups() (near, far cpuSet, ok bool) {
+	var all cpuSet
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(all), uintptr(unsafe.Pointer(&all)))
+	if errno != 0 {
+		return near, far, false
+	}
+	var cpus []int
+	for cpu := range len(all) * 64 {
+		if all.has(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	if len(cpus) < 2 {
+		return near, far, false
+	}
+	for i, cpu := range cpus {
+		if i < len(cpus)/2 {
+			near.set(cpu)
+		} else {
+			far.set(cpu)
+		}
+	}
+	return near, far, true
+}
+
+// pinTo returns a setup hook that locks the calling goroutine to its OS
+// thread and restricts that thread to set. The goroutine must not unlock:
+// the pinned thread then exits with it instead of returning to the pool.
+func pinTo(b *testing.B, set cpuSet) func() {
+	return func() {
+		runtime.LockOSThread()
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+		if errno != 0 {
+			b.Errorf("sched_setaffinity: %v", errno)
+		}
+	}
+}
+
+// runNUMA moves b.N items with consumers pinned to the near group and
+// producers pinned to the far group if cross is set, else to the near one.
+func runNUMA(b *testing.B, q cmpQueue, producers, consumers int, cross bool) {
+	near, far, ok := numaGroups()
+	if !ok {
+		b.Skip("skip: NUMA simulation needs at least 2 CPUs")
+	}
+	prod := near
+	if cross {
+		prod = far
+	}
+	runProducerConsumerOn(b, q, producers, consumers, pinTo(b, prod), pinTo(b, near))
+}
+
+func BenchmarkNUMASimulated_MPMC(b *testing.B) {
+	runNUMA(b, cmpGeneric{lfq.NewMPMC[uintptr](1024)}, 4, 4, true)
+}
+
+func BenchmarkNUMALocal_MPMC(b *testing.B) {
+	runNUMA(b, cmpGeneric{lfq.NewMPMC[uintptr](1024)}, 4, 4, false)
+}
+
+// The SPSCIndirect benchmarks exercise the assembly hot path where the
+// platform provides one.
+
+func BenchmarkNUMASimulated_SPSCIndirect(b *testing.B) {
+	runNUMA(b, lfq.NewSPSCIndirect(1024), 1, 1, true)
+}
+
+func BenchmarkNUMALocal_SPSCIndirect(b *testing.B) {
+	runNUMA(b, lfq.NewSPSCIndirect(1024), 1, 1, false)
+}
@@ -0,0 +1,120 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "sync/atomic"
+
+// observers holds the optional hooks of an FAA-based MPMC or MPSC: depth
+// marks, throughput, latency and notification signals.
+//
+// A queue holds a nil *observers while none of them is enabled, so its
+// Enqueue and Dequeue pay a single pointer load and nil check. The
+// Builder installs the hooks it enables before returning the queue; the
+// first request for a notification channel installs the observers if the
+// Builder did not.
+type observers struct {
+	marks *depthMarks        // Nil unless built WithDepthTracking
+	tput  *ThroughputTracker // Nil unless built WithThroughputSampleRate
+	lat   *latencyHistogram  // Nil unless built WithLatencyHistogram
+	sig   signalsRef         // Nil until a notification channel is requested
+}
+
+// observersRef points to a queue's observers, nil while none is enabled.
+type observersRef struct {
+	p atomic.Pointer[observers]
+}
+
+// load returns the observers, or nil if none is enabled.
+func (r *observersRef) load() *observers {
+	return r.p.Load()
+}
+
+// get returns the observers, creating them on first use.
+func (r *observersRef) get() *observers {
+	if o := r.p.Load(); o != nil {
+		return o
+	}
+	r.p.CompareAndSwap(nil, &observers{})
+	return r.p.Load()
+}
+
+// full records that a producer was turned away, if signals are in use.
+func (r *observersRef) full() {
+	if o := r.p.Load(); o != nil {
+		o.sig.full()
+	}
+}
+
+// empty records that a consumer found nothing, if signals are in use.
+func (r *observersRef) empty() {
+	if o := r.p.Load(); o != nil {
+		o.sig.empty()
+	}
+}
+
+// marks returns the depth marks, or nil.
+func (r *observersRef) marks() *depthMarks {
+	if o := r.p.Load(); o != nil {
+		return o.marks
+	}
+	return nil
+}
+
+// tput returns the throughput tracker, or nil.
+func (r *observersRef) tput() *ThroughputTracker {
+	if o := r.p.Load(); o != nil {
+		return o.tput
+	}
+	return nil
+}
+
+// lat returns the latency histogram, or nil.
+func (r *observersRef) lat() *latencyHistogram {
+	if o := r.p.Load(); o != nil {
+		return o.lat
+	}
+	return nil
+}
+
+// stamp records the enqueue time of slot i, before the slot is published.
+func (o *observers) stamp(i uint64) {
+	if o.lat != nil {
+		o.lat.stamp(i)
+	}
+}
+
+// observe records the wait of the element in slot i, before the slot is
+// released.
+func (o *observers) observe(i uint64) {
+	if o.lat != nil {
+		o.lat.observe(i)
+	}
+}
+
+// enqueued runs the hooks after n elements were added to q.
+func (o *observers) enqueued(q depther, n uint64) {
+	if o.marks != nil {
+		o.marks.raise(q.depth())
+	}
+	if s := o.sig.load(); s != nil {
+		s.enqueued(q)
+	}
+	if o.tput != nil {
+		o.tput.enq.record(n, o.tput.every)
+	}
+}
+
+// dequeued runs the hooks after n elements were removed from q.
+func (o *observers) dequeued(q depther, n uint64) {
+	if o.marks != nil {
+		o.marks.lower(q.depth())
+	}
+	if s := o.sig.load(); s != nil {
+		s.dequeued(q)
+	}
+	if o.tput != nil {
+		o.tput.deq.record(n, o.tput.every)
+	}
+}
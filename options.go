@@ -4,7 +4,11 @@
 
 package lfq
 
-import "unsafe"
+import (
+	"strconv"
+	"time"
+	"unsafe"
+)
 
 // Options configures queue creation and algorithm selection.
 type Options struct {
@@ -13,7 +17,34 @@ type Options struct {
 	singleConsumer bool
 
 	// Performance hints
-	compact bool // Effort to save slots
+	compact  bool // Effort to save slots
+	indirect bool // Store elements as uintptr
+	exact    bool // Keep capacity as given for Compact queues
+
+	// Instrumentation
+	sampleRate int             // Record every k-th operation; 0 disables tracking
+	latBuckets []time.Duration // Latency histogram bounds; nil disables it
+	depthMarks bool            // Track MaxDepth and MinDepth
+
+	// Moving-average weight of the adaptive threshold; 0 disables it
+	thresholdAlpha float64
+
+	// Full-queue behavior of Build
+	overflow   OverflowPolicy
+	overflowFn any // func(T) for the element type T passed to Build
+
+	// Bits Compact indirect queues reject; 0 means bit 63 only
+	upperMask uintptr
+
+	// Fault in slot arrays at build time
+	prealloc bool
+
+	// Clear Ptr slots on Dequeue
+	gcSafe bool
+
+	// Memory accounting
+	heapLabel string // Name suffix of the pprof profile listing the queue
+	memStats  bool   // Log the queue's bytes when it is collected
 
 	// Capacity (rounds up to next power of 2)
 	capacity int
@@ -39,13 +70,17 @@ type Builder struct {
 	opts Options
 }
 
+// MaxCapacity is the largest capacity New accepts: the largest power of 2
+// an int holds, so that rounding up cannot overflow.
+const MaxCapacity = 1 << (strconv.IntSize - 2)
+
 // New creates a queue builder with the given capacity.
 //
 // Capacity rounds up to the next power of 2.
 // For example, capacity=4 results in actual capacity=4, capacity=1000 results
 // in actual capacity=1024.
 //
-// Panics if capacity < 2.
+// Panics if capacity < 2 or capacity > MaxCapacity.
 //
 // Example:
 //
@@ -57,7 +92,10 @@ type Builder struct {
 //	q := lfq.BuildMPMC[int](lfq.New(1024))
 func New(capacity int) *Builder {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("New", "capacity", capacity, 2))
+	}
+	if capacity > MaxCapacity {
+		panic(aboveMaximum("New", "capacity", capacity, MaxCapacity))
 	}
 	return &Builder{opts: Options{capacity: capacity}}
 }
@@ -87,6 +125,271 @@ func (b *Builder) Compact() *Builder {
 	return b
 }
 
+// ExactCapacity keeps the requested capacity instead of rounding it up
+// to a power of 2, for queues built with Compact().
+//
+// Seq and CompactIndirect queues index slots by integer modulo when the
+// capacity is not a power of 2, at the cost of a division per operation.
+// FAA-based, SPSC, and Ptr queues still round up.
+//
+//	q := lfq.BuildMPMC[int](lfq.New(100).Compact().ExactCapacity()) // Cap() == 100
+func (b *Builder) ExactCapacity() *Builder {
+	b.opts.exact = true
+	return b
+}
+
+// Indirect makes Build store elements in an indirect (uintptr) queue.
+//
+// The element type passed to Build must have underlying type uintptr,
+// e.g. `type BufferIndex uintptr`. Build panics otherwise.
+//
+//	q := lfq.Build[BufferIndex](lfq.New(1024).Indirect())
+//
+// Use BuildTypedIndirect for compile-time checking of the element type.
+func (b *Builder) Indirect() *Builder {
+	b.opts.indirect = true
+	return b
+}
+
+// WithThroughputSampleRate attaches a [ThroughputTracker] that records
+// every k-th successful operation. Smaller k gives finer rates at the cost
+// of an extra FAA and clock read per sample.
+//
+// Applies to the FAA-based MPMC and MPSC queues, whose Throughput method
+// returns the tracker; other queues ignore it. Panics if k < 1.
+//
+//	q := lfq.BuildMPMC[int](lfq.New(1024).WithThroughputSampleRate(16))
+//	rate := lfq.Unrecorded(q).(*lfq.MPMC[int]).Throughput().EnqueueRate()
+func (b *Builder) WithThroughputSampleRate(k int) *Builder {
+	if k < 1 {
+		panic(belowMinimum("Builder.WithThroughputSampleRate", "k", k, 1))
+	}
+	b.opts.sampleRate = k
+	return b
+}
+
+// WithLatencyHistogram makes the queue record how long each element
+// waits between Enqueue and Dequeue in a histogram with the given bucket
+// upper bounds, read with LatencyPercentile. Each Enqueue stores a
+// timestamp for its slot and each Dequeue adds one to an atomic bucket
+// counter, costing two clock reads per element. Memory: one counter per
+// bucket plus one int64 timestamp per physical slot.
+//
+// Applies to the FAA-based MPMC and MPSC queues; other queues ignore it.
+// Panics unless buckets is non-empty, positive and strictly ascending.
+//
+//	q := lfq.BuildMPMC[int](lfq.New(1024).WithLatencyHistogram([]time.Duration{
+//		time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond, time.Millisecond,
+//	}))
+//	p99 := lfq.Unrecorded(q).(*lfq.MPMC[int]).LatencyPercentile(0.99)
+func (b *Builder) WithLatencyHistogram(buckets []time.Duration) *Builder {
+	b.opts.latBuckets = validLatencyBuckets(buckets)
+	return b
+}
+
+// WithDepthTracking makes the queue record the highest depth seen after
+// an Enqueue and the lowest seen after a Dequeue, read with MaxDepth and
+// MinDepth. Each successful operation then computes the depth and may
+// update a shared counter with a CAS.
+//
+// Applies to the FAA-based MPMC, MPSC and SPMC queues; other queues
+// ignore it.
+//
+//	q := lfq.BuildMPMC[int](lfq.New(1024).WithDepthTracking())
+//	peak := lfq.Unrecorded(q).(*lfq.MPMC[int]).MaxDepth()
+func (b *Builder) WithDepthTracking() *Builder {
+	b.opts.depthMarks = true
+	return b
+}
+
+// WithAdaptiveThreshold makes the livelock threshold follow consumer lag.
+// Every 100ms a background goroutine measures the share of Dequeue calls
+// that reported an empty queue and folds it into an exponential moving
+// average with weight alpha for the newest interval. The value each
+// Enqueue resets the threshold to then moves between the default 3n-1,
+// when consumers are never blocked, and four times that, when they always
+// are, so that consumers search longer before giving up on a queue that
+// often looks empty. It never drops below 3n-1, the bound under which
+// Dequeue cannot report empty while elements remain.
+//
+// Every Dequeue then adds to a shared counter. The goroutine stops once
+// the queue is garbage collected.
+//
+// Applies to the FAA-based MPMC and SPMC queues, whose ThresholdLimit
+// method reports the current value; other queues ignore it. Panics unless
+// 0 < alpha <= 1.
+//
+//	q := lfq.BuildMPMC[int](lfq.New(1024).WithAdaptiveThreshold(0.3))
+//	limit := lfq.Unrecorded(q).(*lfq.MPMC[int]).ThresholdLimit()
+func (b *Builder) WithAdaptiveThreshold(alpha float64) *Builder {
+	if !(alpha > 0 && alpha <= 1) {
+		panic(outsideUnitInterval("Builder.WithAdaptiveThreshold", "alpha", alpha))
+	}
+	b.opts.thresholdAlpha = alpha
+	return b
+}
+
+// WithOverflowPolicy sets what Enqueue does on a full queue created by
+// Build, BuildMPSC, BuildSPMC or BuildMPMC. callback must be a func(T) for
+// the element type T later passed to Build, or nil; Build panics if the
+// types differ.
+//
+// OverflowDropOldest makes each producer evict through Dequeue, so the
+// producer acts as an additional consumer. Build therefore selects the
+// multi-consumer variant: SPMC where SPSC would be chosen, MPMC where
+// MPSC would be.
+//
+// Panics if policy is OverflowCallback and callback is nil.
+//
+//	q := lfq.Build[Event](lfq.New(1024).SingleConsumer().
+//	    WithOverflowPolicy(lfq.OverflowDropOldest, func(ev Event) { dropped.Add(1) }))
+func (b *Builder) WithOverflowPolicy(policy OverflowPolicy, callback any) *Builder {
+	if policy == OverflowCallback && callback == nil {
+		panic(requires("Builder.WithOverflowPolicy", "a callback for OverflowCallback"))
+	}
+	b.opts.overflow = policy
+	b.opts.overflowFn = callback
+	return b
+}
+
+// WithUpperBitMask reserves the upper bits in mask in Compact indirect
+// queues, whose Enqueue then panics on values with any of them set.
+//
+// Compact indirect slots hold either a value or an empty marker with bit
+// 63 set, so bit 63 is always reserved; that is the default mask. Reserve
+// further bits to catch values that carry tags in their upper bits, and
+// use EnqueueMasked and DequeueMasked to pass such values through: bit 63
+// travels in the highest other reserved bit, which therefore must equal
+// it, while the remaining reserved bits are stored as they are.
+//
+// Panics if mask does not include bit 63.
+//
+//	q := lfq.New(1024).Compact().WithUpperBitMask(1<<63 | 1<<62).BuildIndirect()
+func (b *Builder) WithUpperBitMask(mask uintptr) *Builder {
+	if mask&emptyFlag == 0 {
+		panic("lfq: Builder.WithUpperBitMask mask must include bit 63; see " + docURL + "Builder.WithUpperBitMask")
+	}
+	b.opts.upperMask = mask
+	return b
+}
+
+// WithPrealloc makes the Build methods touch every page of the queue's
+// slot arrays before returning it.
+//
+// Large allocations are backed by fresh pages that the kernel commits on
+// first write, so the first pass of enqueues through a big queue takes a
+// page fault every few slots. Prefaulting moves that cost to build time,
+// for latency-sensitive queues sized in megabytes. The contents of the
+// queue are not changed.
+func (b *Builder) WithPrealloc() *Builder {
+	b.opts.prealloc = true
+	return b
+}
+
+// WithGCSafe makes BuildPtr and BuildPtrSPSC return queues that clear
+// each slot as its pointer is dequeued, at the cost of one extra store
+// per Dequeue, so the object becomes collectable as soon as the consumer
+// drops it rather than when the slot is next overwritten.
+//
+// Only SPSCPtr needs it. The other Ptr queues keep pointers in 128-bit
+// slot words that the collector does not trace, and Dequeue zeroes the
+// word as it claims the slot; they are unaffected. Indirect queues hold
+// uintptr values, which never keep objects alive.
+func (b *Builder) WithGCSafe() *Builder {
+	b.opts.gcSafe = true
+	return b
+}
+
+// WithHeapProfile lists every queue the Build methods create in a pprof
+// profile named "lfq." + label, with the stack that built it, until the
+// queue is garbage collected. Queues built with the same label share the
+// profile. See "Memory Profiling" in the package documentation.
+//
+// Panics if label is empty.
+//
+//	q := lfq.BuildMPSC[Event](lfq.New(1<<20).SingleConsumer().WithHeapProfile("ingest"))
+func (b *Builder) WithHeapProfile(label string) *Builder {
+	if label == "" {
+		panic(requires("Builder.WithHeapProfile", "a label"))
+	}
+	b.opts.heapLabel = label
+	return b
+}
+
+// WithMemStats makes each queue the Build methods create log, through
+// [slog.Default] once it has been garbage collected, the bytes allocated
+// for it and freed with it: the queue struct and its slot arrays.
+func (b *Builder) WithMemStats() *Builder {
+	b.opts.memStats = true
+	return b
+}
+
+// QueueConfig is the configuration a Builder will build, as reported by
+// Builder.Config.
+type QueueConfig struct {
+	Capacity       int    // Capacity the built queue will report from Cap
+	Algorithm      string // "Lamport", "FAA" or "Seq"
+	Compact        bool
+	SingleProducer bool
+	SingleConsumer bool // False under OverflowDropOldest, as for Build
+
+	// Flavor is "Indirect" if Indirect() was called, else "Generic".
+	// BuildIndirect and BuildPtr select their flavor themselves, so a
+	// builder never reports "Ptr".
+	Flavor string
+}
+
+// Config returns the configuration Build would use, without building.
+//
+//	cfg := lfq.New(1000).SingleConsumer().Compact().Config()
+//	// cfg.Capacity == 1024, cfg.Algorithm == "Seq"
+func (b *Builder) Config() QueueConfig {
+	c := QueueConfig{
+		Capacity:       roundToPow2(b.opts.capacity),
+		Algorithm:      "FAA",
+		Compact:        b.opts.compact,
+		SingleProducer: b.opts.singleProducer,
+		SingleConsumer: b.opts.singleConsumer,
+		Flavor:         "Generic",
+	}
+	if b.opts.indirect {
+		c.Flavor = "Indirect"
+	}
+	// Mirrors build: evicting producers are consumers too
+	if b.opts.overflow == OverflowDropOldest {
+		c.SingleConsumer = false
+	}
+	switch {
+	case c.SingleProducer && c.SingleConsumer:
+		c.Algorithm = "Lamport"
+	case b.opts.compact:
+		c.Algorithm = "Seq"
+		c.Capacity = int(b.compactSlots())
+	}
+	return c
+}
+
+// Describe returns a one-line summary of Config, for logging:
+//
+//	log.Printf("creating queue: %s", lfq.New(1024).Compact().Describe())
+//	// creating queue: MPMC Seq Generic cap=1024 compact
+func (b *Builder) Describe() string {
+	c := b.Config()
+	pattern := [2][2]string{{"MPMC", "MPSC"}, {"SPMC", "SPSC"}}
+	sp, sc := 0, 0
+	if c.SingleProducer {
+		sp = 1
+	}
+	if c.SingleConsumer {
+		sc = 1
+	}
+	s := pattern[sp][sc] + " " + c.Algorithm + " " + c.Flavor + " cap=" + strconv.Itoa(c.Capacity)
+	if c.Compact {
+		s += " compact"
+	}
+	return s
+}
+
 // Build creates a Queue[T] with automatic algorithm selection.
 //
 // Algorithm selection:
@@ -98,74 +401,146 @@ func (b *Builder) Compact() *Builder {
 //
 // Default: FAA-based algorithms with 2n physical slots (better scalability).
 // Compact(): CAS-based algorithms with n slots (half memory footprint).
+// Indirect(): same selection as BuildIndirect, for uintptr-based T.
 //
 // For type-safe returns with concrete types, use:
 //   - BuildSPSC[T](b) → *SPSC[T]
 //   - BuildMPSC[T](b) → *MPSC[T] (or *MPSCSeq[T] if Compact)
 //   - BuildSPMC[T](b) → *SPMC[T] (or *SPMCSeq[T] if Compact)
 //   - BuildMPMC[T](b) → *MPMC[T] (or *MPMCSeq[T] if Compact)
+//
+// Build panics on an invalid configuration. For builders assembled at run
+// time, e.g. from a configuration file, [TryBuild] and the other TryBuild
+// variants return an error wrapping [ErrInvalidConfig] instead.
+//
+// Built with the lfq_assert_linearizability tag, Build, BuildMPSC, BuildSPMC
+// and BuildMPMC wrap queues of comparable T in a [LinearizabilityRecorder];
+// use [Unrecorded] to reach the concrete queue. Queues from the New
+// constructors, such as NewMPMC, are not recorded.
 func Build[T any](b *Builder) Queue[T] {
+	return recordBuilt(withOverflow(b, build[T](b)))
+}
+
+func build[T any](b *Builder) Queue[T] {
+	if b.opts.indirect {
+		return buildIndirectAs[T](b)
+	}
+	// Evicting producers are consumers too
+	singleConsumer := b.opts.singleConsumer && b.opts.overflow != OverflowDropOldest
 	switch {
-	case b.opts.singleProducer && b.opts.singleConsumer:
-		return NewSPSC[T](b.opts.capacity)
+	case b.opts.singleProducer && singleConsumer:
+		return built(b, NewSPSC[T](b.opts.capacity))
 	case b.opts.singleProducer && b.opts.compact:
-		return NewSPMCSeq[T](b.opts.capacity)
+		return built(b, newSPMCSeq[T](b.compactSlots()))
 	case b.opts.singleProducer:
-		return NewSPMC[T](b.opts.capacity)
-	case b.opts.singleConsumer && b.opts.compact:
-		return NewMPSCSeq[T](b.opts.capacity)
-	case b.opts.singleConsumer:
-		return NewMPSC[T](b.opts.capacity)
+		return built(b, newSPMCWith[T](b))
+	case singleConsumer && b.opts.compact:
+		return built(b, newMPSCSeq[T](b.compactSlots()))
+	case singleConsumer:
+		return built(b, newMPSCWith[T](b))
 	case b.opts.compact:
-		return NewMPMCSeq[T](b.opts.capacity)
+		return built(b, newMPMCSeq[T](b.compactSlots()))
 	default:
-		return NewMPMC[T](b.opts.capacity)
+		return built(b, newMPMCWith[T](b))
 	}
 }
 
 // BuildSPSC creates an SPSC queue with compile-time type safety.
-// Panics if builder is not configured with SingleProducer().SingleConsumer().
+// Panics if builder is not configured with SingleProducer().SingleConsumer(),
+// or if an overflow policy is set; use Build for that.
 func BuildSPSC[T any](b *Builder) *SPSC[T] {
-	if !b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildSPSC requires SingleProducer().SingleConsumer()")
+	if err := b.requireTopology("BuildSPSC", true, true); err != nil {
+		panic(err.Error())
+	}
+	if b.opts.overflow != OverflowBlock {
+		panic(unsupported("BuildSPSC", "overflow policies"))
 	}
-	return NewSPSC[T](b.opts.capacity)
+	return built(b, NewSPSC[T](b.opts.capacity))
 }
 
 // BuildMPSC creates an MPSC queue with compile-time type safety.
 // Panics if builder is not configured with SingleConsumer() only.
 func BuildMPSC[T any](b *Builder) Queue[T] {
-	if b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildMPSC requires SingleConsumer() without SingleProducer()")
+	if err := b.requireTopology("BuildMPSC", false, true); err != nil {
+		panic(err.Error())
+	}
+	if b.opts.overflow == OverflowDropOldest {
+		return recordBuilt(withOverflow(b, build[T](b)))
 	}
 	if b.opts.compact {
-		return NewMPSCSeq[T](b.opts.capacity)
+		return recordBuilt(withOverflow[T](b, built(b, newMPSCSeq[T](b.compactSlots()))))
 	}
-	return NewMPSC[T](b.opts.capacity)
+	return recordBuilt(withOverflow[T](b, built(b, newMPSCWith[T](b))))
 }
 
 // BuildSPMC creates an SPMC queue with compile-time type safety.
 // Panics if builder is not configured with SingleProducer() only.
 func BuildSPMC[T any](b *Builder) Queue[T] {
-	if !b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildSPMC requires SingleProducer() without SingleConsumer()")
+	if err := b.requireTopology("BuildSPMC", true, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewSPMCSeq[T](b.opts.capacity)
+		return recordBuilt(withOverflow[T](b, built(b, newSPMCSeq[T](b.compactSlots()))))
 	}
-	return NewSPMC[T](b.opts.capacity)
+	return recordBuilt(withOverflow[T](b, built(b, newSPMCWith[T](b))))
 }
 
 // BuildMPMC creates an MPMC queue with compile-time type safety.
 // Panics if builder has any constraints set.
 func BuildMPMC[T any](b *Builder) Queue[T] {
-	if b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildMPMC requires no constraints")
+	if err := b.requireTopology("BuildMPMC", false, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewMPMCSeq[T](b.opts.capacity)
+		return recordBuilt(withOverflow[T](b, built(b, newMPMCSeq[T](b.compactSlots()))))
+	}
+	return recordBuilt(withOverflow[T](b, built(b, newMPMCWith[T](b))))
+}
+
+// newMPMCWith creates an FAA-based MPMC with the builder's instrumentation.
+func newMPMCWith[T any](b *Builder) *MPMC[T] {
+	q := NewMPMC[T](b.opts.capacity)
+	if b.opts.depthMarks {
+		q.obs.get().marks = newDepthMarks(q.capacity)
+	}
+	if b.opts.sampleRate > 0 {
+		q.obs.get().tput = newThroughputTracker(b.opts.sampleRate)
+	}
+	if b.opts.latBuckets != nil {
+		q.obs.get().lat = newLatencyHistogram(b.opts.latBuckets, q.size)
+	}
+	if b.opts.thresholdAlpha > 0 {
+		q.tune = startThresholdTuner(q, q.capacity, b.opts.thresholdAlpha)
+	}
+	return q
+}
+
+// newSPMCWith creates an FAA-based SPMC with the builder's adaptive
+// threshold.
+func newSPMCWith[T any](b *Builder) *SPMC[T] {
+	q := NewSPMC[T](b.opts.capacity)
+	if b.opts.depthMarks {
+		q.marks = newDepthMarks(q.capacity)
+	}
+	if b.opts.thresholdAlpha > 0 {
+		q.tune = startThresholdTuner(q, q.capacity, b.opts.thresholdAlpha)
+	}
+	return q
+}
+
+// newMPSCWith creates an FAA-based MPSC with the builder's instrumentation.
+func newMPSCWith[T any](b *Builder) *MPSC[T] {
+	q := NewMPSC[T](b.opts.capacity)
+	if b.opts.depthMarks {
+		q.obs.get().marks = newDepthMarks(q.capacity)
+	}
+	if b.opts.sampleRate > 0 {
+		q.obs.get().tput = newThroughputTracker(b.opts.sampleRate)
+	}
+	if b.opts.latBuckets != nil {
+		q.obs.get().lat = newLatencyHistogram(b.opts.latBuckets, q.size)
 	}
-	return NewMPMC[T](b.opts.capacity)
+	return q
 }
 
 // BuildIndirect creates a QueueIndirect for uintptr values.
@@ -177,64 +552,64 @@ func BuildMPMC[T any](b *Builder) Queue[T] {
 func (b *Builder) BuildIndirect() QueueIndirect {
 	switch {
 	case b.opts.singleProducer && b.opts.singleConsumer:
-		return NewSPSCIndirect(b.opts.capacity)
+		return built(b, NewSPSCIndirect(b.opts.capacity))
 	case b.opts.compact && b.opts.singleProducer:
-		return NewSPMCCompactIndirect(b.opts.capacity)
+		return built(b, newSPMCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	case b.opts.compact && b.opts.singleConsumer:
-		return NewMPSCCompactIndirect(b.opts.capacity)
+		return built(b, newMPSCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	case b.opts.compact:
-		return NewMPMCCompactIndirect(b.opts.capacity)
+		return built(b, newMPMCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	case b.opts.singleProducer:
-		return NewSPMCIndirect(b.opts.capacity)
+		return built(b, NewSPMCIndirect(b.opts.capacity))
 	case b.opts.singleConsumer:
-		return NewMPSCIndirect(b.opts.capacity)
+		return built(b, NewMPSCIndirect(b.opts.capacity))
 	default:
-		return NewMPMCIndirect(b.opts.capacity)
+		return built(b, NewMPMCIndirect(b.opts.capacity))
 	}
 }
 
 // BuildIndirectSPSC creates an SPSC queue for uintptr values.
 func (b *Builder) BuildIndirectSPSC() *SPSCIndirect {
-	if !b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildIndirectSPSC requires SingleProducer().SingleConsumer()")
+	if err := b.requireTopology("BuildIndirectSPSC", true, true); err != nil {
+		panic(err.Error())
 	}
-	return NewSPSCIndirect(b.opts.capacity)
+	return built(b, NewSPSCIndirect(b.opts.capacity))
 }
 
 // BuildIndirectMPSC creates an MPSC queue for uintptr values.
 // Panics if builder is not configured with SingleConsumer() only.
 func (b *Builder) BuildIndirectMPSC() QueueIndirect {
-	if b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildIndirectMPSC requires SingleConsumer() without SingleProducer()")
+	if err := b.requireTopology("BuildIndirectMPSC", false, true); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewMPSCCompactIndirect(b.opts.capacity)
+		return built(b, newMPSCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	}
-	return NewMPSCIndirect(b.opts.capacity)
+	return built(b, NewMPSCIndirect(b.opts.capacity))
 }
 
 // BuildIndirectSPMC creates an SPMC queue for uintptr values.
 // Panics if builder is not configured with SingleProducer() only.
 func (b *Builder) BuildIndirectSPMC() QueueIndirect {
-	if !b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildIndirectSPMC requires SingleProducer() without SingleConsumer()")
+	if err := b.requireTopology("BuildIndirectSPMC", true, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewSPMCCompactIndirect(b.opts.capacity)
+		return built(b, newSPMCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	}
-	return NewSPMCIndirect(b.opts.capacity)
+	return built(b, NewSPMCIndirect(b.opts.capacity))
 }
 
 // BuildIndirectMPMC creates an MPMC queue for uintptr values.
 // Panics if builder has any constraints set.
 func (b *Builder) BuildIndirectMPMC() QueueIndirect {
-	if b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildIndirectMPMC requires no constraints")
+	if err := b.requireTopology("BuildIndirectMPMC", false, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewMPMCCompactIndirect(b.opts.capacity)
+		return built(b, newMPMCCompactIndirect(b.compactSlots(), b.reservedBits()))
 	}
-	return NewMPMCIndirect(b.opts.capacity)
+	return built(b, NewMPMCIndirect(b.opts.capacity))
 }
 
 // BuildPtr creates a QueuePtr for unsafe.Pointer values.
@@ -248,65 +623,99 @@ func (b *Builder) BuildIndirectMPMC() QueueIndirect {
 func (b *Builder) BuildPtr() QueuePtr {
 	switch {
 	case b.opts.singleProducer && b.opts.singleConsumer:
-		return NewSPSCPtr(b.opts.capacity)
+		return built(b, b.newSPSCPtr())
 	case b.opts.singleProducer && b.opts.compact:
-		return NewSPMCPtrSeq(b.opts.capacity)
+		return built(b, NewSPMCPtrSeq(b.opts.capacity))
 	case b.opts.singleProducer:
-		return NewSPMCPtr(b.opts.capacity)
+		return built(b, NewSPMCPtr(b.opts.capacity))
 	case b.opts.singleConsumer && b.opts.compact:
-		return NewMPSCPtrSeq(b.opts.capacity)
+		return built(b, NewMPSCPtrSeq(b.opts.capacity))
 	case b.opts.singleConsumer:
-		return NewMPSCPtr(b.opts.capacity)
+		return built(b, NewMPSCPtr(b.opts.capacity))
 	case b.opts.compact:
-		return NewMPMCPtrSeq(b.opts.capacity)
+		return built(b, NewMPMCPtrSeq(b.opts.capacity))
 	default:
-		return NewMPMCPtr(b.opts.capacity)
+		return built(b, NewMPMCPtr(b.opts.capacity))
 	}
 }
 
 // BuildPtrSPSC creates an SPSC queue for unsafe.Pointer values.
 // Panics if builder is not configured with SingleProducer().SingleConsumer().
 func (b *Builder) BuildPtrSPSC() *SPSCPtr {
-	if !b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildPtrSPSC requires SingleProducer().SingleConsumer()")
+	if err := b.requireTopology("BuildPtrSPSC", true, true); err != nil {
+		panic(err.Error())
 	}
-	return NewSPSCPtr(b.opts.capacity)
+	return built(b, b.newSPSCPtr())
+}
+
+func (b *Builder) newSPSCPtr() *SPSCPtr {
+	q := NewSPSCPtr(b.opts.capacity)
+	q.gcSafe = b.opts.gcSafe
+	return q
 }
 
 // BuildPtrMPSC creates an MPSC queue for unsafe.Pointer values.
 // Panics if builder is not configured with SingleConsumer() only.
 func (b *Builder) BuildPtrMPSC() QueuePtr {
-	if b.opts.singleProducer || !b.opts.singleConsumer {
-		panic("lfq: BuildPtrMPSC requires SingleConsumer() without SingleProducer()")
+	if err := b.requireTopology("BuildPtrMPSC", false, true); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewMPSCPtrSeq(b.opts.capacity)
+		return built(b, NewMPSCPtrSeq(b.opts.capacity))
 	}
-	return NewMPSCPtr(b.opts.capacity)
+	return built(b, NewMPSCPtr(b.opts.capacity))
 }
 
 // BuildPtrSPMC creates an SPMC queue for unsafe.Pointer values.
 // Panics if builder is not configured with SingleProducer() only.
 func (b *Builder) BuildPtrSPMC() QueuePtr {
-	if !b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildPtrSPMC requires SingleProducer() without SingleConsumer()")
+	if err := b.requireTopology("BuildPtrSPMC", true, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewSPMCPtrSeq(b.opts.capacity)
+		return built(b, NewSPMCPtrSeq(b.opts.capacity))
 	}
-	return NewSPMCPtr(b.opts.capacity)
+	return built(b, NewSPMCPtr(b.opts.capacity))
 }
 
 // BuildPtrMPMC creates an MPMC queue for unsafe.Pointer values.
 // Panics if builder has any constraints set.
 func (b *Builder) BuildPtrMPMC() QueuePtr {
-	if b.opts.singleProducer || b.opts.singleConsumer {
-		panic("lfq: BuildPtrMPMC requires no constraints")
+	if err := b.requireTopology("BuildPtrMPMC", false, false); err != nil {
+		panic(err.Error())
 	}
 	if b.opts.compact {
-		return NewMPMCPtrSeq(b.opts.capacity)
+		return built(b, NewMPMCPtrSeq(b.opts.capacity))
+	}
+	return built(b, NewMPMCPtr(b.opts.capacity))
+}
+
+// built applies the build-time options of b to the new queue q and
+// returns it.
+func built[Q any](b *Builder, q Q) Q {
+	if b.opts.prealloc {
+		prefault(q)
+	}
+	if b.opts.heapLabel != "" || b.opts.memStats {
+		trackMemory(b, q)
+	}
+	return q
+}
+
+// reservedBits returns the mask of bits Compact indirect queues reject.
+func (b *Builder) reservedBits() uintptr {
+	if b.opts.upperMask == 0 {
+		return emptyFlag
+	}
+	return b.opts.upperMask
+}
+
+// compactSlots returns the slot count for Seq and CompactIndirect queues.
+func (b *Builder) compactSlots() uint64 {
+	if b.opts.exact {
+		return uint64(b.opts.capacity)
 	}
-	return NewMPMCPtr(b.opts.capacity)
+	return uint64(roundToPow2(b.opts.capacity))
 }
 
 // roundToPow2 rounds n up to the next power of 2.
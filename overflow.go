@@ -0,0 +1,105 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "strconv"
+
+// OverflowPolicy selects what Enqueue does when a built queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock returns ErrFull and leaves backoff to the caller.
+	// This is the default.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop discards the new element and returns nil.
+	OverflowDrop
+
+	// OverflowDropOldest dequeues the oldest element, passes it to the
+	// callback if one is set, and enqueues the new element in its place.
+	OverflowDropOldest
+
+	// OverflowCallback passes the new element to the callback and
+	// returns nil.
+	OverflowCallback
+)
+
+// String returns the policy name.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "OverflowBlock"
+	case OverflowDrop:
+		return "OverflowDrop"
+	case OverflowDropOldest:
+		return "OverflowDropOldest"
+	case OverflowCallback:
+		return "OverflowCallback"
+	}
+	return "OverflowPolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// overflowQueue applies an OverflowPolicy to a full queue's Enqueue.
+type overflowQueue[T any] struct {
+	q      Queue[T]
+	policy OverflowPolicy
+	fn     func(T) // May be nil except under OverflowCallback
+}
+
+// Enqueue adds an element, applying the overflow policy if the queue is
+// full. Returns ErrFull only under OverflowBlock.
+func (q *overflowQueue[T]) Enqueue(elem *T) error {
+	for {
+		err := q.q.Enqueue(elem)
+		if err == nil || q.policy == OverflowBlock {
+			return err
+		}
+		switch q.policy {
+		case OverflowDrop:
+			return nil
+		case OverflowCallback:
+			q.fn(*elem)
+			return nil
+		}
+		// OverflowDropOldest: evict one element and retry. Another
+		// producer may take the freed slot, in which case evict again.
+		if old, err := q.q.Dequeue(); err == nil && q.fn != nil {
+			q.fn(old)
+		}
+	}
+}
+
+// Dequeue removes and returns an element.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (q *overflowQueue[T]) Dequeue() (T, error) {
+	return q.q.Dequeue()
+}
+
+// Drain forwards to the underlying queue if it implements Drainer.
+func (q *overflowQueue[T]) Drain() {
+	if d, ok := q.q.(Drainer); ok {
+		d.Drain()
+	}
+}
+
+// Cap returns the queue capacity.
+func (q *overflowQueue[T]) Cap() int {
+	return q.q.Cap()
+}
+
+// withOverflow wraps q in the builder's overflow policy, if any.
+func withOverflow[T any](b *Builder, q Queue[T]) Queue[T] {
+	if b.opts.overflow == OverflowBlock {
+		return q
+	}
+	var fn func(T)
+	if b.opts.overflowFn != nil {
+		var ok bool
+		if fn, ok = b.opts.overflowFn.(func(T)); !ok {
+			panic("lfq: overflow callback type does not match the element type; see " + docURL + "Builder.WithOverflowPolicy")
+		}
+	}
+	return &overflowQueue[T]{q: q, policy: b.opts.overflow, fn: fn}
+}
@@ -0,0 +1,128 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// overflowBuilders returns SPSC and MPSC builders for the overflow tests.
+func overflowBuilders() map[string]func() *lfq.Builder {
+	return map[string]func() *lfq.Builder{
+		"SPSC": func() *lfq.Builder { return lfq.New(4).SingleProducer().SingleConsumer() },
+		"MPSC": func() *lfq.Builder { return lfq.New(4).SingleConsumer() },
+	}
+}
+
+// fillThenEnqueue fills q and enqueues 4 more elements, all of which must
+// be accepted. It returns the dequeued contents.
+func fillThenEnqueue(t *testing.T, q lfq.Queue[int]) []int {
+	t.Helper()
+	for i := range q.Cap() + 4 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	var got []int
+	for {
+		v, err := q.Dequeue()
+		if err != nil {
+			return got
+		}
+		got = append(got, v)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	for name, b := range overflowBuilders() {
+		t.Run(name, func(t *testing.T) {
+			q := lfq.Build[int](b().WithOverflowPolicy(lfq.OverflowBlock, nil))
+			for i := range q.Cap() {
+				q.Enqueue(&i)
+			}
+			v := 99
+			if err := q.Enqueue(&v); !lfq.IsFull(err) {
+				t.Fatalf("Enqueue on full queue: got %v, want ErrFull", err)
+			}
+		})
+	}
+}
+
+func TestOverflowDrop(t *testing.T) {
+	for name, b := range overflowBuilders() {
+		t.Run(name, func(t *testing.T) {
+			q := lfq.Build[int](b().WithOverflowPolicy(lfq.OverflowDrop, nil))
+			got := fillThenEnqueue(t, q)
+			if len(got) != q.Cap() || got[0] != 0 || got[len(got)-1] != q.Cap()-1 {
+				t.Fatalf("contents: got %v, want the first %d elements", got, q.Cap())
+			}
+		})
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	for name, b := range overflowBuilders() {
+		t.Run(name, func(t *testing.T) {
+			var evicted []int
+			q := lfq.Build[int](b().WithOverflowPolicy(lfq.OverflowDropOldest, func(v int) {
+				evicted = append(evicted, v)
+			}))
+			got := fillThenEnqueue(t, q)
+			if want := []int{0, 1, 2, 3}; !slices.Equal(evicted, want) {
+				t.Fatalf("evicted: got %v, want %v", evicted, want)
+			}
+			if want := []int{4, 5, 6, 7}; !slices.Equal(got, want) {
+				t.Fatalf("contents: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOverflowCallback(t *testing.T) {
+	for name, b := range overflowBuilders() {
+		t.Run(name, func(t *testing.T) {
+			var rejected []int
+			q := lfq.Build[int](b().WithOverflowPolicy(lfq.OverflowCallback, func(v int) {
+				rejected = append(rejected, v)
+			}))
+			got := fillThenEnqueue(t, q)
+			if want := []int{4, 5, 6, 7}; !slices.Equal(rejected, want) {
+				t.Fatalf("rejected: got %v, want %v", rejected, want)
+			}
+			if want := []int{0, 1, 2, 3}; !slices.Equal(got, want) {
+				t.Fatalf("contents: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOverflowPolicyPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func()
+	}{
+		{"CallbackWithoutFunc", func() { lfq.New(4).WithOverflowPolicy(lfq.OverflowCallback, nil) }},
+		{"FuncTypeMismatch", func() {
+			lfq.Build[int](lfq.New(4).WithOverflowPolicy(lfq.OverflowDrop, func(string) {}))
+		}},
+		{"BuildSPSC", func() {
+			lfq.BuildSPSC[int](lfq.New(4).SingleProducer().SingleConsumer().WithOverflowPolicy(lfq.OverflowDrop, nil))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			tt.build()
+		})
+	}
+}
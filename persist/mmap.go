@@ -0,0 +1,264 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package persist provides lfq queues whose contents live in a file, so
+// that they survive a restart of the process.
+//
+// [MmapSPSC] maps its slot array and its head and tail counters from a
+// file with mmap. Every Enqueue and Dequeue writes straight to the shared
+// page cache, so the queue survives the process crashing or exiting
+// without any call: reopening the file resumes where the process stopped.
+//
+// Surviving an operating system crash or power loss requires Flush, which
+// writes the mapping to disk with msync. The kernel also writes pages back
+// on its own schedule and in any order, so each slot carries the sequence
+// number of the item it holds, and reopening trusts only the slots whose
+// sequence numbers form an unbroken run. After such a crash the queue
+// holds at least every item that was enqueued and not dequeued before the
+// last Flush; items dequeued since may be delivered again.
+//
+// The file stores elements in the machine's byte order and layout, and is
+// not portable between architectures. Mapping files is supported on Linux;
+// elsewhere NewMmapSPSC returns [errors.ErrUnsupported].
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"reflect"
+	"unsafe"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+var (
+	// ErrPointers is returned for an element type holding pointers, which
+	// would be meaningless when read back by another process.
+	ErrPointers = errors.New("persist: element type contains pointers")
+
+	// ErrLayout is returned when an existing file was written for a
+	// different element type, capacity or architecture.
+	ErrLayout = errors.New("persist: file does not match the queue layout")
+)
+
+// headerSize is the file offset of the slot array, one page.
+const headerSize = 4096
+
+// fileMagic identifies a queue file; byteOrder reads back differently on
+// a machine of the other endianness.
+const (
+	fileMagic = "lfqmmap1"
+	byteOrder = 0x0102030405060708
+)
+
+// header is the start of the file. head and tail sit on their own cache
+// lines, as in lfq.SPSC.
+type header struct {
+	magic    [8]byte
+	order    uint64
+	slotSize uint64
+	capacity uint64
+	_        [32]byte
+	head     atomix.Uint64 // Consumer reads from here
+	_        [56]byte
+	tail     atomix.Uint64 // Producer writes here
+}
+
+// slot is one element of the ring. seq is the position of the item it
+// holds plus one, so that the zeroed slots of a new file match nothing.
+type slot[T any] struct {
+	seq atomix.Uint64
+	val T
+}
+
+// MmapSPSC is a single-producer single-consumer bounded queue stored in a
+// memory-mapped file.
+//
+// It follows lfq.SPSC: the producer and consumer each cache the other's
+// index. T must not contain pointers, strings, slices, maps, channels,
+// functions or interfaces.
+type MmapSPSC[T any] struct {
+	f    *os.File
+	data []byte
+	hdr  *header
+	buf  []slot[T]
+	mask uint64
+
+	_          [64]byte
+	cachedTail uint64 // Consumer's cached view of tail
+	_          [64]byte
+	cachedHead uint64 // Producer's cached view of head
+	_          [64]byte
+}
+
+var _ lfq.Queue[int] = (*MmapSPSC[int])(nil)
+
+// NewMmapSPSC opens the queue stored at path, creating the file if it does
+// not exist. Capacity rounds up to the next power of 2.
+//
+// An existing file must have been created with the same element type and
+// rounded capacity; otherwise NewMmapSPSC returns ErrLayout. Its queue
+// resumes with the items recovered from it.
+func NewMmapSPSC[T any](path string, capacity int) (*MmapSPSC[T], error) {
+	if capacity < 2 {
+		return nil, fmt.Errorf("persist: capacity %d below minimum 2", capacity)
+	}
+	if t := reflect.TypeFor[T](); hasPointers(t) {
+		return nil, fmt.Errorf("%w: %v", ErrPointers, t)
+	}
+	n := uint64(1) << bits.Len(uint(capacity-1))
+	slotSize := uint64(unsafe.Sizeof(slot[T]{}))
+	size := headerSize + int64(n*slotSize)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	created := fi.Size() == 0
+	if created {
+		err = f.Truncate(size)
+	} else if fi.Size() != size {
+		err = fmt.Errorf("%w: %s is %d bytes, want %d", ErrLayout, path, fi.Size(), size)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	data, err := mmap(f, int(size))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	q := &MmapSPSC[T]{
+		f:    f,
+		data: data,
+		hdr:  (*header)(unsafe.Pointer(&data[0])),
+		buf:  unsafe.Slice((*slot[T])(unsafe.Pointer(&data[headerSize])), n),
+		mask: n - 1,
+	}
+
+	if created {
+		copy(q.hdr.magic[:], fileMagic)
+		q.hdr.order = byteOrder
+		q.hdr.slotSize = slotSize
+		q.hdr.capacity = n
+	} else if string(q.hdr.magic[:]) != fileMagic || q.hdr.order != byteOrder ||
+		q.hdr.slotSize != slotSize || q.hdr.capacity != n {
+		err := fmt.Errorf("%w: %s holds %d slots of %d bytes, want %d of %d",
+			ErrLayout, path, q.hdr.capacity, q.hdr.slotSize, n, slotSize)
+		q.Close()
+		return nil, err
+	}
+	q.recover()
+	return q, nil
+}
+
+// recover sets head and tail to the longest unbroken run of slots, ending
+// at the newest item, that does not reach below the stored head.
+//
+// After a process crash this reproduces the stored counters, except that
+// an Enqueue interrupted after writing its slot is kept. After a system
+// crash the stored tail may be ahead of slots that never reached the disk,
+// or the stored head behind slots already reused; the slot sequence
+// numbers settle both.
+func (q *MmapSPSC[T]) recover() {
+	head := q.hdr.head.Load()
+	tail := head
+	for i := range q.buf {
+		if p := q.buf[i].seq.Load(); p > tail && p-1 >= head && (p-1)&q.mask == uint64(i) {
+			tail = p
+		}
+	}
+	h := tail
+	for h > head && tail-h < uint64(len(q.buf)) && q.buf[(h-1)&q.mask].seq.Load() == h {
+		h--
+	}
+	q.hdr.head.Store(h)
+	q.hdr.tail.Store(tail)
+	q.cachedHead, q.cachedTail = h, tail
+}
+
+// hasPointers reports whether values of t hold anything but plain data.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Slice,
+		reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// Enqueue adds an element to the queue (producer only).
+// Returns lfq.ErrFull if the queue is full.
+func (q *MmapSPSC[T]) Enqueue(elem *T) error {
+	tail := q.hdr.tail.LoadRelaxed()
+	if tail-q.cachedHead > q.mask {
+		q.cachedHead = q.hdr.head.LoadAcquire()
+		if tail-q.cachedHead > q.mask {
+			return lfq.ErrFull
+		}
+	}
+
+	s := &q.buf[tail&q.mask]
+	s.val = *elem
+	s.seq.StoreRelease(tail + 1)
+	q.hdr.tail.StoreRelease(tail + 1)
+	return nil
+}
+
+// Dequeue removes and returns an element (consumer only).
+// Returns (zero-value, lfq.ErrEmpty) if the queue is empty.
+func (q *MmapSPSC[T]) Dequeue() (T, error) {
+	head := q.hdr.head.LoadRelaxed()
+	if head >= q.cachedTail {
+		q.cachedTail = q.hdr.tail.LoadAcquire()
+		if head >= q.cachedTail {
+			var zero T
+			return zero, lfq.ErrEmpty
+		}
+	}
+
+	elem := q.buf[head&q.mask].val
+	q.hdr.head.StoreRelease(head + 1)
+	return elem, nil
+}
+
+// Cap returns the queue capacity.
+func (q *MmapSPSC[T]) Cap() int {
+	return len(q.buf)
+}
+
+// Flush writes the queue to disk and returns once the writes complete, so
+// that its current contents survive a system crash. It may be called from
+// either side while the other keeps running.
+func (q *MmapSPSC[T]) Flush() error {
+	return msync(q.data)
+}
+
+// Close unmaps the queue and closes its file. It does not flush: the
+// contents stay in the page cache, safe from process crashes but not
+// system crashes. The queue must not be used afterwards.
+func (q *MmapSPSC[T]) Close() error {
+	err := munmap(q.data)
+	q.data, q.hdr, q.buf = nil, nil, nil
+	return errors.Join(err, q.f.Close())
+}
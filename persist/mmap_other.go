@@ -0,0 +1,18 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package persist
+
+import (
+	"errors"
+	"os"
+)
+
+func mmap(*os.File, int) ([]byte, error) { return nil, errors.ErrUnsupported }
+
+func munmap([]byte) error { return errors.ErrUnsupported }
+
+func msync([]byte) error { return errors.ErrUnsupported }
@@ -0,0 +1,240 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+
+package persist_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/persist"
+	"code.hybscloud.com/lfq/testutil"
+)
+
+type record struct {
+	ID    uint32
+	Value [3]float64
+}
+
+func TestMmapSPSCCompliance(t *testing.T) {
+	q, err := persist.NewMmapSPSC[record](filepath.Join(t.TempDir(), "q"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	testutil.VerifyQueueCompliance(t, q, func(i int) record {
+		return record{ID: uint32(i), Value: [3]float64{float64(i), -1, 0.5}}
+	})
+	if err := q.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+}
+
+func TestMmapSPSCReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q")
+	q, err := persist.NewMmapSPSC[int64](path, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Cap() != 8 {
+		t.Fatalf("Cap: got %d, want 8", q.Cap())
+	}
+	// Wrap the ring once so the reopened queue spans the boundary
+	for i := range int64(13) {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+		if i < 10 {
+			q.Dequeue()
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	q, err = persist.NewMmapSPSC[int64](path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	for want := int64(10); want < 13; want++ {
+		if got, err := q.Dequeue(); err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue on drained queue: got %v, want ErrEmpty", err)
+	}
+}
+
+// File offsets of the stored head and tail counters.
+const (
+	headOffset = 64
+	tailOffset = 128
+)
+
+// TestMmapSPSCRecoverStale reopens files whose stored counters lag behind
+// the slots, as after a system crash that wrote back only some pages.
+func TestMmapSPSCRecoverStale(t *testing.T) {
+	tests := []struct {
+		name       string
+		offset     int64
+		first, end int64 // Recovered items
+	}{
+		// Slots 10 to 12 are intact; the tail is found from them
+		{"tail", tailOffset, 10, 13},
+		// Slots 0 to 4 now hold items 8 to 12; items 5 to 9 are delivered again
+		{"head", headOffset, 5, 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "q")
+			q, err := persist.NewMmapSPSC[int64](path, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range int64(13) {
+				q.Enqueue(&i)
+				if i < 10 {
+					q.Dequeue()
+				}
+			}
+			q.Close()
+
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteAt(binary.NativeEndian.AppendUint64(nil, 0), tt.offset)
+			f.Close()
+
+			q, err = persist.NewMmapSPSC[int64](path, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer q.Close()
+			for want := tt.first; want < tt.end; want++ {
+				if got, err := q.Dequeue(); err != nil || got != want {
+					t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+				}
+			}
+			if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+				t.Fatalf("Dequeue after item %d: got %v, want ErrEmpty", tt.end-1, err)
+			}
+		})
+	}
+}
+
+func TestMmapSPSCRejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q")
+	q, err := persist.NewMmapSPSC[int64](path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	if _, err := persist.NewMmapSPSC[int64](path, 16); !errors.Is(err, persist.ErrLayout) {
+		t.Errorf("other capacity: got %v, want ErrLayout", err)
+	}
+	if _, err := persist.NewMmapSPSC[[2]int64](path, 8); !errors.Is(err, persist.ErrLayout) {
+		t.Errorf("other element size: got %v, want ErrLayout", err)
+	}
+	os.WriteFile(path, make([]byte, 4096+8*16), 0o600)
+	if _, err := persist.NewMmapSPSC[int64](path, 8); !errors.Is(err, persist.ErrLayout) {
+		t.Errorf("foreign file: got %v, want ErrLayout", err)
+	}
+
+	dir := t.TempDir()
+	if _, err := persist.NewMmapSPSC[string](filepath.Join(dir, "s"), 8); !errors.Is(err, persist.ErrPointers) {
+		t.Errorf("string: got %v, want ErrPointers", err)
+	}
+	if _, err := persist.NewMmapSPSC[struct{ P *int }](filepath.Join(dir, "p"), 8); !errors.Is(err, persist.ErrPointers) {
+		t.Errorf("struct with pointer: got %v, want ErrPointers", err)
+	}
+}
+
+// TestMmapSPSCExitWithoutFlush enqueues 100 items in a child process that
+// exits without Flush or Close, then recovers them from the page cache.
+func TestMmapSPSCExitWithoutFlush(t *testing.T) {
+	const items = 100
+	if path := os.Getenv("PERSIST_TEST_PATH"); path != "" {
+		q, err := persist.NewMmapSPSC[record](path, 128)
+		if err != nil {
+			os.Exit(2)
+		}
+		for i := range items {
+			if q.Enqueue(&record{ID: uint32(i)}) != nil {
+				os.Exit(2)
+			}
+		}
+		os.Exit(0)
+	}
+
+	path := filepath.Join(t.TempDir(), "q")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMmapSPSCExitWithoutFlush$")
+	cmd.Env = append(os.Environ(), "PERSIST_TEST_PATH="+path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("child: %v\n%s", err, out)
+	}
+
+	q, err := persist.NewMmapSPSC[record](path, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	for i := range items {
+		r, err := q.Dequeue()
+		if err != nil || r.ID != uint32(i) {
+			t.Fatalf("Dequeue(%d): got (%v, %v)", i, r, err)
+		}
+	}
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue after %d items: got %v, want ErrEmpty", items, err)
+	}
+}
+
+func TestMmapSPSCConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const items = 20000
+	q, err := persist.NewMmapSPSC[uint64](filepath.Join(t.TempDir(), "q"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := range uint64(items) {
+			for q.Enqueue(&i) != nil {
+				runtime.Gosched()
+			}
+			if i%5000 == 0 {
+				q.Flush()
+			}
+		}
+	})
+	for want := uint64(0); want < items; {
+		got, err := q.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if got != want {
+			t.Fatalf("Dequeue: got %d, want %d", got, want)
+		}
+		want++
+	}
+	wg.Wait()
+}
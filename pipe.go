@@ -0,0 +1,164 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"io"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/iox"
+)
+
+// spscPipe is the shared state between an SPSCWriter and an SPSCReader.
+//
+// Chunks live in a pre-allocated pool. Two SPSCIndirect queues carry
+// chunk indices: full moves filled chunks writer → reader, free returns
+// consumed chunks reader → writer. The release on full's tail publishes
+// both the chunk bytes and its length to the reader.
+type spscPipe struct {
+	full        *SPSCIndirect
+	free        *SPSCIndirect
+	pool        []byte
+	lens        []int
+	chunkSize   int
+	writeClosed atomix.Bool
+	readClosed  atomix.Bool
+}
+
+// SPSCWriter is the write half of an SPSC pipe.
+// It implements io.WriteCloser and must be used by a single goroutine.
+type SPSCWriter struct {
+	p *spscPipe
+}
+
+// SPSCReader is the read half of an SPSC pipe.
+// It implements io.ReadCloser and must be used by a single goroutine.
+type SPSCReader struct {
+	p   *spscPipe
+	cur int // Index of the chunk being read, -1 if none
+	off int // Read offset within the current chunk
+}
+
+// NewSPSCPipe creates a byte stream pipe backed by SPSC queues.
+//
+// Data is staged in capacity chunks of chunkSize bytes each. Capacity
+// rounds up to the next power of 2. Write blocks while all chunks are
+// in flight; Read blocks while none are available.
+//
+// Closing the writer causes Read to return io.EOF once buffered data is
+// consumed. Closing the reader causes Write to return io.ErrClosedPipe.
+func NewSPSCPipe(chunkSize, capacity int) (*SPSCWriter, *SPSCReader) {
+	if chunkSize < 1 {
+		panic(belowMinimum("NewSPSCPipe", "chunkSize", chunkSize, 1))
+	}
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCPipe", "capacity", capacity, 2))
+	}
+
+	n := roundToPow2(capacity)
+	p := &spscPipe{
+		full:      NewSPSCIndirect(n),
+		free:      NewSPSCIndirect(n),
+		pool:      make([]byte, n*chunkSize),
+		lens:      make([]int, n),
+		chunkSize: chunkSize,
+	}
+	for i := range n {
+		p.free.Enqueue(uintptr(i))
+	}
+	return &SPSCWriter{p: p}, &SPSCReader{p: p, cur: -1}
+}
+
+// chunk returns the pool memory backing chunk i.
+func (p *spscPipe) chunk(i int) []byte {
+	off := i * p.chunkSize
+	return p.pool[off : off+p.chunkSize : off+p.chunkSize]
+}
+
+// Write copies b into the pipe in chunkSize pieces.
+// Blocks until every byte is staged or the reader is closed.
+func (w *SPSCWriter) Write(b []byte) (int, error) {
+	p := w.p
+	if p.writeClosed.LoadRelaxed() {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := 0
+	ba := iox.Backoff{}
+	for n < len(b) {
+		if p.readClosed.LoadAcquire() {
+			return n, io.ErrClosedPipe
+		}
+		idx, err := p.free.Dequeue()
+		if err != nil {
+			ba.Wait()
+			continue
+		}
+		ba.Reset()
+
+		c := copy(p.chunk(int(idx)), b[n:])
+		p.lens[idx] = c
+		// Cannot fail: at most capacity chunks are ever in flight
+		p.full.Enqueue(idx)
+		n += c
+	}
+	return n, nil
+}
+
+// Close signals end of stream to the reader.
+// Data already written remains readable.
+func (w *SPSCWriter) Close() error {
+	w.p.writeClosed.StoreRelease(true)
+	return nil
+}
+
+// Read copies the next staged bytes into b.
+// Blocks until data is available; returns io.EOF after the writer is
+// closed and all staged data has been read.
+func (r *SPSCReader) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	p := r.p
+	if p.readClosed.LoadRelaxed() {
+		return 0, io.ErrClosedPipe
+	}
+
+	if r.cur < 0 {
+		ba := iox.Backoff{}
+		for {
+			idx, err := p.full.Dequeue()
+			if err == nil {
+				r.cur, r.off = int(idx), 0
+				break
+			}
+			if p.writeClosed.LoadAcquire() {
+				// Re-check: the writer may have staged data before closing
+				if idx, err = p.full.Dequeue(); err == nil {
+					r.cur, r.off = int(idx), 0
+					break
+				}
+				return 0, io.EOF
+			}
+			ba.Wait()
+		}
+	}
+
+	end := p.lens[r.cur]
+	n := copy(b, p.chunk(r.cur)[r.off:end])
+	r.off += n
+	if r.off == end {
+		// Cannot fail: free has room for every chunk
+		p.free.Enqueue(uintptr(r.cur))
+		r.cur = -1
+	}
+	return n, nil
+}
+
+// Close releases the reader. Subsequent writes return io.ErrClosedPipe.
+func (r *SPSCReader) Close() error {
+	r.p.readClosed.StoreRelease(true)
+	return nil
+}
@@ -0,0 +1,92 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCPipeRoundTrip streams 1MB through the pipe and verifies
+// byte-for-byte equality on the read side.
+func TestSPSCPipeRoundTrip(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	src := make([]byte, 1<<20)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range src {
+		src[i] = byte(rng.Uint32())
+	}
+
+	w, r := lfq.NewSPSCPipe(4096, 16)
+	done := make(chan error, 1)
+	go func() {
+		// Uneven write sizes exercise chunk splitting
+		for off := 0; off < len(src); {
+			n := min(len(src)-off, 1000+off%7919)
+			if _, err := w.Write(src[off : off+n]); err != nil {
+				done <- err
+				return
+			}
+			off += n
+		}
+		done <- w.Close()
+	}()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !bytes.Equal(got, src) {
+		t.Fatalf("round-trip mismatch: got %d bytes, want %d", len(got), len(src))
+	}
+}
+
+// TestSPSCPipeSequential tests partial reads, EOF and close semantics
+// from a single goroutine.
+func TestSPSCPipeSequential(t *testing.T) {
+	w, r := lfq.NewSPSCPipe(4, 2)
+
+	if n, err := w.Write([]byte("abcdefg")); err != nil || n != 7 {
+		t.Fatalf("Write: got (%d, %v), want (7, nil)", n, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	buf := make([]byte, 3)
+	var got []byte
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+	}
+	if string(got) != "abcdefg" {
+		t.Fatalf("Read: got %q, want %q", got, "abcdefg")
+	}
+
+	w, r = lfq.NewSPSCPipe(4, 2)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Write after reader Close: got %v, want io.ErrClosedPipe", err)
+	}
+}
@@ -0,0 +1,54 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"runtime"
+	"sync"
+	"time"
+	"weak"
+)
+
+// poller is a background goroutine that calls a poll function for a
+// queue until it is stopped or the queue is collected.
+//
+// The goroutine holds the queue only through a weak pointer, so it does
+// not keep the queue reachable; a cleanup on the queue stops it once the
+// queue is collected, as with thresholdTuner.
+type poller struct {
+	once sync.Once
+	done chan struct{}
+}
+
+// startPoller starts a goroutine that calls poll(q) after interval and
+// then after each interval poll returns.
+func startPoller[Q any](q *Q, interval time.Duration, poll func(*Q) time.Duration) *poller {
+	p := &poller{done: make(chan struct{})}
+	runtime.AddCleanup(q, (*poller).stop, p)
+	go runPoller(p.done, weak.Make(q), interval, poll)
+	return p
+}
+
+// stop stops the goroutine. It is safe to call more than once.
+func (p *poller) stop() {
+	p.once.Do(func() { close(p.done) })
+}
+
+func runPoller[Q any](done <-chan struct{}, wq weak.Pointer[Q], interval time.Duration, poll func(*Q) time.Duration) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+		q := wq.Value()
+		if q == nil {
+			return
+		}
+		t.Reset(poll(q))
+	}
+}
@@ -0,0 +1,69 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// pageSize is the granularity at which prefault touches memory.
+var pageSize = uintptr(os.Getpagesize())
+
+// prefault touches every page of the slices held by the queue q, and by
+// queues it delegates to, so that the kernel commits them now rather
+// than on first use.
+func prefault(q any) {
+	forEachSlice(reflect.ValueOf(q), 2, faultPages)
+	runtime.KeepAlive(q)
+}
+
+// forEachSlice walks the struct behind v, following pointers to structs
+// up to depth levels, and calls fn with the backing array of each
+// non-empty slice and its size in bytes.
+func forEachSlice(v reflect.Value, depth int, fn func(p unsafe.Pointer, n uintptr)) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := range v.NumField() {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Slice:
+			if f.Len() > 0 {
+				fn(f.UnsafePointer(), uintptr(f.Len())*f.Type().Elem().Size())
+			}
+		case reflect.Pointer:
+			if depth > 0 && f.Type().Elem().Kind() == reflect.Struct {
+				forEachSlice(f, depth-1, fn)
+			}
+		}
+	}
+}
+
+// faultPages writes to one aligned word in each page of the n bytes at
+// p. The atomic add of zero leaves the contents unchanged but is a real
+// store, which the compiler cannot drop and which commits the page for
+// writing rather than mapping the shared zero page. The memory must not
+// yet be shared with other goroutines.
+func faultPages(p unsafe.Pointer, n uintptr) {
+	base := uintptr(p)
+	first := (base + 3) &^ 3
+	if n < 4 || base+n-4 < first {
+		return
+	}
+	last := (base + n - 4) &^ 3
+	for addr := first; addr <= last; addr = (addr + pageSize) &^ (pageSize - 1) {
+		atomic.AddUint32((*uint32)(unsafe.Add(p, addr-base)), 0)
+	}
+}
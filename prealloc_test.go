@@ -0,0 +1,111 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"runtime/debug"
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/testutil"
+)
+
+// TestWithPrealloc verifies that prefaulted queues of every flavor work
+// and start empty.
+func TestWithPrealloc(t *testing.T) {
+	item := func(i int) int { return i }
+	builders := map[string]func() *lfq.Builder{
+		"SPSC":    func() *lfq.Builder { return lfq.New(64).SingleProducer().SingleConsumer() },
+		"MPSC":    func() *lfq.Builder { return lfq.New(64).SingleConsumer() },
+		"SPMC":    func() *lfq.Builder { return lfq.New(64).SingleProducer() },
+		"MPMC":    func() *lfq.Builder { return lfq.New(64) },
+		"Compact": func() *lfq.Builder { return lfq.New(64).Compact() },
+	}
+	for name, nb := range builders {
+		t.Run(name, func(t *testing.T) {
+			testutil.VerifyQueueCompliance(t, lfq.Build[int](nb().WithPrealloc()), item)
+
+			qi := nb().WithPrealloc().BuildIndirect()
+			for i := range qi.Cap() {
+				if err := qi.Enqueue(uintptr(i)); err != nil {
+					t.Fatalf("Indirect Enqueue(%d): %v", i, err)
+				}
+			}
+			for i := range qi.Cap() {
+				if v, err := qi.Dequeue(); err != nil || v != uintptr(i) {
+					t.Fatalf("Indirect Dequeue: got (%d, %v), want (%d, nil)", v, err, i)
+				}
+			}
+
+			x := 7
+			qp := nb().WithPrealloc().BuildPtr()
+			if _, err := qp.Dequeue(); !lfq.IsEmpty(err) {
+				t.Fatalf("Ptr Dequeue on new queue: got %v, want ErrEmpty", err)
+			}
+			qp.Enqueue(unsafe.Pointer(&x))
+			if p, err := qp.Dequeue(); err != nil || p != unsafe.Pointer(&x) {
+				t.Fatalf("Ptr Dequeue: got (%p, %v), want (%p, nil)", p, err, &x)
+			}
+		})
+	}
+}
+
+// firstWriteSlots sizes the benchmark queue at 64MB of uintptr slots.
+const firstWriteSlots = 64 << 20 / 8
+
+// benchmarkFirstWrite fills a freshly built 64MB SPSCIndirect once and
+// reports the latency of each page-sized batch of enqueues, which is
+// where page faults show up. Building the queue is not timed.
+//
+// Expect the baseline's percentiles to be markedly higher; by how much
+// depends on the page size and on whether transparent huge pages are in
+// use.
+func benchmarkFirstWrite(b *testing.B, prealloc bool) {
+	const batch = 4096 / 8 // Slots per 4KB page
+	lat := make([]time.Duration, 0, firstWriteSlots/batch)
+	b.ReportAllocs()
+	for range b.N {
+		b.StopTimer()
+		// Return the previous iteration's pages to the OS, so that this
+		// queue is backed by uncommitted memory as on first allocation
+		debug.FreeOSMemory()
+		nb := lfq.New(firstWriteSlots).SingleProducer().SingleConsumer()
+		if prealloc {
+			nb.WithPrealloc()
+		}
+		q := nb.BuildIndirectSPSC()
+		b.StartTimer()
+
+		for i := 0; i < firstWriteSlots; i += batch {
+			start := time.Now()
+			for j := range batch {
+				q.Enqueue(uintptr(i + j))
+			}
+			lat = append(lat, time.Since(start))
+		}
+	}
+	slices.Sort(lat)
+	pct := func(p float64) float64 { return float64(lat[int(p*float64(len(lat)-1))].Nanoseconds()) }
+	b.ReportMetric(pct(0.50), "p50-ns/page")
+	b.ReportMetric(pct(0.99), "p99-ns/page")
+	b.ReportMetric(pct(0.999), "p999-ns/page")
+}
+
+// BenchmarkFirstWriteWithPrealloc measures the first enqueue cycle of a
+// 64MB queue built with WithPrealloc.
+//
+//	go test -run=^$ -bench=FirstWrite -benchtime=5x
+func BenchmarkFirstWriteWithPrealloc(b *testing.B) {
+	benchmarkFirstWrite(b, true)
+}
+
+// BenchmarkFirstWriteWithoutPrealloc is the baseline for
+// BenchmarkFirstWriteWithPrealloc.
+func BenchmarkFirstWriteWithoutPrealloc(b *testing.B) {
+	benchmarkFirstWrite(b, false)
+}
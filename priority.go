@@ -0,0 +1,129 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// MPMCPriorityAged is a multi-level priority queue with aging.
+//
+// Priority 0 is the highest. Dequeue serves the highest non-empty level,
+// so a steady stream of high-priority elements would starve lower levels.
+// Aging prevents this: every element records the dequeue cycle at which
+// it entered its current level, and every ageThreshold dequeue cycles the
+// oldest element of each lower level is promoted one level once its age
+// reaches ageThreshold. An element at level p is therefore served within
+// roughly p×ageThreshold cycles of reaching the head of its level.
+//
+// Each level is an FAA-based MPMC queue. Order within a level is FIFO,
+// except that an aging round may move a head element that has not yet
+// aged to the tail of its level.
+type MPMCPriorityAged[T any] struct {
+	_         pad
+	cycles    atomix.Uint64 // Dequeue cycle counter (age clock)
+	_         pad
+	levels    []*MPMC[agedItem[T]]
+	threshold uint64
+}
+
+type agedItem[T any] struct {
+	stamp uint64 // Cycle at which the element entered its level
+	data  T
+}
+
+// NewMPMCPriorityAged creates a priority queue with the given number of
+// levels, each holding up to capacity elements.
+// Capacity rounds up to the next power of 2.
+func NewMPMCPriorityAged[T any](capacity, levels, ageThreshold int) *MPMCPriorityAged[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewMPMCPriorityAged", "capacity", capacity, 2))
+	}
+	if levels < 1 {
+		panic(belowMinimum("NewMPMCPriorityAged", "levels", levels, 1))
+	}
+	if ageThreshold < 1 {
+		panic(belowMinimum("NewMPMCPriorityAged", "ageThreshold", ageThreshold, 1))
+	}
+
+	q := &MPMCPriorityAged[T]{
+		levels:    make([]*MPMC[agedItem[T]], levels),
+		threshold: uint64(ageThreshold),
+	}
+	for i := range q.levels {
+		q.levels[i] = NewMPMC[agedItem[T]](capacity)
+	}
+	return q
+}
+
+// EnqueueWithPriority adds an element at the given priority level.
+// Returns ErrWouldBlock if that level is full.
+// Panics if priority is outside [0, Levels()).
+func (q *MPMCPriorityAged[T]) EnqueueWithPriority(elem *T, priority int) error {
+	if priority < 0 {
+		panic(belowMinimum("MPMCPriorityAged.EnqueueWithPriority", "priority", priority, 0))
+	}
+	if priority >= len(q.levels) {
+		panic(aboveMaximum("MPMCPriorityAged.EnqueueWithPriority", "priority", priority, len(q.levels)-1))
+	}
+	item := agedItem[T]{stamp: q.cycles.LoadRelaxed(), data: *elem}
+	return q.levels[priority].Enqueue(&item)
+}
+
+// Dequeue removes and returns the element with the highest effective
+// priority. Returns (zero-value, ErrWouldBlock) if all levels are empty.
+func (q *MPMCPriorityAged[T]) Dequeue() (T, error) {
+	if c := q.cycles.AddAcqRel(1); c%q.threshold == 0 {
+		if item, ok := q.promote(c); ok {
+			return item.data, nil
+		}
+	}
+	for _, l := range q.levels {
+		if item, err := l.Dequeue(); err == nil {
+			return item.data, nil
+		}
+	}
+	var zero T
+	return zero, ErrEmpty
+}
+
+// promote moves the head of each lower level up one level if it has aged.
+//
+// Levels are visited from high to low priority so an element moves at
+// most one level per aging round. The head is the oldest element of its
+// level, so if it has not aged, nothing behind it has either; it is then
+// returned to the tail of its level. If a concurrent producer fills the
+// destination first, the element is handed back to the caller to be
+// served directly rather than waiting for space.
+func (q *MPMCPriorityAged[T]) promote(now uint64) (agedItem[T], bool) {
+	for l := 1; l < len(q.levels); l++ {
+		up := q.levels[l-1]
+		if up.depth() >= up.Cap() {
+			continue
+		}
+		item, err := q.levels[l].Dequeue()
+		if err != nil {
+			continue
+		}
+		dst := up
+		if now-item.stamp < q.threshold {
+			dst = q.levels[l]
+		} else {
+			item.stamp = now
+		}
+		if dst.Enqueue(&item) != nil {
+			return item, true
+		}
+	}
+	return agedItem[T]{}, false
+}
+
+// Levels returns the number of priority levels.
+func (q *MPMCPriorityAged[T]) Levels() int {
+	return len(q.levels)
+}
+
+// Cap returns the capacity of each level.
+func (q *MPMCPriorityAged[T]) Cap() int {
+	return q.levels[0].Cap()
+}
@@ -0,0 +1,70 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestMPMCPriorityAgedOrder verifies strict priority order without aging.
+func TestMPMCPriorityAgedOrder(t *testing.T) {
+	q := lfq.NewMPMCPriorityAged[int](8, 3, 1000)
+	for _, p := range []int{2, 1, 0, 2, 0} {
+		v := p
+		if err := q.EnqueueWithPriority(&v, p); err != nil {
+			t.Fatalf("EnqueueWithPriority(%d): %v", p, err)
+		}
+	}
+	for _, want := range []int{0, 0, 1, 2, 2} {
+		got, err := q.Dequeue()
+		if err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want %d", got, err, want)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestMPMCPriorityAgedPromotion keeps the high-priority level busy and
+// verifies a low-priority element is promoted and consumed instead of
+// starving.
+func TestMPMCPriorityAgedPromotion(t *testing.T) {
+	const threshold = 10
+	q := lfq.NewMPMCPriorityAged[int](16, 3, threshold)
+
+	low := -1
+	if err := q.EnqueueWithPriority(&low, 2); err != nil {
+		t.Fatalf("EnqueueWithPriority: %v", err)
+	}
+	high := 0
+	for range 2 {
+		if err := q.EnqueueWithPriority(&high, 0); err != nil {
+			t.Fatalf("EnqueueWithPriority: %v", err)
+		}
+	}
+
+	for cycle := 1; cycle <= 10*threshold; cycle++ {
+		got, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("cycle %d: Dequeue: %v", cycle, err)
+		}
+		if got == low {
+			// Two promotions (level 2 → 1 → 0) take 2×threshold cycles
+			if cycle < 2*threshold {
+				t.Fatalf("low-priority element served at cycle %d, before aging", cycle)
+			}
+			return
+		}
+		// Keep level 0 non-empty so only aging can reach the low element
+		if err := q.EnqueueWithPriority(&high, 0); err != nil {
+			t.Fatalf("cycle %d: EnqueueWithPriority: %v", cycle, err)
+		}
+	}
+	t.Fatal("low-priority element starved")
+}
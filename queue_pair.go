@@ -0,0 +1,134 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"sync"
+
+	"code.hybscloud.com/atomix"
+)
+
+// RequestToken correlates a QueuePair request with its response. Obtain
+// tokens from [QueuePair.NewRequestToken]; the zero value is not a valid
+// token.
+type RequestToken struct {
+	id uint64
+}
+
+// QueuePair carries requests and their responses between clients and a
+// server over two MPSC queues, for RPC-style exchanges.
+//
+// Clients call SendRequest with a fresh token and wait for the answer with
+// ReceiveResponse. The server, a single goroutine, takes requests with
+// ReceiveRequest and answers with SendResponse, passing the token back.
+//
+// Responses may arrive in any order. A client waiting for its response
+// moves others it comes across into a side table under a mutex, where
+// their own clients find them; this makes the response queue's single
+// consumer whichever client holds the mutex.
+//
+// Memory: two MPSC queues of the given capacity plus one table entry per
+// response collected on behalf of another client
+type QueuePair[Req, Resp any] struct {
+	requests  *MPSC[tokenized[Req]]
+	responses *MPSC[tokenized[Resp]]
+	tokens    atomix.Uint64 // Last issued RequestToken
+
+	mu      sync.Mutex
+	stashed map[RequestToken]Resp // Responses collected for other clients
+}
+
+// tokenized is an element paired with its request token.
+type tokenized[T any] struct {
+	token RequestToken
+	elem  T
+}
+
+// NewQueuePair creates a request-response queue pair. Each direction holds
+// up to capacity elements; capacity rounds up to the next power of 2.
+func NewQueuePair[Req, Resp any](capacity int) *QueuePair[Req, Resp] {
+	if capacity < 2 {
+		panic(belowMinimum("NewQueuePair", "capacity", capacity, 2))
+	}
+	return &QueuePair[Req, Resp]{
+		requests:  NewMPSC[tokenized[Req]](capacity),
+		responses: NewMPSC[tokenized[Resp]](capacity),
+		stashed:   make(map[RequestToken]Resp),
+	}
+}
+
+// NewRequestToken returns a token distinct from all others issued by p.
+func (p *QueuePair[Req, Resp]) NewRequestToken() RequestToken {
+	return RequestToken{id: p.tokens.AddRelaxed(1)}
+}
+
+// SendRequest enqueues req under token (multiple clients safe).
+// Returns ErrFull if the request queue is full.
+func (p *QueuePair[Req, Resp]) SendRequest(req *Req, token RequestToken) error {
+	return p.requests.Enqueue(&tokenized[Req]{token: token, elem: *req})
+}
+
+// ReceiveRequest dequeues the next request and its token (server only).
+// Returns ErrEmpty if no request is waiting.
+func (p *QueuePair[Req, Resp]) ReceiveRequest() (Req, RequestToken, error) {
+	t, err := p.requests.Dequeue()
+	return t.elem, t.token, err
+}
+
+// SendResponse enqueues resp as the answer to the request sent under
+// token. Returns ErrFull if the response queue is full.
+func (p *QueuePair[Req, Resp]) SendResponse(token RequestToken, resp *Resp) error {
+	return p.responses.Enqueue(&tokenized[Resp]{token: token, elem: *resp})
+}
+
+// ReceiveResponse waits until the response for token arrives and returns
+// it, escalating from spinning to parking as [DefaultSpinPolicy] does.
+// The error is always nil; see ReceiveResponseCtx for a wait that can be
+// abandoned.
+func (p *QueuePair[Req, Resp]) ReceiveResponse(token RequestToken) (Resp, error) {
+	return p.ReceiveResponseCtx(context.Background(), token)
+}
+
+// ReceiveResponseCtx is like ReceiveResponse but gives up when ctx is
+// done, returning ctx.Err(). A response arriving after that is kept
+// until a later call with the same token collects it.
+func (p *QueuePair[Req, Resp]) ReceiveResponseCtx(ctx context.Context, token RequestToken) (Resp, error) {
+	s := newSpinner(nil)
+	for {
+		if resp, ok := p.collect(token); ok {
+			return resp, nil
+		}
+		if err := ctx.Err(); err != nil {
+			var zero Resp
+			return zero, err
+		}
+		s.wait()
+	}
+}
+
+// collect moves queued responses into the side table and takes the one
+// for token, if present.
+func (p *QueuePair[Req, Resp]) collect(token RequestToken) (Resp, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		t, err := p.responses.Dequeue()
+		if err != nil {
+			break
+		}
+		p.stashed[t.token] = t.elem
+	}
+	resp, ok := p.stashed[token]
+	if ok {
+		delete(p.stashed, token)
+	}
+	return resp, ok
+}
+
+// Cap returns the capacity of each direction.
+func (p *QueuePair[Req, Resp]) Cap() int {
+	return p.requests.Cap()
+}
@@ -0,0 +1,134 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestQueuePairCycle sends two requests, answers them out of order and
+// verifies each client receives the response for its own token.
+func TestQueuePairCycle(t *testing.T) {
+	p := lfq.NewQueuePair[int, string](8)
+	a, b := p.NewRequestToken(), p.NewRequestToken()
+	if a == b {
+		t.Fatal("NewRequestToken returned the same token twice")
+	}
+
+	reqA, reqB := 1, 2
+	if err := p.SendRequest(&reqA, a); err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if err := p.SendRequest(&reqB, b); err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+
+	type received struct {
+		req   int
+		token lfq.RequestToken
+	}
+	var got []received
+	for range 2 {
+		req, token, err := p.ReceiveRequest()
+		if err != nil {
+			t.Fatalf("ReceiveRequest: %v", err)
+		}
+		got = append(got, received{req, token})
+	}
+	if _, _, err := p.ReceiveRequest(); !lfq.IsEmpty(err) {
+		t.Fatalf("ReceiveRequest on empty: got %v, want ErrEmpty", err)
+	}
+	if got[0] != (received{1, a}) || got[1] != (received{2, b}) {
+		t.Fatalf("ReceiveRequest: got %v", got)
+	}
+
+	// Answer b first; a's client must stash it for b's
+	for _, r := range []received{got[1], got[0]} {
+		resp := "re:" + strconv.Itoa(r.req)
+		if err := p.SendResponse(r.token, &resp); err != nil {
+			t.Fatalf("SendResponse: %v", err)
+		}
+	}
+	if resp, err := p.ReceiveResponse(a); err != nil || resp != "re:1" {
+		t.Fatalf("ReceiveResponse(a): got (%q, %v), want (\"re:1\", nil)", resp, err)
+	}
+	if resp, err := p.ReceiveResponse(b); err != nil || resp != "re:2" {
+		t.Fatalf("ReceiveResponse(b): got (%q, %v), want (\"re:2\", nil)", resp, err)
+	}
+}
+
+// TestQueuePairCtx verifies that ReceiveResponseCtx gives up at the
+// deadline and that a late response is still delivered.
+func TestQueuePairCtx(t *testing.T) {
+	p := lfq.NewQueuePair[int, int](4)
+	token := p.NewRequestToken()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.ReceiveResponseCtx(ctx, token); err != context.DeadlineExceeded {
+		t.Fatalf("ReceiveResponseCtx: got %v, want DeadlineExceeded", err)
+	}
+
+	v := 42
+	p.SendResponse(token, &v)
+	if got, err := p.ReceiveResponse(token); err != nil || got != 42 {
+		t.Fatalf("ReceiveResponse: got (%d, %v), want (42, nil)", got, err)
+	}
+}
+
+// TestQueuePairConcurrent runs 4 clients against one server goroutine.
+func TestQueuePairConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const clients, calls = 4, 200
+	p := lfq.NewQueuePair[int, int](16)
+	done := make(chan struct{})
+	go func() {
+		for {
+			req, token, err := p.ReceiveRequest()
+			if err != nil {
+				select {
+				case <-done:
+					return
+				default:
+					time.Sleep(time.Microsecond)
+					continue
+				}
+			}
+			resp := req * 2
+			for p.SendResponse(token, &resp) != nil {
+				time.Sleep(time.Microsecond)
+			}
+		}
+	}()
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for c := range clients {
+		wg.Go(func() {
+			for i := range calls {
+				req := c*calls + i
+				token := p.NewRequestToken()
+				for p.SendRequest(&req, token) != nil {
+					time.Sleep(time.Microsecond)
+				}
+				resp, err := p.ReceiveResponse(token)
+				if err != nil || resp != 2*req {
+					t.Errorf("client %d call %d: got (%d, %v), want (%d, nil)", c, i, resp, err, 2*req)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,74 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "sync/atomic"
+
+// QueueRef is a swappable reference to a Queue, for hot-standby failover
+// and zero-downtime capacity upgrades.
+//
+// Enqueue, Dequeue and Cap forward to the current queue. Swap installs a
+// new queue atomically: operations that loaded the old queue before the
+// swap complete on it, and later ones go to the new queue. Swap returns
+// the old queue for the caller to drain.
+//
+// A producer may still be inside Enqueue on the old queue when Swap
+// returns, so an element can land there after the swap. Drain the old
+// queue once in-flight producers are known to have returned, or keep
+// dequeuing from it until it stays empty.
+//
+// Memory: one pointer plus one small box per installed queue
+type QueueRef[T any] struct {
+	cur atomic.Pointer[queueBox[T]]
+}
+
+// queueBox lets an interface value be swapped through atomic.Pointer.
+type queueBox[T any] struct {
+	q Queue[T]
+}
+
+// NewQueueRef returns a QueueRef to q.
+// Panics if q is nil.
+func NewQueueRef[T any](q Queue[T]) *QueueRef[T] {
+	r := &QueueRef[T]{}
+	r.cur.Store(boxQueue("NewQueueRef", q))
+	return r
+}
+
+func boxQueue[T any](fn string, q Queue[T]) *queueBox[T] {
+	if q == nil {
+		panic(requires(fn, "a non-nil queue"))
+	}
+	return &queueBox[T]{q: q}
+}
+
+// Get returns the current queue.
+func (r *QueueRef[T]) Get() Queue[T] {
+	return r.cur.Load().q
+}
+
+// Swap makes newQ the current queue and returns the one it replaces.
+// Panics if newQ is nil.
+func (r *QueueRef[T]) Swap(newQ Queue[T]) Queue[T] {
+	return r.cur.Swap(boxQueue("QueueRef.Swap", newQ)).q
+}
+
+// Enqueue adds an element to the current queue.
+// Returns ErrFull if that queue is full.
+func (r *QueueRef[T]) Enqueue(elem *T) error {
+	return r.cur.Load().q.Enqueue(elem)
+}
+
+// Dequeue removes an element from the current queue.
+// Returns (zero-value, ErrEmpty) if that queue is empty. Elements left in
+// a queue replaced by Swap are not seen; drain it separately.
+func (r *QueueRef[T]) Dequeue() (T, error) {
+	return r.cur.Load().q.Dequeue()
+}
+
+// Cap returns the capacity of the current queue.
+func (r *QueueRef[T]) Cap() int {
+	return r.cur.Load().q.Cap()
+}
@@ -0,0 +1,120 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+func TestQueueRef(t *testing.T) {
+	a := lfq.NewMPMC[int](4)
+	b := lfq.NewMPMC[int](8)
+	r := lfq.NewQueueRef[int](a)
+
+	if r.Get() != lfq.Queue[int](a) || r.Cap() != 4 {
+		t.Fatalf("Get/Cap: got (%v, %d), want (a, 4)", r.Get(), r.Cap())
+	}
+	v := 1
+	if err := r.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if old := r.Swap(b); old != lfq.Queue[int](a) {
+		t.Fatalf("Swap: returned %v, want a", old)
+	}
+	if r.Get() != lfq.Queue[int](b) || r.Cap() != 8 {
+		t.Fatalf("Get/Cap after Swap: got (%v, %d), want (b, 8)", r.Get(), r.Cap())
+	}
+	if _, err := r.Dequeue(); err != lfq.ErrEmpty {
+		t.Fatalf("Dequeue after Swap: got %v, want ErrEmpty", err)
+	}
+	if got, err := a.Dequeue(); err != nil || got != 1 {
+		t.Fatalf("old queue: got (%d, %v), want (1, nil)", got, err)
+	}
+
+	v = 2
+	r.Enqueue(&v)
+	if got, err := r.Dequeue(); err != nil || got != 2 {
+		t.Fatalf("Dequeue: got (%d, %v), want (2, nil)", got, err)
+	}
+}
+
+func TestQueueRefNilPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"NewQueueRef", func() { lfq.NewQueueRef[int](nil) }},
+		{"Swap", func() { lfq.NewQueueRef[int](lfq.NewMPMC[int](4)).Swap(nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// TestQueueRefConcurrentSwap enqueues from several producers through a
+// QueueRef while the underlying queue is swapped repeatedly, then drains
+// every queue ever installed and verifies each item appears exactly once.
+func TestQueueRefConcurrentSwap(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const (
+		producers = 4
+		perProd   = 2000
+		swaps     = 16
+	)
+	queues := []lfq.Queue[int]{lfq.NewMPMC[int](producers * perProd)}
+	r := lfq.NewQueueRef(queues[0])
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProd {
+				v := p*perProd + i
+				for r.Enqueue(&v) != nil {
+				}
+			}
+		})
+	}
+	for range swaps {
+		time.Sleep(50 * time.Microsecond)
+		q := lfq.NewMPMC[int](producers * perProd)
+		queues = append(queues, q)
+		r.Swap(q)
+	}
+	wg.Wait()
+
+	seen := make([]bool, producers*perProd)
+	for _, q := range queues {
+		for {
+			v, err := q.Dequeue()
+			if err != nil {
+				break
+			}
+			if seen[v] {
+				t.Fatalf("item %d dequeued twice", v)
+			}
+			seen[v] = true
+		}
+	}
+	for v, ok := range seen {
+		if !ok {
+			t.Fatalf("item %d lost", v)
+		}
+	}
+}
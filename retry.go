@@ -0,0 +1,81 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"unsafe"
+
+	"code.hybscloud.com/spin"
+)
+
+// The WithRetry functions bridge non-blocking calls and the blocking Ctx
+// calls: they retry in place, with one PAUSE instruction between
+// attempts, up to maxRetries times, and then report ErrWouldBlock as the
+// plain call would. maxRetries of 0 is the plain call. Any other error,
+// such as ErrClosed, is returned at once.
+//
+// On FAA queues each failed Dequeue also spends the livelock threshold, so
+// a long retry on an empty queue ends in the threshold's fast path.
+
+// EnqueueWithRetry is q.Enqueue retried up to maxRetries times while the
+// queue is full, pausing the CPU between attempts.
+func EnqueueWithRetry[T any](q Producer[T], elem *T, maxRetries int) error {
+	return retry(maxRetries, func() error {
+		return q.Enqueue(elem)
+	})
+}
+
+// DequeueWithRetry is q.Dequeue retried up to maxRetries times while the
+// queue is empty, pausing the CPU between attempts.
+func DequeueWithRetry[T any](q Consumer[T], maxRetries int) (elem T, err error) {
+	err = retry(maxRetries, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// EnqueueIndirectWithRetry is [EnqueueWithRetry] for indirect queues.
+func EnqueueIndirectWithRetry(q ProducerIndirect, elem uintptr, maxRetries int) error {
+	return retry(maxRetries, func() error {
+		return q.Enqueue(elem)
+	})
+}
+
+// DequeueIndirectWithRetry is [DequeueWithRetry] for indirect queues.
+func DequeueIndirectWithRetry(q ConsumerIndirect, maxRetries int) (elem uintptr, err error) {
+	err = retry(maxRetries, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// EnqueuePtrWithRetry is [EnqueueWithRetry] for pointer queues.
+func EnqueuePtrWithRetry(q ProducerPtr, elem unsafe.Pointer, maxRetries int) error {
+	return retry(maxRetries, func() error {
+		return q.Enqueue(elem)
+	})
+}
+
+// DequeuePtrWithRetry is [DequeueWithRetry] for pointer queues.
+func DequeuePtrWithRetry(q ConsumerPtr, maxRetries int) (elem unsafe.Pointer, err error) {
+	err = retry(maxRetries, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// retry calls op, then calls it again up to maxRetries times while it
+// returns ErrWouldBlock, pausing the CPU before each attempt.
+func retry(maxRetries int, op func() error) error {
+	err := op()
+	for i := 0; err == ErrWouldBlock && i < maxRetries; i++ {
+		spin.Pause(1)
+		err = op()
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/spin"
+)
+
+// TestWithRetryGivesUp verifies that the retry functions report
+// ErrWouldBlock once their retries are spent, and behave like the plain
+// calls otherwise.
+func TestWithRetryGivesUp(t *testing.T) {
+	for _, retries := range []int{0, 1, 100} {
+		q := lfq.NewMPMC[int](2)
+		if _, err := lfq.DequeueWithRetry(q, retries); !lfq.IsEmpty(err) {
+			t.Fatalf("retries=%d: DequeueWithRetry on empty: got %v, want ErrEmpty", retries, err)
+		}
+		for i := range 2 {
+			if err := lfq.EnqueueWithRetry(q, &i, retries); err != nil {
+				t.Fatalf("retries=%d: EnqueueWithRetry(%d): %v", retries, i, err)
+			}
+		}
+		v := 2
+		if err := lfq.EnqueueWithRetry(q, &v, retries); !lfq.IsFull(err) {
+			t.Fatalf("retries=%d: EnqueueWithRetry on full: got %v, want ErrFull", retries, err)
+		}
+		for i := range 2 {
+			if got, err := lfq.DequeueWithRetry(q, retries); err != nil || got != i {
+				t.Fatalf("retries=%d: DequeueWithRetry: got (%d, %v), want (%d, nil)", retries, got, err, i)
+			}
+		}
+	}
+
+	qi := lfq.NewSPSCIndirect(2)
+	lfq.EnqueueIndirectWithRetry(qi, 1, 10)
+	lfq.EnqueueIndirectWithRetry(qi, 2, 10)
+	if err := lfq.EnqueueIndirectWithRetry(qi, 3, 10); !lfq.IsFull(err) {
+		t.Fatalf("EnqueueIndirectWithRetry on full: got %v, want ErrFull", err)
+	}
+
+	x := 0
+	qp := lfq.NewMPSCPtr(2)
+	lfq.EnqueuePtrWithRetry(qp, unsafe.Pointer(&x), 10)
+	if p, err := lfq.DequeuePtrWithRetry(qp, 10); err != nil || p != unsafe.Pointer(&x) {
+		t.Fatalf("DequeuePtrWithRetry: got (%p, %v), want (%p, nil)", p, err, &x)
+	}
+}
+
+// TestWithRetryWaitsForPeer verifies that a retrying consumer picks up an
+// element enqueued while it spins.
+func TestWithRetryWaitsForPeer(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	q := lfq.NewSPSC[int](4)
+	var started atomix.Bool
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for !started.Load() {
+		}
+		v := 7
+		q.Enqueue(&v)
+	})
+	started.Store(true)
+	var got int
+	var err error
+	for range 1000 {
+		if got, err = lfq.DequeueWithRetry(q, 1_000_000); err == nil {
+			break
+		}
+	}
+	wg.Wait()
+	if err != nil || got != 7 {
+		t.Fatalf("DequeueWithRetry: got (%d, %v), want (7, nil)", got, err)
+	}
+}
+
+// BenchmarkEnqueueWithRetry enqueues into an MPMC kept about 90% full by
+// a consumer that pauses between dequeues, and reports the fraction of
+// enqueues that still gave up. Raising maxRetries trades time per call
+// for fewer failures; the sweet spot is where fails/op stops falling.
+//
+//	go test -run=^$ -bench=EnqueueWithRetry -cpu=2
+func BenchmarkEnqueueWithRetry(b *testing.B) {
+	const capacity = 1024
+	for _, retries := range []int{0, 10, 100, 1000} {
+		b.Run("retries="+strconv.Itoa(retries), func(b *testing.B) {
+			q := lfq.NewMPMC[int](capacity)
+			for i := range capacity * 9 / 10 {
+				q.Enqueue(&i)
+			}
+
+			// Depth is tracked by the two sides rather than read from q
+			var enqueued atomix.Int64
+			var stop atomix.Bool
+			var wg sync.WaitGroup
+			wg.Go(func() {
+				dequeued := int64(0)
+				for !stop.Load() {
+					// Dequeue only above 90% so the queue stays there
+					if capacity*9/10+enqueued.Load()-dequeued >= capacity*9/10 {
+						if _, err := q.Dequeue(); err == nil {
+							dequeued++
+						}
+					}
+					spin.Pause(8)
+				}
+			})
+
+			fails := 0
+			b.ResetTimer()
+			for i := range b.N {
+				if lfq.EnqueueWithRetry(q, &i, retries) != nil {
+					fails++
+				} else {
+					enqueued.Add(1)
+				}
+			}
+			b.StopTimer()
+			stop.Store(true)
+			wg.Wait()
+			b.ReportMetric(float64(fails)/float64(b.N), "fails/op")
+		})
+	}
+}
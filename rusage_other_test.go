@@ -0,0 +1,14 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package lfq_test
+
+import "time"
+
+// processCPUTime reports -1: CPU time is unavailable on this platform.
+func processCPUTime() time.Duration {
+	return -1
+}
@@ -5,6 +5,7 @@
 package lfq_test
 
 import (
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -247,6 +248,164 @@ func TestMPMCSeqStressFillDrain(t *testing.T) {
 	}
 }
 
+// TestMPMCSeqReset verifies Reset discards queued elements and the queue
+// remains usable at full capacity afterwards.
+func TestMPMCSeqReset(t *testing.T) {
+	q := lfq.NewMPMCSeq[int](8)
+
+	for i := range 5 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	q.Reset()
+	if _, err := q.Dequeue(); err == nil {
+		t.Fatal("Dequeue after Reset: got element, want ErrWouldBlock")
+	}
+
+	for i := range 8 {
+		v := 100 + i
+		if err := q.Enqueue(&v); err != nil {
+			t.Fatalf("Enqueue after Reset(%d): %v", i, err)
+		}
+	}
+	for i := range 8 {
+		val, err := q.Dequeue()
+		if err != nil || val != 100+i {
+			t.Fatalf("Dequeue after Reset(%d): got (%d, %v), want %d", i, val, err, 100+i)
+		}
+	}
+
+	// Reset frees the capacity of a full queue without any Dequeue,
+	// including at a non-power-of-2 capacity
+	for _, q := range []lfq.Queue[int]{q, lfq.BuildMPMC[int](lfq.New(6).Compact().ExactCapacity())} {
+		r := lfq.Unrecorded(q).(*lfq.MPMCSeq[int])
+		for round := range 3 {
+			for i := range r.Cap() {
+				v := round*100 + i
+				if err := q.Enqueue(&v); err != nil {
+					t.Fatalf("round %d: Enqueue(%d): %v", round, i, err)
+				}
+			}
+			v := -1
+			if err := q.Enqueue(&v); !lfq.IsWouldBlock(err) {
+				t.Fatalf("round %d: Enqueue on full queue: got %v, want ErrWouldBlock", round, err)
+			}
+			r.Reset()
+		}
+		for i := range r.Cap() {
+			v := 1000 + i
+			if err := q.Enqueue(&v); err != nil {
+				t.Fatalf("Enqueue after Reset(%d): %v", i, err)
+			}
+		}
+		for i := range r.Cap() {
+			val, err := q.Dequeue()
+			if err != nil || val != 1000+i {
+				t.Fatalf("Dequeue after Reset(%d): got (%d, %v), want %d", i, val, err, 1000+i)
+			}
+		}
+	}
+}
+
+// TestMPMCSeqStressReset calls Reset continuously while 8 producers and
+// 8 consumers run. Each element carries a checksum so a torn or stale
+// slot read is detected, and each consumer checks per-producer FIFO order.
+func TestMPMCSeqStressReset(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: CAS-based algorithm uses cross-variable memory ordering")
+	}
+
+	type item struct {
+		id, seq, sum uint64
+	}
+	const (
+		numProducers = 8
+		numConsumers = 8
+		itemsPerProd = 10000
+	)
+
+	q := lfq.NewMPMCSeq[item](64)
+	var producersDone, stop atomix.Bool
+	var resets, consumed atomix.Int64
+	errs := make(chan string, numConsumers)
+
+	var resetWg sync.WaitGroup
+	resetWg.Add(1)
+	go func() {
+		defer resetWg.Done()
+		for !stop.Load() {
+			q.Reset()
+			resets.Add(1)
+			runtime.Gosched()
+		}
+	}()
+
+	var prodWg sync.WaitGroup
+	for p := range numProducers {
+		prodWg.Add(1)
+		go func(id uint64) {
+			defer prodWg.Done()
+			backoff := iox.Backoff{}
+			for i := range uint64(itemsPerProd) {
+				v := item{id: id, seq: i, sum: id*0x9E3779B97F4A7C15 ^ i}
+				for q.Enqueue(&v) != nil {
+					backoff.Wait()
+				}
+				backoff.Reset()
+			}
+		}(uint64(p))
+	}
+
+	var consWg sync.WaitGroup
+	for range numConsumers {
+		consWg.Add(1)
+		go func() {
+			defer consWg.Done()
+			var last [numProducers]int64
+			for i := range last {
+				last[i] = -1
+			}
+			for {
+				v, err := q.Dequeue()
+				if err != nil {
+					if producersDone.Load() {
+						return
+					}
+					runtime.Gosched()
+					continue
+				}
+				if v.id >= numProducers || v.sum != v.id*0x9E3779B97F4A7C15^v.seq {
+					errs <- "corrupted element"
+					return
+				}
+				if int64(v.seq) <= last[v.id] {
+					errs <- "per-producer order violated"
+					return
+				}
+				last[v.id] = int64(v.seq)
+				consumed.Add(1)
+			}
+		}()
+	}
+
+	prodWg.Wait()
+	producersDone.Store(true)
+	consWg.Wait()
+	stop.Store(true)
+	resetWg.Wait()
+
+	select {
+	case e := <-errs:
+		t.Fatal(e)
+	default:
+	}
+	if consumed.Load() > numProducers*itemsPerProd {
+		t.Fatalf("consumed %d, more than produced %d", consumed.Load(), numProducers*itemsPerProd)
+	}
+	t.Logf("resets=%d consumed=%d/%d", resets.Load(), consumed.Load(), numProducers*itemsPerProd)
+}
+
 // =============================================================================
 // MPSCSeq Stress Tests
 // =============================================================================
@@ -0,0 +1,71 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+
+package shutdown_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/shutdown"
+)
+
+// ExampleDrainWithContext drains a queue with a 5-second shutdown deadline.
+func ExampleDrainWithContext() {
+	q := lfq.NewMPMC[string](8)
+	for _, s := range []string{"a", "b", "c"} {
+		q.Enqueue(&s)
+	}
+
+	// Producers have stopped; give consumers 5 seconds to finish
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := shutdown.DrainWithContext(ctx, q, func() (string, error) {
+		s, err := q.Dequeue()
+		if err == nil {
+			fmt.Println("handled", s)
+		}
+		return s, err
+	})
+	if err != nil {
+		fmt.Println("shutdown incomplete:", err)
+	}
+
+	// Output:
+	// handled a
+	// handled b
+	// handled c
+}
+
+// ExampleDrainAllWithContext drains several queues concurrently under one
+// deadline.
+func ExampleDrainAllWithContext() {
+	events := lfq.NewMPMC[int](8)
+	metrics := lfq.NewMPSC[int](8)
+	for i := range 3 {
+		events.Enqueue(&i)
+		metrics.Enqueue(&i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan int, 6)
+	err := shutdown.DrainAllWithContext(ctx, func(v int) { done <- v }, events, metrics)
+	close(done)
+
+	n := 0
+	for range done {
+		n++
+	}
+	fmt.Println("drained:", n, "error:", err)
+
+	// Output:
+	// drained: 6 error: <nil>
+}
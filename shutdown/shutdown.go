@@ -0,0 +1,83 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package shutdown coordinates draining lfq queues under a deadline.
+//
+// The helpers put queues into drain mode, then dequeue until each queue
+// reports empty or the context is done. Typical use bounds shutdown with
+// context.WithTimeout:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := shutdown.DrainWithContext(ctx, q, handle); err != nil {
+//	    log.Printf("shutdown incomplete: %v", err)
+//	}
+//
+// As with [lfq.Drainer], the caller must ensure producers have stopped
+// before draining begins.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"code.hybscloud.com/lfq"
+)
+
+// Queue is a queue that can be drained and dequeued, such as *lfq.MPMC[T].
+type Queue[T any] interface {
+	lfq.Drainer
+	lfq.Consumer[T]
+}
+
+// DrainWithContext calls q.Drain, then calls dequeue until it reports
+// ErrWouldBlock or ctx is done.
+//
+// dequeue is responsible for handling each element; the values it
+// returns are discarded. Pass a closure that dequeues and processes, or
+// the queue's Dequeue method to discard remaining elements.
+//
+// Returns nil once the queue is empty, ctx.Err() if ctx is done first,
+// or the first error from dequeue other than ErrWouldBlock.
+func DrainWithContext[T any](ctx context.Context, q lfq.Drainer, dequeue func() (T, error)) error {
+	q.Drain()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := dequeue(); err != nil {
+			if lfq.IsWouldBlock(err) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// DrainAllWithContext drains every queue concurrently, one goroutine per
+// queue, passing each element to process. process is called concurrently
+// and must be safe for concurrent use.
+//
+// Returns nil once every queue is empty, or the errors of the queues that
+// did not finish, joined with errors.Join.
+func DrainAllWithContext[T any](ctx context.Context, process func(T), queues ...Queue[T]) error {
+	errs := make([]error, len(queues))
+	var wg sync.WaitGroup
+	wg.Add(len(queues))
+	for i, q := range queues {
+		go func() {
+			defer wg.Done()
+			errs[i] = DrainWithContext(ctx, q, func() (T, error) {
+				elem, err := q.Dequeue()
+				if err == nil {
+					process(elem)
+				}
+				return elem, err
+			})
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
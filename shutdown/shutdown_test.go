@@ -0,0 +1,79 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/shutdown"
+)
+
+func fill(q lfq.Queue[int], n int) {
+	for i := range n {
+		q.Enqueue(&i)
+	}
+}
+
+func TestDrainWithContext(t *testing.T) {
+	q := lfq.NewMPMC[int](16)
+	fill(q, 10)
+
+	sum := 0
+	err := shutdown.DrainWithContext(context.Background(), q, func() (int, error) {
+		v, err := q.Dequeue()
+		sum += v
+		return v, err
+	})
+	if err != nil {
+		t.Fatalf("DrainWithContext: %v", err)
+	}
+	if sum != 45 {
+		t.Fatalf("sum: got %d, want 45", sum)
+	}
+}
+
+func TestDrainWithContextCancelled(t *testing.T) {
+	q := lfq.NewMPMC[int](16)
+	fill(q, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := shutdown.DrainWithContext(ctx, q, q.Dequeue); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DrainWithContext: got %v, want context.Canceled", err)
+	}
+}
+
+func TestDrainWithContextError(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+	boom := errors.New("boom")
+	err := shutdown.DrainWithContext(context.Background(), q, func() (int, error) { return 0, boom })
+	if !errors.Is(err, boom) {
+		t.Fatalf("DrainWithContext: got %v, want boom", err)
+	}
+}
+
+func TestDrainAllWithContext(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	a, b := lfq.NewMPMC[int](16), lfq.NewMPSC[int](16)
+	fill(a, 10)
+	fill(b, 5)
+
+	var n atomic.Int64
+	err := shutdown.DrainAllWithContext(context.Background(), func(int) { n.Add(1) },
+		a, b)
+	if err != nil {
+		t.Fatalf("DrainAllWithContext: %v", err)
+	}
+	if n.Load() != 15 {
+		t.Fatalf("processed: got %d, want 15", n.Load())
+	}
+}
@@ -0,0 +1,173 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"math"
+	"sync/atomic"
+
+	"code.hybscloud.com/atomix"
+)
+
+// signals delivers edge-triggered notifications for reactive callers.
+//
+// A producer that observes a full queue marks itself blocked; the next
+// successful Dequeue clears the mark and sends on notFull. Likewise a
+// consumer that observes an empty queue marks itself starved and the next
+// successful Enqueue sends on notEmpty. Sends never block: each channel
+// has a buffer of one and surplus signals are dropped.
+//
+// Watermarks are edge-triggered the same way: high fires once when the
+// depth reaches the high level and re-arms when it falls back below it;
+// low fires once when the depth falls to the low level and re-arms when
+// it rises above it.
+//
+// Once created, signals cost each successful operation three relaxed
+// loads; the queue's depth is computed only while a watermark is set.
+type signals struct {
+	_        pad
+	blocked  atomix.Bool // A producer saw the queue full
+	_        [64 - 1]byte
+	starved  atomix.Bool // A consumer saw the queue empty
+	_        [64 - 1]byte
+	above    atomix.Bool  // Depth is at or above hiLevel
+	below    atomix.Bool  // Depth is at or below loLevel
+	hiLevel  atomix.Int64 // High watermark in elements, 0 if unset
+	loLevel  atomix.Int64 // Low watermark in elements, -1 if unset
+	notFull  chan struct{}
+	notEmpty chan struct{}
+	high     chan struct{}
+	low      chan struct{}
+}
+
+func newSignals() *signals {
+	s := &signals{
+		notFull:  make(chan struct{}, 1),
+		notEmpty: make(chan struct{}, 1),
+		high:     make(chan struct{}, 1),
+		low:      make(chan struct{}, 1),
+	}
+	s.loLevel.StoreRelaxed(-1)
+	s.below.StoreRelaxed(true) // Queues start empty
+	return s
+}
+
+// signalsRef points to a queue's signals, created by the first request
+// for a notification channel. Until then the queue tracks nothing and
+// each hook costs a single pointer load; events that happen before the
+// request are not signaled.
+type signalsRef struct {
+	p atomic.Pointer[signals]
+}
+
+// load returns the signals, or nil if no channel was requested.
+func (r *signalsRef) load() *signals {
+	return r.p.Load()
+}
+
+// get returns the signals, creating them on first use.
+func (r *signalsRef) get() *signals {
+	if s := r.p.Load(); s != nil {
+		return s
+	}
+	r.p.CompareAndSwap(nil, newSignals())
+	return r.p.Load()
+}
+
+// checkWatermark panics if the threshold passed to fn is not a fraction
+// in (0, 1].
+func checkWatermark(fn string, threshold float64) {
+	if !(threshold > 0 && threshold <= 1) {
+		panic(outsideUnitInterval(fn, "threshold", threshold))
+	}
+}
+
+// setHigh sets the high watermark to threshold*capacity elements, rounded
+// up, and returns its channel.
+func (s *signals) setHigh(threshold float64, capacity uint64) <-chan struct{} {
+	s.hiLevel.StoreRelease(max(1, int64(math.Ceil(threshold*float64(capacity)))))
+	return s.high
+}
+
+// setLow sets the low watermark to threshold*capacity elements, rounded
+// down, and returns its channel.
+func (s *signals) setLow(threshold float64, capacity uint64) <-chan struct{} {
+	s.loLevel.StoreRelease(int64(threshold * float64(capacity)))
+	return s.low
+}
+
+// full records that a producer was turned away, if signals are in use.
+func (r *signalsRef) full() {
+	if s := r.p.Load(); s != nil {
+		s.full()
+	}
+}
+
+// empty records that a consumer found nothing, if signals are in use.
+func (r *signalsRef) empty() {
+	if s := r.p.Load(); s != nil {
+		s.empty()
+	}
+}
+
+// full records that a producer was turned away.
+func (s *signals) full() {
+	if !s.blocked.LoadRelaxed() {
+		s.blocked.StoreRelease(true)
+	}
+}
+
+// empty records that a consumer found nothing.
+func (s *signals) empty() {
+	if !s.starved.LoadRelaxed() {
+		s.starved.StoreRelease(true)
+	}
+}
+
+// dequeued wakes a blocked producer, if any, and tracks watermarks for
+// the depth of q after the Dequeue.
+func (s *signals) dequeued(q depther) {
+	if s.blocked.LoadRelaxed() && s.blocked.CompareAndSwapAcqRel(true, false) {
+		notify(s.notFull)
+	}
+	hi, lo := s.hiLevel.LoadRelaxed(), s.loLevel.LoadRelaxed()
+	if hi <= 0 && lo < 0 {
+		return
+	}
+	d := int64(q.depth())
+	if hi > 0 && d < hi && s.above.LoadRelaxed() {
+		s.above.StoreRelease(false)
+	}
+	if d <= lo && !s.below.LoadRelaxed() && s.below.CompareAndSwapAcqRel(false, true) {
+		notify(s.low)
+	}
+}
+
+// enqueued wakes a starved consumer, if any, and tracks watermarks for
+// the depth of q after the Enqueue.
+func (s *signals) enqueued(q depther) {
+	if s.starved.LoadRelaxed() && s.starved.CompareAndSwapAcqRel(true, false) {
+		notify(s.notEmpty)
+	}
+	hi, lo := s.hiLevel.LoadRelaxed(), s.loLevel.LoadRelaxed()
+	if hi <= 0 && lo < 0 {
+		return
+	}
+	d := int64(q.depth())
+	if hi > 0 && d >= hi && !s.above.LoadRelaxed() && s.above.CompareAndSwapAcqRel(false, true) {
+		notify(s.high)
+	}
+	if lo >= 0 && d > lo && s.below.LoadRelaxed() {
+		s.below.StoreRelease(false)
+	}
+}
+
+// notify performs a non-blocking send on c.
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,218 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// signalQueue is implemented by queues with reactive notifications.
+type signalQueue interface {
+	lfq.Queue[int]
+	BackpressureChannel() <-chan struct{}
+	CapacitySignalChannel() <-chan struct{}
+}
+
+// TestBackpressureChannel blocks a producer on a full queue and verifies
+// that a Dequeue wakes it through BackpressureChannel.
+func TestBackpressureChannel(t *testing.T) {
+	tests := []struct {
+		name string
+		q    signalQueue
+	}{
+		{"MPMC", lfq.NewMPMC[int](4)},
+		{"MPSC", lfq.NewMPSC[int](4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			backpressure := q.BackpressureChannel()
+			for i := range q.Cap() {
+				if err := q.Enqueue(&i); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+
+			blocked := make(chan struct{})
+			done := make(chan error, 1)
+			go func() {
+				v := 100
+				if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+					done <- err
+					return
+				}
+				close(blocked)
+				select {
+				case <-backpressure:
+					done <- q.Enqueue(&v)
+				case <-time.After(5 * time.Second):
+					done <- errors.New("producer not woken")
+				}
+			}()
+
+			<-blocked
+			if _, err := q.Dequeue(); err != nil {
+				t.Fatalf("Dequeue: %v", err)
+			}
+			if err := <-done; err != nil {
+				t.Fatalf("producer: %v", err)
+			}
+		})
+	}
+}
+
+// TestCapacitySignalChannel verifies an empty-to-non-empty transition
+// observed by a consumer is signaled exactly once.
+func TestCapacitySignalChannel(t *testing.T) {
+	q := lfq.NewMPMC[int](4)
+	capacity, backpressure := q.CapacitySignalChannel(), q.BackpressureChannel()
+
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+	for i := range 2 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	select {
+	case <-capacity:
+	default:
+		t.Fatal("CapacitySignalChannel: no signal after empty-to-non-empty")
+	}
+	select {
+	case <-capacity:
+		t.Fatal("CapacitySignalChannel: duplicate signal")
+	default:
+	}
+
+	// No producer was blocked, so Dequeue must not signal backpressure
+	if _, err := q.Dequeue(); err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	select {
+	case <-backpressure:
+		t.Fatal("BackpressureChannel: unexpected signal")
+	default:
+	}
+}
+
+// TestSignalsStartOnRequest verifies a queue tracks nothing before its
+// first channel request: a producer turned away earlier is not signaled.
+func TestSignalsStartOnRequest(t *testing.T) {
+	q := lfq.NewMPSC[int](2)
+	for i := range 3 {
+		q.Enqueue(&i)
+	}
+	backpressure := q.BackpressureChannel()
+	q.Dequeue()
+	if n := pending(backpressure); n != 0 {
+		t.Fatalf("BackpressureChannel: got %d signals for a rejection before the request, want 0", n)
+	}
+
+	// Tracked from now on
+	q.Enqueue(new(int))
+	q.Enqueue(new(int))
+	q.Dequeue()
+	if n := pending(backpressure); n != 1 {
+		t.Fatalf("BackpressureChannel: got %d signals, want 1", n)
+	}
+}
+
+// watermarkQueue is implemented by queues with watermark notifications.
+type watermarkQueue interface {
+	lfq.Queue[int]
+	HighWaterMark(threshold float64) <-chan struct{}
+	LowWaterMark(threshold float64) <-chan struct{}
+}
+
+// pending reports how many signals are buffered on c, draining it.
+func pending(c <-chan struct{}) int {
+	n := 0
+	for {
+		select {
+		case <-c:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+// TestWaterMarks fills a queue past its high watermark and drains it to
+// its low watermark, verifying each edge fires exactly once.
+func TestWaterMarks(t *testing.T) {
+	tests := []struct {
+		name string
+		q    watermarkQueue
+	}{
+		{"MPMC", lfq.NewMPMC[int](16)},
+		{"MPSC", lfq.NewMPSC[int](16)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			high := q.HighWaterMark(0.8)
+			low := q.LowWaterMark(0.5)
+
+			// 0.8 * 16 rounds up to 13
+			for i := range 13 {
+				if err := q.Enqueue(&i); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+			if n := pending(high); n != 1 {
+				t.Fatalf("high after 80%%: got %d signals, want 1", n)
+			}
+			for i := 13; i < 16; i++ {
+				q.Enqueue(&i)
+			}
+			if n := pending(high); n != 0 {
+				t.Fatalf("high after 100%%: got %d signals, want 0", n)
+			}
+			if n := pending(low); n != 0 {
+				t.Fatalf("low while filling: got %d signals, want 0", n)
+			}
+
+			for range 8 {
+				if _, err := q.Dequeue(); err != nil {
+					t.Fatalf("Dequeue: %v", err)
+				}
+			}
+			if n := pending(low); n != 1 {
+				t.Fatalf("low after draining to 50%%: got %d signals, want 1", n)
+			}
+
+			// Rising again re-arms high
+			for i := range 5 {
+				q.Enqueue(&i)
+			}
+			if n := pending(high); n != 1 {
+				t.Fatalf("high after second crossing: got %d signals, want 1", n)
+			}
+		})
+	}
+}
+
+// TestWaterMarkRange tests that out-of-range thresholds panic.
+func TestWaterMarkRange(t *testing.T) {
+	for _, th := range []float64{0, -0.5, 1.5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("HighWaterMark(%v) did not panic", th)
+				}
+			}()
+			lfq.NewMPMC[int](8).HighWaterMark(th)
+		}()
+	}
+}
@@ -0,0 +1,80 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race && (amd64 || arm64)
+
+package lfq_test
+
+import (
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// Expected struct sizes in bytes on 64-bit platforms. Each hot field sits
+// between 64-byte pads, so an accidental field can push a neighbour onto
+// another cache line; the assertions below catch any change at compile
+// time. Sizes exclude the slot buffers, which are allocated separately.
+//
+// When a change grows a struct on purpose, update its constant and note
+// the reason next to it.
+const (
+	// Indices and cached indices, the buffer, and the compact delegate
+	spscSize = 392
+	// Indices, drain flag, producer tokens, and the observers and spin
+	// policy pointers
+	mpscSize = 416
+	// Indices, threshold, drain flag, and the depth marks and threshold
+	// tuner pointers
+	spmcSize = 416
+	// As MPSC, with the threshold in place of producer tokens, plus the
+	// threshold tuner pointer
+	mpmcSize = 424
+
+	mpscSeqSize     = 256
+	spmcSeqSize     = 256
+	mpmcSeqSize     = 328 // Reset epoch
+	spscCompactSize = 240
+
+	spscIndirectSize        = 384
+	mpscIndirectSize        = 328
+	spmcIndirectSize        = 400
+	mpmcIndirectSize        = 400
+	mpmcCompactIndirectSize = 272 // Reserved bit mask
+	mpscCompactIndirectSize = 272
+	spmcCompactIndirectSize = 272
+
+	spscPtrSize = 392
+	mpscPtrSize = 328
+	spmcPtrSize = 400
+	mpmcPtrSize = 400
+)
+
+// Indexing a one-element array with size - expected compiles only when the
+// difference is 0: a larger struct indexes out of range, and a smaller one
+// wraps around to a huge index.
+var (
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPSC[int]{})-spscSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPSC[int]{})-mpscSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPMC[int]{})-spmcSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPMC[int]{})-mpmcSize]
+
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPSCSeq[int]{})-mpscSeqSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPMCSeq[int]{})-spmcSeqSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPMCSeq[int]{})-mpmcSeqSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPSCCompact[int]{})-spscCompactSize]
+
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPSCIndirect{})-spscIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPSCIndirect{})-mpscIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPMCIndirect{})-spmcIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPMCIndirect{})-mpmcIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPMCCompactIndirect{})-mpmcCompactIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPSCCompactIndirect{})-mpscCompactIndirectSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPMCCompactIndirect{})-spmcCompactIndirectSize]
+
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPSCPtr{})-spscPtrSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPSCPtr{})-mpscPtrSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.SPMCPtr{})-spmcPtrSize]
+	_ = [1]struct{}{}[unsafe.Sizeof(lfq.MPMCPtr{})-mpmcPtrSize]
+)
@@ -0,0 +1,126 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package slogadapter wraps lfq queues with structured logging.
+//
+// Each successful operation emits a log/slog record carrying the queue
+// capacity and an approximate depth. Operations that keep returning
+// ErrWouldBlock for longer than a threshold emit a Debug record, which
+// helps diagnose stalled producers or consumers in production.
+//
+// When the logger does not enable the configured level, the wrapper adds
+// only the Enabled check to each operation.
+package slogadapter
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"code.hybscloud.com/atomix"
+	"code.hybscloud.com/lfq"
+)
+
+// DefaultBlockThreshold is how long an operation must keep returning
+// ErrWouldBlock before a Debug record is emitted.
+const DefaultBlockThreshold = 100 * time.Millisecond
+
+// Option configures a logging queue.
+type Option func(*config)
+
+type config struct {
+	blockThreshold time.Duration
+}
+
+// WithBlockThreshold sets how long Enqueue or Dequeue must keep returning
+// ErrWouldBlock before a Debug record is emitted. A blocked streak logs at
+// most once per threshold.
+func WithBlockThreshold(d time.Duration) Option {
+	return func(c *config) { c.blockThreshold = d }
+}
+
+// loggingQueue logs operations on an underlying queue.
+//
+// depth is the wrapper's own count of elements enqueued minus dequeued
+// through it, so it is exact only if all access goes through the wrapper.
+type loggingQueue[T any] struct {
+	q         lfq.Queue[T]
+	logger    *slog.Logger
+	level     slog.Level
+	threshold time.Duration
+	depth     atomix.Int64
+	enqSince  atomix.Int64 // Start of the current blocked streak (UnixNano), 0 if none
+	deqSince  atomix.Int64
+}
+
+// NewLoggingQueue wraps q so that each successful Enqueue and Dequeue is
+// logged to logger at level with "cap" and "depth" attributes.
+func NewLoggingQueue[T any](q lfq.Queue[T], logger *slog.Logger, level slog.Level, opts ...Option) lfq.Queue[T] {
+	c := config{blockThreshold: DefaultBlockThreshold}
+	for _, o := range opts {
+		o(&c)
+	}
+	return &loggingQueue[T]{q: q, logger: logger, level: level, threshold: c.blockThreshold}
+}
+
+// Enqueue adds an element and logs "enqueue" on success.
+func (l *loggingQueue[T]) Enqueue(elem *T) error {
+	err := l.q.Enqueue(elem)
+	if err != nil {
+		l.blocked(&l.enqSince, "enqueue blocked", err)
+		return err
+	}
+	l.enqSince.StoreRelaxed(0)
+	d := l.depth.AddRelaxed(1)
+	l.log("enqueue", d)
+	return nil
+}
+
+// Dequeue removes an element and logs "dequeue" on success.
+func (l *loggingQueue[T]) Dequeue() (T, error) {
+	elem, err := l.q.Dequeue()
+	if err != nil {
+		l.blocked(&l.deqSince, "dequeue blocked", err)
+		return elem, err
+	}
+	l.deqSince.StoreRelaxed(0)
+	d := l.depth.AddRelaxed(-1)
+	l.log("dequeue", d)
+	return elem, nil
+}
+
+// Cap returns the capacity of the underlying queue.
+func (l *loggingQueue[T]) Cap() int {
+	return l.q.Cap()
+}
+
+func (l *loggingQueue[T]) log(msg string, depth int64) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, l.level) {
+		return
+	}
+	l.logger.LogAttrs(ctx, l.level, msg,
+		slog.Int("cap", l.q.Cap()),
+		slog.Int64("depth", max(depth, 0)))
+}
+
+// blocked tracks a streak of ErrWouldBlock results and logs at Debug once
+// the streak exceeds the threshold.
+func (l *loggingQueue[T]) blocked(since *atomix.Int64, msg string, err error) {
+	ctx := context.Background()
+	if !lfq.IsWouldBlock(err) || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	now := time.Now().UnixNano()
+	start := since.LoadRelaxed()
+	if start == 0 {
+		since.CompareAndSwapRelaxed(0, now)
+		return
+	}
+	if elapsed := time.Duration(now - start); elapsed > l.threshold && since.CompareAndSwapRelaxed(start, now) {
+		l.logger.LogAttrs(ctx, slog.LevelDebug, msg,
+			slog.Int("cap", l.q.Cap()),
+			slog.Duration("blocked", elapsed))
+	}
+}
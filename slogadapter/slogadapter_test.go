@@ -0,0 +1,93 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package slogadapter_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/slogadapter"
+)
+
+// recordHandler collects records at or above a minimum level.
+type recordHandler struct {
+	mu      sync.Mutex
+	min     slog.Level
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.min }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler           { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler                { return h }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	h.records = append(h.records, r)
+	h.mu.Unlock()
+	return nil
+}
+
+func TestLoggingQueueRecords(t *testing.T) {
+	h := &recordHandler{min: slog.LevelInfo}
+	q := slogadapter.NewLoggingQueue(lfq.NewMPMC[int](8), slog.New(h), slog.LevelInfo)
+
+	for i := range 5 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for i := range 3 {
+		if _, err := q.Dequeue(); err != nil {
+			t.Fatalf("Dequeue(%d): %v", i, err)
+		}
+	}
+
+	if len(h.records) != 8 {
+		t.Fatalf("records: got %d, want 8", len(h.records))
+	}
+	last := h.records[7]
+	if last.Message != "dequeue" || last.Level != slog.LevelInfo {
+		t.Fatalf("last record: got (%q, %v), want (\"dequeue\", INFO)", last.Message, last.Level)
+	}
+	attrs := map[string]int64{}
+	last.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Int64()
+		return true
+	})
+	if attrs["cap"] != 8 || attrs["depth"] != 2 {
+		t.Fatalf("last record attrs: got %v, want cap=8 depth=2", attrs)
+	}
+}
+
+func TestLoggingQueueDisabledLevel(t *testing.T) {
+	h := &recordHandler{min: slog.LevelWarn}
+	q := slogadapter.NewLoggingQueue(lfq.NewSPSC[int](4), slog.New(h), slog.LevelInfo)
+
+	v := 1
+	q.Enqueue(&v)
+	q.Dequeue()
+	q.Dequeue()
+	if len(h.records) != 0 {
+		t.Fatalf("records: got %d, want 0", len(h.records))
+	}
+}
+
+func TestLoggingQueueBlocked(t *testing.T) {
+	h := &recordHandler{min: slog.LevelDebug}
+	q := slogadapter.NewLoggingQueue(lfq.NewSPSC[int](4), slog.New(h), slog.LevelInfo,
+		slogadapter.WithBlockThreshold(time.Millisecond))
+
+	q.Dequeue()
+	time.Sleep(2 * time.Millisecond)
+	q.Dequeue()
+
+	if len(h.records) != 1 || h.records[0].Message != "dequeue blocked" || h.records[0].Level != slog.LevelDebug {
+		t.Fatalf("records: got %v, want one Debug \"dequeue blocked\"", h.records)
+	}
+}
@@ -0,0 +1,70 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSnapshot fills a queue, checks the snapshot, then verifies Dequeue
+// still returns every element.
+func TestSnapshot(t *testing.T) {
+	type snapshotter interface {
+		lfq.Queue[int]
+		Snapshot() []int
+	}
+	tests := []struct {
+		name string
+		q    snapshotter
+	}{
+		{"SPSC", lfq.NewSPSC[int](8)},
+		{"MPSC", lfq.NewMPSC[int](8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			if s := q.Snapshot(); len(s) != 0 {
+				t.Fatalf("Snapshot on empty: got %v", s)
+			}
+
+			// Advance past the first round so the snapshot crosses a wrap
+			for i := range 5 {
+				q.Enqueue(&i)
+				q.Dequeue()
+			}
+			want := []int{10, 11, 12, 13, 14, 15}
+			for i := range want {
+				if err := q.Enqueue(&want[i]); err != nil {
+					t.Fatalf("Enqueue(%d): %v", i, err)
+				}
+			}
+
+			if s := q.Snapshot(); !slices.Equal(s, want) {
+				t.Fatalf("Snapshot: got %v, want %v", s, want)
+			}
+			for _, w := range want {
+				got, err := q.Dequeue()
+				if err != nil || got != w {
+					t.Fatalf("Dequeue after Snapshot: got (%d, %v), want %d", got, err, w)
+				}
+			}
+		})
+	}
+
+	q := lfq.NewMPSCIndirect(4)
+	for i := range 3 {
+		q.Enqueue(uintptr(i + 1))
+	}
+	if s := q.SnapshotIndirect(); !slices.Equal(s, []uintptr{1, 2, 3}) {
+		t.Fatalf("SnapshotIndirect: got %v, want [1 2 3]", s)
+	}
+	if v, err := q.Dequeue(); err != nil || v != 1 {
+		t.Fatalf("Dequeue after SnapshotIndirect: got (%d, %v), want 1", v, err)
+	}
+}
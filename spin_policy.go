@@ -0,0 +1,86 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"runtime"
+
+	"code.hybscloud.com/iox"
+	"code.hybscloud.com/spin"
+)
+
+// SpinPolicy controls how the blocking EnqueueCtx and DequeueCtx paths
+// wait between attempts.
+//
+// A waiter escalates through three stages while the queue makes no
+// progress for it: CPU pause instructions, then runtime.Gosched, then
+// parking with [iox.Backoff] sleeps. Spinning keeps latency lowest while
+// burning a core; parking frees the core at the cost of wake-up delay.
+type SpinPolicy struct {
+	// PauseCount is the number of PAUSE cycles per spin. Values < 1 use 1.
+	PauseCount int
+	// YieldAfterSpins is the number of spins before yielding. A negative
+	// value never yields or parks.
+	YieldAfterSpins int
+	// ParkAfterYields is the number of yields before parking. A negative
+	// value never parks.
+	ParkAfterYields int
+}
+
+// DefaultSpinPolicy is the policy of queues without SetSpinPolicy.
+var DefaultSpinPolicy = SpinPolicy{PauseCount: 30, YieldAfterSpins: 16, ParkAfterYields: 64}
+
+// spinner is the wait state of one blocking call.
+type spinner struct {
+	policy SpinPolicy
+	spins  int
+	yields int
+	ba     iox.Backoff
+}
+
+func newSpinner(p *SpinPolicy) spinner {
+	if p == nil {
+		return spinner{policy: DefaultSpinPolicy}
+	}
+	return spinner{policy: *p}
+}
+
+// wait performs one step of the policy.
+func (s *spinner) wait() {
+	p := &s.policy
+	switch {
+	case p.YieldAfterSpins < 0 || s.spins < p.YieldAfterSpins:
+		s.spins++
+		spin.Pause(max(1, p.PauseCount))
+	case p.ParkAfterYields < 0 || s.yields < p.ParkAfterYields:
+		s.yields++
+		runtime.Gosched()
+	default:
+		s.ba.Wait()
+	}
+}
+
+// retryCtx calls op until it stops returning ErrWouldBlock or ctx is done,
+// waiting between attempts according to p.
+func retryCtx(ctx context.Context, p *SpinPolicy, op func() error) error {
+	err := op()
+	if err != ErrWouldBlock {
+		return err
+	}
+	done := ctx.Done()
+	s := newSpinner(p)
+	for {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		s.wait()
+		if err = op(); err != ErrWouldBlock {
+			return err
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+var (
+	aggressiveSpinPolicy   = lfq.SpinPolicy{PauseCount: 100, YieldAfterSpins: -1, ParkAfterYields: -1}
+	conservativeSpinPolicy = lfq.SpinPolicy{PauseCount: 1, YieldAfterSpins: 0, ParkAfterYields: 0}
+)
+
+// ctxQueue adapts the EnqueueCtx and DequeueCtx methods of a queue of
+// any flavor to int elements.
+type ctxQueue struct {
+	name    string
+	q       lenQueue
+	enqueue func(context.Context, int) error
+	dequeue func(context.Context) (int, error)
+}
+
+// ctxQueues returns every queue variant with EnqueueCtx and DequeueCtx,
+// each with capacity 2.
+func ctxQueues() []ctxQueue {
+	generic := func(name string, q lenQueue, enq func(context.Context, *int) error, deq func(context.Context) (int, error)) ctxQueue {
+		return ctxQueue{name, q, func(ctx context.Context, v int) error { return enq(ctx, &v) }, deq}
+	}
+	indirect := func(name string, q lenQueue, enq func(context.Context, uintptr) error, deq func(context.Context) (uintptr, error)) ctxQueue {
+		return ctxQueue{name, q, func(ctx context.Context, v int) error { return enq(ctx, uintptr(v)) },
+			func(ctx context.Context) (int, error) { v, err := deq(ctx); return int(v), err }}
+	}
+	ptr := func(name string, q lenQueue, enq func(context.Context, unsafe.Pointer) error, deq func(context.Context) (unsafe.Pointer, error)) ctxQueue {
+		return ctxQueue{name, q, func(ctx context.Context, v int) error { return enq(ctx, unsafe.Pointer(&v)) },
+			func(ctx context.Context) (int, error) {
+				p, err := deq(ctx)
+				if err != nil {
+					return 0, err
+				}
+				return *(*int)(p), nil
+			}}
+	}
+	spsc, mpsc, spmc, mpmc := lfq.NewSPSC[int](2), lfq.NewMPSC[int](2), lfq.NewSPMC[int](2), lfq.NewMPMC[int](2)
+	spscI, mpscI, spmcI, mpmcI := lfq.NewSPSCIndirect(2), lfq.NewMPSCIndirect(2), lfq.NewSPMCIndirect(2), lfq.NewMPMCIndirect(2)
+	spscP, mpscP, spmcP, mpmcP := lfq.NewSPSCPtr(2), lfq.NewMPSCPtr(2), lfq.NewSPMCPtr(2), lfq.NewMPMCPtr(2)
+	mpmcS, mpscS, spmcS, spscC := lfq.NewMPMCSeq[int](2), lfq.NewMPSCSeq[int](2), lfq.NewSPMCSeq[int](2), lfq.NewSPSCCompact[int](2)
+	mpmcIS, mpscIS, spmcIS := lfq.NewMPMCIndirectSeq(2), lfq.NewMPSCIndirectSeq(2), lfq.NewSPMCIndirectSeq(2)
+	mpmcPS, mpscPS, spmcPS := lfq.NewMPMCPtrSeq(2), lfq.NewMPSCPtrSeq(2), lfq.NewSPMCPtrSeq(2)
+	mpmcC, mpscC, spmcC := lfq.NewMPMCCompactIndirect(2), lfq.NewMPSCCompactIndirect(2), lfq.NewSPMCCompactIndirect(2)
+	spscG := lfq.NewSPSCIndirectPureGo(2)
+	return []ctxQueue{
+		generic("SPSC", spsc, spsc.EnqueueCtx, spsc.DequeueCtx),
+		generic("MPSC", mpsc, mpsc.EnqueueCtx, mpsc.DequeueCtx),
+		generic("SPMC", spmc, spmc.EnqueueCtx, spmc.DequeueCtx),
+		generic("MPMC", mpmc, mpmc.EnqueueCtx, mpmc.DequeueCtx),
+		indirect("SPSCIndirect", spscI, spscI.EnqueueCtx, spscI.DequeueCtx),
+		indirect("MPSCIndirect", mpscI, mpscI.EnqueueCtx, mpscI.DequeueCtx),
+		indirect("SPMCIndirect", spmcI, spmcI.EnqueueCtx, spmcI.DequeueCtx),
+		indirect("MPMCIndirect", mpmcI, mpmcI.EnqueueCtx, mpmcI.DequeueCtx),
+		ptr("SPSCPtr", spscP, spscP.EnqueueCtx, spscP.DequeueCtx),
+		ptr("MPSCPtr", mpscP, mpscP.EnqueueCtx, mpscP.DequeueCtx),
+		ptr("SPMCPtr", spmcP, spmcP.EnqueueCtx, spmcP.DequeueCtx),
+		ptr("MPMCPtr", mpmcP, mpmcP.EnqueueCtx, mpmcP.DequeueCtx),
+		generic("MPMCSeq", mpmcS, mpmcS.EnqueueCtx, mpmcS.DequeueCtx),
+		generic("MPSCSeq", mpscS, mpscS.EnqueueCtx, mpscS.DequeueCtx),
+		generic("SPMCSeq", spmcS, spmcS.EnqueueCtx, spmcS.DequeueCtx),
+		generic("SPSCCompact", spscC, spscC.EnqueueCtx, spscC.DequeueCtx),
+		indirect("MPMCIndirectSeq", mpmcIS, mpmcIS.EnqueueCtx, mpmcIS.DequeueCtx),
+		indirect("MPSCIndirectSeq", mpscIS, mpscIS.EnqueueCtx, mpscIS.DequeueCtx),
+		indirect("SPMCIndirectSeq", spmcIS, spmcIS.EnqueueCtx, spmcIS.DequeueCtx),
+		indirect("MPMCCompactIndirect", mpmcC, mpmcC.EnqueueCtx, mpmcC.DequeueCtx),
+		indirect("MPSCCompactIndirect", mpscC, mpscC.EnqueueCtx, mpscC.DequeueCtx),
+		indirect("SPMCCompactIndirect", spmcC, spmcC.EnqueueCtx, spmcC.DequeueCtx),
+		indirect("SPSCIndirectPureGo", spscG, spscG.EnqueueCtx, spscG.DequeueCtx),
+		ptr("MPMCPtrSeq", mpmcPS, mpmcPS.EnqueueCtx, mpmcPS.DequeueCtx),
+		ptr("MPSCPtrSeq", mpscPS, mpscPS.EnqueueCtx, mpscPS.DequeueCtx),
+		ptr("SPMCPtrSeq", spmcPS, spmcPS.EnqueueCtx, spmcPS.DequeueCtx),
+	}
+}
+
+// TestCtxDeadline verifies EnqueueCtx and DequeueCtx give up with the
+// context error on a full or empty queue, and pass elements otherwise.
+func TestCtxDeadline(t *testing.T) {
+	for _, c := range ctxQueues() {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if _, err := c.dequeue(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("DequeueCtx on empty: got %v, want DeadlineExceeded", err)
+			}
+
+			for i := range 2 {
+				if err := c.enqueue(context.Background(), i+1); err != nil {
+					t.Fatalf("EnqueueCtx(%d): %v", i+1, err)
+				}
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := c.enqueue(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("EnqueueCtx on full: got %v, want DeadlineExceeded", err)
+			}
+
+			for want := 1; want <= 2; want++ {
+				if got, err := c.dequeue(context.Background()); err != nil || got != want {
+					t.Fatalf("DequeueCtx: got (%d, %v), want (%d, nil)", got, err, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCtxCancelUnblocks verifies that canceling the context releases a
+// goroutine waiting in DequeueCtx on an empty queue, then one waiting in
+// EnqueueCtx on a full queue.
+func TestCtxCancelUnblocks(t *testing.T) {
+	for _, c := range ctxQueues() {
+		t.Run(c.name, func(t *testing.T) {
+			waitCanceled := func(op string, call func(context.Context) error) {
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() { done <- call(ctx) }()
+				time.Sleep(5 * time.Millisecond)
+				select {
+				case err := <-done:
+					t.Fatalf("%s returned %v before cancel", op, err)
+				default:
+				}
+				cancel()
+				select {
+				case err := <-done:
+					if !errors.Is(err, context.Canceled) {
+						t.Fatalf("%s after cancel: got %v, want Canceled", op, err)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("%s still blocked 5s after cancel", op)
+				}
+			}
+
+			waitCanceled("DequeueCtx", func(ctx context.Context) error {
+				_, err := c.dequeue(ctx)
+				return err
+			})
+			for i := range 2 {
+				c.enqueue(context.Background(), i)
+			}
+			waitCanceled("EnqueueCtx", func(ctx context.Context) error {
+				return c.enqueue(ctx, 2)
+			})
+		})
+	}
+}
+
+// TestSpinPolicyWakeup verifies a waiting DequeueCtx receives an element
+// enqueued later under each policy.
+func TestSpinPolicyWakeup(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	policies := []struct {
+		name string
+		p    lfq.SpinPolicy
+	}{
+		{"Default", lfq.DefaultSpinPolicy},
+		{"Aggressive", aggressiveSpinPolicy},
+		{"Conservative", conservativeSpinPolicy},
+	}
+	for _, pc := range policies {
+		t.Run(pc.name, func(t *testing.T) {
+			q := lfq.NewMPMC[int](4)
+			q.SetSpinPolicy(pc.p)
+			go func() {
+				time.Sleep(time.Millisecond)
+				v := 7
+				q.Enqueue(&v)
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			v, err := q.DequeueCtx(ctx)
+			if err != nil {
+				t.Fatalf("DequeueCtx: %v", err)
+			}
+			if v != 7 {
+				t.Fatalf("DequeueCtx: got %d, want 7", v)
+			}
+		})
+	}
+}
+
+// BenchmarkSpinPolicy compares spin policies on a 4P×4C MPMC small
+// enough to keep producers and consumers contending.
+//
+//	go test -run=^$ -bench=SpinPolicy -cpu=8
+func BenchmarkSpinPolicy(b *testing.B) {
+	const workers = 4
+	policies := []struct {
+		name string
+		p    lfq.SpinPolicy
+	}{
+		{"Default", lfq.DefaultSpinPolicy},
+		{"Aggressive", aggressiveSpinPolicy},
+		{"Conservative", conservativeSpinPolicy},
+	}
+	for _, pc := range policies {
+		b.Run(pc.name, func(b *testing.B) {
+			q := lfq.NewMPMC[int](16)
+			q.SetSpinPolicy(pc.p)
+			ctx := context.Background()
+			per := max(1, b.N/workers)
+
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			wg.Add(2 * workers)
+			for range workers {
+				go func() {
+					defer wg.Done()
+					for i := range per {
+						q.EnqueueCtx(ctx, &i)
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					for range per {
+						q.DequeueCtx(ctx)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// SPSCProducer is the producer end of an SPSC queue created by NewSPSCSplit.
+//
+// It exposes only Enqueue and Cap, so the consumer side cannot be reached
+// from a value handed to the producer goroutine.
+type SPSCProducer[T any] struct {
+	q *SPSC[T]
+}
+
+// SPSCConsumer is the consumer end of an SPSC queue created by NewSPSCSplit.
+type SPSCConsumer[T any] struct {
+	q *SPSC[T]
+}
+
+// NewSPSCSplit creates an SPSC queue and returns its two ends as distinct
+// types. Passing each end to its own goroutine makes the single-producer,
+// single-consumer contract a type-system guarantee.
+// Capacity rounds up to the next power of 2.
+func NewSPSCSplit[T any](capacity int) (*SPSCProducer[T], *SPSCConsumer[T]) {
+	q := NewSPSC[T](capacity)
+	return &SPSCProducer[T]{q: q}, &SPSCConsumer[T]{q: q}
+}
+
+// Enqueue adds an element to the queue.
+// Returns ErrFull if the queue is full.
+func (p *SPSCProducer[T]) Enqueue(elem *T) error { return p.q.Enqueue(elem) }
+
+// Cap returns the queue capacity.
+func (p *SPSCProducer[T]) Cap() int { return p.q.Cap() }
+
+// Dequeue removes and returns an element.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (c *SPSCConsumer[T]) Dequeue() (T, error) { return c.q.Dequeue() }
+
+// Cap returns the queue capacity.
+func (c *SPSCConsumer[T]) Cap() int { return c.q.Cap() }
+
+// MPSCProducer is the producer end of an MPSC queue created by NewMPSCSplit.
+// It may be shared by any number of producer goroutines.
+type MPSCProducer[T any] struct {
+	q *MPSC[T]
+}
+
+// MPSCConsumer is the consumer end of an MPSC queue created by NewMPSCSplit.
+type MPSCConsumer[T any] struct {
+	q *MPSC[T]
+}
+
+// NewMPSCSplit creates an MPSC queue and returns its two ends as distinct
+// types. The producer end may be shared; the consumer end belongs to a
+// single goroutine.
+// Capacity rounds up to the next power of 2.
+func NewMPSCSplit[T any](capacity int) (*MPSCProducer[T], *MPSCConsumer[T]) {
+	q := NewMPSC[T](capacity)
+	return &MPSCProducer[T]{q: q}, &MPSCConsumer[T]{q: q}
+}
+
+// Enqueue adds an element to the queue.
+// Returns ErrFull if the queue is full.
+func (p *MPSCProducer[T]) Enqueue(elem *T) error { return p.q.Enqueue(elem) }
+
+// Cap returns the queue capacity.
+func (p *MPSCProducer[T]) Cap() int { return p.q.Cap() }
+
+// Dequeue removes and returns an element.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (c *MPSCConsumer[T]) Dequeue() (T, error) { return c.q.Dequeue() }
+
+// Cap returns the queue capacity.
+func (c *MPSCConsumer[T]) Cap() int { return c.q.Cap() }
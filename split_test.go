@@ -0,0 +1,97 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// Each end satisfies only its half of the queue interfaces.
+var (
+	_ lfq.Producer[int] = (*lfq.SPSCProducer[int])(nil)
+	_ lfq.Consumer[int] = (*lfq.SPSCConsumer[int])(nil)
+	_ lfq.Producer[int] = (*lfq.MPSCProducer[int])(nil)
+	_ lfq.Consumer[int] = (*lfq.MPSCConsumer[int])(nil)
+)
+
+// TestSplitMethodSets verifies that producer and consumer ends do not
+// expose each other's methods.
+func TestSplitMethodSets(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   reflect.Type
+		has   string
+		lacks string
+	}{
+		{"SPSCProducer", reflect.TypeFor[*lfq.SPSCProducer[int]](), "Enqueue", "Dequeue"},
+		{"SPSCConsumer", reflect.TypeFor[*lfq.SPSCConsumer[int]](), "Dequeue", "Enqueue"},
+		{"MPSCProducer", reflect.TypeFor[*lfq.MPSCProducer[int]](), "Enqueue", "Dequeue"},
+		{"MPSCConsumer", reflect.TypeFor[*lfq.MPSCConsumer[int]](), "Dequeue", "Enqueue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.typ.NumMethod() != 2 {
+				t.Fatalf("NumMethod: got %d, want 2", tt.typ.NumMethod())
+			}
+			if _, ok := tt.typ.MethodByName(tt.has); !ok {
+				t.Fatalf("missing method %s", tt.has)
+			}
+			if _, ok := tt.typ.MethodByName("Cap"); !ok {
+				t.Fatalf("missing method Cap")
+			}
+			if _, ok := tt.typ.MethodByName(tt.lacks); ok {
+				t.Fatalf("unexpected method %s", tt.lacks)
+			}
+		})
+	}
+}
+
+// TestSPSCSplit tests that both ends share one underlying queue.
+func TestSPSCSplit(t *testing.T) {
+	p, c := lfq.NewSPSCSplit[int](3)
+	if p.Cap() != 4 || c.Cap() != 4 {
+		t.Fatalf("Cap: got (%d, %d), want (4, 4)", p.Cap(), c.Cap())
+	}
+
+	for i := range 4 {
+		if err := p.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	v := 4
+	if err := p.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+	for i := range 4 {
+		got, err := c.Dequeue()
+		if err != nil || got != i {
+			t.Fatalf("Dequeue(%d): got (%d, %v), want (%d, nil)", i, got, err, i)
+		}
+	}
+	if _, err := c.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestMPSCSplit tests that both ends share one underlying queue.
+func TestMPSCSplit(t *testing.T) {
+	p, c := lfq.NewMPSCSplit[string](8)
+	if p.Cap() != 8 || c.Cap() != 8 {
+		t.Fatalf("Cap: got (%d, %d), want (8, 8)", p.Cap(), c.Cap())
+	}
+
+	s := "hello"
+	if err := p.Enqueue(&s); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if got, err := c.Dequeue(); err != nil || got != "hello" {
+		t.Fatalf("Dequeue: got (%q, %v), want (\"hello\", nil)", got, err)
+	}
+}
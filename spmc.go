@@ -7,12 +7,17 @@ package lfq
 import (
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
+	"context"
 )
 
 // SPMC is an FAA-based single-producer multi-consumer bounded queue.
 //
 // Consumers use FAA to blindly claim positions (SCQ-style), requiring 2n
-// physical slots for capacity n.
+// physical slots for capacity n. The producer has no atomic
+// read-modify-write: it publishes a slot with a release store of its
+// cycle and advances tail with a plain store. For CAS-claiming consumers
+// over n slots, use SPMCSeq; BenchmarkAlgorithmComparison/SPMC compares
+// the two up to 1 producer and 8 consumers.
 //
 // Memory: 2n slots for capacity n (16+ bytes per slot)
 type SPMC[T any] struct {
@@ -23,12 +28,14 @@ type SPMC[T any] struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention for consumers
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []spmcSlot[T]
-	capacity  uint64 // n (usable capacity)
-	size      uint64 // 2n (physical slots)
-	mask      uint64 // 2n - 1
+	capacity  uint64          // n (usable capacity)
+	size      uint64          // 2n (physical slots)
+	mask      uint64          // 2n - 1
+	marks     *depthMarks     // Nil unless built WithDepthTracking
+	tune      *thresholdTuner // Nil unless built WithAdaptiveThreshold
 }
 
 type spmcSlot[T any] struct {
@@ -41,7 +48,7 @@ type spmcSlot[T any] struct {
 // Capacity rounds up to the next power of 2.
 func NewSPMC[T any](capacity int) *SPMC[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMC", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -64,13 +71,19 @@ func NewSPMC[T any](capacity int) *SPMC[T] {
 }
 
 // Enqueue adds an element to the queue (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *SPMC[T]) Enqueue(elem *T) error {
+	if debugAssert {
+		defer checkFAA("SPMC", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	tail := q.tail.LoadRelaxed()
 	head := q.head.LoadAcquire()
 
 	if tail >= head+q.capacity {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	cycle := tail / q.capacity
@@ -79,33 +92,61 @@ func (q *SPMC[T]) Enqueue(elem *T) error {
 	slotCycle := slot.cycle.LoadAcquire()
 
 	if slotCycle != cycle {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	slot.data = *elem
 	slot.cycle.StoreRelease(cycle + 1)
 	q.tail.StoreRelaxed(tail + 1)
 
-	q.threshold.StoreRelaxed(3*int64(q.capacity) - 1)
+	q.threshold.StoreRelaxed(q.thresholdLimit())
 
+	if q.marks != nil {
+		q.marks.raise(q.depth())
+	}
 	return nil
 }
 
+// EnqueueValue adds a copy of val (single producer only), without the
+// caller taking an address. It does not allocate.
+func (q *SPMC[T]) EnqueueValue(val T) error {
+	return q.Enqueue(&val)
+}
+
 // Drain signals that no more enqueues will occur.
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *SPMC[T]) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *SPMC[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *SPMC[T]) DequeueInto(dst *T) error {
+	err := q.dequeueInto(dst)
+	if q.tune != nil {
+		q.tune.observe(err)
+	}
+	return err
+}
+
+func (q *SPMC[T]) dequeueInto(dst *T) error {
+	if debugAssert {
+		defer checkFAA("SPMC", q)
+	}
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		var zero T
-		return zero, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		return ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -117,12 +158,15 @@ func (q *SPMC[T]) Dequeue() (T, error) {
 		slotCycle := slot.cycle.LoadAcquire()
 
 		if slotCycle == expectedCycle {
-			elem := slot.data
+			*dst = slot.data
 			var zero T
 			slot.data = zero
 			nextEnqCycle := (myHead + q.size) / q.capacity
 			slot.cycle.StoreRelease(nextEnqCycle)
-			return elem, nil
+			if q.marks != nil {
+				q.marks.lower(q.depth())
+			}
+			return nil
 		}
 
 		if int64(slotCycle) < int64(expectedCycle) {
@@ -134,12 +178,10 @@ func (q *SPMC[T]) Dequeue() (T, error) {
 			if tail <= myHead+1 {
 				q.catchup(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				var zero T
-				return zero, ErrWouldBlock
+				return q.state.emptyErr()
 			}
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				var zero T
-				return zero, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				return ErrEmpty
 			}
 		}
 		sw.Once()
@@ -156,7 +198,65 @@ func (q *SPMC[T]) catchup(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMC[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMC[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMC[T]) Cap() int {
 	return int(q.capacity)
 }
+
+// ThresholdLimit returns the value each Enqueue resets the livelock
+// threshold to: 3n-1 for capacity n, or the current tuned value if the
+// queue was built with [Builder.WithAdaptiveThreshold].
+func (q *SPMC[T]) ThresholdLimit() int {
+	return int(q.thresholdLimit())
+}
+
+func (q *SPMC[T]) thresholdLimit() int64 {
+	if q.tune != nil {
+		return q.tune.limit.Load()
+	}
+	return 3*int64(q.capacity) - 1
+}
+
+func (q *SPMC[T]) thresholdTuner() *thresholdTuner {
+	return q.tune
+}
+
+// MaxDepth returns the highest depth observed after a successful Enqueue,
+// or 0 unless the queue was built with [Builder.WithDepthTracking].
+// The value is advisory and survives Drain until ResetMaxDepth is called.
+func (q *SPMC[T]) MaxDepth() int {
+	return q.marks.maxDepth()
+}
+
+// ResetMaxDepth clears the maximum depth watermark.
+func (q *SPMC[T]) ResetMaxDepth() {
+	q.marks.resetMax()
+}
+
+// MinDepth returns the lowest depth observed after a successful Dequeue,
+// or Cap() before the first Dequeue and unless the queue was built with
+// [Builder.WithDepthTracking]. The value is advisory and survives Drain
+// until ResetMinDepth is called.
+func (q *SPMC[T]) MinDepth() int {
+	return q.marks.minDepth(q.capacity)
+}
+
+// ResetMinDepth resets the minimum depth watermark to Cap().
+func (q *SPMC[T]) ResetMinDepth() {
+	q.marks.resetMin(q.capacity)
+}
@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -19,6 +20,10 @@ import (
 // Entry format: [lo=cycle | hi=value]
 //
 // Memory: 2n slots, 16 bytes per slot
+//
+// Layout: head, tail, threshold and draining each occupy their own cache
+// line; consumers contend on head and threshold, never on the producer's
+// tail line.
 type SPMCIndirect struct {
 	_         pad
 	head      atomix.Uint64 // Consumer index (FAA)
@@ -27,7 +32,7 @@ type SPMCIndirect struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []mpmc128Slot
 	capacity  uint64
@@ -39,7 +44,7 @@ type SPMCIndirect struct {
 // Capacity rounds up to the next power of 2.
 func NewSPMCIndirect(capacity int) *SPMCIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCIndirect", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -68,18 +73,24 @@ func NewSPMCIndirect(capacity int) *SPMCIndirect {
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *SPMCIndirect) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *SPMCIndirect) Enqueue(elem uintptr) error {
+	if debugAssert {
+		defer checkFAA("SPMCIndirect", q)
+	}
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	tail := q.tail.LoadRelaxed()
 	head := q.head.LoadAcquire()
 
 	// Check if full
 	if tail >= head+q.capacity {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	cycle := tail / q.capacity
@@ -88,7 +99,7 @@ func (q *SPMCIndirect) Enqueue(elem uintptr) error {
 	slotCycle, _ := slot.entry.LoadAcquire()
 
 	if slotCycle != cycle {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	// Write value and advance cycle
@@ -102,12 +113,15 @@ func (q *SPMCIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *SPMCIndirect) Dequeue() (uintptr, error) {
+	if debugAssert {
+		defer checkFAA("SPMCIndirect", q)
+	}
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		return 0, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		return 0, ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -140,11 +154,11 @@ func (q *SPMCIndirect) Dequeue() (uintptr, error) {
 				// Queue is empty, help reset indices
 				q.catchup(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				return 0, ErrWouldBlock
+				return 0, q.state.emptyErr()
 			}
 			// Decrement threshold for livelock prevention
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				return 0, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				return 0, ErrEmpty
 			}
 		}
 
@@ -162,6 +176,21 @@ func (q *SPMCIndirect) catchup(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMCIndirect) Cap() int {
 	return int(q.capacity)
@@ -182,7 +211,7 @@ type SPMCPtr struct {
 	_         pad
 	threshold atomix.Int64 // Livelock prevention
 	_         pad
-	draining  atomix.Bool // Drain mode: skip threshold check
+	state     lifecycle // Drain and Close; past Active skips threshold
 	_         pad
 	buffer    []mpmc128Slot
 	capacity  uint64
@@ -194,7 +223,7 @@ type SPMCPtr struct {
 // Capacity rounds up to the next power of 2.
 func NewSPMCPtr(capacity int) *SPMCPtr {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCPtr", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -220,17 +249,30 @@ func NewSPMCPtr(capacity int) *SPMCPtr {
 // After Drain is called, Dequeue skips the threshold check to allow
 // consumers to drain all remaining items without producer pressure.
 func (q *SPMCPtr) Drain() {
-	q.draining.StoreRelease(true)
+	q.state.drain()
 }
 
 // Enqueue adds an element to the queue (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *SPMCPtr) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("SPMCPtr"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *SPMCPtr) EnqueueNilable(elem unsafe.Pointer) error {
+	if err := q.state.enqueueErr(); err != nil {
+		return err
+	}
 	tail := q.tail.LoadRelaxed()
 	head := q.head.LoadAcquire()
 
 	if tail >= head+q.capacity {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	cycle := tail / q.capacity
@@ -239,7 +281,7 @@ func (q *SPMCPtr) Enqueue(elem unsafe.Pointer) error {
 	slotCycle, _ := slot.entry.LoadAcquire()
 
 	if slotCycle != cycle {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	slot.entry.StoreRelease(cycle+1, uint64(uintptr(elem)))
@@ -251,12 +293,12 @@ func (q *SPMCPtr) Enqueue(elem unsafe.Pointer) error {
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *SPMCPtr) Dequeue() (unsafe.Pointer, error) {
 	// Early exit via threshold (livelock prevention)
 	// Skip threshold check in drain mode
-	if !q.draining.LoadAcquire() && q.threshold.LoadRelaxed() < 0 {
-		return nil, ErrWouldBlock
+	if !q.state.draining() && q.threshold.LoadRelaxed() < 0 {
+		return nil, ErrEmpty
 	}
 
 	sw := spin.Wait{}
@@ -283,10 +325,10 @@ func (q *SPMCPtr) Dequeue() (unsafe.Pointer, error) {
 			if tail <= myHead+1 {
 				q.catchupPtr(tail, myHead+1)
 				q.threshold.AddAcqRel(-1)
-				return nil, ErrWouldBlock
+				return nil, q.state.emptyErr()
 			}
-			if q.threshold.AddAcqRel(-1) <= 0 && !q.draining.LoadAcquire() {
-				return nil, ErrWouldBlock
+			if q.threshold.AddAcqRel(-1) <= 0 && !q.state.draining() {
+				return nil, ErrEmpty
 			}
 		}
 
@@ -304,6 +346,21 @@ func (q *SPMCPtr) catchupPtr(tail, head uint64) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCPtr) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCPtr) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMCPtr) Cap() int {
 	return int(q.capacity)
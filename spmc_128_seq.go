@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -33,7 +34,7 @@ type SPMCIndirectSeq struct {
 // Capacity rounds up to the next power of 2.
 func NewSPMCIndirectSeq(capacity int) *SPMCIndirectSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCIndirectSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -51,14 +52,14 @@ func NewSPMCIndirectSeq(capacity int) *SPMCIndirectSeq {
 }
 
 // Enqueue adds an element (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *SPMCIndirectSeq) Enqueue(elem uintptr) error {
 	tail := q.tail.LoadRelaxed()
 	slot := &q.buffer[tail&q.mask]
 	seqLo, _ := slot.entry.LoadAcquire()
 
 	if seqLo != tail {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	slot.entry.StoreRelease(tail+1, uint64(elem))
@@ -68,7 +69,7 @@ func (q *SPMCIndirectSeq) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *SPMCIndirectSeq) Dequeue() (uintptr, error) {
 	sw := spin.Wait{}
 	for {
@@ -76,7 +77,7 @@ func (q *SPMCIndirectSeq) Dequeue() (uintptr, error) {
 		tail := q.tail.LoadAcquire()
 
 		if head >= tail {
-			return 0, ErrWouldBlock
+			return 0, ErrEmpty
 		}
 
 		slot := &q.buffer[head&q.mask]
@@ -88,12 +89,27 @@ func (q *SPMCIndirectSeq) Dequeue() (uintptr, error) {
 				return uintptr(valHi), nil
 			}
 		} else if seqLo < head+1 {
-			return 0, ErrWouldBlock
+			return 0, ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCIndirectSeq) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCIndirectSeq) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMCIndirectSeq) Cap() int {
 	return int(q.capacity)
@@ -119,7 +135,7 @@ type SPMCPtrSeq struct {
 // Capacity rounds up to the next power of 2.
 func NewSPMCPtrSeq(capacity int) *SPMCPtrSeq {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCPtrSeq", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -137,14 +153,24 @@ func NewSPMCPtrSeq(capacity int) *SPMCPtrSeq {
 }
 
 // Enqueue adds an element (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
+// Panics if elem is nil; see [ProducerPtr].
 func (q *SPMCPtrSeq) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("SPMCPtrSeq"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *SPMCPtrSeq) EnqueueNilable(elem unsafe.Pointer) error {
 	tail := q.tail.LoadRelaxed()
 	slot := &q.buffer[tail&q.mask]
 	seqLo, _ := slot.entry.LoadAcquire()
 
 	if seqLo != tail {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	slot.entry.StoreRelease(tail+1, uint64(uintptr(elem)))
@@ -154,7 +180,7 @@ func (q *SPMCPtrSeq) Enqueue(elem unsafe.Pointer) error {
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (nil, ErrWouldBlock) if the queue is empty.
+// Returns (nil, ErrEmpty) if the queue is empty.
 func (q *SPMCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 	sw := spin.Wait{}
 	for {
@@ -162,7 +188,7 @@ func (q *SPMCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 		tail := q.tail.LoadAcquire()
 
 		if head >= tail {
-			return nil, ErrWouldBlock
+			return nil, ErrEmpty
 		}
 
 		slot := &q.buffer[head&q.mask]
@@ -174,12 +200,27 @@ func (q *SPMCPtrSeq) Dequeue() (unsafe.Pointer, error) {
 				return *(*unsafe.Pointer)(unsafe.Pointer(&valHi)), nil
 			}
 		} else if seqLo < head+1 {
-			return nil, ErrWouldBlock
+			return nil, ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCPtrSeq) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCPtrSeq) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMCPtrSeq) Cap() int {
 	return int(q.capacity)
@@ -0,0 +1,141 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"strconv"
+
+	"code.hybscloud.com/atomix"
+)
+
+// CursorID identifies a registered SPMCBroadcast consumer.
+type CursorID int
+
+// SPMCBroadcast is a single-producer queue whose elements are delivered to
+// every consumer, for event fan-out.
+//
+// The producer writes each element once. Every consumer reads the shared
+// slots through its own head cursor, so consumers never contend with one
+// another, and an element stays in its slot until all consumers have
+// moved past it. The ring is full when the slowest consumer is capacity
+// elements behind the producer.
+//
+// All numConsumers cursors hold the ring back from the start, registered
+// or not: a consumer registered late still receives every element, and an
+// idle cursor eventually stalls the producer. Slots are not cleared on
+// dequeue, so elements stay reachable until overwritten.
+//
+// Memory: O(capacity) with no per-slot overhead, plus one cache line per
+// consumer
+type SPMCBroadcast[T any] struct {
+	_          pad
+	tail       atomix.Uint64 // Producer index
+	_          pad
+	cachedMin  uint64 // Producer's cached view of the slowest head
+	_          pad
+	cursors    []broadcastCursor
+	buffer     []T
+	mask       uint64
+	registered atomix.Int64 // Number of cursor IDs handed out
+}
+
+type broadcastCursor struct {
+	head       atomix.Uint64 // Next position, owned by the consumer
+	cachedTail uint64        // Consumer's cached view of tail
+	_          [64 - 16]byte // Pad to cache line
+}
+
+// NewSPMCBroadcast creates a broadcast queue for numConsumers consumers.
+// Capacity rounds up to the next power of 2.
+// Panics if numConsumers < 1.
+func NewSPMCBroadcast[T any](capacity, numConsumers int) *SPMCBroadcast[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPMCBroadcast", "capacity", capacity, 2))
+	}
+	if numConsumers < 1 {
+		panic(belowMinimum("NewSPMCBroadcast", "numConsumers", numConsumers, 1))
+	}
+
+	n := uint64(roundToPow2(capacity))
+	return &SPMCBroadcast[T]{
+		cursors: make([]broadcastCursor, numConsumers),
+		buffer:  make([]T, n),
+		mask:    n - 1,
+	}
+}
+
+// RegisterConsumer claims the next cursor ID.
+// Returns ErrTooManyConsumers once all numConsumers IDs are taken.
+func (q *SPMCBroadcast[T]) RegisterConsumer() (CursorID, error) {
+	id := q.registered.AddAcqRel(1) - 1
+	if id >= int64(len(q.cursors)) {
+		q.registered.AddAcqRel(-1)
+		return -1, ErrTooManyConsumers
+	}
+	return CursorID(id), nil
+}
+
+// cursor returns the cursor of a registered consumer, panicking otherwise.
+func (q *SPMCBroadcast[T]) cursor(id CursorID) *broadcastCursor {
+	if id < 0 || int64(id) >= q.registered.LoadAcquire() || int(id) >= len(q.cursors) {
+		panic("lfq: SPMCBroadcast: cursor " + strconv.Itoa(int(id)) + " not registered")
+	}
+	return &q.cursors[id]
+}
+
+// Enqueue adds an element for all consumers (producer only).
+// Returns ErrFull if the slowest consumer is capacity elements behind.
+func (q *SPMCBroadcast[T]) Enqueue(elem *T) error {
+	tail := q.tail.LoadRelaxed()
+	if tail-q.cachedMin > q.mask {
+		q.cachedMin = q.minHead(tail)
+		if tail-q.cachedMin > q.mask {
+			return ErrFull
+		}
+	}
+
+	q.buffer[tail&q.mask] = *elem
+	q.tail.StoreRelease(tail + 1)
+	return nil
+}
+
+// minHead returns the head of the slowest cursor, at most tail.
+func (q *SPMCBroadcast[T]) minHead(tail uint64) uint64 {
+	m := tail
+	for i := range q.cursors {
+		m = min(m, q.cursors[i].head.LoadAcquire())
+	}
+	return m
+}
+
+// DequeueAs returns the next element for consumer id without removing it
+// for the other consumers. Each id must be used by one goroutine at a
+// time. Returns (zero-value, ErrEmpty) if id has received every element.
+// Panics if id was not returned by RegisterConsumer.
+func (q *SPMCBroadcast[T]) DequeueAs(id CursorID) (T, error) {
+	c := q.cursor(id)
+	head := c.head.LoadRelaxed()
+	if head >= c.cachedTail {
+		c.cachedTail = q.tail.LoadAcquire()
+		if head >= c.cachedTail {
+			var zero T
+			return zero, ErrEmpty
+		}
+	}
+
+	elem := q.buffer[head&q.mask]
+	c.head.StoreRelease(head + 1)
+	return elem, nil
+}
+
+// Cap returns the queue capacity.
+func (q *SPMCBroadcast[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// Consumers returns numConsumers, the number of cursors.
+func (q *SPMCBroadcast[T]) Consumers() int {
+	return len(q.cursors)
+}
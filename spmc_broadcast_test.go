@@ -0,0 +1,122 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPMCBroadcastAllReceive runs a producer and 3 consumers
+// concurrently and verifies every consumer receives all 100 items in
+// order.
+func TestSPMCBroadcastAllReceive(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const items, consumers = 100, 3
+	q := lfq.NewSPMCBroadcast[int](16, consumers)
+
+	var wg sync.WaitGroup
+	got := make([][]int, consumers)
+	for range consumers {
+		id, err := q.RegisterConsumer()
+		if err != nil {
+			t.Fatalf("RegisterConsumer: %v", err)
+		}
+		wg.Go(func() {
+			for len(got[id]) < items {
+				if v, err := q.DequeueAs(id); err == nil {
+					got[id] = append(got[id], v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		})
+	}
+	for i := 0; i < items; {
+		if q.Enqueue(&i) == nil {
+			i++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+
+	for id, vs := range got {
+		for i, v := range vs {
+			if v != i {
+				t.Fatalf("consumer %d: item %d: got %d", id, i, v)
+			}
+		}
+	}
+}
+
+// TestSPMCBroadcastFull verifies that the slowest consumer bounds the
+// producer and that its progress frees slots.
+func TestSPMCBroadcastFull(t *testing.T) {
+	q := lfq.NewSPMCBroadcast[int](4, 2)
+	fast, _ := q.RegisterConsumer()
+	slow, _ := q.RegisterConsumer()
+
+	for i := range 4 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for i := range 4 {
+		if v, err := q.DequeueAs(fast); err != nil || v != i {
+			t.Fatalf("DequeueAs(fast): got (%d, %v), want (%d, nil)", v, err, i)
+		}
+	}
+	if _, err := q.DequeueAs(fast); !lfq.IsEmpty(err) {
+		t.Fatalf("DequeueAs(fast) past tail: got %v, want ErrEmpty", err)
+	}
+
+	v := 4
+	if err := q.Enqueue(&v); !lfq.IsFull(err) {
+		t.Fatalf("Enqueue with slow consumer behind: got %v, want ErrFull", err)
+	}
+	if got, err := q.DequeueAs(slow); err != nil || got != 0 {
+		t.Fatalf("DequeueAs(slow): got (%d, %v), want (0, nil)", got, err)
+	}
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue after slow consumer advanced: %v", err)
+	}
+	for want := 1; want <= 4; want++ {
+		if got, err := q.DequeueAs(slow); err != nil || got != want {
+			t.Fatalf("DequeueAs(slow): got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+}
+
+// TestSPMCBroadcastRegistration tests the ID limit and the unregistered
+// panic.
+func TestSPMCBroadcastRegistration(t *testing.T) {
+	q := lfq.NewSPMCBroadcast[int](4, 2)
+	if q.Consumers() != 2 || q.Cap() != 4 {
+		t.Fatalf("Consumers, Cap: got %d, %d, want 2, 4", q.Consumers(), q.Cap())
+	}
+	for want := range 2 {
+		if id, err := q.RegisterConsumer(); err != nil || int(id) != want {
+			t.Fatalf("RegisterConsumer: got (%d, %v), want (%d, nil)", id, err, want)
+		}
+	}
+	if _, err := q.RegisterConsumer(); !errors.Is(err, lfq.ErrTooManyConsumers) {
+		t.Fatalf("RegisterConsumer beyond max: got %v, want ErrTooManyConsumers", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("DequeueAs with unregistered ID did not panic")
+		}
+	}()
+	q.DequeueAs(2)
+}
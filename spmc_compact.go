@@ -5,6 +5,8 @@
 package lfq
 
 import (
+	"context"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -25,6 +27,8 @@ type SPMCCompactIndirect struct {
 	mask     uint64
 	capacity uint64
 	order    uint64
+	mod      uint64  // capacity if not a power of 2, else 0
+	reserved uintptr // Bits Enqueue rejects; always includes bit 63
 }
 
 // NewSPMCCompactIndirect creates a new compact SPMC queue.
@@ -32,10 +36,13 @@ type SPMCCompactIndirect struct {
 // Values are limited to 63 bits (high bit reserved for empty flag).
 func NewSPMCCompactIndirect(capacity int) *SPMCCompactIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCCompactIndirect", "capacity", capacity, 2))
 	}
+	return newSPMCCompactIndirect(uint64(roundToPow2(capacity)), emptyFlag)
+}
 
-	n := uint64(roundToPow2(capacity))
+// newSPMCCompactIndirect creates the queue with exactly n slots.
+func newSPMCCompactIndirect(n uint64, reserved uintptr) *SPMCCompactIndirect {
 	order := uint64(0)
 	for (1 << order) < n {
 		order++
@@ -46,6 +53,8 @@ func NewSPMCCompactIndirect(capacity int) *SPMCCompactIndirect {
 		mask:     n - 1,
 		capacity: n,
 		order:    order,
+		mod:      exactMod(n),
+		reserved: reserved,
 	}
 
 	for i := range q.buffer {
@@ -56,25 +65,44 @@ func NewSPMCCompactIndirect(capacity int) *SPMCCompactIndirect {
 }
 
 // Enqueue adds a value (single producer only).
-// Values must fit in 63 bits. Returns ErrWouldBlock if the queue is full.
+// Values must fit in 63 bits. Returns ErrFull if the queue is full.
 func (q *SPMCCompactIndirect) Enqueue(elem uintptr) error {
-	if elem&emptyFlag != 0 {
-		panic("lfq: value exceeds 63 bits")
+	if elem&q.reserved != 0 {
+		panic(reservedBitsSet("SPMCCompactIndirect", elem, q.reserved))
 	}
+	return q.enqueue(elem)
+}
 
+// EnqueueMasked adds a value whose reserved bits carry a tag. Bit 63 is
+// dropped before storing and restored by DequeueMasked from the highest
+// other reserved bit, so the two bits must be equal, as in sign-extended
+// addresses; other reserved bits are stored as they are. Panics if the
+// tag cannot be represented. See [Builder.WithUpperBitMask].
+func (q *SPMCCompactIndirect) EnqueueMasked(elem uintptr) error {
+	return q.enqueue(stealTag("SPMCCompactIndirect", elem, q.reserved))
+}
+
+// DequeueMasked removes a value added by EnqueueMasked and restores its
+// bit 63. Returns (0, ErrEmpty) if the queue is empty.
+func (q *SPMCCompactIndirect) DequeueMasked() (uintptr, error) {
+	elem, err := q.Dequeue()
+	return restoreTag(elem, q.reserved), err
+}
+
+func (q *SPMCCompactIndirect) enqueue(elem uintptr) error {
 	tail := q.tail.LoadRelaxed()
 	head := q.head.LoadAcquire()
 
 	if tail >= head+q.capacity {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
-	idx := tail & q.mask
-	round := (tail >> q.order) & (emptyFlag - 1)
+	idx := slotIndex(tail, q.mask, q.mod)
+	round := slotRound(tail, q.order, q.mod) & (emptyFlag - 1)
 	expected := emptyFlag | uintptr(round)
 
 	if !q.buffer[idx].CompareAndSwapAcqRel(expected, elem) {
-		return ErrWouldBlock
+		return ErrFull
 	}
 	q.tail.StoreRelease(tail + 1)
 
@@ -82,7 +110,7 @@ func (q *SPMCCompactIndirect) Enqueue(elem uintptr) error {
 }
 
 // Dequeue removes and returns a value (multiple consumers safe).
-// Returns (0, ErrWouldBlock) if the queue is empty.
+// Returns (0, ErrEmpty) if the queue is empty.
 func (q *SPMCCompactIndirect) Dequeue() (uintptr, error) {
 	sw := spin.Wait{}
 	for {
@@ -90,17 +118,17 @@ func (q *SPMCCompactIndirect) Dequeue() (uintptr, error) {
 		tail := q.tail.LoadAcquire()
 
 		if head >= tail {
-			return 0, ErrWouldBlock
+			return 0, ErrEmpty
 		}
 
-		idx := head & q.mask
+		idx := slotIndex(head, q.mask, q.mod)
 		elem := q.buffer[idx].LoadAcquire()
 
 		if head != q.head.LoadAcquire() {
 			continue
 		}
 
-		currentRound := (head >> q.order) & (emptyFlag - 1)
+		currentRound := slotRound(head, q.order, q.mod) & (emptyFlag - 1)
 		nextRound := (currentRound + 1) & (emptyFlag - 1)
 		nextEmpty := emptyFlag | uintptr(nextRound)
 
@@ -124,6 +152,21 @@ func (q *SPMCCompactIndirect) Dequeue() (uintptr, error) {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCCompactIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCCompactIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns queue capacity.
 func (q *SPMCCompactIndirect) Cap() int {
 	return int(q.capacity)
@@ -0,0 +1,135 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "sync/atomic"
+
+// SPMCOrdered is an SPMC queue that tracks a global commit position.
+//
+// Consumers dequeue concurrently and may finish out of order. Every
+// element carries a sequence number; consumers acknowledge the elements
+// they have finished, and the commit position advances only across a
+// contiguous run of acknowledged sequence numbers. This suits ordered
+// writers that need results in production order while decoding in
+// parallel.
+//
+// At most Cap() elements may be uncommitted at once: Enqueue returns
+// ErrWouldBlock until the commit position catches up.
+//
+// Memory: 2n slots (SPMC) plus n acknowledgement stamps
+type SPMCOrdered[T any] struct {
+	_      pad
+	next   uint64 // Producer's next sequence number
+	_      pad
+	commit atomic.Uint64 // All sequence numbers below commit are acknowledged
+	_      pad
+	q      *SPMC[orderedItem[T]]
+	acks   []atomic.Uint64 // acks[s&mask] == s+1 once s is acknowledged
+	mask   uint64
+}
+
+type orderedItem[T any] struct {
+	seq  uint64
+	data T
+}
+
+// SPMCOrderedConsumer is a per-consumer handle for an SPMCOrdered queue.
+// Each consumer goroutine uses its own handle.
+type SPMCOrderedConsumer[T any] struct {
+	q       *SPMCOrdered[T]
+	pending []uint64 // Dequeued, not yet acknowledged (ascending)
+}
+
+// NewSPMCOrdered creates a new ordered SPMC queue.
+// Capacity rounds up to the next power of 2.
+func NewSPMCOrdered[T any](capacity int) *SPMCOrdered[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPMCOrdered", "capacity", capacity, 2))
+	}
+
+	n := uint64(roundToPow2(capacity))
+	return &SPMCOrdered[T]{
+		q:    NewSPMC[orderedItem[T]](int(n)),
+		acks: make([]atomic.Uint64, n),
+		mask: n - 1,
+	}
+}
+
+// Enqueue adds an element (single producer only) and assigns it the next
+// sequence number. Returns ErrFull if the queue is full or Cap()
+// elements are awaiting acknowledgement.
+func (q *SPMCOrdered[T]) Enqueue(elem *T) error {
+	if q.next-q.commit.Load() > q.mask {
+		return ErrFull
+	}
+	item := orderedItem[T]{seq: q.next, data: *elem}
+	if err := q.q.Enqueue(&item); err != nil {
+		return err
+	}
+	q.next++
+	return nil
+}
+
+// NewConsumer returns a consumer handle.
+func (q *SPMCOrdered[T]) NewConsumer() *SPMCOrderedConsumer[T] {
+	return &SPMCOrderedConsumer[T]{q: q}
+}
+
+// Committed returns the commit position: every sequence number below it
+// has been acknowledged.
+func (q *SPMCOrdered[T]) Committed() uint64 {
+	return q.commit.Load()
+}
+
+// Cap returns the queue capacity.
+func (q *SPMCOrdered[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// advance moves the commit position across acknowledged sequence numbers.
+//
+// acks and commit use sync/atomic, whose operations are sequentially
+// consistent, unlike the relaxed atomix Load and Store: when two consumers
+// acknowledge adjacent sequence numbers at once, each stores its ack
+// before loading, so at least one of them observes both and advances past
+// them. With relaxed accesses both could miss the other's store and leave
+// commit stuck below the gap.
+func (q *SPMCOrdered[T]) advance() {
+	for {
+		c := q.commit.Load()
+		if q.acks[c&q.mask].Load() != c+1 {
+			return
+		}
+		q.commit.CompareAndSwap(c, c+1)
+	}
+}
+
+// Dequeue removes an element and returns it with its sequence number.
+// Returns ErrEmpty if the queue is empty.
+func (c *SPMCOrderedConsumer[T]) Dequeue() (T, uint64, error) {
+	item, err := c.q.q.Dequeue()
+	if err != nil {
+		return item.data, 0, err
+	}
+	c.pending = append(c.pending, item.seq)
+	return item.data, item.seq, nil
+}
+
+// AcknowledgeUpTo acknowledges every element this consumer has dequeued
+// with a sequence number <= seq. The commit position advances once all
+// earlier sequence numbers, from any consumer, are acknowledged.
+func (c *SPMCOrderedConsumer[T]) AcknowledgeUpTo(seq uint64) {
+	n := 0
+	for n < len(c.pending) && c.pending[n] <= seq {
+		s := c.pending[n]
+		c.q.acks[s&c.q.mask].Store(s + 1)
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	c.pending = append(c.pending[:0], c.pending[n:]...)
+	c.q.advance()
+}
@@ -0,0 +1,126 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPMCOrderedCommit verifies out-of-order acknowledgement delays the
+// commit position and in-order acknowledgement advances it.
+func TestSPMCOrderedCommit(t *testing.T) {
+	q := lfq.NewSPMCOrdered[int](4)
+	c1, c2 := q.NewConsumer(), q.NewConsumer()
+
+	for i := range 4 {
+		v := i * 10
+		if err := q.Enqueue(&v); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	// c1 takes seq 0 and 2, c2 takes seq 1 and 3
+	for i, c := range []*lfq.SPMCOrderedConsumer[int]{c1, c2, c1, c2} {
+		v, seq, err := c.Dequeue()
+		if err != nil || seq != uint64(i) || v != i*10 {
+			t.Fatalf("Dequeue(%d): got (%d, %d, %v)", i, v, seq, err)
+		}
+	}
+
+	// Full: all 4 slots await acknowledgement
+	v := 99
+	if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue with uncommitted window full: got %v, want ErrWouldBlock", err)
+	}
+
+	// Out of order: seq 1 and 3 acknowledged, seq 0 missing
+	c2.AcknowledgeUpTo(3)
+	if got := q.Committed(); got != 0 {
+		t.Fatalf("Committed after out-of-order ack: got %d, want 0", got)
+	}
+
+	// seq 0 fills the gap: commit advances through 1, stops at 2
+	c1.AcknowledgeUpTo(0)
+	if got := q.Committed(); got != 2 {
+		t.Fatalf("Committed after ack 0: got %d, want 2", got)
+	}
+
+	// seq 2 completes the run through 3
+	c1.AcknowledgeUpTo(2)
+	if got := q.Committed(); got != 4 {
+		t.Fatalf("Committed after ack 2: got %d, want 4", got)
+	}
+
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue after commit: %v", err)
+	}
+	if _, seq, err := c2.Dequeue(); err != nil || seq != 4 {
+		t.Fatalf("Dequeue after commit: got (%d, %v), want seq 4", seq, err)
+	}
+}
+
+// TestSPMCOrderedConcurrentAck runs consumers that acknowledge in batches
+// of different sizes, so adjacent sequence numbers are acknowledged by
+// different consumers at once, and verifies the commit position reaches
+// the total. A lost acknowledgement would leave Enqueue full forever.
+func TestSPMCOrderedConcurrentAck(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const (
+		total        = 200000
+		numConsumers = 4
+	)
+	q := lfq.NewSPMCOrdered[int](8)
+	var wg sync.WaitGroup
+	for id := range numConsumers {
+		c := q.NewConsumer()
+		wg.Go(func() {
+			var last uint64
+			held := 0
+			for q.Committed() < total {
+				_, seq, err := c.Dequeue()
+				if err == nil {
+					last = seq
+					held++
+				}
+				// Consumer id acknowledges every id+1 elements, and
+				// whatever it holds when the queue runs dry
+				if held > id || (held > 0 && err != nil) {
+					c.AcknowledgeUpTo(last)
+					held = 0
+				}
+				if err != nil {
+					runtime.Gosched()
+				}
+			}
+		})
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for i := 0; i < total; {
+		if q.Enqueue(&i) == nil {
+			i++
+			continue
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Enqueue stuck: committed %d of %d enqueued", q.Committed(), i)
+		}
+		runtime.Gosched()
+	}
+	for q.Committed() < total {
+		if time.Now().After(deadline) {
+			t.Fatalf("Committed: got %d, want %d", q.Committed(), total)
+		}
+		runtime.Gosched()
+	}
+	wg.Wait()
+}
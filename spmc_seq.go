@@ -5,6 +5,8 @@
 package lfq
 
 import (
+	"context"
+
 	"code.hybscloud.com/atomix"
 	"code.hybscloud.com/spin"
 )
@@ -26,6 +28,7 @@ type SPMCSeq[T any] struct {
 	buffer   []spmcSeqSlot[T]
 	mask     uint64
 	capacity uint64
+	mod      uint64 // capacity if not a power of 2, else 0
 }
 
 type spmcSeqSlot[T any] struct {
@@ -39,14 +42,18 @@ type spmcSeqSlot[T any] struct {
 // This is the Compact variant. Use NewSPMC for the default FAA-based implementation.
 func NewSPMCSeq[T any](capacity int) *SPMCSeq[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPMCSeq", "capacity", capacity, 2))
 	}
+	return newSPMCSeq[T](uint64(roundToPow2(capacity)))
+}
 
-	n := uint64(roundToPow2(capacity))
+// newSPMCSeq creates the queue with exactly n slots.
+func newSPMCSeq[T any](n uint64) *SPMCSeq[T] {
 	q := &SPMCSeq[T]{
 		buffer:   make([]spmcSeqSlot[T], n),
 		mask:     n - 1,
 		capacity: n,
+		mod:      exactMod(n),
 	}
 
 	for i := uint64(0); i < n; i++ {
@@ -57,14 +64,14 @@ func NewSPMCSeq[T any](capacity int) *SPMCSeq[T] {
 }
 
 // Enqueue adds an element to the queue (single producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *SPMCSeq[T]) Enqueue(elem *T) error {
 	tail := q.tail.LoadRelaxed()
-	slot := &q.buffer[tail&q.mask]
+	slot := &q.buffer[slotIndex(tail, q.mask, q.mod)]
 	seq := slot.seq.LoadAcquire()
 
 	if seq != tail {
-		return ErrWouldBlock
+		return ErrFull
 	}
 
 	slot.data = *elem
@@ -75,37 +82,59 @@ func (q *SPMCSeq[T]) Enqueue(elem *T) error {
 }
 
 // Dequeue removes and returns an element (multiple consumers safe).
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *SPMCSeq[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *SPMCSeq[T]) DequeueInto(dst *T) error {
 	sw := spin.Wait{}
 	for {
 		head := q.head.LoadAcquire()
 		tail := q.tail.LoadAcquire()
 
 		if head >= tail {
-			var zero T
-			return zero, ErrWouldBlock
+			return ErrEmpty
 		}
 
-		slot := &q.buffer[head&q.mask]
+		slot := &q.buffer[slotIndex(head, q.mask, q.mod)]
 		seq := slot.seq.LoadAcquire()
 
 		if seq == head+1 {
 			if q.head.CompareAndSwapAcqRel(head, head+1) {
-				elem := slot.data
+				*dst = slot.data
 				var zero T
 				slot.data = zero
 				slot.seq.StoreRelease(head + q.capacity)
-				return elem, nil
+				return nil
 			}
 		} else if seq < head+1 {
-			var zero T
-			return zero, ErrWouldBlock
+			return ErrEmpty
 		}
 		sw.Once()
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPMCSeq[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPMCSeq[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPMCSeq[T]) Cap() int {
 	return int(q.capacity)
@@ -5,6 +5,7 @@
 package lfq
 
 import (
+	"context"
 	"unsafe"
 
 	"code.hybscloud.com/atomix"
@@ -17,6 +18,9 @@ import (
 // reducing cross-core cache line traffic.
 //
 // Memory: O(capacity) with minimal per-slot overhead
+//
+// When built with the lfq_spsc_compact tag, SPSC delegates to SPSCCompact
+// instead. The API and FIFO behavior are unchanged.
 type SPSC[T any] struct {
 	_          pad
 	head       atomix.Uint64 // Consumer reads from here
@@ -29,16 +33,20 @@ type SPSC[T any] struct {
 	_          pad
 	buffer     []T
 	mask       uint64
+	compact    *SPSCCompact[T] // Non-nil only with lfq_spsc_compact
 }
 
 // NewSPSC creates a new SPSC queue.
 // Capacity rounds up to the next power of 2.
 func NewSPSC[T any](capacity int) *SPSC[T] {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPSC", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
+	if spscCompact {
+		return &SPSC[T]{compact: newSPSCCompact[T](n), mask: n - 1}
+	}
 	return &SPSC[T]{
 		buffer: make([]T, n),
 		mask:   n - 1,
@@ -46,13 +54,16 @@ func NewSPSC[T any](capacity int) *SPSC[T] {
 }
 
 // Enqueue adds an element to the queue (producer only).
-// Returns ErrWouldBlock if the queue is full.
+// Returns ErrFull if the queue is full.
 func (q *SPSC[T]) Enqueue(elem *T) error {
+	if spscCompact {
+		return q.compact.Enqueue(elem)
+	}
 	tail := q.tail.LoadRelaxed()
 	if tail-q.cachedHead > q.mask {
 		q.cachedHead = q.head.LoadAcquire()
 		if tail-q.cachedHead > q.mask {
-			return ErrWouldBlock
+			return ErrFull
 		}
 	}
 
@@ -61,23 +72,131 @@ func (q *SPSC[T]) Enqueue(elem *T) error {
 	return nil
 }
 
+// EnqueueValue adds a copy of val (producer only). It is Enqueue(&val)
+// for small element types such as int, where taking the caller's address
+// is awkward; val stays on the stack, so the call does not allocate.
+func (q *SPSC[T]) EnqueueValue(val T) error {
+	return q.Enqueue(&val)
+}
+
 // Dequeue removes and returns an element (consumer only).
-// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+// Returns (zero-value, ErrEmpty) if the queue is empty.
 func (q *SPSC[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *SPSC[T]) DequeueInto(dst *T) error {
+	if spscCompact {
+		return q.compact.DequeueInto(dst)
+	}
 	head := q.head.LoadRelaxed()
 	if head >= q.cachedTail {
 		q.cachedTail = q.tail.LoadAcquire()
 		if head >= q.cachedTail {
-			var zero T
-			return zero, ErrWouldBlock
+			return ErrEmpty
 		}
 	}
 
-	elem := q.buffer[head&q.mask]
+	*dst = q.buffer[head&q.mask]
 	var zero T
 	q.buffer[head&q.mask] = zero
 	q.head.StoreRelease(head + 1)
-	return elem, nil
+	return nil
+}
+
+// DequeueMany removes up to len(dst) elements without copying them
+// (consumer only), setting dst[i] to point at each element in its slot,
+// and returns the number removed.
+// Returns (0, ErrEmpty) if the queue is empty.
+//
+// The slots are released to the producer on return, so the pointers stay
+// valid only while no Enqueue runs: the producer may overwrite an element
+// as soon as the ring wraps around to its slot. Use it once the producer
+// has stopped, e.g. to drain the queue. Slots are not cleared, so the
+// elements stay reachable until overwritten.
+func (q *SPSC[T]) DequeueMany(dst []*T) (int, error) {
+	if spscCompact {
+		return q.compact.DequeueMany(dst)
+	}
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	head := q.head.LoadRelaxed()
+	if head+uint64(len(dst)) > q.cachedTail {
+		q.cachedTail = q.tail.LoadAcquire()
+		if head >= q.cachedTail {
+			return 0, ErrEmpty
+		}
+	}
+	n := min(uint64(len(dst)), q.cachedTail-head)
+	for i := range n {
+		dst[i] = &q.buffer[(head+i)&q.mask]
+	}
+	q.head.StoreRelease(head + n)
+	return int(n), nil
+}
+
+// EnqueueAndDepth adds an element (producer only) and returns the queue
+// depth observed immediately after the operation.
+//
+// The depth is advisory: the consumer may dequeue concurrently. On
+// ErrWouldBlock the returned depth is the capacity.
+func (q *SPSC[T]) EnqueueAndDepth(elem *T) (int, error) {
+	if err := q.Enqueue(elem); err != nil {
+		return int(q.mask + 1), err
+	}
+	return q.depth(), nil
+}
+
+// DequeueAndDepth removes an element (consumer only) and returns the
+// queue depth observed immediately after the operation.
+//
+// The depth is advisory: the producer may enqueue concurrently. On
+// ErrWouldBlock the returned depth is 0.
+func (q *SPSC[T]) DequeueAndDepth() (T, int, error) {
+	elem, err := q.Dequeue()
+	if err != nil {
+		return elem, 0, err
+	}
+	return elem, q.depth(), nil
+}
+
+// Snapshot returns a copy of the queued elements in FIFO order without
+// consuming them. It does not modify queue state.
+//
+// Call it from the consumer goroutine. A concurrent Enqueue may or may
+// not be reflected in the result.
+func (q *SPSC[T]) Snapshot() []T {
+	if spscCompact {
+		return q.compact.Snapshot()
+	}
+	head := q.head.LoadRelaxed()
+	tail := q.tail.LoadAcquire()
+	out := make([]T, 0, tail-head)
+	for pos := head; pos < tail; pos++ {
+		out = append(out, q.buffer[pos&q.mask])
+	}
+	return out
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPSC[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPSC[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
 }
 
 // Cap returns the queue capacity.
@@ -86,6 +205,15 @@ func (q *SPSC[T]) Cap() int {
 }
 
 // SPSCIndirect is a SPSC queue for uintptr values.
+//
+// Layout: head and cachedTail belong to the consumer, tail and cachedHead
+// to the producer, and each sits alone on a 64-byte line between pads
+// (offsets 64, 136, 208 and 280 on 64-bit platforms). The assembly in
+// internal/asm hard-codes these offsets, so the fields must not move.
+//
+// Enqueue and Dequeue run that assembly where [IsASMOptimized] reports
+// true. Build with the lfq_noasm tag, or use [SPSCIndirectPureGo], to
+// run the Go implementation instead.
 type SPSCIndirect struct {
 	_          pad
 	head       atomix.Uint64
@@ -104,7 +232,7 @@ type SPSCIndirect struct {
 // Capacity rounds up to the next power of 2.
 func NewSPSCIndirect(capacity int) *SPSCIndirect {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPSCIndirect", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -114,13 +242,67 @@ func NewSPSCIndirect(capacity int) *SPSCIndirect {
 	}
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPSCIndirect) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPSCIndirect) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPSCIndirect) Cap() int {
 	return int(q.mask + 1)
 }
 
+// EnqueueMany adds as many elements from vals as fit (producer only).
+// Returns the number enqueued, and ErrFull if any remained.
+//
+// The elements are copied into consecutive slots and published with a
+// single tail store, so consumers observe them all at once.
+func (q *SPSCIndirect) EnqueueMany(vals []uintptr) (int, error) {
+	if len(vals) == 0 {
+		return 0, nil
+	}
+	tail := q.tail.LoadRelaxed()
+	size := q.mask + 1
+
+	free := size - (tail - q.cachedHead)
+	if free < uint64(len(vals)) {
+		q.cachedHead = q.head.LoadAcquire()
+		free = size - (tail - q.cachedHead)
+	}
+	n := min(free, uint64(len(vals)))
+	if n == 0 {
+		return 0, ErrFull
+	}
+
+	// Copy up to the wrap boundary, then the remainder from the start
+	idx := tail & q.mask
+	first := copy(q.buffer[idx:], vals[:n])
+	copy(q.buffer, vals[first:n])
+	q.tail.StoreRelease(tail + n)
+
+	if n < uint64(len(vals)) {
+		return int(n), ErrFull
+	}
+	return int(n), nil
+}
+
 // SPSCPtr is a SPSC queue for unsafe.Pointer values.
 // Useful for zero-copy pointer passing between goroutines.
+//
+// Its slots are traced by the garbage collector, and Dequeue leaves the
+// pointer in place until a later Enqueue overwrites it, keeping the
+// object alive meanwhile. Build with [Builder.WithGCSafe] to clear slots
+// on Dequeue instead.
 type SPSCPtr struct {
 	_          pad
 	head       atomix.Uint64
@@ -133,13 +315,14 @@ type SPSCPtr struct {
 	_          pad
 	buffer     []unsafe.Pointer
 	mask       uint64
+	gcSafe     bool // Clear slots on Dequeue
 }
 
 // NewSPSCPtr creates a new SPSC queue for unsafe.Pointer values.
 // Capacity rounds up to the next power of 2.
 func NewSPSCPtr(capacity int) *SPSCPtr {
 	if capacity < 2 {
-		panic("lfq: capacity must be >= 2")
+		panic(belowMinimum("NewSPSCPtr", "capacity", capacity, 2))
 	}
 
 	n := uint64(roundToPow2(capacity))
@@ -150,13 +333,23 @@ func NewSPSCPtr(capacity int) *SPSCPtr {
 }
 
 // Enqueue adds an element (producer only).
+// Panics if elem is nil; see [ProducerPtr].
 func (q *SPSCPtr) Enqueue(elem unsafe.Pointer) error {
+	if elem == nil {
+		panic(nilPointer("SPSCPtr"))
+	}
+	return q.EnqueueNilable(elem)
+}
+
+// EnqueueNilable is Enqueue without the nil check, for callers that use
+// nil as a sentinel.
+func (q *SPSCPtr) EnqueueNilable(elem unsafe.Pointer) error {
 	tail := q.tail.LoadRelaxed()
 
 	if tail-q.cachedHead > q.mask {
 		q.cachedHead = q.head.LoadAcquire()
 		if tail-q.cachedHead > q.mask {
-			return ErrWouldBlock
+			return ErrFull
 		}
 	}
 	// Pointer arithmetic avoids slice bounds checking in hot path.
@@ -173,16 +366,35 @@ func (q *SPSCPtr) Dequeue() (unsafe.Pointer, error) {
 	if head >= q.cachedTail {
 		q.cachedTail = q.tail.LoadAcquire()
 		if head >= q.cachedTail {
-			return nil, ErrWouldBlock
+			return nil, ErrEmpty
 		}
 	}
 	// Pointer arithmetic avoids slice bounds checking in hot path.
-	// Equivalent to elem := q.buffer[head&q.mask]
-	elem := *(*unsafe.Pointer)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(q.buffer)), int(head&q.mask)*ptrSize))
+	// Equivalent to slot := &q.buffer[head&q.mask]
+	slot := (*unsafe.Pointer)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(q.buffer)), int(head&q.mask)*ptrSize))
+	elem := *slot
+	if q.gcSafe {
+		*slot = nil
+	}
 	q.head.StoreRelease(head + 1)
 	return elem, nil
 }
 
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPSCPtr) EnqueueCtx(ctx context.Context, elem unsafe.Pointer) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPSCPtr) DequeueCtx(ctx context.Context) (unsafe.Pointer, error) {
+	var elem unsafe.Pointer
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
 // Cap returns the queue capacity.
 func (q *SPSCPtr) Cap() int {
 	return int(q.mask + 1)
@@ -0,0 +1,84 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "sync/atomic"
+
+// SPSCBlocking is an SPSC queue whose consumer can sleep while the queue
+// is empty instead of spinning.
+//
+// Enqueue and Dequeue are the non-blocking SPSC operations. DequeueWait
+// blocks: on Linux the consumer sleeps on a futex and Enqueue wakes it;
+// elsewhere it falls back to yielding with runtime.Gosched.
+//
+// Each Enqueue pays one atomic increment and, while the consumer sleeps,
+// a wake-up system call. Use it for latency-tolerant pipelines such as
+// log processing, where an idle consumer should not burn a core; use SPSC
+// where every nanosecond counts.
+//
+// Memory: O(capacity), as SPSC
+type SPSCBlocking[T any] struct {
+	_       pad
+	seq     uint32 // Futex word, bumped by every Enqueue
+	_       pad
+	waiting uint32 // 1 while the consumer may be sleeping on seq
+	_       pad
+	q       *SPSC[T]
+}
+
+// NewSPSCBlocking creates a new blocking SPSC queue.
+// Capacity rounds up to the next power of 2.
+func NewSPSCBlocking[T any](capacity int) *SPSCBlocking[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCBlocking", "capacity", capacity, 2))
+	}
+	return &SPSCBlocking[T]{q: NewSPSC[T](capacity)}
+}
+
+// Enqueue adds an element (producer only) and wakes a sleeping consumer.
+// Returns ErrFull if the queue is full.
+func (q *SPSCBlocking[T]) Enqueue(elem *T) error {
+	if err := q.q.Enqueue(elem); err != nil {
+		return err
+	}
+	// The increment orders the publish above before the load of waiting,
+	// pairing with the consumer's store to waiting before its re-check
+	atomic.AddUint32(&q.seq, 1)
+	if atomic.LoadUint32(&q.waiting) != 0 {
+		futexWake(&q.seq)
+	}
+	return nil
+}
+
+// Dequeue removes and returns an element (consumer only).
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (q *SPSCBlocking[T]) Dequeue() (T, error) {
+	return q.q.Dequeue()
+}
+
+// DequeueWait removes and returns an element (consumer only), sleeping
+// while the queue is empty.
+func (q *SPSCBlocking[T]) DequeueWait() T {
+	for {
+		if elem, err := q.q.Dequeue(); err == nil {
+			return elem
+		}
+		seq := atomic.LoadUint32(&q.seq)
+		atomic.StoreUint32(&q.waiting, 1)
+		// Re-check: an Enqueue that missed waiting published before it
+		if elem, err := q.q.Dequeue(); err == nil {
+			atomic.StoreUint32(&q.waiting, 0)
+			return elem
+		}
+		// Returns at once if an Enqueue bumped seq since it was read
+		futexWait(&q.seq, seq)
+		atomic.StoreUint32(&q.waiting, 0)
+	}
+}
+
+// Cap returns the queue capacity.
+func (q *SPSCBlocking[T]) Cap() int {
+	return q.q.Cap()
+}
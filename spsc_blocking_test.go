@@ -0,0 +1,119 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/spin"
+)
+
+func TestSPSCBlockingBasic(t *testing.T) {
+	q := lfq.NewSPSCBlocking[int](4)
+	if q.Cap() != 4 {
+		t.Fatalf("Cap: got %d, want 4", q.Cap())
+	}
+	if _, err := q.Dequeue(); err != lfq.ErrWouldBlock {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+	for i := range 4 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	v := 4
+	if err := q.Enqueue(&v); err != lfq.ErrWouldBlock {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+	for i := range 4 {
+		if got := q.DequeueWait(); got != i {
+			t.Fatalf("DequeueWait: got %d, want %d", got, i)
+		}
+	}
+}
+
+// TestSPSCBlockingWakeup verifies a consumer sleeping in DequeueWait
+// receives every element from a slow producer in order.
+func TestSPSCBlockingWakeup(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const n = 200
+	q := lfq.NewSPSCBlocking[int](8)
+	go func() {
+		for i := range n {
+			if i%10 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			for q.Enqueue(&i) != nil {
+				time.Sleep(time.Microsecond)
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range n {
+			if got := q.DequeueWait(); got != i {
+				t.Errorf("DequeueWait: got %d, want %d", got, i)
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("consumer did not wake up")
+	}
+}
+
+// BenchmarkSPSCBlockingLatency measures the delivery latency of elements
+// produced every millisecond, consumed by a spinning SPSC consumer and by
+// a sleeping SPSCBlocking consumer. cpu-ns/op is the process CPU time per
+// element, where the runtime reports it.
+func BenchmarkSPSCBlockingLatency(b *testing.B) {
+	const interval = time.Millisecond
+	run := func(b *testing.B, enqueue func(*int64) error, dequeue func() int64) {
+		go func() {
+			for range b.N {
+				time.Sleep(interval)
+				now := time.Now().UnixNano()
+				for enqueue(&now) != nil {
+				}
+			}
+		}()
+		cpu := processCPUTime()
+		var total time.Duration
+		b.ResetTimer()
+		for range b.N {
+			sent := dequeue()
+			total += time.Duration(time.Now().UnixNano() - sent)
+		}
+		b.StopTimer()
+		b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "latency-ns/op")
+		if cpu >= 0 {
+			b.ReportMetric(float64((processCPUTime()-cpu).Nanoseconds())/float64(b.N), "cpu-ns/op")
+		}
+	}
+
+	b.Run("Spin", func(b *testing.B) {
+		q := lfq.NewSPSC[int64](64)
+		run(b, q.Enqueue, func() int64 {
+			sw := spin.Wait{}
+			for {
+				if v, err := q.Dequeue(); err == nil {
+					return v
+				}
+				sw.Once()
+			}
+		})
+	})
+	b.Run("Futex", func(b *testing.B) {
+		q := lfq.NewSPSCBlocking[int64](64)
+		run(b, q.Enqueue, q.DequeueWait)
+	})
+}
@@ -0,0 +1,113 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// SPSCCoalescing is an SPSC queue that drops an element equal to the most
+// recently enqueued one while that element is still queued.
+//
+// It suits update streams where a burst of identical events carries no
+// more information than one. Only the producer writes the tail, so the
+// newest queued element is stable from the producer's point of view. The
+// consumer may dequeue it concurrently; the comparison then uses a value
+// that was just consumed, which only affects whether the new element is
+// coalesced, never the queue's integrity.
+//
+// Memory: O(capacity) with minimal per-slot overhead
+type SPSCCoalescing[T any] struct {
+	_          pad
+	head       atomix.Uint64 // Consumer reads from here
+	_          pad
+	cachedTail uint64 // Consumer's cached view of tail
+	_          pad
+	tail       atomix.Uint64 // Producer writes here
+	_          pad
+	cachedHead uint64 // Producer's cached view of head
+	_          pad
+	last       T // Copy of the newest enqueued element (producer only)
+	eq         func(existing, elem T) bool
+	buffer     []T
+	mask       uint64
+}
+
+// NewSPSCCoalescing creates a new coalescing SPSC queue that compares
+// elements with ==.
+// Capacity rounds up to the next power of 2.
+func NewSPSCCoalescing[T comparable](capacity int) *SPSCCoalescing[T] {
+	return NewSPSCCoalescingFunc(capacity, func(existing, elem T) bool { return existing == elem })
+}
+
+// NewSPSCCoalescingFunc creates a new coalescing SPSC queue that compares
+// elements with eq, for element types that are not comparable. If eq is
+// nil, Enqueue never coalesces and only CoalesceIf does.
+// Capacity rounds up to the next power of 2.
+func NewSPSCCoalescingFunc[T any](capacity int, eq func(existing, elem T) bool) *SPSCCoalescing[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCCoalescingFunc", "capacity", capacity, 2))
+	}
+
+	n := uint64(roundToPow2(capacity))
+	return &SPSCCoalescing[T]{
+		eq:     eq,
+		buffer: make([]T, n),
+		mask:   n - 1,
+	}
+}
+
+// Enqueue adds an element (producer only). If the queue is non-empty and
+// its newest element equals *elem, the element is discarded and Enqueue
+// returns nil.
+// Returns ErrFull if the queue is full.
+func (q *SPSCCoalescing[T]) Enqueue(elem *T) error {
+	return q.CoalesceIf(elem, q.eq)
+}
+
+// CoalesceIf is Enqueue with a caller-supplied equality. The element is
+// discarded if the queue is non-empty and eq(newest, *elem) reports true.
+// A nil eq never coalesces.
+func (q *SPSCCoalescing[T]) CoalesceIf(elem *T, eq func(existing, elem T) bool) error {
+	tail := q.tail.LoadRelaxed()
+	if tail != q.cachedHead {
+		// Refresh only when the cache says non-empty, so a drained queue
+		// is never coalesced against
+		q.cachedHead = q.head.LoadAcquire()
+	}
+	if eq != nil && tail != q.cachedHead && eq(q.last, *elem) {
+		return nil
+	}
+	if tail-q.cachedHead > q.mask {
+		return ErrFull
+	}
+
+	q.buffer[tail&q.mask] = *elem
+	q.last = *elem
+	q.tail.StoreRelease(tail + 1)
+	return nil
+}
+
+// Dequeue removes and returns an element (consumer only).
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (q *SPSCCoalescing[T]) Dequeue() (T, error) {
+	head := q.head.LoadRelaxed()
+	if head >= q.cachedTail {
+		q.cachedTail = q.tail.LoadAcquire()
+		if head >= q.cachedTail {
+			var zero T
+			return zero, ErrEmpty
+		}
+	}
+
+	elem := q.buffer[head&q.mask]
+	var zero T
+	q.buffer[head&q.mask] = zero
+	q.head.StoreRelease(head + 1)
+	return elem, nil
+}
+
+// Cap returns the queue capacity.
+func (q *SPSCCoalescing[T]) Cap() int {
+	return int(q.mask + 1)
+}
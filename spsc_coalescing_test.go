@@ -0,0 +1,85 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCCoalescing tests that identical adjacent elements collapse into one.
+func TestSPSCCoalescing(t *testing.T) {
+	q := lfq.NewSPSCCoalescing[int](8)
+
+	for _, v := range []int{7, 7, 7, 8} {
+		if err := q.Enqueue(&v); err != nil {
+			t.Fatalf("Enqueue(%d): %v", v, err)
+		}
+	}
+	for _, want := range []int{7, 8} {
+		got, err := q.Dequeue()
+		if err != nil || got != want {
+			t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+
+	// A drained queue does not coalesce against the consumed element
+	v := 8
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if got, err := q.Dequeue(); err != nil || got != 8 {
+		t.Fatalf("Dequeue after drain: got (%d, %v), want (8, nil)", got, err)
+	}
+}
+
+// TestSPSCCoalescingFull tests that a coalesced element succeeds on a full queue.
+func TestSPSCCoalescingFull(t *testing.T) {
+	q := lfq.NewSPSCCoalescing[int](2)
+	for _, v := range []int{1, 2} {
+		q.Enqueue(&v)
+	}
+	v := 2
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue duplicate on full: got %v, want nil", err)
+	}
+	v = 3
+	if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+}
+
+// TestSPSCCoalesceIf tests custom equality for non-comparable elements.
+func TestSPSCCoalesceIf(t *testing.T) {
+	q := lfq.NewSPSCCoalescingFunc[[]int](4, nil)
+	eq := func(existing, elem []int) bool { return slices.Equal(existing, elem) }
+
+	a, b := []int{1, 2}, []int{1, 2}
+	if err := q.Enqueue(&a); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.CoalesceIf(&b, eq); err != nil {
+		t.Fatalf("CoalesceIf: %v", err)
+	}
+	// nil eq never coalesces
+	if err := q.Enqueue(&b); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	for range 2 {
+		if _, err := q.Dequeue(); err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
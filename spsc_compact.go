@@ -0,0 +1,161 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+
+	"code.hybscloud.com/atomix"
+)
+
+// SPSCCompact is a single-producer single-consumer bounded queue that
+// synchronizes through per-slot sequence numbers.
+//
+// It follows the MPMCSeq slot protocol, but each index has a single owner,
+// so the index CAS of MPMCSeq reduces to a plain store. Producer and
+// consumer never read each other's index: a slot's sequence alone decides
+// whether it may be written or read. This keeps FIFO behavior identical to
+// SPSC while relying only on per-slot release/acquire pairs, which makes it
+// the conservative choice where Lamport's cross-index ordering is suspect.
+//
+// Build with the lfq_spsc_compact tag to make NewSPSC use this algorithm.
+//
+// Memory: n slots (16+ bytes per slot)
+type SPSCCompact[T any] struct {
+	_      pad
+	tail   atomix.Uint64 // Producer index, written only by the producer
+	_      pad
+	head   atomix.Uint64 // Consumer index, written only by the consumer
+	_      pad
+	buffer []spscCompactSlot[T]
+	mask   uint64
+}
+
+type spscCompactSlot[T any] struct {
+	seq  atomix.Uint64
+	data T
+	_    padShort // Pad to cache line
+}
+
+// NewSPSCCompact creates a new sequence-based SPSC queue.
+// Capacity rounds up to the next power of 2.
+func NewSPSCCompact[T any](capacity int) *SPSCCompact[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCCompact", "capacity", capacity, 2))
+	}
+	return newSPSCCompact[T](uint64(roundToPow2(capacity)))
+}
+
+// newSPSCCompact creates the queue with n slots; n must be a power of 2.
+func newSPSCCompact[T any](n uint64) *SPSCCompact[T] {
+	q := &SPSCCompact[T]{
+		buffer: make([]spscCompactSlot[T], n),
+		mask:   n - 1,
+	}
+	for i := uint64(0); i < n; i++ {
+		q.buffer[i].seq.StoreRelaxed(i)
+	}
+	return q
+}
+
+// Enqueue adds an element to the queue (producer only).
+// Returns ErrFull if the queue is full.
+func (q *SPSCCompact[T]) Enqueue(elem *T) error {
+	tail := q.tail.LoadRelaxed()
+	slot := &q.buffer[tail&q.mask]
+	if slot.seq.LoadAcquire() != tail {
+		return ErrFull
+	}
+	slot.data = *elem
+	slot.seq.StoreRelease(tail + 1)
+	q.tail.StoreRelaxed(tail + 1)
+	return nil
+}
+
+// Dequeue removes and returns an element (consumer only).
+// Returns (zero-value, ErrEmpty) if the queue is empty.
+func (q *SPSCCompact[T]) Dequeue() (T, error) {
+	var elem T
+	err := q.DequeueInto(&elem)
+	return elem, err
+}
+
+// DequeueInto removes an element and stores it in *dst, sparing large
+// element types the copy through Dequeue's return value.
+// Returns ErrEmpty if the queue is empty, leaving *dst unchanged.
+func (q *SPSCCompact[T]) DequeueInto(dst *T) error {
+	head := q.head.LoadRelaxed()
+	slot := &q.buffer[head&q.mask]
+	if slot.seq.LoadAcquire() != head+1 {
+		return ErrEmpty
+	}
+	*dst = slot.data
+	var zero T
+	slot.data = zero
+	slot.seq.StoreRelease(head + q.mask + 1)
+	q.head.StoreRelaxed(head + 1)
+	return nil
+}
+
+// DequeueMany removes up to len(dst) elements without copying them
+// (consumer only), setting dst[i] to point at each element in its slot.
+// The pointers stay valid only while no Enqueue runs; see
+// [SPSC.DequeueMany]. Returns (0, ErrEmpty) if the queue is empty.
+func (q *SPSCCompact[T]) DequeueMany(dst []*T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	head := q.head.LoadRelaxed()
+	n := 0
+	for ; n < len(dst); n++ {
+		pos := head + uint64(n)
+		slot := &q.buffer[pos&q.mask]
+		if slot.seq.LoadAcquire() != pos+1 {
+			break
+		}
+		dst[n] = &slot.data
+		slot.seq.StoreRelease(pos + q.mask + 1)
+	}
+	if n == 0 {
+		return 0, ErrEmpty
+	}
+	q.head.StoreRelaxed(head + uint64(n))
+	return n, nil
+}
+
+// Snapshot returns a copy of the queued elements in FIFO order without
+// consuming them. Call it from the consumer goroutine.
+func (q *SPSCCompact[T]) Snapshot() []T {
+	head := q.head.LoadRelaxed()
+	var out []T
+	for pos := head; pos-head <= q.mask; pos++ {
+		slot := &q.buffer[pos&q.mask]
+		if slot.seq.LoadAcquire() != pos+1 {
+			break
+		}
+		out = append(out, slot.data)
+	}
+	return out
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPSCCompact[T]) EnqueueCtx(ctx context.Context, elem *T) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPSCCompact[T]) DequeueCtx(ctx context.Context) (T, error) {
+	var elem T
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// Cap returns the queue capacity.
+func (q *SPSCCompact[T]) Cap() int {
+	return int(q.mask + 1)
+}
@@ -0,0 +1,10 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build lfq_spsc_compact
+
+package lfq
+
+// spscCompact makes SPSC delegate to the SPSCCompact algorithm.
+const spscCompact = true
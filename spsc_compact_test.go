@@ -0,0 +1,79 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCCompactBasic tests FIFO order, full and empty on SPSCCompact.
+func TestSPSCCompactBasic(t *testing.T) {
+	q := lfq.NewSPSCCompact[int](3)
+	if q.Cap() != 4 {
+		t.Fatalf("Cap: got %d, want 4", q.Cap())
+	}
+
+	// Several rounds exercise slot sequence reuse
+	for round := range 3 {
+		for i := range 4 {
+			v := round*10 + i
+			if err := q.Enqueue(&v); err != nil {
+				t.Fatalf("Enqueue(%d): %v", v, err)
+			}
+		}
+		v := -1
+		if err := q.Enqueue(&v); !errors.Is(err, lfq.ErrWouldBlock) {
+			t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+		}
+		if s := q.Snapshot(); len(s) != 4 || s[0] != round*10 {
+			t.Fatalf("Snapshot: got %v", s)
+		}
+		for i := range 4 {
+			got, err := q.Dequeue()
+			if err != nil || got != round*10+i {
+				t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, round*10+i)
+			}
+		}
+		if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+			t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+		}
+	}
+}
+
+// TestSPSCCompactConcurrent streams items from one producer to one
+// consumer and verifies FIFO order.
+func TestSPSCCompactConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const n = 100000
+	q := lfq.NewSPSCCompact[int](64)
+	go func() {
+		for i := 0; i < n; {
+			if q.Enqueue(&i) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	for want := 0; want < n; {
+		got, err := q.Dequeue()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		if got != want {
+			t.Fatalf("Dequeue: got %d, want %d", got, want)
+		}
+		want++
+	}
+}
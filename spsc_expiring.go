@@ -0,0 +1,97 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// SPSCExpiring is an SPSC queue whose elements expire if they are not
+// consumed within a fixed time to live.
+//
+// Enqueue stamps each element with its expiry time. Dequeue drops expired
+// elements and returns the first live one, so stale data such as old
+// sensor readings is never processed. Expiry is checked only on the
+// consumer side; an expired element occupies its slot until the consumer
+// reaches it.
+//
+// Memory: O(capacity), each slot holding the element and an 8-byte expiry
+type SPSCExpiring[T any] struct {
+	_       pad
+	expired atomix.Int64 // Elements dropped on expiry
+	_       pad
+	q       *SPSC[expiringItem[T]]
+	ttl     int64
+}
+
+type expiringItem[T any] struct {
+	value  T
+	expiry int64 // Monotonic nanoseconds, see monotime
+}
+
+// NewSPSCExpiring creates an SPSC queue whose elements expire ttl after
+// they are enqueued. Capacity rounds up to the next power of 2.
+// Panics if ttl <= 0.
+func NewSPSCExpiring[T any](capacity int, ttl time.Duration) *SPSCExpiring[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCExpiring", "capacity", capacity, 2))
+	}
+	if ttl <= 0 {
+		panic("lfq: NewSPSCExpiring ttl " + ttl.String() + " must be > 0; see " + docURL + "NewSPSCExpiring")
+	}
+	return &SPSCExpiring[T]{q: NewSPSC[expiringItem[T]](capacity), ttl: int64(ttl)}
+}
+
+// Enqueue adds an element that expires after the queue's TTL (producer only).
+// Returns ErrFull if the queue is full.
+func (q *SPSCExpiring[T]) Enqueue(elem *T) error {
+	item := expiringItem[T]{value: *elem, expiry: monotime() + q.ttl}
+	return q.q.Enqueue(&item)
+}
+
+// Dequeue removes and returns the oldest unexpired element (consumer
+// only), dropping expired elements ahead of it.
+// Returns (zero-value, ErrEmpty) if no unexpired element remains.
+func (q *SPSCExpiring[T]) Dequeue() (T, error) {
+	for {
+		elem, expired, err := q.DequeueOrExpire()
+		if err != nil || !expired {
+			return elem, err
+		}
+	}
+}
+
+// DequeueOrExpire removes the oldest element (consumer only). If it has
+// expired, it is counted as dropped and returned with expired set, so the
+// caller can log it.
+// Returns (zero-value, false, ErrEmpty) if the queue is empty.
+func (q *SPSCExpiring[T]) DequeueOrExpire() (elem T, expired bool, err error) {
+	item, err := q.q.Dequeue()
+	if err != nil {
+		return elem, false, err
+	}
+	if monotime() > item.expiry {
+		q.expired.AddRelaxed(1)
+		return item.value, true, nil
+	}
+	return item.value, false, nil
+}
+
+// ExpiredCount returns the number of elements dropped on expiry.
+func (q *SPSCExpiring[T]) ExpiredCount() int64 {
+	return q.expired.LoadRelaxed()
+}
+
+// TTL returns the time to live of enqueued elements.
+func (q *SPSCExpiring[T]) TTL() time.Duration {
+	return time.Duration(q.ttl)
+}
+
+// Cap returns the queue capacity.
+func (q *SPSCExpiring[T]) Cap() int {
+	return q.q.Cap()
+}
@@ -0,0 +1,60 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCExpiring verifies elements outliving their TTL are dropped and
+// counted, while fresh elements are delivered in order.
+func TestSPSCExpiring(t *testing.T) {
+	q := lfq.NewSPSCExpiring[int](8, 10*time.Millisecond)
+	for i := range 3 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	time.Sleep(15 * time.Millisecond)
+	fresh := 3
+	q.Enqueue(&fresh)
+
+	got, err := q.Dequeue()
+	if err != nil || got != 3 {
+		t.Fatalf("Dequeue: got (%d, %v), want (3, nil)", got, err)
+	}
+	if n := q.ExpiredCount(); n != 3 {
+		t.Fatalf("ExpiredCount: got %d, want 3", n)
+	}
+	if _, err := q.Dequeue(); err != lfq.ErrEmpty {
+		t.Fatalf("Dequeue on empty: got %v, want ErrEmpty", err)
+	}
+}
+
+// TestSPSCExpiringDequeueOrExpire verifies an expired element is returned
+// with its expired flag instead of being skipped.
+func TestSPSCExpiringDequeueOrExpire(t *testing.T) {
+	q := lfq.NewSPSCExpiring[int](4, 10*time.Millisecond)
+	v := 7
+	q.Enqueue(&v)
+	if got, expired, err := q.DequeueOrExpire(); err != nil || expired || got != 7 {
+		t.Fatalf("DequeueOrExpire fresh: got (%d, %v, %v), want (7, false, nil)", got, expired, err)
+	}
+
+	q.Enqueue(&v)
+	time.Sleep(15 * time.Millisecond)
+	if got, expired, err := q.DequeueOrExpire(); err != nil || !expired || got != 7 {
+		t.Fatalf("DequeueOrExpire stale: got (%d, %v, %v), want (7, true, nil)", got, expired, err)
+	}
+	if n := q.ExpiredCount(); n != 1 {
+		t.Fatalf("ExpiredCount: got %d, want 1", n)
+	}
+	if _, expired, err := q.DequeueOrExpire(); err != lfq.ErrEmpty || expired {
+		t.Fatalf("DequeueOrExpire on empty: got (%v, %v), want (false, ErrEmpty)", expired, err)
+	}
+}
@@ -2,7 +2,7 @@
 // Use of this source code is governed by a MIT-style
 // license that can be found in the LICENSE file.
 
-//go:build amd64 || arm64 || riscv64 || loong64
+//go:build (amd64 || arm64 || riscv64 || loong64) && !lfq_noasm
 
 package lfq
 
@@ -12,10 +12,12 @@ import (
 	"code.hybscloud.com/lfq/internal/asm"
 )
 
+const asmOptimized = true
+
 // Enqueue adds an element (producer only).
 func (q *SPSCIndirect) Enqueue(elem uintptr) error {
 	if asm.SPSCEnqueue(uintptr(unsafe.Pointer(q)), elem) != 0 {
-		return ErrWouldBlock
+		return ErrFull
 	}
 	return nil
 }
@@ -24,7 +26,7 @@ func (q *SPSCIndirect) Enqueue(elem uintptr) error {
 func (q *SPSCIndirect) Dequeue() (uintptr, error) {
 	elem, err := asm.SPSCDequeue(uintptr(unsafe.Pointer(q)))
 	if err != 0 {
-		return 0, ErrWouldBlock
+		return 0, ErrEmpty
 	}
 	return elem, nil
 }
@@ -0,0 +1,31 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64) && !lfq_noasm
+
+package lfq
+
+import (
+	"unsafe"
+
+	"code.hybscloud.com/lfq/internal/asm"
+)
+
+// EnqueueBulk16 adds a and b in order, claiming both tail positions at
+// once (producer only). The two values are written with a single 16-byte
+// store; where the positions straddle the end of the buffer, they are
+// enqueued one at a time instead. Both or neither are added.
+// Returns ErrFull if fewer than 2 slots are free.
+func (q *SPSCIndirect) EnqueueBulk16(a, b uintptr) error {
+	switch asm.SPSCEnqueue2(uintptr(unsafe.Pointer(q)), a, b) {
+	case 0:
+		return nil
+	case 1:
+		return ErrFull
+	}
+	// Two slots are free and only the producer fills them
+	q.Enqueue(a)
+	q.Enqueue(b)
+	return nil
+}
@@ -0,0 +1,16 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build lfq_noasm || !(amd64 || arm64)
+
+package lfq
+
+// EnqueueBulk16 adds a and b in order, claiming both tail positions at
+// once (producer only). Without the 16-byte store of the amd64 and arm64
+// assembly, the values are written one at a time after checking that
+// both fit. Both or neither are added.
+// Returns ErrFull if fewer than 2 slots are free.
+func (q *SPSCIndirect) EnqueueBulk16(a, b uintptr) error {
+	return q.enqueueBulk16Go(a, b)
+}
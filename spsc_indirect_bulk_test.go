@@ -0,0 +1,91 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestEnqueueBulk16 enqueues pairs across several wraps, including pairs
+// that straddle the end of the buffer, on SPSCIndirect and its pure Go
+// counterpart.
+func TestEnqueueBulk16(t *testing.T) {
+	type bulkQueue interface {
+		lfq.QueueIndirect
+		EnqueueBulk16(a, b uintptr) error
+	}
+	for _, tc := range []struct {
+		name string
+		q    bulkQueue
+	}{
+		{"SPSCIndirect", lfq.NewSPSCIndirect(8)},
+		{"PureGo", lfq.NewSPSCIndirectPureGo(8)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := tc.q
+
+			// Shift the ring by one so that every fourth pair straddles the end
+			q.Enqueue(0)
+			q.Dequeue()
+
+			next := uintptr(0)
+			for range 10 {
+				for range 3 {
+					if err := q.EnqueueBulk16(next, next+1); err != nil {
+						t.Fatalf("EnqueueBulk16(%d): %v", next, err)
+					}
+					next += 2
+				}
+				// 6 of 8 slots used: one pair fits, the next does not
+				if err := q.EnqueueBulk16(next, next+1); err != nil {
+					t.Fatalf("EnqueueBulk16(%d) into last 2 slots: %v", next, err)
+				}
+				next += 2
+				if err := q.EnqueueBulk16(next, next+1); !lfq.IsFull(err) {
+					t.Fatalf("EnqueueBulk16 on full queue: got %v, want ErrFull", err)
+				}
+				for want := next - 8; want < next; want++ {
+					got, err := q.Dequeue()
+					if err != nil || got != want {
+						t.Fatalf("Dequeue: got (%d, %v), want (%d, nil)", got, err, want)
+					}
+				}
+			}
+
+			// One free slot is not enough for a pair, and nothing is written
+			for i := range 7 {
+				q.Enqueue(uintptr(i))
+			}
+			if err := q.EnqueueBulk16(7, 8); !lfq.IsFull(err) {
+				t.Fatalf("EnqueueBulk16 with 1 free: got %v, want ErrFull", err)
+			}
+			if err := q.Enqueue(7); err != nil {
+				t.Fatalf("Enqueue into last slot: %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkEnqueueBulk16(b *testing.B) {
+	b.Run("Bulk16", func(b *testing.B) {
+		q := lfq.NewSPSCIndirect(1024)
+		for i := range b.N {
+			q.EnqueueBulk16(uintptr(i), uintptr(i))
+			q.Dequeue()
+			q.Dequeue()
+		}
+	})
+	b.Run("Enqueue2x", func(b *testing.B) {
+		q := lfq.NewSPSCIndirect(1024)
+		for i := range b.N {
+			q.Enqueue(uintptr(i))
+			q.Enqueue(uintptr(i))
+			q.Dequeue()
+			q.Dequeue()
+		}
+	})
+}
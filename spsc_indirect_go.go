@@ -0,0 +1,121 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"context"
+	"unsafe"
+)
+
+// SPSCIndirectPureGo is an SPSCIndirect whose operations always run the
+// Go implementation, even where assembly is available. It behaves exactly
+// like SPSCIndirect, and exists to test and benchmark the fallback path
+// on any architecture. Being a separate type, it leaves the SPSCIndirect
+// fast path without a branch.
+type SPSCIndirectPureGo struct {
+	q SPSCIndirect
+}
+
+var _ QueueIndirect = (*SPSCIndirectPureGo)(nil)
+
+// NewSPSCIndirectPureGo creates a new pure Go SPSC queue for uintptr
+// values. Capacity rounds up to the next power of 2.
+func NewSPSCIndirectPureGo(capacity int) *SPSCIndirectPureGo {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCIndirectPureGo", "capacity", capacity, 2))
+	}
+	n := uint64(roundToPow2(capacity))
+	return &SPSCIndirectPureGo{q: SPSCIndirect{buffer: make([]uintptr, n), mask: n - 1}}
+}
+
+// Enqueue adds an element (producer only).
+func (q *SPSCIndirectPureGo) Enqueue(elem uintptr) error {
+	return q.q.enqueueGo(elem)
+}
+
+// Dequeue removes and returns an element (consumer only).
+func (q *SPSCIndirectPureGo) Dequeue() (uintptr, error) {
+	return q.q.dequeueGo()
+}
+
+// EnqueueBulk16 adds a and b in order (producer only). Both or neither
+// are added. Returns ErrFull if fewer than 2 slots are free.
+func (q *SPSCIndirectPureGo) EnqueueBulk16(a, b uintptr) error {
+	return q.q.enqueueBulk16Go(a, b)
+}
+
+// EnqueueCtx is Enqueue that waits while the queue is full. See [MPMC.EnqueueCtx].
+func (q *SPSCIndirectPureGo) EnqueueCtx(ctx context.Context, elem uintptr) error {
+	return retryCtx(ctx, nil, func() error { return q.Enqueue(elem) })
+}
+
+// DequeueCtx is Dequeue that waits while the queue is empty. See [MPMC.DequeueCtx].
+func (q *SPSCIndirectPureGo) DequeueCtx(ctx context.Context) (uintptr, error) {
+	var elem uintptr
+	err := retryCtx(ctx, nil, func() (err error) {
+		elem, err = q.Dequeue()
+		return err
+	})
+	return elem, err
+}
+
+// Cap returns the queue capacity.
+func (q *SPSCIndirectPureGo) Cap() int {
+	return q.q.Cap()
+}
+
+// IsASMOptimized reports whether SPSCIndirect uses assembly on this
+// architecture. It is false on other architectures and in builds with the
+// lfq_noasm tag.
+func IsASMOptimized() bool {
+	return asmOptimized
+}
+
+func (q *SPSCIndirect) enqueueGo(elem uintptr) error {
+	tail := q.tail.LoadRelaxed()
+
+	if tail-q.cachedHead > q.mask {
+		q.cachedHead = q.head.LoadAcquire()
+		if tail-q.cachedHead > q.mask {
+			return ErrFull
+		}
+	}
+
+	// Bounds check eliminated: tail&mask is always < len(buffer)
+	// because mask = len(buffer)-1 and x&mask <= mask
+	*(*uintptr)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(q.buffer)), int(tail&q.mask)*ptrSize)) = elem
+	q.tail.StoreRelease(tail + 1)
+	return nil
+}
+
+// enqueueBulk16Go checks for two free slots, then enqueues a and b.
+func (q *SPSCIndirect) enqueueBulk16Go(a, b uintptr) error {
+	tail := q.tail.LoadRelaxed()
+	if tail+1-q.cachedHead > q.mask {
+		q.cachedHead = q.head.LoadAcquire()
+		if tail+1-q.cachedHead > q.mask {
+			return ErrFull
+		}
+	}
+	q.enqueueGo(a)
+	q.enqueueGo(b)
+	return nil
+}
+
+func (q *SPSCIndirect) dequeueGo() (uintptr, error) {
+	head := q.head.LoadRelaxed()
+
+	if head >= q.cachedTail {
+		q.cachedTail = q.tail.LoadAcquire()
+		if head >= q.cachedTail {
+			return 0, ErrEmpty
+		}
+	}
+
+	// Bounds check eliminated: head&mask is always < len(buffer)
+	elem := *(*uintptr)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(q.buffer)), int(head&q.mask)*ptrSize))
+	q.head.StoreRelease(head + 1)
+	return elem, nil
+}
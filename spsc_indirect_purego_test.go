@@ -0,0 +1,72 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCIndirectPureGo applies the same random sequence of operations
+// to a queue on the default path and one on the pure Go path, and
+// verifies every result matches.
+func TestSPSCIndirectPureGo(t *testing.T) {
+	def := lfq.NewSPSCIndirect(16)
+	pure := lfq.NewSPSCIndirectPureGo(16)
+	if def.Cap() != pure.Cap() {
+		t.Fatalf("Cap: got %d and %d", def.Cap(), pure.Cap())
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range 100000 {
+		if rng.IntN(2) == 0 {
+			v := uintptr(rng.Uint64())
+			e1, e2 := def.Enqueue(v), pure.Enqueue(v)
+			if e1 != e2 {
+				t.Fatalf("op %d: Enqueue: got %v and %v", i, e1, e2)
+			}
+			continue
+		}
+		v1, e1 := def.Dequeue()
+		v2, e2 := pure.Dequeue()
+		if v1 != v2 || e1 != e2 {
+			t.Fatalf("op %d: Dequeue: got (%d, %v) and (%d, %v)", i, v1, e1, v2, e2)
+		}
+	}
+}
+
+func TestIsASMOptimized(t *testing.T) {
+	supported := []string{"amd64", "arm64", "riscv64", "loong64"}
+	if lfq.IsASMOptimized() && !slices.Contains(supported, runtime.GOARCH) {
+		t.Fatalf("IsASMOptimized: true on %s", runtime.GOARCH)
+	}
+	if err := lfq.NewSPSCIndirectPureGo(4).Enqueue(1); err != nil {
+		t.Fatalf("Enqueue on pure Go queue: %v", err)
+	}
+}
+
+// BenchmarkSPSCIndirectPath compares an Enqueue/Dequeue pair on the
+// default path, assembly where IsASMOptimized, with the pure Go path.
+func BenchmarkSPSCIndirectPath(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		q    lfq.QueueIndirect
+	}{
+		{"Default", lfq.NewSPSCIndirect(1024)},
+		{"PureGo", lfq.NewSPSCIndirectPureGo(1024)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			q := bc.q
+			for i := range b.N {
+				q.Enqueue(uintptr(i))
+				q.Dequeue()
+			}
+		})
+	}
+}
@@ -392,3 +392,43 @@ func TestSPSCIndirectBuilderInterface(t *testing.T) {
 		t.Fatalf("got %d, want 100", val)
 	}
 }
+
+// TestSPSCIndirectEnqueueMany tests batch enqueue across the wrap boundary
+// and partial batches on a nearly full queue.
+func TestSPSCIndirectEnqueueMany(t *testing.T) {
+	q := lfq.NewSPSCIndirect(8)
+
+	if n, err := q.EnqueueMany(nil); n != 0 || err != nil {
+		t.Fatalf("EnqueueMany(nil): got (%d, %v), want (0, nil)", n, err)
+	}
+
+	// Advance head and tail so the next batch wraps
+	for i := range 5 {
+		q.Enqueue(uintptr(i))
+		q.Dequeue()
+	}
+
+	vals := []uintptr{10, 11, 12, 13, 14, 15}
+	if n, err := q.EnqueueMany(vals); n != 6 || err != nil {
+		t.Fatalf("EnqueueMany: got (%d, %v), want (6, nil)", n, err)
+	}
+	if n, err := q.EnqueueMany([]uintptr{16, 17, 18, 19}); n != 2 || !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("EnqueueMany on nearly full: got (%d, %v), want (2, ErrWouldBlock)", n, err)
+	}
+	if n, err := q.EnqueueMany([]uintptr{99}); n != 0 || !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("EnqueueMany on full: got (%d, %v), want (0, ErrWouldBlock)", n, err)
+	}
+
+	for i := range 8 {
+		val, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue(%d): %v", i, err)
+		}
+		if val != uintptr(10+i) {
+			t.Fatalf("Dequeue(%d): got %d, want %d", i, val, 10+i)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+}
@@ -0,0 +1,83 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+// SPSCPool is a sharded free list of pool indices, following the per-CPU
+// memory pool pattern.
+//
+// Indices 0 to poolSize-1 start spread evenly over the shards. A client
+// keeps to one shard, e.g. by worker number, so Get and Put mostly touch
+// that shard's cache lines. Put spills to the following shards when its
+// shard is full, and Get steals from them when its shard is empty.
+//
+// Go has no goroutine IDs, so the caller chooses shards. Stealing lets
+// several goroutines use a shard at once, which a single-producer,
+// single-consumer ring does not allow: each shard is an MPMCIndirect,
+// and a client that never leaves its shard pays only uncontended FAAs.
+//
+// Memory: numShards × 2(poolSize/numShards) slots, rounded up to powers of 2
+type SPSCPool struct {
+	shards []*MPMCIndirect
+	size   int
+}
+
+// NewSPSCPool creates a pool of indices 0 to poolSize-1 over numShards
+// shards. Panics if numShards < 1 or poolSize < 1.
+func NewSPSCPool(numShards, poolSize int) *SPSCPool {
+	if numShards < 1 {
+		panic(belowMinimum("NewSPSCPool", "numShards", numShards, 1))
+	}
+	if poolSize < 1 {
+		panic(belowMinimum("NewSPSCPool", "poolSize", poolSize, 1))
+	}
+	per := max(2, (poolSize+numShards-1)/numShards)
+	p := &SPSCPool{shards: make([]*MPMCIndirect, numShards), size: poolSize}
+	for i := range p.shards {
+		p.shards[i] = NewMPMCIndirect(per)
+	}
+	for idx := range poolSize {
+		p.shards[idx%numShards].Enqueue(uintptr(idx))
+	}
+	return p
+}
+
+// Get takes a free index, from shard if it has one, otherwise from the
+// following shards in turn. Returns false if every shard is empty.
+// Any shard is valid; it is reduced modulo Shards().
+func (p *SPSCPool) Get(shard int) (uintptr, bool) {
+	n := len(p.shards)
+	start := int(uint(shard) % uint(n))
+	for i := range n {
+		if idx, err := p.shards[(start+i)%n].Dequeue(); err == nil {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// Put returns idx to shard, spilling to the following shards if it is
+// full. Returns false if every shard is full, which means idx was not
+// obtained from Get or was put twice.
+// Any shard is valid; it is reduced modulo Shards().
+func (p *SPSCPool) Put(shard int, idx uintptr) bool {
+	n := len(p.shards)
+	start := int(uint(shard) % uint(n))
+	for i := range n {
+		if p.shards[(start+i)%n].Enqueue(idx) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Shards returns the number of shards.
+func (p *SPSCPool) Shards() int {
+	return len(p.shards)
+}
+
+// Size returns the number of indices the pool manages.
+func (p *SPSCPool) Size() int {
+	return p.size
+}
@@ -0,0 +1,85 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"sync"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCPoolStealing tests that Get steals from other shards once its
+// own is empty and that Put spills over when its shard is full.
+func TestSPSCPoolStealing(t *testing.T) {
+	p := lfq.NewSPSCPool(4, 8)
+	got := make(map[uintptr]bool)
+	for range 8 {
+		idx, ok := p.Get(0)
+		if !ok || got[idx] {
+			t.Fatalf("Get: got (%d, %v), duplicate=%v", idx, ok, got[idx])
+		}
+		got[idx] = true
+	}
+	if _, ok := p.Get(0); ok {
+		t.Fatal("Get on exhausted pool: got ok")
+	}
+	for idx := range got {
+		if !p.Put(1, idx) {
+			t.Fatalf("Put(%d): got false", idx)
+		}
+	}
+	// Each shard holds 2, so the returned indices fill the pool
+	if p.Put(1, 99) {
+		t.Fatal("Put on full pool: got true")
+	}
+}
+
+// TestSPSCPoolConcurrent runs 8 goroutines over 8 shards, each cycling
+// Get and Put, and verifies that every index is back in the pool at the
+// end with none duplicated.
+func TestSPSCPoolConcurrent(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+	const shards, size, cycles = 8, 64, 10000
+	p := lfq.NewSPSCPool(shards, size)
+
+	var wg sync.WaitGroup
+	for g := range shards {
+		wg.Go(func() {
+			held := make([]uintptr, 0, 4)
+			for i := range cycles {
+				if idx, ok := p.Get(g); ok {
+					held = append(held, idx)
+				}
+				if len(held) == cap(held) || i == cycles-1 {
+					for _, idx := range held {
+						if !p.Put(g, idx) {
+							t.Errorf("Put(%d, %d): got false", g, idx)
+						}
+					}
+					held = held[:0]
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	seen := make([]bool, size)
+	for range size {
+		idx, ok := p.Get(0)
+		if !ok {
+			t.Fatal("slot leak: pool exhausted before all indices returned")
+		}
+		if seen[idx] {
+			t.Fatalf("index %d returned twice", idx)
+		}
+		seen[idx] = true
+	}
+	if idx, ok := p.Get(0); ok {
+		t.Fatalf("extra index %d after draining the pool", idx)
+	}
+}
@@ -0,0 +1,74 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// SPSCRawBuffer exposes the primitives of an SPSC ring buffer: the backing
+// array and the two positions, for callers that write and read elements
+// in place, such as zero-copy media pipelines.
+//
+// Positions increase monotonically; position p lives at Buffer()[p%Cap()].
+// The producer writes elements at positions from ProducerHead onward and
+// publishes them with ProducerAdvance. The consumer reads elements from
+// ConsumerHead up to ProducerHead and releases them with ConsumerAdvance.
+//
+// No bounds are checked. The caller must keep ProducerHead - ConsumerHead
+// within [0, Cap()], must not touch elements it does not own, and must
+// split accesses that cross the end of the array. The advance operations
+// publish with the same release/acquire ordering as SPSC's Enqueue and
+// Dequeue.
+//
+// Memory: O(capacity), no per-slot overhead
+type SPSCRawBuffer[T any] struct {
+	_      pad
+	head   atomix.Uint64 // Consumer position
+	_      pad
+	tail   atomix.Uint64 // Producer position
+	_      pad
+	buffer []T
+}
+
+// NewSPSCRawBuffer creates a new raw SPSC ring buffer.
+// Capacity rounds up to the next power of 2.
+func NewSPSCRawBuffer[T any](capacity int) *SPSCRawBuffer[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCRawBuffer", "capacity", capacity, 2))
+	}
+	return &SPSCRawBuffer[T]{buffer: make([]T, roundToPow2(capacity))}
+}
+
+// Buffer returns the full backing array. Its length is Cap().
+func (q *SPSCRawBuffer[T]) Buffer() []T {
+	return q.buffer
+}
+
+// ProducerHead returns the position of the next element to be written.
+// Elements before it are visible to the consumer.
+func (q *SPSCRawBuffer[T]) ProducerHead() uint64 {
+	return q.tail.LoadAcquire()
+}
+
+// ProducerAdvance publishes the next n written elements (producer only).
+func (q *SPSCRawBuffer[T]) ProducerAdvance(n uint64) {
+	q.tail.StoreRelease(q.tail.LoadRelaxed() + n)
+}
+
+// ConsumerHead returns the position of the next element to be read.
+// Elements before it may be overwritten by the producer.
+func (q *SPSCRawBuffer[T]) ConsumerHead() uint64 {
+	return q.head.LoadAcquire()
+}
+
+// ConsumerAdvance releases the next n read elements to the producer
+// (consumer only).
+func (q *SPSCRawBuffer[T]) ConsumerAdvance(n uint64) {
+	q.head.StoreRelease(q.head.LoadRelaxed() + n)
+}
+
+// Cap returns the buffer capacity.
+func (q *SPSCRawBuffer[T]) Cap() int {
+	return len(q.buffer)
+}
@@ -0,0 +1,86 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCRawBuffer writes elements directly at ProducerHead and reads
+// them back at ConsumerHead, crossing the end of the array.
+func TestSPSCRawBuffer(t *testing.T) {
+	q := lfq.NewSPSCRawBuffer[int](6)
+	if q.Cap() != 8 || len(q.Buffer()) != 8 {
+		t.Fatalf("Cap: got %d (buffer %d), want 8", q.Cap(), len(q.Buffer()))
+	}
+	buf, size := q.Buffer(), uint64(q.Cap())
+
+	for round := range 3 {
+		for i := range 5 {
+			buf[(q.ProducerHead()+uint64(i))%size] = round*10 + i
+		}
+		q.ProducerAdvance(5)
+		if got := q.ProducerHead() - q.ConsumerHead(); got != 5 {
+			t.Fatalf("round %d: available: got %d, want 5", round, got)
+		}
+		for i := range 5 {
+			if got := buf[(q.ConsumerHead()+uint64(i))%size]; got != round*10+i {
+				t.Fatalf("round %d: element %d: got %d, want %d", round, i, got, round*10+i)
+			}
+		}
+		q.ConsumerAdvance(5)
+		if q.ConsumerHead() != q.ProducerHead() {
+			t.Fatalf("round %d: heads differ after consuming all", round)
+		}
+	}
+	if q.ProducerHead() != 15 {
+		t.Fatalf("ProducerHead: got %d, want 15", q.ProducerHead())
+	}
+}
+
+// TestSPSCRawBufferStream streams a sequence between two goroutines using
+// only the raw primitives.
+func TestSPSCRawBufferStream(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const total = 100000
+	q := lfq.NewSPSCRawBuffer[int](64)
+	buf, size := q.Buffer(), uint64(q.Cap())
+	go func() {
+		for next := uint64(0); next < total; {
+			free := size - (next - q.ConsumerHead())
+			if free == 0 {
+				runtime.Gosched()
+				continue
+			}
+			n := min(free, 7, total-next)
+			for i := range n {
+				buf[(next+i)%size] = int(next + i)
+			}
+			q.ProducerAdvance(n)
+			next += n
+		}
+	}()
+
+	for want := uint64(0); want < total; {
+		avail := q.ProducerHead() - want
+		if avail == 0 {
+			runtime.Gosched()
+			continue
+		}
+		for i := range avail {
+			if got := buf[(want+i)%size]; got != int(want+i) {
+				t.Fatalf("got %d, want %d", got, want+i)
+			}
+		}
+		q.ConsumerAdvance(avail)
+		want += avail
+	}
+}
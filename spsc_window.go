@@ -0,0 +1,134 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import "code.hybscloud.com/atomix"
+
+// SPSCRingWindow is an SPSC ring buffer that hands out windows into its
+// backing array, so producer and consumer work on elements in place.
+//
+// The producer reserves a window with ReserveWrite, fills it and publishes
+// it with CommitWrite. The consumer reserves filled elements with
+// ReserveRead, processes them and releases them with CommitRead. A window
+// never wraps: near the end of the array a reservation returns a shorter
+// slice, and the caller reserves again for the remainder.
+//
+// The synchronization is Lamport's, as in SPSC: a window is private to
+// its side until committed.
+//
+// Memory: O(capacity), no per-slot overhead
+type SPSCRingWindow[T any] struct {
+	_          pad
+	head       atomix.Uint64 // Consumer reads from here
+	_          pad
+	cachedTail uint64 // Consumer's cached view of tail
+	rreserved  uint64 // Length of the outstanding read window
+	_          pad
+	tail       atomix.Uint64 // Producer writes here
+	_          pad
+	cachedHead uint64 // Producer's cached view of head
+	wreserved  uint64 // Length of the outstanding write window
+	_          pad
+	buffer     []T
+	mask       uint64
+}
+
+// NewSPSCRingWindow creates a new windowed SPSC ring buffer.
+// Capacity rounds up to the next power of 2.
+func NewSPSCRingWindow[T any](capacity int) *SPSCRingWindow[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewSPSCRingWindow", "capacity", capacity, 2))
+	}
+
+	n := uint64(roundToPow2(capacity))
+	return &SPSCRingWindow[T]{
+		buffer: make([]T, n),
+		mask:   n - 1,
+	}
+}
+
+// ReserveWrite returns a window of up to n free elements at the tail
+// (producer only). The window is shorter than n if less space is free or
+// the array ends first. Elements in the window hold stale or zero values.
+// Returns ErrWouldBlock if the ring is full.
+//
+// A new reservation replaces any uncommitted one.
+func (q *SPSCRingWindow[T]) ReserveWrite(n int) ([]T, error) {
+	tail := q.tail.LoadRelaxed()
+	size := q.mask + 1
+	if tail-q.cachedHead+uint64(n) > size {
+		q.cachedHead = q.head.LoadAcquire()
+	}
+	free := size - (tail - q.cachedHead)
+	idx := tail & q.mask
+	k := min(uint64(max(n, 0)), free, size-idx)
+	q.wreserved = k
+	if k == 0 {
+		if free == 0 {
+			return nil, ErrFull
+		}
+		return nil, nil
+	}
+	return q.buffer[idx : idx+k : idx+k], nil
+}
+
+// CommitWrite publishes the first n elements of the write window to the
+// consumer (producer only). Panics if n exceeds the reservation.
+func (q *SPSCRingWindow[T]) CommitWrite(n int) {
+	checkCommit("SPSCRingWindow.CommitWrite", n, q.wreserved)
+	q.wreserved = 0
+	q.tail.StoreRelease(q.tail.LoadRelaxed() + uint64(n))
+}
+
+// ReserveRead returns a window of up to n filled elements at the head
+// (consumer only). The window is shorter than n if fewer elements are
+// available or the array ends first. The elements stay in the ring until
+// CommitRead. Returns ErrWouldBlock if the ring is empty.
+//
+// A new reservation replaces any uncommitted one.
+func (q *SPSCRingWindow[T]) ReserveRead(n int) ([]T, error) {
+	head := q.head.LoadRelaxed()
+	if q.cachedTail-head < uint64(max(n, 1)) {
+		q.cachedTail = q.tail.LoadAcquire()
+	}
+	avail := q.cachedTail - head
+	idx := head & q.mask
+	k := min(uint64(max(n, 0)), avail, q.mask+1-idx)
+	q.rreserved = k
+	if k == 0 {
+		if avail == 0 {
+			return nil, ErrEmpty
+		}
+		return nil, nil
+	}
+	return q.buffer[idx : idx+k : idx+k], nil
+}
+
+// CommitRead releases the first n elements of the read window back to the
+// producer (consumer only). The released slots are cleared so they do not
+// retain references. Panics if n exceeds the reservation.
+func (q *SPSCRingWindow[T]) CommitRead(n int) {
+	checkCommit("SPSCRingWindow.CommitRead", n, q.rreserved)
+	q.rreserved = 0
+	head := q.head.LoadRelaxed()
+	idx := head & q.mask
+	clear(q.buffer[idx : idx+uint64(n)])
+	q.head.StoreRelease(head + uint64(n))
+}
+
+// Cap returns the ring capacity.
+func (q *SPSCRingWindow[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// checkCommit panics unless 0 <= n <= reserved for commit method fn.
+func checkCommit(fn string, n int, reserved uint64) {
+	if n < 0 {
+		panic(belowMinimum(fn, "n", n, 0))
+	}
+	if uint64(n) > reserved {
+		panic(aboveMaximum(fn, "n", n, int(reserved)))
+	}
+}
@@ -0,0 +1,122 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestSPSCRingWindowBoundary tests short windows at the wrap boundary and
+// full/empty reporting.
+func TestSPSCRingWindowBoundary(t *testing.T) {
+	q := lfq.NewSPSCRingWindow[int](8)
+
+	w, err := q.ReserveWrite(6)
+	if err != nil || len(w) != 6 {
+		t.Fatalf("ReserveWrite(6): got (%d, %v), want (6, nil)", len(w), err)
+	}
+	for i := range w {
+		w[i] = i
+	}
+	q.CommitWrite(6)
+
+	r, err := q.ReserveRead(8)
+	if err != nil || len(r) != 6 || r[5] != 5 {
+		t.Fatalf("ReserveRead(8): got (%v, %v), want 6 elements", r, err)
+	}
+	q.CommitRead(6)
+
+	// Tail is at index 6: only 2 elements fit before the array ends
+	w, _ = q.ReserveWrite(5)
+	if len(w) != 2 {
+		t.Fatalf("ReserveWrite at boundary: got %d elements, want 2", len(w))
+	}
+	w[0], w[1] = 6, 7
+	q.CommitWrite(2)
+	w, _ = q.ReserveWrite(3)
+	if len(w) != 3 {
+		t.Fatalf("ReserveWrite after wrap: got %d elements, want 3", len(w))
+	}
+	w[0], w[1], w[2] = 8, 9, 10
+	q.CommitWrite(3)
+
+	if _, err := q.ReserveWrite(4); err != nil {
+		t.Fatalf("ReserveWrite with 3 free: %v", err)
+	}
+	q.CommitWrite(0)
+
+	r, _ = q.ReserveRead(5)
+	if len(r) != 2 || r[0] != 6 || r[1] != 7 {
+		t.Fatalf("ReserveRead at boundary: got %v, want [6 7]", r)
+	}
+	q.CommitRead(2)
+	r, _ = q.ReserveRead(5)
+	if len(r) != 3 || r[2] != 10 {
+		t.Fatalf("ReserveRead after wrap: got %v, want [8 9 10]", r)
+	}
+	q.CommitRead(3)
+
+	if _, err := q.ReserveRead(1); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("ReserveRead on empty: got %v, want ErrWouldBlock", err)
+	}
+	for range 2 {
+		w, _ := q.ReserveWrite(8)
+		q.CommitWrite(len(w))
+	}
+	if _, err := q.ReserveWrite(1); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("ReserveWrite on full: got %v, want ErrWouldBlock", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("CommitWrite beyond reservation did not panic")
+		}
+	}()
+	q.CommitWrite(1)
+}
+
+// TestSPSCRingWindowStream writes a sequence through reserved windows on
+// one goroutine and verifies it through read windows on another.
+func TestSPSCRingWindowStream(t *testing.T) {
+	if lfq.RaceEnabled {
+		t.Skip("skip: lock-free algorithm uses cross-variable memory ordering")
+	}
+
+	const total = 100000
+	q := lfq.NewSPSCRingWindow[int](64)
+	go func() {
+		for next := 0; next < total; {
+			w, err := q.ReserveWrite(min(17, total-next))
+			if err != nil {
+				runtime.Gosched()
+				continue
+			}
+			for i := range w {
+				w[i] = next + i
+			}
+			q.CommitWrite(len(w))
+			next += len(w)
+		}
+	}()
+
+	for want := 0; want < total; {
+		r, err := q.ReserveRead(13)
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		for _, v := range r {
+			if v != want {
+				t.Fatalf("got %d, want %d", v, want)
+			}
+			want++
+		}
+		q.CommitRead(len(r))
+	}
+}
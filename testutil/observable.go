@@ -0,0 +1,173 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package testutil
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// EventKind distinguishes the operations recorded by [ObservableQueue].
+type EventKind uint8
+
+const (
+	// EventEnqueue records a successful Enqueue.
+	EventEnqueue EventKind = iota + 1
+	// EventDequeue records a successful Dequeue.
+	EventDequeue
+)
+
+// String returns "enqueue" or "dequeue".
+func (k EventKind) String() string {
+	switch k {
+	case EventEnqueue:
+		return "enqueue"
+	case EventDequeue:
+		return "dequeue"
+	}
+	return "unknown"
+}
+
+// QueueEvent is one successful operation on an [ObservableQueue].
+type QueueEvent[T any] struct {
+	Kind  EventKind
+	Time  time.Time
+	Value T
+
+	// Seq numbers enqueued items from 1 in enqueue order. A dequeue event
+	// carries the Seq of the item it removed, or 0 if the value matches
+	// no outstanding enqueue.
+	Seq uint64
+
+	// Delay is the time the item spent in the queue; dequeue events only.
+	Delay time.Duration
+}
+
+// ObservableQueue wraps a queue and records every successful Enqueue and
+// Dequeue, for asserting on the flow of items in integration tests.
+//
+// Each operation runs under a mutex together with its recording, so the
+// history is a linearization of the inner queue's operations. That
+// serializes all callers: the wrapper is for tests, not production.
+//
+// A dequeued value is attributed to the oldest outstanding enqueue with
+// an equal value, compared with reflect.DeepEqual.
+type ObservableQueue[T any] struct {
+	q lfq.Queue[T]
+
+	mu      sync.Mutex
+	seq     uint64
+	history []QueueEvent[T]
+	pending []QueueEvent[T] // Enqueue events not yet matched, in order
+}
+
+// NewObservableQueue returns an ObservableQueue recording operations on q.
+func NewObservableQueue[T any](q lfq.Queue[T]) *ObservableQueue[T] {
+	return &ObservableQueue[T]{q: q}
+}
+
+// Enqueue adds an element to the inner queue and records it on success.
+func (o *ObservableQueue[T]) Enqueue(elem *T) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.q.Enqueue(elem); err != nil {
+		return err
+	}
+	o.seq++
+	ev := QueueEvent[T]{Kind: EventEnqueue, Time: time.Now(), Value: *elem, Seq: o.seq}
+	o.history = append(o.history, ev)
+	o.pending = append(o.pending, ev)
+	return nil
+}
+
+// Dequeue removes an element from the inner queue and records it on
+// success.
+func (o *ObservableQueue[T]) Dequeue() (T, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	elem, err := o.q.Dequeue()
+	if err != nil {
+		return elem, err
+	}
+	ev := QueueEvent[T]{Kind: EventDequeue, Time: time.Now(), Value: elem}
+	for i, p := range o.pending {
+		if reflect.DeepEqual(p.Value, elem) {
+			ev.Seq = p.Seq
+			ev.Delay = ev.Time.Sub(p.Time)
+			o.pending = append(o.pending[:i], o.pending[i+1:]...)
+			break
+		}
+	}
+	o.history = append(o.history, ev)
+	return elem, nil
+}
+
+// Drain forwards to the inner queue if it implements [lfq.Drainer], and
+// does nothing otherwise.
+func (o *ObservableQueue[T]) Drain() {
+	if d, ok := o.q.(lfq.Drainer); ok {
+		d.Drain()
+	}
+}
+
+// Cap returns the capacity of the inner queue.
+func (o *ObservableQueue[T]) Cap() int {
+	return o.q.Cap()
+}
+
+// History returns a copy of the recorded events in the order they
+// occurred.
+func (o *ObservableQueue[T]) History() []QueueEvent[T] {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]QueueEvent[T](nil), o.history...)
+}
+
+// AssertFIFO fails t unless items were dequeued in the order they were
+// enqueued, and every dequeued value matched an enqueue.
+func (o *ObservableQueue[T]) AssertFIFO(t testing.TB) {
+	t.Helper()
+	var last uint64
+	for i, ev := range o.History() {
+		if ev.Kind != EventDequeue {
+			continue
+		}
+		if ev.Seq == 0 {
+			t.Errorf("event %d: dequeued %v, which was never enqueued", i, ev.Value)
+			continue
+		}
+		if ev.Seq < last {
+			t.Errorf("event %d: dequeued item %d (%v) after item %d", i, ev.Seq, ev.Value, last)
+		}
+		last = max(last, ev.Seq)
+	}
+}
+
+// AssertNoDrops fails t for each enqueued item that has not been
+// dequeued.
+func (o *ObservableQueue[T]) AssertNoDrops(t testing.TB) {
+	t.Helper()
+	o.mu.Lock()
+	pending := append([]QueueEvent[T](nil), o.pending...)
+	o.mu.Unlock()
+	for _, ev := range pending {
+		t.Errorf("item %d (%v) enqueued at %v was never dequeued", ev.Seq, ev.Value, ev.Time.Format(time.RFC3339Nano))
+	}
+}
+
+// AssertMaxLatency fails t for each item that spent longer than d in the
+// queue. Items still queued are not checked; combine with AssertNoDrops.
+func (o *ObservableQueue[T]) AssertMaxLatency(t testing.TB, d time.Duration) {
+	t.Helper()
+	for _, ev := range o.History() {
+		if ev.Kind == EventDequeue && ev.Seq != 0 && ev.Delay > d {
+			t.Errorf("item %d (%v) waited %v, want at most %v", ev.Seq, ev.Value, ev.Delay, d)
+		}
+	}
+}
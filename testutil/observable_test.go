@@ -0,0 +1,168 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package testutil_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/testutil"
+)
+
+// stack is a LIFO lfq.Queue, for checking that ObservableQueue attributes
+// dequeues to the right enqueue.
+type stack struct{ items []int }
+
+func (s *stack) Enqueue(elem *int) error {
+	if len(s.items) == 4 {
+		return lfq.ErrFull
+	}
+	s.items = append(s.items, *elem)
+	return nil
+}
+
+func (s *stack) Dequeue() (int, error) {
+	if len(s.items) == 0 {
+		return 0, lfq.ErrEmpty
+	}
+	v := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return v, nil
+}
+
+func (s *stack) Cap() int { return 4 }
+
+func TestObservableQueueHistory(t *testing.T) {
+	o := testutil.NewObservableQueue[int](&stack{})
+	for _, v := range []int{10, 20, 10} {
+		if err := o.Enqueue(&v); err != nil {
+			t.Fatalf("Enqueue(%d): %v", v, err)
+		}
+	}
+	for range 2 {
+		if _, err := o.Dequeue(); err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+	}
+	if _, err := o.Dequeue(); err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	if _, err := o.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrEmpty", err)
+	}
+
+	// The stack returns 10, 20, 10. Equal values are attributed oldest
+	// first, so the first 10 maps to item 1 and 20 to item 2.
+	want := []struct {
+		kind  testutil.EventKind
+		value int
+		seq   uint64
+	}{
+		{testutil.EventEnqueue, 10, 1},
+		{testutil.EventEnqueue, 20, 2},
+		{testutil.EventEnqueue, 10, 3},
+		{testutil.EventDequeue, 10, 1},
+		{testutil.EventDequeue, 20, 2},
+		{testutil.EventDequeue, 10, 3},
+	}
+	h := o.History()
+	if len(h) != len(want) {
+		t.Fatalf("History: got %d events, want %d", len(h), len(want))
+	}
+	for i, w := range want {
+		ev := h[i]
+		if ev.Kind != w.kind || ev.Value != w.value || ev.Seq != w.seq {
+			t.Errorf("event %d: got %v %d seq %d, want %v %d seq %d",
+				i, ev.Kind, ev.Value, ev.Seq, w.kind, w.value, w.seq)
+		}
+		if ev.Kind == testutil.EventDequeue && ev.Delay < 0 {
+			t.Errorf("event %d: negative delay %v", i, ev.Delay)
+		}
+	}
+}
+
+func TestObservableQueueLIFODetected(t *testing.T) {
+	o := testutil.NewObservableQueue[int](&stack{})
+	for v := range 3 {
+		o.Enqueue(&v)
+	}
+	var seqs []uint64
+	for range 3 {
+		o.Dequeue()
+	}
+	for _, ev := range o.History() {
+		if ev.Kind == testutil.EventDequeue {
+			seqs = append(seqs, ev.Seq)
+		}
+	}
+	if len(seqs) != 3 || seqs[0] != 3 || seqs[1] != 2 || seqs[2] != 1 {
+		t.Fatalf("dequeue seqs: got %v, want [3 2 1]", seqs)
+	}
+
+	r := &recorder{TB: t}
+	o.AssertFIFO(r)
+	if len(r.errs) != 2 {
+		t.Fatalf("AssertFIFO on LIFO order: got %d errors %q, want 2", len(r.errs), r.errs)
+	}
+}
+
+// recorder is a testing.TB that collects Errorf messages instead of
+// failing, for checking that assertions report.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestObservableQueueAssertNoDrops(t *testing.T) {
+	o := testutil.NewObservableQueue[int](&stack{})
+	for v := range 3 {
+		o.Enqueue(&v)
+	}
+	o.Dequeue()
+
+	r := &recorder{TB: t}
+	o.AssertNoDrops(r)
+	if len(r.errs) != 2 {
+		t.Fatalf("AssertNoDrops with 2 queued: got %d errors %q, want 2", len(r.errs), r.errs)
+	}
+}
+
+func TestObservableQueuePipeline(t *testing.T) {
+	const items = 1000
+	o := testutil.NewObservableQueue[int](lfq.NewMPSC[int](64))
+
+	var wg sync.WaitGroup
+	for p := range 4 {
+		wg.Go(func() {
+			for i := range items / 4 {
+				v := p*items + i
+				for o.Enqueue(&v) != nil {
+					time.Sleep(time.Microsecond)
+				}
+			}
+		})
+	}
+	for got := 0; got < items; {
+		if _, err := o.Dequeue(); err == nil {
+			got++
+		} else {
+			time.Sleep(time.Microsecond)
+		}
+	}
+	wg.Wait()
+
+	o.AssertFIFO(t)
+	o.AssertNoDrops(t)
+	o.AssertMaxLatency(t, time.Minute)
+}
@@ -0,0 +1,183 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package testutil provides compliance checks for lfq queue implementations.
+//
+// Wrappers and adapters built on lfq queues can run these checks from
+// their own tests to confirm they preserve the contracts of [lfq.Queue]
+// and [lfq.Drainer]:
+//
+//	func TestMyQueue(t *testing.T) {
+//	    testutil.VerifyQueueCompliance(t, NewMyQueue[int](8), func(i int) int { return i })
+//	    testutil.VerifyDrainerCompliance(t, NewMyQueue[int](8))
+//	}
+//
+// Each check expects a fresh, empty queue and leaves it empty on success.
+// The checks and assertions take a [testing.TB], so benchmarks can verify
+// the queue they measure with the same helpers.
+//
+// [ObservableQueue] wraps a queue inside a pipeline under test and records
+// the items passing through it, for assertions on order, loss and latency.
+//
+// # The lfq benchmark protocol
+//
+// [BenchmarkEnqueueDequeue] is the benchmark body lfq measures its own
+// queues with. A queue implementation outside lfq runs it next to the
+// built-in types to obtain directly comparable numbers:
+//
+//	func BenchmarkMyQueue(b *testing.B) {
+//	    b.Run("MyQueue", func(b *testing.B) { testutil.BenchmarkEnqueueDequeue(b, NewMyQueue[int](1024)) })
+//	    b.Run("lfq.MPMC", func(b *testing.B) { testutil.BenchmarkEnqueueDequeue(b, lfq.NewMPMC[int](1024)) })
+//	}
+//
+// Under the protocol the queue is half filled before the timer starts, and
+// one operation is an Enqueue followed by a Dequeue from a single
+// goroutine, so the ring never runs empty or full. Each dequeued value is
+// checked against FIFO order. Results are comparable only at equal
+// capacity, on the same machine and with the same GOMAXPROCS.
+package testutil
+
+import (
+	"reflect"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// drainerItems is the number of items VerifyDrainerCompliance enqueues.
+const drainerItems = 8
+
+// VerifyDrainerCompliance checks that Drain lets consumers retrieve every
+// remaining item after the threshold has been exhausted.
+//
+// It drives the threshold down with repeated dequeues on the empty queue,
+// enqueues 8 items, calls Drain, and then requires all 8 items to dequeue
+// followed by an empty report. q must be empty and have capacity of at
+// least 8; Enqueue must not be called on it afterwards.
+func VerifyDrainerCompliance[T any](t testing.TB, q interface {
+	lfq.Queue[T]
+	lfq.Drainer
+}) {
+	t.Helper()
+	if q.Cap() < drainerItems {
+		t.Fatalf("Cap: got %d, want at least %d", q.Cap(), drainerItems)
+	}
+
+	// Far more failed dequeues than any threshold allows
+	for range 4 * q.Cap() {
+		if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+			t.Fatalf("Dequeue on empty queue: got %v, want ErrEmpty", err)
+		}
+	}
+
+	var zero T
+	for i := range drainerItems {
+		if err := q.Enqueue(&zero); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	q.Drain()
+
+	for i := range drainerItems {
+		if _, err := q.Dequeue(); err != nil {
+			t.Fatalf("Dequeue(%d) after Drain: %v", i, err)
+		}
+	}
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue after draining: got %v, want ErrEmpty", err)
+	}
+}
+
+// VerifyQueueCompliance checks the single-goroutine contract of a queue:
+// Cap reports the number of items Enqueue accepts, a full queue rejects
+// Enqueue with ErrFull, items come out in FIFO order, and an empty queue
+// rejects Dequeue with ErrEmpty.
+//
+// newItem(i) supplies the i-th item; items are compared with
+// reflect.DeepEqual. The queue is filled and emptied twice so that the
+// second pass crosses the ring boundary. q must be empty.
+func VerifyQueueCompliance[T any](t testing.TB, q lfq.Queue[T], newItem func(int) T) {
+	t.Helper()
+	n := q.Cap()
+	if n <= 0 {
+		t.Fatalf("Cap: got %d, want positive", n)
+	}
+
+	if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+		t.Fatalf("Dequeue on new queue: got %v, want ErrEmpty", err)
+	}
+
+	for pass := range 2 {
+		base := pass * n
+		for i := range n {
+			item := newItem(base + i)
+			if err := q.Enqueue(&item); err != nil {
+				t.Fatalf("pass %d: Enqueue(%d) of %d: %v", pass, i, n, err)
+			}
+		}
+		extra := newItem(base + n)
+		if err := q.Enqueue(&extra); !lfq.IsFull(err) {
+			t.Fatalf("pass %d: Enqueue on full queue: got %v, want ErrFull", pass, err)
+		}
+
+		for i := range n {
+			got, err := q.Dequeue()
+			if err != nil {
+				t.Fatalf("pass %d: Dequeue(%d) of %d: %v", pass, i, n, err)
+			}
+			if want := newItem(base + i); !reflect.DeepEqual(got, want) {
+				t.Fatalf("pass %d: Dequeue(%d): got %v, want %v", pass, i, got, want)
+			}
+		}
+		if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+			t.Fatalf("pass %d: Dequeue on drained queue: got %v, want ErrEmpty", pass, err)
+		}
+	}
+}
+
+// BenchmarkEnqueueDequeue is the body of the lfq benchmark protocol,
+// described in the package documentation. It reports ns and allocations
+// per Enqueue and Dequeue pair. q must be empty with capacity at least 2;
+// it is empty again when the benchmark returns.
+func BenchmarkEnqueueDequeue(b *testing.B, q lfq.Queue[int]) {
+	b.Helper()
+	n := q.Cap()
+	if n < 2 {
+		b.Fatalf("Cap: got %d, want at least 2", n)
+	}
+
+	// v lives outside the loop so passing &v through the interface
+	// allocates once, not per operation
+	var v int
+	fill := n / 2
+	for v = range fill {
+		if err := q.Enqueue(&v); err != nil {
+			b.Fatalf("prefill Enqueue(%d): %v", v, err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		v = fill + i
+		if err := q.Enqueue(&v); err != nil {
+			b.Fatalf("Enqueue(%d): %v", v, err)
+		}
+		got, err := q.Dequeue()
+		if err != nil {
+			b.Fatalf("Dequeue(%d): %v", i, err)
+		}
+		if got != i {
+			b.Fatalf("Dequeue(%d): got %d, want %d", i, got, i)
+		}
+	}
+	b.StopTimer()
+
+	for i := range fill {
+		if _, err := q.Dequeue(); err != nil {
+			b.Fatalf("draining Dequeue(%d) of %d: %v", i, fill, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package testutil_test
+
+import (
+	"strconv"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/testutil"
+)
+
+func TestVerifyQueueCompliance(t *testing.T) {
+	item := func(i int) string { return strconv.Itoa(i) }
+	tests := []struct {
+		name string
+		q    lfq.Queue[string]
+	}{
+		{"SPSC", lfq.NewSPSC[string](8)},
+		{"MPSC", lfq.NewMPSC[string](8)},
+		{"SPMC", lfq.NewSPMC[string](8)},
+		{"MPMC", lfq.NewMPMC[string](8)},
+		{"MPSCSeq", lfq.NewMPSCSeq[string](8)},
+		{"SPMCSeq", lfq.NewSPMCSeq[string](8)},
+		{"MPMCSeq", lfq.NewMPMCSeq[string](8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.VerifyQueueCompliance(t, tt.q, item)
+		})
+	}
+}
+
+func TestVerifyDrainerCompliance(t *testing.T) {
+	t.Run("MPSC", func(t *testing.T) {
+		testutil.VerifyDrainerCompliance[int](t, lfq.NewMPSC[int](8))
+	})
+	t.Run("SPMC", func(t *testing.T) {
+		testutil.VerifyDrainerCompliance[int](t, lfq.NewSPMC[int](8))
+	})
+	t.Run("MPMC", func(t *testing.T) {
+		testutil.VerifyDrainerCompliance[int](t, lfq.NewMPMC[int](16))
+	})
+}
+
+// BenchmarkEnqueueDequeue runs the lfq benchmark protocol on the built-in
+// queues, as the reference for other implementations.
+func BenchmarkEnqueueDequeue(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		q    lfq.Queue[int]
+	}{
+		{"SPSC", lfq.NewSPSC[int](1024)},
+		{"MPSC", lfq.NewMPSC[int](1024)},
+		{"SPMC", lfq.NewSPMC[int](1024)},
+		{"MPMC", lfq.NewMPMC[int](1024)},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			testutil.BenchmarkEnqueueDequeue(b, bm.q)
+		})
+	}
+}
+
+// TestBenchmarkEnqueueDequeue runs the protocol under testing.Benchmark and
+// checks the queue is left empty and verifiable from a *testing.B.
+func TestBenchmarkEnqueueDequeue(t *testing.T) {
+	q := lfq.NewMPMC[int](16)
+	res := testing.Benchmark(func(b *testing.B) {
+		testutil.BenchmarkEnqueueDequeue(b, q)
+		testutil.VerifyQueueCompliance(b, q, func(i int) int { return i })
+	})
+	if res.N == 0 {
+		t.Fatal("benchmark failed")
+	}
+	if got := res.AllocsPerOp(); got != 0 {
+		t.Fatalf("AllocsPerOp: got %d, want 0", got)
+	}
+}
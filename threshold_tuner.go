@@ -0,0 +1,91 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// thresholdTuneInterval is how often an adaptive threshold is adjusted.
+const thresholdTuneInterval = 100 * time.Millisecond
+
+// thresholdMaxFactor bounds an adaptive threshold: it ranges from the
+// default 3n-1, reached when no Dequeue is blocked, to thresholdMaxFactor
+// times that, reached when every Dequeue is.
+const thresholdMaxFactor = 4
+
+// thresholdTuner adjusts the value an FAA queue resets its livelock
+// threshold to, from the share of Dequeue calls that report empty.
+//
+// A background goroutine samples the counters every
+// thresholdTuneInterval and folds the blocked ratio of the interval into
+// an exponential moving average. The goroutine references only the tuner,
+// so the queue can be collected; a cleanup on the queue then stops it.
+// The fields shared with it use sync/atomic, which the race detector
+// understands, as each is a single independent value.
+type thresholdTuner struct {
+	_        pad
+	attempts atomic.Uint64 // Dequeue calls
+	_        pad
+	blocked  atomic.Uint64 // Dequeue calls that returned an error
+	_        pad
+	limit    atomic.Int64 // Value Enqueue resets the threshold to
+	_        pad
+	base     int64   // 3n-1, the SCQ bound and the floor of limit
+	alpha    float64 // Weight of the newest interval in the average
+	stop     chan struct{}
+}
+
+// startThresholdTuner attaches a tuner for capacity n to q and starts its
+// goroutine, which stops once q is collected.
+func startThresholdTuner[Q any](q *Q, n uint64, alpha float64) *thresholdTuner {
+	t := &thresholdTuner{base: 3*int64(n) - 1, alpha: alpha, stop: make(chan struct{})}
+	t.limit.Store(t.base)
+	runtime.AddCleanup(q, func(stop chan struct{}) { close(stop) }, t.stop)
+	go t.run()
+	return t
+}
+
+// observe counts one Dequeue call.
+func (t *thresholdTuner) observe(err error) {
+	t.attempts.Add(1)
+	if err != nil {
+		t.blocked.Add(1)
+	}
+}
+
+// ceiling returns the largest limit the tuner sets.
+func (t *thresholdTuner) ceiling() int64 {
+	return thresholdMaxFactor * t.base
+}
+
+func (t *thresholdTuner) run() {
+	tick := time.NewTicker(thresholdTuneInterval)
+	defer tick.Stop()
+	var attempts, blocked uint64
+	ema, seeded := 0.0, false
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-tick.C:
+		}
+		a, b := t.attempts.Load(), t.blocked.Load()
+		da, db := a-attempts, b-blocked
+		attempts, blocked = a, b
+		if da == 0 {
+			continue // An idle interval says nothing about consumer lag
+		}
+		ratio := float64(db) / float64(da)
+		if seeded {
+			ema += t.alpha * (ratio - ema)
+		} else {
+			ema, seeded = ratio, true
+		}
+		t.limit.Store(t.base + int64(ema*float64((thresholdMaxFactor-1)*t.base)))
+	}
+}
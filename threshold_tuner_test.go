@@ -0,0 +1,105 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestAdaptiveThresholdConverges drives two MPMC queues with workloads
+// that keep them 50% and 90% full: each round enqueues that share of the
+// capacity and then makes capacity Dequeue calls, so 50% and 10% of the
+// calls find the queue empty. Within 5 adjustment cycles each limit must
+// settle near base + ratio*3*base for its ratio.
+func TestAdaptiveThresholdConverges(t *testing.T) {
+	const capacity = 64
+	const base = 3*capacity - 1
+	tests := []struct {
+		name    string
+		fill    int
+		blocked float64
+	}{
+		{"50%", capacity / 2, 0.5},
+		{"90%", capacity * 9 / 10, float64(capacity-capacity*9/10) / capacity},
+	}
+
+	queues := make([]*lfq.MPMC[int], len(tests))
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, tt := range tests {
+		q := lfq.Unrecorded(lfq.BuildMPMC[int](lfq.New(capacity).WithAdaptiveThreshold(0.5))).(*lfq.MPMC[int])
+		if got := q.ThresholdLimit(); got != base {
+			t.Fatalf("%s: initial ThresholdLimit: got %d, want %d", tt.name, got, base)
+		}
+		queues[i] = q
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for j := range tt.fill {
+					q.Enqueue(&j)
+				}
+				for range capacity {
+					q.Dequeue()
+				}
+			}
+		})
+	}
+	time.Sleep(5*100*time.Millisecond + 50*time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	limits := make([]int, len(tests))
+	for i, tt := range tests {
+		limits[i] = queues[i].ThresholdLimit()
+		want := base + tt.blocked*3*base
+		if got := float64(limits[i]); got < 0.9*want || got > 1.1*want {
+			t.Errorf("%s: ThresholdLimit: got %d, want about %.0f", tt.name, limits[i], want)
+		}
+	}
+	if limits[0] <= limits[1] {
+		t.Errorf("ThresholdLimit at 50%% (%d) not above 90%% (%d)", limits[0], limits[1])
+	}
+}
+
+func TestAdaptiveThresholdSPMC(t *testing.T) {
+	q := lfq.Unrecorded(lfq.BuildSPMC[int](lfq.New(8).SingleProducer().WithAdaptiveThreshold(1))).(*lfq.SPMC[int])
+	// Every call blocks, so one cycle takes the limit to its ceiling
+	deadline := time.Now().Add(time.Second)
+	for q.ThresholdLimit() != 4*(3*8-1) && time.Now().Before(deadline) {
+		q.Dequeue()
+		time.Sleep(time.Millisecond)
+	}
+	if got := q.ThresholdLimit(); got != 4*(3*8-1) {
+		t.Fatalf("ThresholdLimit after only blocked dequeues: got %d, want %d", got, 4*(3*8-1))
+	}
+	// The next Enqueue resets the threshold to the tuned limit
+	v := 1
+	q.Enqueue(&v)
+	if got := q.DebugState().Threshold; got != 4*(3*8-1) {
+		t.Fatalf("threshold after Enqueue: got %d, want %d", got, 4*(3*8-1))
+	}
+}
+
+func TestAdaptiveThresholdDefaults(t *testing.T) {
+	if got := lfq.NewMPMC[int](16).ThresholdLimit(); got != 3*16-1 {
+		t.Errorf("MPMC ThresholdLimit: got %d, want %d", got, 3*16-1)
+	}
+	if got := lfq.NewSPMC[int](16).ThresholdLimit(); got != 3*16-1 {
+		t.Errorf("SPMC ThresholdLimit: got %d, want %d", got, 3*16-1)
+	}
+	for _, alpha := range []float64{0, -0.5, 1.5} {
+		if recovered(func() { lfq.New(8).WithAdaptiveThreshold(alpha) }) == nil {
+			t.Errorf("WithAdaptiveThreshold(%v): expected panic", alpha)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// throughputWindow is the number of timestamps kept per direction.
+// Must be a power of 2.
+const throughputWindow = 1024
+
+// ThroughputTracker measures recent enqueue and dequeue rates.
+//
+// Every k-th operation, as counted by an FAA on a per-direction counter,
+// stores a monotonic timestamp in a ring of the last 1024 samples. A rate
+// is the number of samples within the last second times k. When the ring
+// spans less than a second, the rate is extrapolated from the span.
+//
+// Attach one to an MPMC or MPSC with [Builder.WithThroughputSampleRate].
+// A nil *ThroughputTracker reports zero rates.
+type ThroughputTracker struct {
+	enq   rateWindow
+	deq   rateWindow
+	every uint64 // Record every k-th operation
+}
+
+type rateWindow struct {
+	_      pad
+	ops    atomix.Uint64 // Operations counted
+	_      pad
+	stamps [throughputWindow]atomix.Int64
+}
+
+func newThroughputTracker(every int) *ThroughputTracker {
+	return &ThroughputTracker{every: uint64(every)}
+}
+
+// EnqueueRate returns successful enqueues per second over the last second.
+func (t *ThroughputTracker) EnqueueRate() float64 {
+	if t == nil {
+		return 0
+	}
+	return t.enq.rate(t.every)
+}
+
+// DequeueRate returns successful dequeues per second over the last second.
+func (t *ThroughputTracker) DequeueRate() float64 {
+	if t == nil {
+		return 0
+	}
+	return t.deq.rate(t.every)
+}
+
+// record counts n operations and timestamps each sample boundary crossed.
+func (w *rateWindow) record(n, every uint64) {
+	end := w.ops.AddRelaxed(n)
+	first := (end - n) / every
+	last := end / every
+	if last == first {
+		return
+	}
+	if last-first > throughputWindow {
+		first = last - throughputWindow
+	}
+	now := monotime()
+	for s := first; s < last; s++ {
+		w.stamps[s&(throughputWindow-1)].StoreRelaxed(now)
+	}
+}
+
+func (w *rateWindow) rate(every uint64) float64 {
+	samples := w.ops.LoadRelaxed() / every
+	if samples == 0 {
+		return 0
+	}
+	now := monotime()
+	n := min(samples, throughputWindow)
+	recent, oldest := uint64(0), now
+	for s := samples - n; s < samples; s++ {
+		ts := w.stamps[s&(throughputWindow-1)].LoadRelaxed()
+		if now-ts <= int64(time.Second) {
+			recent++
+			oldest = min(oldest, ts)
+		}
+	}
+	if recent == throughputWindow && now > oldest {
+		// The ring holds less than a second of history
+		return float64(recent*every) / time.Duration(now-oldest).Seconds()
+	}
+	return float64(recent * every)
+}
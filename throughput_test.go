@@ -0,0 +1,83 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestThroughputRate paces 1000 enqueues over just under a second and
+// checks the reported rate is about 1000/s.
+func TestThroughputRate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip: paced over one second")
+	}
+	q := lfq.Unrecorded(lfq.BuildMPMC[int](lfq.New(2048).WithThroughputSampleRate(1))).(*lfq.MPMC[int])
+
+	start := time.Now()
+	for i := range 1000 {
+		time.Sleep(time.Until(start.Add(time.Duration(i) * 900 * time.Microsecond)))
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	if rate := q.Throughput().EnqueueRate(); math.Abs(rate-1000) > 100 {
+		t.Fatalf("EnqueueRate: got %.1f, want 1000 ± 10%%", rate)
+	}
+	if rate := q.Throughput().DequeueRate(); rate != 0 {
+		t.Fatalf("DequeueRate: got %.1f, want 0", rate)
+	}
+}
+
+// TestThroughputSampleRate verifies sampled counting for MPSC and the
+// extrapolation once the sample window spans less than a second.
+func TestThroughputSampleRate(t *testing.T) {
+	q := lfq.Unrecorded(lfq.BuildMPSC[int](lfq.New(4096).SingleConsumer().WithThroughputSampleRate(4))).(*lfq.MPSC[int])
+
+	for i := range 4000 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for range 40 {
+		if _, err := q.Dequeue(); err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+	}
+
+	// 1000 samples fit the window, so all count
+	if rate := q.Throughput().EnqueueRate(); rate != 4000 {
+		t.Fatalf("EnqueueRate: got %.1f, want 4000", rate)
+	}
+	if rate := q.Throughput().DequeueRate(); rate != 40 {
+		t.Fatalf("DequeueRate: got %.1f, want 40", rate)
+	}
+
+	// A burst overflowing the window is extrapolated from its span
+	for i := range 8192 {
+		q.Dequeue()
+		q.Enqueue(&i)
+	}
+	if rate := q.Throughput().DequeueRate(); rate <= 4096 {
+		t.Fatalf("DequeueRate after burst: got %.1f, want > 4096", rate)
+	}
+}
+
+// TestThroughputDisabled verifies queues without a tracker report zero.
+func TestThroughputDisabled(t *testing.T) {
+	q := lfq.NewMPMC[int](8)
+	v := 1
+	q.Enqueue(&v)
+	if q.Throughput() != nil {
+		t.Fatal("Throughput: got tracker, want nil")
+	}
+	if rate := q.Throughput().EnqueueRate(); rate != 0 {
+		t.Fatalf("EnqueueRate: got %.1f, want 0", rate)
+	}
+}
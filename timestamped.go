@@ -0,0 +1,120 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"slices"
+	"time"
+
+	"code.hybscloud.com/atomix"
+)
+
+// latencyWindow is the number of recent latency samples kept for
+// LatencyPercentiles. Must be a power of 2.
+const latencyWindow = 1024
+
+// clockBase anchors timestamps to the monotonic clock.
+var clockBase = time.Now()
+
+// monotime returns nanoseconds on the monotonic clock since clockBase.
+func monotime() int64 {
+	return int64(time.Since(clockBase))
+}
+
+// TimestampedItem is an element together with its enqueue time.
+type TimestampedItem[T any] struct {
+	Value      T
+	EnqueuedAt int64 // Monotonic nanoseconds, comparable only within this process
+}
+
+// Latency returns the time elapsed since the item was enqueued.
+func (it TimestampedItem[T]) Latency() time.Duration {
+	return time.Duration(monotime() - it.EnqueuedAt)
+}
+
+// LatencyStats summarizes queue latency over recent dequeues.
+type LatencyStats struct {
+	Samples int // Number of samples summarized, at most the window size
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// TimestampedMPMC is an MPMC queue that records when each element was
+// enqueued, for measuring queue-induced latency.
+//
+// Enqueue reads the monotonic clock once. Dequeue reads it once more to
+// record a latency sample; the most recent samples are summarized by
+// LatencyPercentiles. Use MPMC where timestamps are not needed, since it
+// avoids both clock reads.
+//
+// Memory: 2n slots (MPMC) plus a fixed window of latency samples
+type TimestampedMPMC[T any] struct {
+	_       pad
+	sampled atomix.Uint64 // Total latency samples recorded
+	_       pad
+	q       *MPMC[TimestampedItem[T]]
+	samples [latencyWindow]atomix.Int64
+}
+
+// NewTimestampedMPMC creates a new timestamped MPMC queue.
+// Capacity rounds up to the next power of 2.
+func NewTimestampedMPMC[T any](capacity int) *TimestampedMPMC[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewTimestampedMPMC", "capacity", capacity, 2))
+	}
+	return &TimestampedMPMC[T]{q: NewMPMC[TimestampedItem[T]](capacity)}
+}
+
+// Enqueue adds an element stamped with the current time.
+// Returns ErrFull if the queue is full.
+func (q *TimestampedMPMC[T]) Enqueue(elem *T) error {
+	item := TimestampedItem[T]{Value: *elem, EnqueuedAt: monotime()}
+	return q.q.Enqueue(&item)
+}
+
+// Dequeue removes an element and returns it with its enqueue timestamp.
+// Returns ErrEmpty if the queue is empty.
+func (q *TimestampedMPMC[T]) Dequeue() (TimestampedItem[T], error) {
+	item, err := q.q.Dequeue()
+	if err != nil {
+		return item, err
+	}
+	n := q.sampled.AddRelaxed(1) - 1
+	q.samples[n&(latencyWindow-1)].StoreRelaxed(int64(item.Latency()))
+	return item, nil
+}
+
+// LatencyPercentiles summarizes the latency of the most recent dequeues.
+//
+// Samples recorded concurrently with the call may or may not be included.
+// Returns the zero LatencyStats if nothing has been dequeued.
+func (q *TimestampedMPMC[T]) LatencyPercentiles() LatencyStats {
+	n := min(q.sampled.LoadRelaxed(), latencyWindow)
+	if n == 0 {
+		return LatencyStats{}
+	}
+	s := make([]int64, n)
+	for i := range s {
+		s[i] = q.samples[i].LoadRelaxed()
+	}
+	slices.Sort(s)
+	at := func(p int) time.Duration {
+		return time.Duration(s[(len(s)-1)*p/100])
+	}
+	return LatencyStats{
+		Samples: len(s),
+		P50:     at(50),
+		P90:     at(90),
+		P99:     at(99),
+		Max:     time.Duration(s[len(s)-1]),
+	}
+}
+
+// Cap returns the queue capacity.
+func (q *TimestampedMPMC[T]) Cap() int {
+	return q.q.Cap()
+}
@@ -0,0 +1,76 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestTimestampedMPMC tests enqueue timestamps and latency percentiles.
+func TestTimestampedMPMC(t *testing.T) {
+	q := lfq.NewTimestampedMPMC[int](8)
+	if s := q.LatencyPercentiles(); s != (lfq.LatencyStats{}) {
+		t.Fatalf("LatencyPercentiles before samples: got %+v, want zero", s)
+	}
+
+	for i := range 4 {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	var prev int64
+	for i := range 4 {
+		item, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue(%d): %v", i, err)
+		}
+		if item.Value != i {
+			t.Fatalf("Dequeue(%d): got %d", i, item.Value)
+		}
+		if item.EnqueuedAt < prev {
+			t.Fatalf("EnqueuedAt not monotonic: %d after %d", item.EnqueuedAt, prev)
+		}
+		prev = item.EnqueuedAt
+		if l := item.Latency(); l < 2*time.Millisecond {
+			t.Fatalf("Latency: got %v, want >= 2ms", l)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+
+	s := q.LatencyPercentiles()
+	if s.Samples != 4 {
+		t.Fatalf("Samples: got %d, want 4", s.Samples)
+	}
+	if s.P50 < 2*time.Millisecond || s.P50 > s.P90 || s.P90 > s.P99 || s.P99 > s.Max {
+		t.Fatalf("LatencyPercentiles: got %+v", s)
+	}
+}
+
+// BenchmarkTimestampedMPMC measures the clock-read overhead against MPMC.
+func BenchmarkTimestampedMPMC(b *testing.B) {
+	b.Run("MPMC", func(b *testing.B) {
+		q := lfq.NewMPMC[int](1024)
+		for i := range b.N {
+			q.Enqueue(&i)
+			q.Dequeue()
+		}
+	})
+
+	b.Run("Timestamped", func(b *testing.B) {
+		q := lfq.NewTimestampedMPMC[int](1024)
+		for i := range b.N {
+			q.Enqueue(&i)
+			q.Dequeue()
+		}
+	})
+}
@@ -0,0 +1,75 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"slices"
+	"sync"
+)
+
+// TopicRouter routes elements to one MPSC queue per string topic, such
+// as an event type or log level, each drained by its own consumer.
+//
+// Topics are created up front with CreateTopic. Enqueue looks the topic
+// up in a sync.Map, whose reads take no lock and do not allocate, so
+// routing adds one map lookup to the queue's Enqueue. Creating topics is
+// serialized by a mutex and may run concurrently with Enqueue.
+type TopicRouter[T any] struct {
+	queues   sync.Map // string → *MPSC[T]
+	mu       sync.Mutex
+	names    []string // Guarded by mu, in creation order
+	capacity int
+}
+
+// NewTopicRouter creates a router whose topic queues have the given
+// capacity. Panics if capacity < 2.
+func NewTopicRouter[T any](capacity int) *TopicRouter[T] {
+	if capacity < 2 {
+		panic(belowMinimum("NewTopicRouter", "capacity", capacity, 2))
+	}
+	return &TopicRouter[T]{capacity: capacity}
+}
+
+// CreateTopic creates an MPSC queue for topic and returns it; its
+// consumer dequeues from the returned queue. If the topic exists, its
+// queue is returned.
+func (r *TopicRouter[T]) CreateTopic(topic string) Queue[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if q, ok := r.queues.Load(topic); ok {
+		return q.(*MPSC[T])
+	}
+	q := NewMPSC[T](r.capacity)
+	r.queues.Store(topic, q)
+	r.names = append(r.names, topic)
+	return q
+}
+
+// Topic returns the queue of topic, if it was created.
+func (r *TopicRouter[T]) Topic(topic string) (Queue[T], bool) {
+	q, ok := r.queues.Load(topic)
+	if !ok {
+		return nil, false
+	}
+	return q.(*MPSC[T]), true
+}
+
+// Enqueue adds elem to the queue of topic.
+// Returns ErrUnknownTopic if the topic was not created, or the queue's
+// error, such as ErrFull.
+func (r *TopicRouter[T]) Enqueue(topic string, elem *T) error {
+	q, ok := r.queues.Load(topic)
+	if !ok {
+		return ErrUnknownTopic
+	}
+	return q.(*MPSC[T]).Enqueue(elem)
+}
+
+// Topics returns the created topics in creation order.
+func (r *TopicRouter[T]) Topics() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.names)
+}
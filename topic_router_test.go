@@ -0,0 +1,104 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestTopicRouter enqueues to three topics and verifies each topic's
+// queue holds only its own items, in order.
+func TestTopicRouter(t *testing.T) {
+	r := lfq.NewTopicRouter[string](16)
+	topics := []string{"info", "warn", "error"}
+	queues := map[string]lfq.Queue[string]{}
+	for _, topic := range topics {
+		queues[topic] = r.CreateTopic(topic)
+	}
+	if got := r.CreateTopic("warn"); got != queues["warn"] {
+		t.Fatal("CreateTopic on an existing topic returned a new queue")
+	}
+	if got := r.Topics(); !slices.Equal(got, topics) {
+		t.Fatalf("Topics: got %v, want %v", got, topics)
+	}
+
+	for i := range 5 {
+		for _, topic := range topics {
+			v := topic + string(rune('0'+i))
+			if err := r.Enqueue(topic, &v); err != nil {
+				t.Fatalf("Enqueue(%q): %v", topic, err)
+			}
+		}
+	}
+	for _, topic := range topics {
+		q, ok := r.Topic(topic)
+		if !ok || q != queues[topic] {
+			t.Fatalf("Topic(%q): got (%v, %v), want the created queue", topic, q, ok)
+		}
+		for i := range 5 {
+			want := topic + string(rune('0'+i))
+			if got, err := q.Dequeue(); err != nil || got != want {
+				t.Fatalf("topic %q Dequeue: got (%q, %v), want (%q, nil)", topic, got, err, want)
+			}
+		}
+		if _, err := q.Dequeue(); !lfq.IsEmpty(err) {
+			t.Fatalf("topic %q after its items: got %v, want ErrEmpty", topic, err)
+		}
+	}
+
+	v := "x"
+	if err := r.Enqueue("debug", &v); !errors.Is(err, lfq.ErrUnknownTopic) {
+		t.Fatalf("Enqueue to unknown topic: got %v, want ErrUnknownTopic", err)
+	}
+	if _, ok := r.Topic("debug"); ok {
+		t.Fatal("Topic on unknown topic: got ok")
+	}
+}
+
+func TestTopicRouterEnqueueNoAlloc(t *testing.T) {
+	r := lfq.NewTopicRouter[int](1024)
+	q := r.CreateTopic("metrics")
+	topic := string([]byte("metrics")) // Not the string CreateTopic stored
+	v := 1
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Enqueue(topic, &v)
+		q.Dequeue()
+	})
+	if allocs != 0 {
+		t.Fatalf("Enqueue allocs: got %v, want 0", allocs)
+	}
+}
+
+// BenchmarkTopicRouter compares Enqueue through a router of 16 topics
+// with Enqueue on the MPSC directly.
+func BenchmarkTopicRouter(b *testing.B) {
+	b.Run("Direct", func(b *testing.B) {
+		q := lfq.NewMPSC[int](1024)
+		v := 1
+		b.ReportAllocs()
+		for range b.N {
+			q.Enqueue(&v)
+			q.Dequeue()
+		}
+	})
+	b.Run("Router", func(b *testing.B) {
+		r := lfq.NewTopicRouter[int](1024)
+		for _, topic := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o"} {
+			r.CreateTopic(topic)
+		}
+		q := r.CreateTopic("orders.created")
+		v := 1
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			r.Enqueue("orders.created", &v)
+			q.Dequeue()
+		}
+	})
+}
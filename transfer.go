@@ -0,0 +1,100 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"unsafe"
+
+	"code.hybscloud.com/spin"
+)
+
+// TryTransferAll moves elements from src to dst without blocking.
+//
+// It stops when src is empty (srcEmpty=true) or dst has no free slot
+// (dstFull=true), and returns the number of elements moved. Elements are
+// moved in FIFO order and none are dropped: src is only dequeued while
+// dst reports room. If another producer fills dst between that check and
+// the enqueue, the element already taken from src is retried until dst
+// accepts it.
+//
+// The caller must be a valid consumer of src and a valid producer of dst.
+// Useful for flush-on-tick patterns that move items from a per-producer
+// SPSC into a shared MPSC.
+//
+// TryTransferAll does not allocate. dst must not retain the element
+// pointer passed to Enqueue; every queue in this package copies it.
+func TryTransferAll[T any](src, dst Queue[T]) (transferred int, srcEmpty bool, dstFull bool) {
+	var v T
+	p := (*T)(noescape(unsafe.Pointer(&v)))
+	for {
+		if !hasRoom(dst) {
+			return transferred, false, true
+		}
+		var err error
+		if v, err = src.Dequeue(); err != nil {
+			return transferred, true, false
+		}
+		for sw := (spin.Wait{}); dst.Enqueue(p) != nil; {
+			sw.Once()
+		}
+		transferred++
+	}
+}
+
+// TryTransferAllIndirect moves uintptr values from src to dst without
+// blocking. See TryTransferAll for semantics.
+func TryTransferAllIndirect(src, dst QueueIndirect) (transferred int, srcEmpty bool, dstFull bool) {
+	for {
+		if !hasRoom(dst) {
+			return transferred, false, true
+		}
+		v, err := src.Dequeue()
+		if err != nil {
+			return transferred, true, false
+		}
+		for sw := (spin.Wait{}); dst.Enqueue(v) != nil; {
+			sw.Once()
+		}
+		transferred++
+	}
+}
+
+// TryTransferAllPtr moves unsafe.Pointer values from src to dst without
+// blocking. See TryTransferAll for semantics.
+func TryTransferAllPtr(src, dst QueuePtr) (transferred int, srcEmpty bool, dstFull bool) {
+	for {
+		if !hasRoom(dst) {
+			return transferred, false, true
+		}
+		v, err := src.Dequeue()
+		if err != nil {
+			return transferred, true, false
+		}
+		for sw := (spin.Wait{}); dst.Enqueue(v) != nil; {
+			sw.Once()
+		}
+		transferred++
+	}
+}
+
+// hasRoom reports whether q has at least one free slot.
+// Queues from outside this package are assumed to have room.
+func hasRoom(q any) bool {
+	d, ok := q.(depther)
+	if !ok {
+		return true
+	}
+	c, ok := q.(interface{ Cap() int })
+	return !ok || d.depth() < c.Cap()
+}
+
+// noescape hides a pointer from escape analysis.
+// The pointee must not outlive the caller's stack frame.
+//
+//go:nosplit
+func noescape(p unsafe.Pointer) unsafe.Pointer {
+	x := uintptr(p) ^ 0
+	return *(*unsafe.Pointer)(unsafe.Pointer(&x))
+}
@@ -0,0 +1,103 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"code.hybscloud.com/lfq"
+)
+
+// TestTryTransferAll moves 10 items into a destination with 7 free slots.
+func TestTryTransferAll(t *testing.T) {
+	src := lfq.NewSPSC[int](16)
+	for i := range 10 {
+		if err := src.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+
+	// Capacity rounds to 8; one pre-filled item leaves 7 free slots
+	dst := lfq.NewMPSC[int](8)
+	v := -1
+	if err := dst.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	n, srcEmpty, dstFull := lfq.TryTransferAll[int](src, dst)
+	if n != 7 || srcEmpty || !dstFull {
+		t.Fatalf("TryTransferAll: got (%d, %v, %v), want (7, false, true)", n, srcEmpty, dstFull)
+	}
+
+	// dst holds the pre-filled item followed by 0..6 in order
+	for i := -1; i < 7; i++ {
+		got, err := dst.Dequeue()
+		if err != nil || got != i {
+			t.Fatalf("dst.Dequeue: got (%d, %v), want %d", got, err, i)
+		}
+	}
+
+	// 3 items remain in src: 7, 8, 9
+	for i := 7; i < 10; i++ {
+		got, err := src.Dequeue()
+		if err != nil || got != i {
+			t.Fatalf("src.Dequeue: got (%d, %v), want %d", got, err, i)
+		}
+	}
+
+	n, srcEmpty, dstFull = lfq.TryTransferAll[int](src, dst)
+	if n != 0 || !srcEmpty || dstFull {
+		t.Fatalf("TryTransferAll empty: got (%d, %v, %v), want (0, true, false)", n, srcEmpty, dstFull)
+	}
+}
+
+// TestTryTransferAllIndirectPtr covers the uintptr and unsafe.Pointer variants.
+func TestTryTransferAllIndirectPtr(t *testing.T) {
+	src := lfq.NewMPMCIndirect(4)
+	dst := lfq.NewSPSCIndirect(8)
+	for i := range 4 {
+		src.Enqueue(uintptr(i))
+	}
+	n, srcEmpty, dstFull := lfq.TryTransferAllIndirect(src, dst)
+	if n != 4 || !srcEmpty || dstFull {
+		t.Fatalf("TryTransferAllIndirect: got (%d, %v, %v), want (4, true, false)", n, srcEmpty, dstFull)
+	}
+
+	vals := [3]int{}
+	psrc := lfq.NewSPMCPtr(4)
+	pdst := lfq.NewMPMCPtrSeq(2)
+	for i := range vals {
+		psrc.Enqueue(unsafe.Pointer(&vals[i]))
+	}
+	n, srcEmpty, dstFull = lfq.TryTransferAllPtr(psrc, pdst)
+	if n != 2 || srcEmpty || !dstFull {
+		t.Fatalf("TryTransferAllPtr: got (%d, %v, %v), want (2, false, true)", n, srcEmpty, dstFull)
+	}
+	if p, _ := pdst.Dequeue(); p != unsafe.Pointer(&vals[0]) {
+		t.Fatalf("TryTransferAllPtr: first element out of order")
+	}
+}
+
+// TestTryTransferAllNoAlloc verifies TryTransferAll does not allocate.
+func TestTryTransferAllNoAlloc(t *testing.T) {
+	src := lfq.NewSPSC[[4]int](64)
+	dst := lfq.NewSPSC[[4]int](64)
+	var q lfq.Queue[[4]int] = dst
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := range 8 {
+			v := [4]int{i}
+			src.Enqueue(&v)
+		}
+		lfq.TryTransferAll[[4]int](src, q)
+		for range 8 {
+			dst.Dequeue()
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("TryTransferAll: got %v allocs/run, want 0", allocs)
+	}
+}
@@ -0,0 +1,94 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// TypedIndirect wraps a QueueIndirect for a named uintptr type.
+//
+// The ~uintptr constraint lets index and handle types such as
+// `type BufferIndex uintptr` flow through an indirect queue without
+// manual conversions at every call site.
+//
+// Example:
+//
+//	type SlotID uintptr
+//	q := lfq.NewTypedIndirectMPMC[SlotID](1024)
+//	q.Enqueue(SlotID(7))
+//	id, err := q.Dequeue() // id is SlotID
+type TypedIndirect[T ~uintptr] struct {
+	q QueueIndirect
+}
+
+// NewTypedIndirect wraps an existing indirect queue.
+func NewTypedIndirect[T ~uintptr](q QueueIndirect) *TypedIndirect[T] {
+	return &TypedIndirect[T]{q: q}
+}
+
+// NewTypedIndirectMPMC creates a typed FAA-based MPMC indirect queue.
+// Capacity rounds up to the next power of 2.
+func NewTypedIndirectMPMC[T ~uintptr](capacity int) *TypedIndirect[T] {
+	return &TypedIndirect[T]{q: NewMPMCIndirect(capacity)}
+}
+
+// BuildTypedIndirect creates a typed indirect queue using the same
+// algorithm selection as Builder.BuildIndirect.
+func BuildTypedIndirect[T ~uintptr](b *Builder) *TypedIndirect[T] {
+	return &TypedIndirect[T]{q: b.BuildIndirect()}
+}
+
+// Enqueue adds an element to the queue.
+// Returns ErrFull if the queue is full.
+func (t *TypedIndirect[T]) Enqueue(elem T) error {
+	return t.q.Enqueue(uintptr(elem))
+}
+
+// Dequeue removes and returns an element.
+// Returns (0, ErrEmpty) if the queue is empty.
+func (t *TypedIndirect[T]) Dequeue() (T, error) {
+	elem, err := t.q.Dequeue()
+	return T(elem), err
+}
+
+// Cap returns the queue capacity.
+func (t *TypedIndirect[T]) Cap() int {
+	return t.q.Cap()
+}
+
+// Unwrap returns the underlying indirect queue.
+func (t *TypedIndirect[T]) Unwrap() QueueIndirect {
+	return t.q
+}
+
+// indirectQueue adapts a QueueIndirect to Queue[T] for a T whose
+// underlying type is uintptr. Build returns it when Indirect() is set.
+type indirectQueue[T any] struct {
+	q QueueIndirect
+}
+
+func (a indirectQueue[T]) Enqueue(elem *T) error {
+	return a.q.Enqueue(*(*uintptr)(unsafe.Pointer(elem)))
+}
+
+func (a indirectQueue[T]) Dequeue() (T, error) {
+	elem, err := a.q.Dequeue()
+	return *(*T)(unsafe.Pointer(&elem)), err
+}
+
+func (a indirectQueue[T]) Cap() int {
+	return a.q.Cap()
+}
+
+// buildIndirectAs returns an indirect-backed Queue[T].
+// Panics if the underlying type of T is not uintptr.
+func buildIndirectAs[T any](b *Builder) Queue[T] {
+	if reflect.TypeFor[T]().Kind() != reflect.Uintptr {
+		panic(requires("Builder.Indirect", "an element type with underlying type uintptr"))
+	}
+	return indirectQueue[T]{q: b.BuildIndirect()}
+}
@@ -0,0 +1,72 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+// SlotID is a named uintptr handle type.
+type SlotID uintptr
+
+// TestTypedIndirect verifies FIFO order and type preservation through a
+// typed indirect queue.
+func TestTypedIndirect(t *testing.T) {
+	q := lfq.NewTypedIndirectMPMC[SlotID](4)
+	if q.Cap() != 4 {
+		t.Fatalf("Cap: got %d, want 4", q.Cap())
+	}
+
+	for i := range SlotID(4) {
+		if err := q.Enqueue(i + 10); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	if err := q.Enqueue(99); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Enqueue on full: got %v, want ErrWouldBlock", err)
+	}
+	for i := range SlotID(4) {
+		var id SlotID
+		id, err := q.Dequeue()
+		if err != nil || id != i+10 {
+			t.Fatalf("Dequeue(%d): got (%d, %v), want %d", i, id, err, i+10)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, lfq.ErrWouldBlock) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrWouldBlock", err)
+	}
+
+	bq := lfq.BuildTypedIndirect[SlotID](lfq.New(8).SingleProducer().SingleConsumer())
+	if _, ok := bq.Unwrap().(*lfq.SPSCIndirect); !ok {
+		t.Fatalf("BuildTypedIndirect: got %T, want *lfq.SPSCIndirect", bq.Unwrap())
+	}
+}
+
+// TestBuildIndirectOption verifies Build honors Indirect() for uintptr-based
+// element types and rejects other types.
+func TestBuildIndirectOption(t *testing.T) {
+	q := lfq.Build[SlotID](lfq.New(8).Compact().Indirect())
+	for i := range SlotID(8) {
+		if err := q.Enqueue(&i); err != nil {
+			t.Fatalf("Enqueue(%d): %v", i, err)
+		}
+	}
+	for i := range SlotID(8) {
+		id, err := q.Dequeue()
+		if err != nil || id != i {
+			t.Fatalf("Dequeue(%d): got (%d, %v), want %d", i, id, err, i)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Build[int] with Indirect(): expected panic")
+		}
+	}()
+	lfq.Build[int](lfq.New(8).Indirect())
+}
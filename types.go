@@ -11,9 +11,10 @@ import "unsafe"
 // Queue provides non-blocking Enqueue and Dequeue operations. Both operations
 // return ErrWouldBlock when they cannot proceed (queue full or empty).
 //
-// The interface intentionally excludes length because accurate counts in
-// lock-free algorithms require expensive cross-core synchronization.
-// Track counts in application logic when needed.
+// The interface excludes length because accurate counts in lock-free
+// algorithms require expensive cross-core synchronization. The concrete
+// queue types provide Len as a best-effort estimate; track counts in
+// application logic when they must be exact.
 //
 // Example:
 //
@@ -44,7 +45,7 @@ type Queue[T any] interface {
 type Producer[T any] interface {
 	// Enqueue adds an element to the queue (non-blocking).
 	// The element is copied into the queue's internal buffer.
-	// Returns nil on success, ErrWouldBlock if the queue is full.
+	// Returns nil on success, ErrFull if the queue is full.
 	//
 	// Thread safety depends on queue type:
 	//   - SPSC: single producer only
@@ -64,7 +65,7 @@ type Producer[T any] interface {
 type Consumer[T any] interface {
 	// Dequeue removes and returns an element from the queue (non-blocking).
 	// Returns the dequeued element on success.
-	// Returns (zero-value, ErrWouldBlock) if the queue is empty.
+	// Returns (zero-value, ErrEmpty) if the queue is empty.
 	//
 	// Thread safety depends on queue type:
 	//   - SPSC: single consumer only
@@ -78,8 +79,8 @@ type Consumer[T any] interface {
 // QueueIndirect passes indices or handles instead of full objects. This is
 // useful for buffer pools, object pools, or any index-based data structure.
 //
-// The interface intentionally excludes length because accurate counts in
-// lock-free algorithms require expensive cross-core synchronization.
+// The interface excludes length; the concrete queue types provide Len as
+// a best-effort estimate.
 //
 // Example (buffer pool):
 //
@@ -107,14 +108,14 @@ type QueueIndirect interface {
 // ProducerIndirect enqueues uintptr values (non-blocking).
 type ProducerIndirect interface {
 	// Enqueue adds an element to the queue.
-	// Returns ErrWouldBlock immediately if the queue is full.
+	// Returns ErrFull immediately if the queue is full.
 	Enqueue(elem uintptr) error
 }
 
 // ConsumerIndirect dequeues uintptr values (non-blocking).
 type ConsumerIndirect interface {
 	// Dequeue removes and returns an element from the queue.
-	// Returns (0, ErrWouldBlock) immediately if the queue is empty.
+	// Returns (0, ErrEmpty) immediately if the queue is empty.
 	Dequeue() (uintptr, error)
 }
 
@@ -127,8 +128,8 @@ type ConsumerIndirect interface {
 // Ownership semantics: The producer transfers ownership to the consumer.
 // After enqueueing, the producer should not access the object.
 //
-// The interface intentionally excludes length because accurate counts in
-// lock-free algorithms require expensive cross-core synchronization.
+// The interface excludes length; the concrete queue types provide Len as
+// a best-effort estimate.
 //
 // Example:
 //
@@ -154,16 +155,22 @@ type QueuePtr interface {
 }
 
 // ProducerPtr enqueues unsafe.Pointer values (non-blocking).
+//
+// Enqueue panics on nil. A nil element usually means the producer lost
+// track of its object, and the consumer would otherwise fail far from the
+// cause when it dereferences the pointer. Callers that use nil as a
+// sentinel, e.g. to signal end of stream, call EnqueueNilable on the
+// concrete queue type instead.
 type ProducerPtr interface {
-	// Enqueue adds an element to the queue.
-	// Returns ErrWouldBlock immediately if the queue is full.
+	// Enqueue adds a non-nil element to the queue.
+	// Returns ErrFull immediately if the queue is full.
 	Enqueue(elem unsafe.Pointer) error
 }
 
 // ConsumerPtr dequeues unsafe.Pointer values (non-blocking).
 type ConsumerPtr interface {
 	// Dequeue removes and returns an element from the queue.
-	// Returns (nil, ErrWouldBlock) immediately if the queue is empty.
+	// Returns (nil, ErrEmpty) immediately if the queue is empty.
 	Dequeue() (unsafe.Pointer, error)
 }
 
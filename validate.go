@@ -0,0 +1,215 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrInvalidConfig is wrapped by the errors [Builder.Validate] and the
+// TryBuild functions return for a configuration the matching Build
+// function would panic on.
+var ErrInvalidConfig = errors.New("lfq: invalid builder configuration")
+
+// configError describes a constraint violation. Its message is the one
+// the panicking Build functions use.
+type configError string
+
+func (e configError) Error() string { return string(e) }
+
+func (e configError) Unwrap() error { return ErrInvalidConfig }
+
+// Validate checks the constraints every Build method shares, without
+// building: the capacity range and the overflow callback. It does not
+// know the element type or the Build method to be called, so the
+// TryBuild functions additionally check those.
+//
+// New already panics on a capacity out of range; Validate also covers a
+// zero Builder that did not come from New.
+//
+//	if err := b.Validate(); err != nil {
+//	    return fmt.Errorf("queue %q: %w", name, err)
+//	}
+func (b *Builder) Validate() error {
+	if c := b.opts.capacity; c < 2 {
+		return configError(belowMinimum("New", "capacity", c, 2))
+	} else if c > MaxCapacity {
+		return configError(aboveMaximum("New", "capacity", c, MaxCapacity))
+	}
+	if fn := b.opts.overflowFn; fn != nil {
+		if t := reflect.TypeOf(fn); t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+			return configError("lfq: overflow callback must be a func(T), not " + t.String() + "; see " + docURL + "Builder.WithOverflowPolicy")
+		}
+	} else if b.opts.overflow == OverflowCallback {
+		return configError(requires("Builder.WithOverflowPolicy", "a callback for OverflowCallback"))
+	}
+	return nil
+}
+
+// validateFor extends Validate with the checks that depend on the
+// element type T.
+func validateFor[T any](b *Builder) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if b.opts.overflowFn != nil {
+		if _, ok := b.opts.overflowFn.(func(T)); !ok {
+			return configError("lfq: overflow callback type does not match the element type; see " + docURL + "Builder.WithOverflowPolicy")
+		}
+	}
+	if b.opts.indirect && reflect.TypeFor[T]().Kind() != reflect.Uintptr {
+		return configError(requires("Builder.Indirect", "an element type with underlying type uintptr"))
+	}
+	return nil
+}
+
+// requireTopology returns an error unless b declares exactly the
+// single-producer and single-consumer constraints fn requires.
+func (b *Builder) requireTopology(fn string, sp, sc bool) error {
+	if b.opts.singleProducer == sp && b.opts.singleConsumer == sc {
+		return nil
+	}
+	switch {
+	case sp && sc:
+		return configError("lfq: " + fn + " requires SingleProducer().SingleConsumer()")
+	case sc:
+		return configError("lfq: " + fn + " requires SingleConsumer() without SingleProducer()")
+	case sp:
+		return configError("lfq: " + fn + " requires SingleProducer() without SingleConsumer()")
+	default:
+		return configError("lfq: " + fn + " requires no constraints")
+	}
+}
+
+// TryBuild is Build returning an error instead of panicking on an
+// invalid configuration, for queues configured at run time:
+//
+//	q, err := lfq.TryBuild[Event](lfq.New(cfg.Capacity).WithOverflowPolicy(cfg.Policy, onDrop))
+//	if err != nil {
+//	    return err
+//	}
+func TryBuild[T any](b *Builder) (Queue[T], error) {
+	if err := validateFor[T](b); err != nil {
+		return nil, err
+	}
+	return Build[T](b), nil
+}
+
+// TryBuildSPSC is BuildSPSC returning an error instead of panicking.
+func TryBuildSPSC[T any](b *Builder) (*SPSC[T], error) {
+	if err := validateFor[T](b); err != nil {
+		return nil, err
+	}
+	if err := b.requireTopology("BuildSPSC", true, true); err != nil {
+		return nil, err
+	}
+	if b.opts.overflow != OverflowBlock {
+		return nil, configError(unsupported("BuildSPSC", "overflow policies"))
+	}
+	return BuildSPSC[T](b), nil
+}
+
+// TryBuildMPSC is BuildMPSC returning an error instead of panicking.
+func TryBuildMPSC[T any](b *Builder) (Queue[T], error) {
+	return tryBuildGeneric(b, "BuildMPSC", false, true, BuildMPSC[T])
+}
+
+// TryBuildSPMC is BuildSPMC returning an error instead of panicking.
+func TryBuildSPMC[T any](b *Builder) (Queue[T], error) {
+	return tryBuildGeneric(b, "BuildSPMC", true, false, BuildSPMC[T])
+}
+
+// TryBuildMPMC is BuildMPMC returning an error instead of panicking.
+func TryBuildMPMC[T any](b *Builder) (Queue[T], error) {
+	return tryBuildGeneric(b, "BuildMPMC", false, false, BuildMPMC[T])
+}
+
+// tryBuildGeneric is tryBuildFixed for element type T.
+func tryBuildGeneric[T any](b *Builder, fn string, sp, sc bool, build func(*Builder) Queue[T]) (Queue[T], error) {
+	if err := validateFor[T](b); err != nil {
+		return nil, err
+	}
+	return tryBuildFixed(b, fn, sp, sc, build)
+}
+
+// TryBuildIndirect is BuildIndirect returning an error instead of
+// panicking.
+func (b *Builder) TryBuildIndirect() (QueueIndirect, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b.BuildIndirect(), nil
+}
+
+// TryBuildIndirectSPSC is BuildIndirectSPSC returning an error instead
+// of panicking.
+func (b *Builder) TryBuildIndirectSPSC() (*SPSCIndirect, error) {
+	return tryBuildFixed(b, "BuildIndirectSPSC", true, true, (*Builder).BuildIndirectSPSC)
+}
+
+// TryBuildIndirectMPSC is BuildIndirectMPSC returning an error instead
+// of panicking.
+func (b *Builder) TryBuildIndirectMPSC() (QueueIndirect, error) {
+	return tryBuildFixed(b, "BuildIndirectMPSC", false, true, (*Builder).BuildIndirectMPSC)
+}
+
+// TryBuildIndirectSPMC is BuildIndirectSPMC returning an error instead
+// of panicking.
+func (b *Builder) TryBuildIndirectSPMC() (QueueIndirect, error) {
+	return tryBuildFixed(b, "BuildIndirectSPMC", true, false, (*Builder).BuildIndirectSPMC)
+}
+
+// TryBuildIndirectMPMC is BuildIndirectMPMC returning an error instead
+// of panicking.
+func (b *Builder) TryBuildIndirectMPMC() (QueueIndirect, error) {
+	return tryBuildFixed(b, "BuildIndirectMPMC", false, false, (*Builder).BuildIndirectMPMC)
+}
+
+// TryBuildPtr is BuildPtr returning an error instead of panicking.
+func (b *Builder) TryBuildPtr() (QueuePtr, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b.BuildPtr(), nil
+}
+
+// TryBuildPtrSPSC is BuildPtrSPSC returning an error instead of
+// panicking.
+func (b *Builder) TryBuildPtrSPSC() (*SPSCPtr, error) {
+	return tryBuildFixed(b, "BuildPtrSPSC", true, true, (*Builder).BuildPtrSPSC)
+}
+
+// TryBuildPtrMPSC is BuildPtrMPSC returning an error instead of
+// panicking.
+func (b *Builder) TryBuildPtrMPSC() (QueuePtr, error) {
+	return tryBuildFixed(b, "BuildPtrMPSC", false, true, (*Builder).BuildPtrMPSC)
+}
+
+// TryBuildPtrSPMC is BuildPtrSPMC returning an error instead of
+// panicking.
+func (b *Builder) TryBuildPtrSPMC() (QueuePtr, error) {
+	return tryBuildFixed(b, "BuildPtrSPMC", true, false, (*Builder).BuildPtrSPMC)
+}
+
+// TryBuildPtrMPMC is BuildPtrMPMC returning an error instead of
+// panicking.
+func (b *Builder) TryBuildPtrMPMC() (QueuePtr, error) {
+	return tryBuildFixed(b, "BuildPtrMPMC", false, false, (*Builder).BuildPtrMPMC)
+}
+
+// tryBuildFixed validates b for a Build method of fixed topology before
+// calling it.
+func tryBuildFixed[Q any](b *Builder, fn string, sp, sc bool, build func(*Builder) Q) (Q, error) {
+	if err := b.Validate(); err != nil {
+		var zero Q
+		return zero, err
+	}
+	if err := b.requireTopology(fn, sp, sc); err != nil {
+		var zero Q
+		return zero, err
+	}
+	return build(b), nil
+}
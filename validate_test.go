@@ -0,0 +1,121 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfq_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+)
+
+func TestBuilderValidate(t *testing.T) {
+	if err := lfq.New(1024).SingleConsumer().Compact().Validate(); err != nil {
+		t.Fatalf("Validate on valid builder: %v", err)
+	}
+	tests := []struct {
+		name string
+		b    *lfq.Builder
+		want string
+	}{
+		{"ZeroBuilder", &lfq.Builder{}, "capacity 0 < minimum 2"},
+		{"CallbackNotFunc", lfq.New(8).WithOverflowPolicy(lfq.OverflowDrop, 42), "must be a func(T), not int"},
+		{"CallbackTwoArgs", lfq.New(8).WithOverflowPolicy(lfq.OverflowCallback, func(int, int) {}), "must be a func(T)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.b.Validate()
+			if !errors.Is(err, lfq.ErrInvalidConfig) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate: got %v, want ErrInvalidConfig mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestTryBuild verifies the TryBuild functions return the message the
+// matching Build function panics with, and a queue when the builder is
+// valid.
+func TestTryBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		try   func() (any, error)
+		build func()
+		want  string
+	}{
+		{"Build/IndirectInt", func() (any, error) { return lfq.TryBuild[int](lfq.New(8).Indirect()) },
+			func() { lfq.Build[int](lfq.New(8).Indirect()) }, "underlying type uintptr"},
+		{"Build/CallbackType", func() (any, error) {
+			return lfq.TryBuild[int](lfq.New(8).WithOverflowPolicy(lfq.OverflowCallback, func(string) {}))
+		}, func() { lfq.Build[int](lfq.New(8).WithOverflowPolicy(lfq.OverflowCallback, func(string) {})) }, "does not match the element type"},
+		{"BuildSPSC/MPMC", func() (any, error) { return lfq.TryBuildSPSC[int](lfq.New(8)) },
+			func() { lfq.BuildSPSC[int](lfq.New(8)) }, "BuildSPSC requires SingleProducer().SingleConsumer()"},
+		{"BuildSPSC/Overflow", func() (any, error) {
+			return lfq.TryBuildSPSC[int](lfq.New(8).SingleProducer().SingleConsumer().WithOverflowPolicy(lfq.OverflowDrop, nil))
+		}, func() {
+			lfq.BuildSPSC[int](lfq.New(8).SingleProducer().SingleConsumer().WithOverflowPolicy(lfq.OverflowDrop, nil))
+		}, "does not support overflow policies"},
+		{"BuildMPSC/SPSC", func() (any, error) { return lfq.TryBuildMPSC[int](lfq.New(8).SingleProducer().SingleConsumer()) },
+			func() { lfq.BuildMPSC[int](lfq.New(8).SingleProducer().SingleConsumer()) }, "BuildMPSC requires SingleConsumer() without SingleProducer()"},
+		{"BuildSPMC/MPMC", func() (any, error) { return lfq.TryBuildSPMC[int](lfq.New(8)) },
+			func() { lfq.BuildSPMC[int](lfq.New(8)) }, "BuildSPMC requires SingleProducer() without SingleConsumer()"},
+		{"BuildMPMC/SPMC", func() (any, error) { return lfq.TryBuildMPMC[int](lfq.New(8).SingleProducer()) },
+			func() { lfq.BuildMPMC[int](lfq.New(8).SingleProducer()) }, "BuildMPMC requires no constraints"},
+		{"BuildIndirectSPSC/MPSC", func() (any, error) { return lfq.New(8).SingleConsumer().TryBuildIndirectSPSC() },
+			func() { lfq.New(8).SingleConsumer().BuildIndirectSPSC() }, "BuildIndirectSPSC requires"},
+		{"BuildIndirectMPSC/MPMC", func() (any, error) { return lfq.New(8).TryBuildIndirectMPSC() },
+			func() { lfq.New(8).BuildIndirectMPSC() }, "BuildIndirectMPSC requires"},
+		{"BuildIndirectSPMC/MPMC", func() (any, error) { return lfq.New(8).TryBuildIndirectSPMC() },
+			func() { lfq.New(8).BuildIndirectSPMC() }, "BuildIndirectSPMC requires"},
+		{"BuildIndirectMPMC/MPSC", func() (any, error) { return lfq.New(8).SingleConsumer().TryBuildIndirectMPMC() },
+			func() { lfq.New(8).SingleConsumer().BuildIndirectMPMC() }, "BuildIndirectMPMC requires"},
+		{"BuildPtrSPSC/SPMC", func() (any, error) { return lfq.New(8).SingleProducer().TryBuildPtrSPSC() },
+			func() { lfq.New(8).SingleProducer().BuildPtrSPSC() }, "BuildPtrSPSC requires"},
+		{"BuildPtrMPSC/MPMC", func() (any, error) { return lfq.New(8).TryBuildPtrMPSC() },
+			func() { lfq.New(8).BuildPtrMPSC() }, "BuildPtrMPSC requires"},
+		{"BuildPtrSPMC/MPSC", func() (any, error) { return lfq.New(8).SingleConsumer().TryBuildPtrSPMC() },
+			func() { lfq.New(8).SingleConsumer().BuildPtrSPMC() }, "BuildPtrSPMC requires"},
+		{"BuildPtrMPMC/SPSC", func() (any, error) { return lfq.New(8).SingleProducer().SingleConsumer().TryBuildPtrMPMC() },
+			func() { lfq.New(8).SingleProducer().SingleConsumer().BuildPtrMPMC() }, "BuildPtrMPMC requires"},
+		{"BuildIndirect/ZeroBuilder", func() (any, error) { return (&lfq.Builder{}).TryBuildIndirect() },
+			func() { (&lfq.Builder{}).BuildIndirect() }, "capacity 0 < minimum 2"},
+		{"BuildPtr/ZeroBuilder", func() (any, error) { return (&lfq.Builder{}).TryBuildPtr() },
+			func() { (&lfq.Builder{}).BuildPtr() }, "capacity 0 < minimum 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.try()
+			if !errors.Is(err, lfq.ErrInvalidConfig) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want ErrInvalidConfig mentioning %q", err, tt.want)
+			}
+			if r := recovered(tt.build); r == nil {
+				t.Fatal("Build did not panic")
+			}
+		})
+	}
+}
+
+func TestTryBuildValid(t *testing.T) {
+	q, err := lfq.TryBuild[int](lfq.New(8).SingleConsumer())
+	if err != nil {
+		t.Fatalf("TryBuild: %v", err)
+	}
+	v := 7
+	if err := q.Enqueue(&v); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if got, err := q.Dequeue(); err != nil || got != 7 {
+		t.Fatalf("Dequeue: got (%d, %v), want (7, nil)", got, err)
+	}
+	if _, err := lfq.TryBuildSPSC[int](lfq.New(8).SingleProducer().SingleConsumer()); err != nil {
+		t.Fatalf("TryBuildSPSC: %v", err)
+	}
+	if _, err := lfq.New(8).Compact().TryBuildIndirectMPMC(); err != nil {
+		t.Fatalf("TryBuildIndirectMPMC: %v", err)
+	}
+	if _, err := lfq.New(8).SingleProducer().TryBuildPtrSPMC(); err != nil {
+		t.Fatalf("TryBuildPtrSPMC: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package wal adds a write-ahead log to an lfq queue so that dequeued
+// items survive a crash until the consumer commits them.
+//
+// Enqueue logs each item before publishing it, and Commit logs that a
+// dequeued item was processed. After a restart, New reads the log back and
+// Replay yields every item that was logged but never committed, so a
+// consumer that commits only after processing sees each item at least
+// once. With idempotent processing this gives effectively exactly-once
+// pipelines.
+//
+// The log is an append-only stream of JSON records, one per line, so T
+// must round-trip through encoding/json. Writes are serialized by a mutex;
+// the queue itself is an lfq.MPSC.
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	"code.hybscloud.com/lfq"
+)
+
+// ErrUnknownToken is returned by Commit for a token that was already
+// committed, or was not issued by the queue.
+var ErrUnknownToken = errors.New("wal: unknown or already committed token")
+
+// DequeueToken identifies a dequeued item for Commit.
+type DequeueToken struct {
+	seq uint64
+}
+
+// Log record operations.
+const (
+	opEnqueue = "enq"
+	opCommit  = "ack"
+)
+
+type record[T any] struct {
+	Op    string `json:"op"`
+	Seq   uint64 `json:"seq"`
+	Value *T     `json:"value,omitempty"`
+}
+
+type entry[T any] struct {
+	seq   uint64
+	value T
+}
+
+// WALQueue is an MPSC queue whose items are logged until committed.
+//
+// Producers call Enqueue concurrently; a single consumer calls Dequeue,
+// then Commit once it has processed the item.
+type WALQueue[T any] struct {
+	q       *lfq.MPSC[entry[T]]
+	store   io.Writer
+	closer  io.Closer
+	mu      sync.Mutex // Guards the fields below and writes to store
+	enc     *json.Encoder
+	seq     uint64       // Last sequence number issued
+	pending map[uint64]T // Logged items dequeued or recovered, not committed
+}
+
+// New creates a WAL queue of the given capacity over store. It first
+// reads store to its end, recovering the items that were logged but not
+// committed for Replay, then appends new records to it.
+//
+// A record torn by a crash during a write is skipped: it is terminated
+// with a newline before new records are appended, and lines that do not
+// parse are ignored.
+func New[T any](capacity int, store io.ReadWriter) (*WALQueue[T], error) {
+	w := &WALQueue[T]{
+		q:       lfq.NewMPSC[entry[T]](capacity),
+		store:   store,
+		enc:     json.NewEncoder(store),
+		pending: make(map[uint64]T),
+	}
+	br := bufio.NewReader(store)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Join(errors.New("wal: reading log"), err)
+		}
+		if err == io.EOF && len(line) > 0 {
+			// Torn final record: end it so the next record starts a line
+			if _, err := store.Write([]byte{'\n'}); err != nil {
+				return nil, err
+			}
+		}
+		var r record[T]
+		if json.Unmarshal(line, &r) == nil {
+			w.seq = max(w.seq, r.Seq)
+			switch r.Op {
+			case opEnqueue:
+				if r.Value != nil {
+					w.pending[r.Seq] = *r.Value
+				}
+			case opCommit:
+				delete(w.pending, r.Seq)
+			}
+		}
+		if err == io.EOF {
+			return w, nil
+		}
+	}
+}
+
+// Open creates a WAL queue logging to the file at path, created if it
+// does not exist. Close closes the file.
+//
+// Records reach the operating system on every write but are not synced
+// to stable storage; survive power loss by syncing the file, e.g. with
+// [os.File.Sync], at the points the application needs.
+func Open[T any](capacity int, path string) (*WALQueue[T], error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := New[T](capacity, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// Enqueue logs an element and adds it to the queue.
+// Returns lfq.ErrFull if the queue is full, or the error from the log.
+func (w *WALQueue[T]) Enqueue(elem *T) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	seq := w.seq
+	if err := w.enc.Encode(record[T]{Op: opEnqueue, Seq: seq, Value: elem}); err != nil {
+		return err
+	}
+	if err := w.q.Enqueue(&entry[T]{seq: seq, value: *elem}); err != nil {
+		// Cancel the logged item so it is not replayed
+		if lerr := w.enc.Encode(record[T]{Op: opCommit, Seq: seq}); lerr != nil {
+			return lerr
+		}
+		return err
+	}
+	return nil
+}
+
+// Dequeue removes an element (single consumer only) and returns it with
+// the token that commits it. The element stays in the log, and is
+// replayed after a restart, until the token is committed.
+// Returns lfq.ErrEmpty if the queue is empty.
+func (w *WALQueue[T]) Dequeue() (T, DequeueToken, error) {
+	e, err := w.q.Dequeue()
+	if err != nil {
+		return e.value, DequeueToken{}, err
+	}
+	w.mu.Lock()
+	w.pending[e.seq] = e.value
+	w.mu.Unlock()
+	return e.value, DequeueToken{seq: e.seq}, nil
+}
+
+// Commit marks a dequeued element as processed, so it is never replayed.
+// Returns ErrUnknownToken if the token was already committed, or the
+// error from the log.
+func (w *WALQueue[T]) Commit(token DequeueToken) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.commit(token.seq)
+}
+
+func (w *WALQueue[T]) commit(seq uint64) error {
+	if _, ok := w.pending[seq]; !ok {
+		return ErrUnknownToken
+	}
+	if err := w.enc.Encode(record[T]{Op: opCommit, Seq: seq}); err != nil {
+		return err
+	}
+	delete(w.pending, seq)
+	return nil
+}
+
+// Replay yields the uncommitted elements in enqueue order: those
+// recovered from the log by New, and those dequeued since without a
+// Commit. Each element is committed when the loop body finishes with it
+// and continues; breaking out of the loop leaves that element and the
+// rest pending, and a failed log write ends the iteration likewise.
+//
+// Tokens of elements committed by Replay become unknown to Commit.
+func (w *WALQueue[T]) Replay() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		w.mu.Lock()
+		seqs := slices.Sorted(maps.Keys(w.pending))
+		w.mu.Unlock()
+		for _, seq := range seqs {
+			w.mu.Lock()
+			v, ok := w.pending[seq]
+			w.mu.Unlock()
+			if !ok {
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+			w.mu.Lock()
+			err := w.commit(seq)
+			w.mu.Unlock()
+			if err != nil && err != ErrUnknownToken {
+				return
+			}
+		}
+	}
+}
+
+// Pending returns the number of uncommitted elements outside the queue,
+// i.e. those Replay would yield.
+func (w *WALQueue[T]) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Cap returns the queue capacity.
+func (w *WALQueue[T]) Cap() int {
+	return w.q.Cap()
+}
+
+// Close closes the file opened by Open. It does nothing for a queue
+// created by New.
+func (w *WALQueue[T]) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
@@ -0,0 +1,98 @@
+// ©Hayabusa Cloud Co., Ltd. 2026. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wal_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"code.hybscloud.com/lfq"
+	"code.hybscloud.com/lfq/wal"
+)
+
+// TestReplayUncommitted dequeues without committing and verifies Replay
+// yields the same items, committing them as it goes.
+func TestReplayUncommitted(t *testing.T) {
+	w, err := wal.New[string](8, new(bytes.Buffer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"a", "b", "c"} {
+		if err := w.Enqueue(&s); err != nil {
+			t.Fatalf("Enqueue(%q): %v", s, err)
+		}
+	}
+	var tokens []wal.DequeueToken
+	for range 3 {
+		_, tok, err := w.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue: %v", err)
+		}
+		tokens = append(tokens, tok)
+	}
+	if err := w.Commit(tokens[1]); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := w.Commit(tokens[1]); !errors.Is(err, wal.ErrUnknownToken) {
+		t.Fatalf("Commit twice: got %v, want ErrUnknownToken", err)
+	}
+
+	if got, want := slices.Collect(w.Replay()), []string{"a", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("Replay: got %v, want %v", got, want)
+	}
+	if n := w.Pending(); n != 0 {
+		t.Fatalf("Pending after Replay: got %d, want 0", n)
+	}
+	if _, _, err := w.Dequeue(); !errors.Is(err, lfq.ErrEmpty) {
+		t.Fatalf("Dequeue on empty: got %v, want ErrEmpty", err)
+	}
+}
+
+// TestReplayAfterRestart reopens a log file, as after a crash, and
+// verifies uncommitted items are replayed, including ones never dequeued,
+// and that a torn final record is skipped.
+func TestReplayAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+	w, err := wal.Open[int](8, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		w.Enqueue(&i)
+	}
+	_, tok, _ := w.Dequeue()
+	w.Commit(tok)
+	w.Dequeue()
+	w.Close()
+
+	// Simulate a crash in the middle of writing a record
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"enq","seq":5,"val`)
+	f.Close()
+
+	w, err = wal.Open[int](8, path)
+	if err != nil {
+		t.Fatalf("Open after crash: %v", err)
+	}
+	v := 9
+	w.Enqueue(&v)
+	w.Close()
+
+	w, err = wal.Open[int](8, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer w.Close()
+	if got, want := slices.Collect(w.Replay()), []int{1, 2, 3, 9}; !slices.Equal(got, want) {
+		t.Fatalf("Replay: got %v, want %v", got, want)
+	}
+}